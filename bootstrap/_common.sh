@@ -389,7 +389,7 @@ install_homebrew() {
         fi
 
         # Try to install Homebrew
-        if /bin/bash -c "$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)" 2>&1; then
+        if /bin/bash -c "$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)" 2>&1; then  # blackdot: allow-dangerous (official Homebrew installer)
             pass "Homebrew installed successfully"
             return 0
         fi
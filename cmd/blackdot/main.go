@@ -25,7 +25,7 @@ func main() {
 
 	// Execute root command
 	if err := cli.Execute(); err != nil {
-		// Error already printed by CLI, just exit
-		os.Exit(1)
+		// Error already printed by CLI; the exit status tells scripts its class
+		os.Exit(cli.ExitCode(err))
 	}
 }
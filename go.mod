@@ -9,9 +9,11 @@ require (
 	github.com/aymerick/raymond v2.0.2+incompatible
 	github.com/blackwell-systems/vaultmux v0.3.3
 	github.com/fatih/color v1.18.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.8.1
 	github.com/spf13/pflag v1.0.5
 	golang.org/x/crypto v0.45.0
+	golang.org/x/term v0.37.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -50,7 +50,13 @@ for encrypted_file in "$TEMPLATES_DIR"/*.age; do
     # Only decrypt if encrypted file is newer or decrypted doesn't exist
     if [[ ! -f "$decrypted_file" ]] || [[ "$encrypted_file" -nt "$decrypted_file" ]]; then
         echo "[hook] Decrypting: $(basename "$encrypted_file")"
-        age -d -i "$AGE_KEY_FILE" -o "$decrypted_file" "$encrypted_file"
+        # Files shared with 'blackdot encrypt --recipient' start with a
+        # one-line blackdot-age/v1 header before the age data
+        if [[ "$(head -c 15 "$encrypted_file")" == "blackdot-age/v1" ]]; then
+            tail -n +2 "$encrypted_file" | age -d -i "$AGE_KEY_FILE" -o "$decrypted_file"
+        else
+            age -d -i "$AGE_KEY_FILE" -o "$decrypted_file" "$encrypted_file"
+        fi
     fi
 done
 
@@ -37,10 +37,7 @@ type backupConfig struct {
 
 func getBackupConfig() *backupConfig {
 	home, _ := os.UserHomeDir()
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		blackdotDir = filepath.Join(home, ".blackdot")
-	}
+	blackdotDir, _ := resolveBlackdotDir()
 
 	return &backupConfig{
 		backupDir:   filepath.Join(home, ".blackdot-backups"),
@@ -372,7 +369,7 @@ func runBackupRestoreImpl(cmd *cobra.Command, args []string, dryRun bool) error
 			}
 		}
 		if backupPath == "" {
-			return fmt.Errorf("backup not found: %s", args[0])
+			return classErrorf(ErrFileNotFound, "backup not found: %s", args[0])
 		}
 	} else {
 		// Find latest backup by modification time
@@ -406,7 +403,7 @@ func runBackupRestoreImpl(cmd *cobra.Command, args []string, dryRun bool) error
 
 	// Check backup exists
 	if _, err := os.Stat(backupPath); err != nil {
-		return fmt.Errorf("backup not found: %s", backupPath)
+		return classErrorf(ErrFileNotFound, "backup not found: %s", backupPath)
 	}
 
 	fmt.Println()
@@ -1,13 +1,24 @@
 package cli
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/blackwell-systems/blackdot/internal/feature"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
 )
 
 // TestRootCommand verifies the root command is configured correctly
@@ -29,6 +40,7 @@ func TestRootCommand(t *testing.T) {
 func TestSubcommandExists(t *testing.T) {
 	expectedCommands := []string{
 		"version",
+		"self-update",
 		"features",
 		"config",
 		"doctor",
@@ -46,11 +58,13 @@ func TestSubcommandExists(t *testing.T) {
 		"metrics",
 		"packages",
 		"setup",
+		"init",
 		"sync",
 		"uninstall",
 		"tools",
 		"import",
 		"devcontainer",
+		"profile",
 	}
 
 	commands := make(map[string]bool)
@@ -74,6 +88,10 @@ func TestGlobalFlags(t *testing.T) {
 	}{
 		{"verbose flag", "verbose", "v"},
 		{"force flag", "force", ""},
+		{"log-level flag", "log-level", ""},
+		{"log-file flag", "log-file", ""},
+		{"print-paths flag", "print-paths", ""},
+		{"retries flag", "retries", ""},
 	}
 
 	for _, tt := range tests {
@@ -112,6 +130,53 @@ func TestBlackdotDir(t *testing.T) {
 	}
 }
 
+// TestProfiles verifies profile add/remove and --profile resolution
+func TestProfiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("BLACKDOT_DIR", "/from/env")
+	work := t.TempDir()
+
+	if err := runProfileAdd("work", work); err != nil {
+		t.Fatal(err)
+	}
+	if err := runProfileAdd("bad", filepath.Join(work, "missing")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+	if err := runProfileAdd("a/b", work); err == nil {
+		t.Error("expected error for invalid name")
+	}
+
+	initConfig()
+	profileName = "work"
+	defer func() { profileName = "" }()
+	if err := applyProfile(); err != nil {
+		t.Fatal(err)
+	}
+	if BlackdotDir() != work {
+		t.Errorf("BlackdotDir() = %q, want %q", BlackdotDir(), work)
+	}
+	if os.Getenv("BLACKDOT_DIR") != work {
+		t.Errorf("BLACKDOT_DIR = %q, want %q", os.Getenv("BLACKDOT_DIR"), work)
+	}
+
+	profileName = "personal"
+	err := applyProfile()
+	if err == nil || !strings.Contains(err.Error(), "valid: work") {
+		t.Errorf("expected unknown profile error listing work, got %v", err)
+	}
+
+	if err := runProfileRemove("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runProfileRemove("work"); err == nil {
+		t.Error("expected error removing unknown profile")
+	}
+	profiles, err := loadProfiles()
+	if err != nil || len(profiles) != 0 {
+		t.Errorf("expected no profiles after remove, got %v, %v", profiles, err)
+	}
+}
+
 // TestConfigDir verifies config directory resolution
 func TestConfigDir(t *testing.T) {
 	// Save original env
@@ -134,6 +199,38 @@ func TestConfigDir(t *testing.T) {
 	}
 }
 
+// TestResolvedPaths verifies the directories reported by --print-paths
+func TestResolvedPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "cfg"))
+
+	saved := blackdotDir
+	defer func() { blackdotDir = saved }()
+
+	blackdotDir = ""
+	dir, err := resolveBlackdotDir()
+	if err != nil || dir != filepath.Join(home, ".blackdot") {
+		t.Errorf("fallback: got %q, %v", dir, err)
+	}
+
+	blackdotDir = filepath.Join(home, "work")
+	var buf bytes.Buffer
+	if err := printResolvedPaths(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"blackdot dir: " + filepath.Join(home, "work"),
+		"config dir:   " + filepath.Join(home, "cfg", "blackdot"),
+		"cache dir:    " + filepath.Join(home, ".cache", "blackdot"),
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
 // TestCommandHasHelp verifies commands have help configured
 func TestCommandHasHelp(t *testing.T) {
 	commands := []string{"vault", "backup", "rollback", "features", "config"}
@@ -196,6 +293,57 @@ func TestVersionCommand(t *testing.T) {
 	}
 }
 
+// TestBuildVersionInfo verifies -ldflags values win and build info fills in
+// the rest
+func TestBuildVersionInfo(t *testing.T) {
+	oldVersion, oldCommit, oldDate := versionStr, commitStr, dateStr
+	t.Cleanup(func() { SetVersionInfo(oldVersion, oldCommit, oldDate) })
+
+	bi := &debug.BuildInfo{
+		GoVersion: "go1.24.2",
+		Main:      debug.Module{Path: "github.com/blackwell-systems/blackdot", Version: "v1.4.0"},
+		Deps: []*debug.Module{
+			{Path: "github.com/spf13/cobra", Version: "v1.8.0", Sum: "h1:abc="},
+			{Path: "example.com/old", Version: "v1.0.0", Replace: &debug.Module{Path: "example.com/fork", Version: "v1.0.1"}},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.time", Value: "2025-06-01T12:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	// Defaults from main: everything comes from the build info
+	SetVersionInfo("dev", "none", "unknown")
+	info := buildVersionInfo(bi, true)
+	if info.Version != "v1.4.0" || info.Commit != "0123456789abcdef" || info.Date != "2025-06-01T12:00:00Z" || !info.Modified {
+		t.Errorf("build info not used: %+v", info)
+	}
+	if info.GoVersion != "go1.24.2" || info.Platform != runtime.GOOS+"/"+runtime.GOARCH || info.Module != bi.Main.Path {
+		t.Errorf("unexpected toolchain fields: %+v", info)
+	}
+	wantDeps := []versionDependency{
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0", Sum: "h1:abc="},
+		{Path: "example.com/fork", Version: "v1.0.1"},
+	}
+	if !slices.Equal(info.Dependencies, wantDeps) {
+		t.Errorf("dependencies = %+v, want %+v", info.Dependencies, wantDeps)
+	}
+
+	// Release builds: -ldflags values win
+	SetVersionInfo("1.5.0", "abcd1234", "2025-07-01T00:00:00Z")
+	info = buildVersionInfo(bi, true)
+	if info.Version != "1.5.0" || info.Commit != "abcd1234" || info.Date != "2025-07-01T00:00:00Z" {
+		t.Errorf("ldflags values should win: %+v", info)
+	}
+
+	// No build info at all
+	info = buildVersionInfo(nil, false)
+	if info.Version != "1.5.0" || info.GoVersion != runtime.Version() || info.Dependencies != nil {
+		t.Errorf("without build info: %+v", info)
+	}
+}
+
 // TestToolsSubcommands verifies all tool categories are registered
 func TestToolsSubcommands(t *testing.T) {
 	// Find the tools command
@@ -255,6 +403,25 @@ func TestDockerSubcommands(t *testing.T) {
 	}
 }
 
+// TestComposeDownVolumesShorthand verifies -v means --volumes on compose
+// down without clashing with the global --verbose
+func TestComposeDownVolumesShorthand(t *testing.T) {
+	down, _, err := rootCmd.Find([]string{"tools", "docker", "compose", "down"})
+	if err != nil {
+		t.Fatalf("compose down not found: %v", err)
+	}
+	if err := down.ParseFlags([]string{"-v"}); err != nil {
+		t.Fatalf("parsing -v: %v", err)
+	}
+	defer down.Flags().Set("volumes", "false")
+	if f := down.Flags().Lookup("volumes"); f == nil || f.Shorthand != "v" || f.Value.String() != "true" {
+		t.Errorf("expected -v to set --volumes, got %+v", f)
+	}
+	if verbose {
+		t.Error("-v should not turn on --verbose")
+	}
+}
+
 // TestCDKSubcommands verifies CDK tool commands are registered
 func TestCDKSubcommands(t *testing.T) {
 	toolsCmd, _, _ := rootCmd.Find([]string{"tools"})
@@ -389,7 +556,8 @@ func TestSSHSubcommands(t *testing.T) {
 
 	expectedCommands := []string{
 		"keys", "gen", "list", "agent", "fp", "copy", "tunnel", "socks", "status",
-		"load", "unload", "clear", "tunnels", "add-host",
+		"load", "unload", "clear", "tunnels", "add-host", "export", "import",
+		"enable-multiplexing", "disable-multiplexing",
 	}
 
 	commands := make(map[string]bool)
@@ -403,3 +571,1018 @@ func TestSSHSubcommands(t *testing.T) {
 		}
 	}
 }
+
+// TestInitScaffold verifies init creates the expected layout
+func TestInitScaffold(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "repo")
+
+	if err := runInit(dir, false); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+
+	for _, d := range scaffoldDirs {
+		if info, err := os.Stat(filepath.Join(dir, d)); err != nil || !info.IsDir() {
+			t.Errorf("expected directory %s", d)
+		}
+	}
+	for _, f := range []string{"zsh/zshrc", "brew/Brewfile", "brew/Brewfile.minimal", "brew/Brewfile.enhanced", "powershell/packages.json"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f))); err != nil {
+			t.Errorf("expected file %s", f)
+		}
+	}
+
+	if result := validateJSON(filepath.Join(dir, "powershell", "packages.json")); len(result.errors) > 0 {
+		t.Errorf("packages.json skeleton is invalid: %v", result.errors)
+	}
+
+	// Second run refuses without --force
+	if err := runInit(dir, false); ExitCode(err) != ExitConflict {
+		t.Errorf("expected a conflict for non-empty directory without --force, got %v", err)
+	}
+
+	// --force adds missing starter files but keeps the user's own
+	zshrc := filepath.Join(dir, "zsh", "zshrc")
+	if err := os.WriteFile(zshrc, []byte("source ~/.zsh_custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "brew", "Brewfile")); err != nil {
+		t.Fatal(err)
+	}
+	if err := runInit(dir, true); err != nil {
+		t.Errorf("expected --force to succeed, got %v", err)
+	}
+	if data, _ := os.ReadFile(zshrc); string(data) != "source ~/.zsh_custom\n" {
+		t.Errorf("--force overwrote zsh/zshrc: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "brew", "Brewfile")); err != nil {
+		t.Errorf("--force should recreate a missing starter file: %v", err)
+	}
+}
+
+// TestCompleteVersion verifies the completion stamp is hidden and stable
+func TestCompleteVersion(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"__complete-version"})
+	if err != nil {
+		t.Fatalf("__complete-version command not found: %v", err)
+	}
+	if !cmd.Hidden {
+		t.Error("__complete-version should be hidden")
+	}
+
+	v1 := completionVersion(rootCmd)
+	v2 := completionVersion(rootCmd)
+	if v1 != v2 {
+		t.Errorf("completion version not stable: %s vs %s", v1, v2)
+	}
+	if !strings.HasPrefix(v1, versionStr+"+") {
+		t.Errorf("expected version prefix %q, got %q", versionStr, v1)
+	}
+
+	// Adding a command changes the stamp
+	root := &cobra.Command{Use: "root"}
+	before := completionVersion(root)
+	root.AddCommand(&cobra.Command{Use: "new", Run: func(*cobra.Command, []string) {}})
+	if completionVersion(root) == before {
+		t.Error("expected completion version to change when a command is added")
+	}
+}
+
+// TestRefreshCompletion verifies --refresh writes the script and stamp
+func TestRefreshCompletion(t *testing.T) {
+	original := os.Getenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", original)
+	os.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := refreshCompletion(rootCmd, "bash"); err != nil {
+		t.Fatalf("refreshCompletion failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(completionDir(), "blackdot.bash")); err != nil {
+		t.Errorf("expected completion script: %v", err)
+	}
+	stamp, err := os.ReadFile(filepath.Join(completionDir(), ".bash-version"))
+	if err != nil {
+		t.Fatalf("expected version stamp: %v", err)
+	}
+	if strings.TrimSpace(string(stamp)) != completionVersion(rootCmd) {
+		t.Errorf("stamp %q does not match %q", stamp, completionVersion(rootCmd))
+	}
+}
+
+// TestCompletionCommandName verifies --command-name renames the completion target
+func TestCompletionCommandName(t *testing.T) {
+	var buf bytes.Buffer
+	if err := genCompletionAs(rootCmd, "bash", "dotfiles", &buf); err != nil {
+		t.Fatal(err)
+	}
+	script := buf.String()
+	if !strings.Contains(script, "__start_dotfiles") || !strings.Contains(script, "-F __start_dotfiles dotfiles") {
+		t.Error("expected completion registered for dotfiles")
+	}
+	if strings.Contains(script, "__start_blackdot") {
+		t.Error("script still references blackdot functions")
+	}
+	if rootCmd.Name() != "blackdot" {
+		t.Errorf("root command name not restored: %q", rootCmd.Name())
+	}
+
+	cmd := newCompletionCmd()
+	cmd.SetArgs([]string{"bash", "--command-name", "bad name"})
+	cmd.SetOut(&buf)
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error for invalid command name")
+	}
+}
+
+// TestInstallCompletion verifies --install paths, shell detection, and --force
+func TestInstallCompletion(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("BASH_COMPLETION_USER_DIR", "")
+	fpath := filepath.Join(home, "zfunc")
+	origFpath := zshFirstFpath
+	defer func() { zshFirstFpath = origFpath }()
+	zshFirstFpath = func() (string, error) { return fpath, nil }
+
+	tests := []struct {
+		shell string
+		env   string
+		want  string
+	}{
+		{"zsh", "/bin/bash", filepath.Join(fpath, "_blackdot")},
+		{"", "/usr/bin/fish", filepath.Join(home, ".config", "fish", "completions", "blackdot.fish")},
+		{"", "/bin/bash", filepath.Join(home, ".local", "share", "bash-completion", "completions", "blackdot")},
+	}
+	for _, tt := range tests {
+		t.Setenv("SHELL", tt.env)
+		if err := installCompletion(rootCmd, tt.shell); err != nil {
+			t.Fatalf("installCompletion(%q) with SHELL=%s failed: %v", tt.shell, tt.env, err)
+		}
+		if info, err := os.Stat(tt.want); err != nil || info.Size() == 0 {
+			t.Errorf("expected script at %s: %v", tt.want, err)
+		}
+	}
+
+	// An existing script is only replaced with --force
+	t.Setenv("SHELL", "/bin/zsh")
+	if err := installCompletion(rootCmd, ""); err == nil {
+		t.Error("expected error overwriting without --force")
+	}
+	origForce := force
+	defer func() { force = origForce }()
+	force = true
+	if err := installCompletion(rootCmd, ""); err != nil {
+		t.Errorf("expected overwrite with --force, got %v", err)
+	}
+
+	t.Setenv("SHELL", "/bin/tcsh")
+	if err := installCompletion(rootCmd, ""); err == nil {
+		t.Error("expected error for unsupported $SHELL")
+	}
+}
+
+// TestParseLogLevel verifies --log-level values
+func TestParseLogLevel(t *testing.T) {
+	for _, level := range []string{"error", "warn", "warning", "info", "debug", "DEBUG"} {
+		if _, err := parseLogLevel(level); err != nil {
+			t.Errorf("parseLogLevel(%q) returned error: %v", level, err)
+		}
+	}
+	if _, err := parseLogLevel("trace"); err == nil {
+		t.Error("expected error for invalid log level")
+	}
+}
+
+// TestSetupLoggingFile verifies --log-file receives debug output
+func TestSetupLoggingFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "logs", "blackdot.log")
+	if err := setupLogging("debug", file); err != nil {
+		t.Fatalf("setupLogging failed: %v", err)
+	}
+	globLogged(filepath.Join(t.TempDir(), "*.zsh"))
+	closeLogging()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("reading log file: %v", err)
+	}
+	if !strings.Contains(string(data), "pattern=") {
+		t.Errorf("expected glob debug line in log, got %q", data)
+	}
+
+	// Restore the default logger for other tests
+	if err := setupLogging("error", ""); err != nil {
+		t.Fatalf("setupLogging failed: %v", err)
+	}
+}
+
+// TestSSHExportImport verifies an export bundle round-trips and excludes private keys
+func TestSSHExportImport(t *testing.T) {
+	src := t.TempDir()
+	pub := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl test@example\n"
+	files := map[string]string{
+		"config":         "Host example\n    HostName example.com\n",
+		"id_ed25519.pub": pub,
+		"id_ed25519":     "PRIVATE KEY DATA\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	archive := filepath.Join(t.TempDir(), "ssh.tar.gz")
+	if err := runSSHExport(src, archive, false); err != nil {
+		t.Fatalf("runSSHExport failed: %v", err)
+	}
+
+	manifest, contents, err := readSSHBundle(archive)
+	if err != nil {
+		t.Fatalf("readSSHBundle failed: %v", err)
+	}
+	if len(manifest.Files) != 2 {
+		t.Errorf("expected config and public key only, got %+v", manifest.Files)
+	}
+	if _, ok := contents["id_ed25519"]; ok {
+		t.Error("private key exported without --include-private")
+	}
+	for _, f := range manifest.Files {
+		if f.Kind == "public" && !strings.HasPrefix(f.Fingerprint, "SHA256:") {
+			t.Errorf("expected fingerprint for %s, got %q", f.Name, f.Fingerprint)
+		}
+	}
+
+	dst := t.TempDir()
+	if err := runSSHImport(archive, dst, false); err != nil {
+		t.Fatalf("runSSHImport failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "id_ed25519.pub")); string(data) != pub {
+		t.Errorf("public key not restored, got %q", data)
+	}
+
+	// Second import would clobber files
+	if err := runSSHImport(archive, dst, false); ExitCode(err) != ExitConflict {
+		t.Errorf("expected a conflict when overwriting without --force, got %v", err)
+	}
+	if err := runSSHImport(archive, dst, true); err != nil {
+		t.Errorf("expected --force import to succeed, got %v", err)
+	}
+}
+
+// writeSSHBundle writes an export archive holding manifest and contents
+func writeSSHBundle(t *testing.T, path string, manifest sshBundleManifest, contents map[string][]byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents[sshBundleManifestName] = data
+	for name, data := range contents {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSSHImportCorruptBundle verifies a public key that doesn't match its
+// manifest fingerprint, or an oversized entry, fails before anything is
+// written
+func TestSSHImportCorruptBundle(t *testing.T) {
+	pub := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl test@example\n"
+	other := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBnmVLLpXeE+rq0aa4UwAjFnUu2sxfNrz+NoI+SORs7d other@example\n"
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := sshBundleManifest{Files: []sshBundleFile{
+		{Name: "config", Kind: "config", Mode: 0600},
+		{Name: "id_ed25519.pub", Kind: "public", Mode: 0644, Fingerprint: ssh.FingerprintSHA256(pubKey)},
+	}}
+	archive := filepath.Join(t.TempDir(), "ssh.tar.gz")
+
+	tests := map[string]map[string][]byte{
+		"swapped key":   {"config": []byte("Host x\n"), "id_ed25519.pub": []byte(other)},
+		"truncated key": {"config": []byte("Host x\n"), "id_ed25519.pub": []byte(pub[:40])},
+		"huge entry":    {"config": bytes.Repeat([]byte("#"), sshBundleMaxEntry+1), "id_ed25519.pub": []byte(pub)},
+	}
+	for name, contents := range tests {
+		t.Run(name, func(t *testing.T) {
+			writeSSHBundle(t, archive, manifest, contents)
+			dst := t.TempDir()
+			if err := runSSHImport(archive, dst, false); err == nil {
+				t.Fatal("expected the corrupt bundle to be rejected")
+			}
+			if entries, _ := os.ReadDir(dst); len(entries) != 0 {
+				t.Errorf("files written from a corrupt bundle: %v", entries)
+			}
+		})
+	}
+
+	writeSSHBundle(t, archive, manifest, map[string][]byte{"config": []byte("Host x\n"), "id_ed25519.pub": []byte(pub)})
+	if err := runSSHImport(archive, t.TempDir(), false); err != nil {
+		t.Errorf("expected the intact bundle to import, got %v", err)
+	}
+}
+
+// TestCheckManagedLinks verifies each kind of symlink drift is classified
+func TestCheckManagedLinks(t *testing.T) {
+	t.Setenv("SKIP_CLAUDE_SETUP", "true")
+	home := t.TempDir()
+	dir := t.TempDir()
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "zsh", "zshrc"), "# zshrc\n")
+	write(filepath.Join(dir, "zsh", "p10k.zsh"), "# p10k\n")
+	write(filepath.Join(dir, "zellij", "config.kdl"), "// zellij\n")
+
+	statuses := func() (map[string]string, int) {
+		results, drifted := checkManagedLinks(home, dir)
+		m := make(map[string]string)
+		for _, r := range results {
+			m[r.Name] = r.Status
+		}
+		return m, drifted
+	}
+
+	// Nothing linked: only the required zshrc counts as missing
+	got, drifted := statuses()
+	if got["zshrc"] != linkMissing || drifted != 1 {
+		t.Fatalf("unlinked: got %v (drifted %d), want only zshrc missing", got, drifted)
+	}
+	if _, ok := got["p10k"]; ok {
+		t.Error("optional link that was never created should not be reported")
+	}
+
+	if err := os.Symlink(filepath.Join(dir, "zsh", "zshrc"), filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+	write(filepath.Join(home, ".p10k.zsh"), "# edited\n")
+	other := filepath.Join(t.TempDir(), "config.kdl")
+	write(other, "// elsewhere\n")
+	if err := os.MkdirAll(filepath.Join(home, ".config", "zellij"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(other, filepath.Join(home, ".config", "zellij", "config.kdl")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, drifted = statuses()
+	want := map[string]string{"zshrc": linkOK, "p10k": linkModified, "zellij": linkWrongTarget}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s: status %q, want %q", name, got[name], status)
+		}
+	}
+	if drifted != 2 {
+		t.Errorf("drifted = %d, want 2", drifted)
+	}
+
+	// A copy matching the source isn't drift; a dangling link is
+	os.Remove(filepath.Join(home, ".p10k.zsh"))
+	write(filepath.Join(home, ".p10k.zsh"), "# p10k\n")
+	os.Remove(other)
+	got, drifted = statuses()
+	if got["p10k"] != linkCopy || got["zellij"] != linkBroken || drifted != 1 {
+		t.Errorf("got %v (drifted %d), want p10k copy and zellij broken", got, drifted)
+	}
+}
+
+// TestDiffManagedFile verifies symlinks, copies, and edited copies are told apart
+func TestDiffManagedFile(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	source := filepath.Join(dir, "zsh", "zshrc")
+	if err := os.MkdirAll(filepath.Dir(source), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(source, []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l := managedLink{Name: "zshrc", Link: filepath.Join(home, ".zshrc"), Source: "zsh/zshrc"}
+
+	d, err := diffManagedFile(l, dir, home)
+	if err != nil || !d.Missing {
+		t.Fatalf("nothing deployed: got %+v, %v", d, err)
+	}
+
+	if err := os.Symlink(source, l.Link); err != nil {
+		t.Fatal(err)
+	}
+	d, err = diffManagedFile(l, dir, home)
+	if err != nil || !d.Linked || d.Diff != "" {
+		t.Fatalf("symlink: got %+v, %v", d, err)
+	}
+
+	os.Remove(l.Link)
+	if err := os.WriteFile(l.Link, []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err = diffManagedFile(l, dir, home)
+	if err != nil || d.Linked || d.Diff != "" {
+		t.Fatalf("identical copy: got %+v, %v", d, err)
+	}
+
+	if err := os.WriteFile(l.Link, []byte("a\nB\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err = diffManagedFile(l, dir, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"--- $BLACKDOT_DIR/zsh/zshrc", "+++ ~/.zshrc", "-b", "+B"} {
+		if !strings.Contains(d.Diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, d.Diff)
+		}
+	}
+}
+
+// TestSplitDiffLines verifies trailing newline handling for unified diffs
+func TestSplitDiffLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"a\nb\n", []string{"a\n", "b\n"}},
+		{"a\nb", []string{"a\n", "b\n\\ No newline at end of file\n"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := splitDiffLines(tt.in)
+		if len(got) != len(tt.want) {
+			t.Errorf("splitDiffLines(%q) = %q, want %q", tt.in, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("splitDiffLines(%q) = %q, want %q", tt.in, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// TestSetupColor verifies --color modes and NO_COLOR handling
+func TestSetupColor(t *testing.T) {
+	defer NoColor()
+
+	if err := setupColor("always"); err != nil || !IsColorEnabled() {
+		t.Errorf("always: err = %v, enabled = %v", err, IsColorEnabled())
+	}
+	if err := setupColor("never"); err != nil || IsColorEnabled() {
+		t.Errorf("never: err = %v, enabled = %v", err, IsColorEnabled())
+	}
+
+	// Test stdout is not a terminal, and NO_COLOR wins regardless
+	ForceColor()
+	t.Setenv("NO_COLOR", "1")
+	if err := setupColor("auto"); err != nil || IsColorEnabled() {
+		t.Errorf("auto with NO_COLOR: err = %v, enabled = %v", err, IsColorEnabled())
+	}
+
+	if err := setupColor("sometimes"); err == nil {
+		t.Error("expected an error for an invalid mode")
+	}
+}
+
+// TestDoctorFix verifies --fix repairs the config dir, Brewfile tiers, and
+// managed symlinks, and leaves a regular file in place of a link alone
+func TestDoctorFix(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	for _, f := range []string{"zsh/zshrc", "zsh/p10k.zsh", "brew/Brewfile"} {
+		p := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("# "+f+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// ~/.zshrc points somewhere stale, ~/.p10k.zsh is the user's own file
+	if err := os.Symlink(filepath.Join(home, "old", "zshrc"), filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".p10k.zsh"), []byte("local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newState := func() *doctorState {
+		s := fmt.Sprint
+		return &doctorState{bold: s, dim: s, red: s, green: s, yellow: s, blue: s, cyan: s}
+	}
+
+	// Without --fix nothing changes, but the repairs are counted as available
+	state := newState()
+	checkCoreComponents(state, home, dir, false)
+	if state.fixedCount != 0 || state.autoFixable != 4 {
+		t.Errorf("report only: fixed = %d, autoFixable = %d, want 0, 4", state.fixedCount, state.autoFixable)
+	}
+	if _, err := os.Stat(ConfigDir()); !os.IsNotExist(err) {
+		t.Error("config dir created without --fix")
+	}
+
+	state = newState()
+	checkCoreComponents(state, home, dir, true)
+	if state.fixedCount != 4 {
+		t.Errorf("fixed = %d, want 4", state.fixedCount)
+	}
+	if info, err := os.Stat(ConfigDir()); err != nil || !info.IsDir() {
+		t.Errorf("config dir not created: %v", err)
+	}
+	for _, name := range []string{"Brewfile.minimal", "Brewfile.enhanced"} {
+		if data, err := os.ReadFile(filepath.Join(dir, "brew", name)); err != nil || len(data) == 0 {
+			t.Errorf("brew/%s not regenerated: %v", name, err)
+		}
+	}
+	if got, _ := os.Readlink(filepath.Join(home, ".zshrc")); got != filepath.Join(dir, "zsh", "zshrc") {
+		t.Errorf("~/.zshrc -> %q, want the repo file", got)
+	}
+	if data, _ := os.ReadFile(filepath.Join(home, ".p10k.zsh")); string(data) != "local\n" {
+		t.Error("~/.p10k.zsh regular file was replaced")
+	}
+}
+
+// TestCheckPathConflicts verifies every blackdot on PATH is found and a
+// shadowing binary is reported with its version
+func TestCheckPathConflicts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as fake binaries")
+	}
+	tmp := t.TempDir()
+	noexec, shadow, other := filepath.Join(tmp, "noexec"), filepath.Join(tmp, "shadow"), filepath.Join(tmp, "other")
+	for dir, mode := range map[string]os.FileMode{noexec: 0644, shadow: 0755, other: 0755} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		script := "#!/bin/sh\necho 'blackdot 0.4.0 (Go CLI)'\n"
+		if err := os.WriteFile(filepath.Join(dir, "blackdot"), []byte(script), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pathList := strings.Join([]string{noexec, shadow, other, shadow}, string(os.PathListSeparator))
+	want := []string{filepath.Join(shadow, "blackdot"), filepath.Join(other, "blackdot")}
+	if got := lookPathAll("blackdot", pathList); !slices.Equal(got, want) {
+		t.Errorf("lookPathAll = %v, want %v", got, want)
+	}
+	if got := binaryVersion(want[0]); got != "blackdot 0.4.0 (Go CLI)" {
+		t.Errorf("binaryVersion = %q", got)
+	}
+
+	// The test binary isn't any of them, so the first one shadows it
+	t.Setenv("PATH", pathList)
+	s := fmt.Sprint
+	state := &doctorState{bold: s, dim: s, red: s, green: s, yellow: s, blue: s, cyan: s}
+	checkPathConflicts(state)
+	if state.checksWarned != 1 || !strings.Contains(state.warnChecks[0], want[0]+" (blackdot 0.4.0 (Go CLI)) shadows") {
+		t.Errorf("warnings = %q, want one about %s shadowing", state.warnChecks, want[0])
+	}
+}
+
+// TestDoctorBundle verifies the support bundle picks up the last lint and
+// health runs and scrubs the home directory and secrets
+func TestDoctorBundle(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	metrics := `{"timestamp":"2026-01-02T03:04:05+00:00","health_score":90,"warnings":2,"hostname":"alice-laptop"}` + "\n"
+	if err := os.WriteFile(filepath.Join(home, ".blackdot-metrics.jsonl"), []byte(metrics), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saveLintSummary(filepath.Join(home, ".blackdot"), lintStats{checked: 12, warnings: 3}, true, false)
+
+	bundle := collectDoctorBundle(home, filepath.Join(home, ".blackdot"))
+	if got := bundle.Paths["blackdot_dir"]; got != filepath.Join("~", ".blackdot") {
+		t.Errorf("blackdot_dir = %q, want home scrubbed", got)
+	}
+	if got := bundle.Paths["cache_dir"]; got != filepath.Join("~", ".cache", "blackdot") {
+		t.Errorf("cache_dir = %q", got)
+	}
+	if l := bundle.LastLint; l == nil || l.Checked != 12 || l.Warnings != 3 || !l.Passed || l.Dir != filepath.Join("~", ".blackdot") {
+		t.Errorf("last_lint = %+v", l)
+	}
+	if h := bundle.LastHealth; h == nil || h.HealthScore != 90 || h.Hostname != bundleRedacted {
+		t.Errorf("last_health = %+v", h)
+	}
+
+	env := bundleEnv([]string{
+		"SHELL=/bin/zsh",
+		"BLACKDOT_DIR=/src/dotfiles",
+		"BLACKDOT_GITHUB_TOKEN=ghp_secret",
+		"BW_SESSION=abc",
+		"AWS_SECRET_ACCESS_KEY=xyz",
+	})
+	want := map[string]string{
+		"SHELL":                 "/bin/zsh",
+		"BLACKDOT_DIR":          "/src/dotfiles",
+		"BLACKDOT_GITHUB_TOKEN": bundleRedacted,
+	}
+	if len(env) != len(want) {
+		t.Errorf("bundleEnv = %v, want %v", env, want)
+	}
+	for name, value := range want {
+		if env[name] != value {
+			t.Errorf("bundleEnv[%s] = %q, want %q", name, env[name], value)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeDoctorBundle(&buf, bundle); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), home) || strings.Contains(buf.String(), "alice-laptop") {
+		t.Errorf("bundle leaks the home directory or hostname:\n%s", buf.String())
+	}
+}
+
+// TestFeatureGraph verifies preset filtering and both output formats
+func TestFeatureGraph(t *testing.T) {
+	reg := feature.NewRegistry()
+
+	g, err := buildFeatureGraph(reg, "claude")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := g.dot()
+	for _, want := range []string{
+		`"claude_integration" -> "workspace_symlink";`,
+		`"preset:claude" -> "vault" [style=dotted];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot output missing %q", want)
+		}
+	}
+	// Not pulled in by claude
+	if strings.Contains(dot, `"aws_helpers"`) || strings.Contains(dot, "preset:full") {
+		t.Error("dot output includes features or presets outside the claude preset")
+	}
+
+	g, err = buildFeatureGraph(reg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mermaid := g.mermaid()
+	for _, want := range []string{
+		"graph LR\n",
+		"  dotclaude --> claude_integration\n",
+		"  preset_full ==>|extends| preset_developer\n",
+		"  preset_full -.-> templates\n",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("mermaid output missing %q", want)
+		}
+	}
+	// Inherited features hang off the parent preset only
+	if strings.Contains(mermaid, "preset_full -.-> vault\n") {
+		t.Error("full preset repeats a feature inherited from developer")
+	}
+
+	if _, err := buildFeatureGraph(reg, "nope"); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+}
+
+// TestFeatureWhy verifies the dependency chain and preset are explained
+func TestFeatureWhy(t *testing.T) {
+	reg := feature.NewRegistry()
+	if err := reg.ApplyPreset("claude"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Enable("dotclaude"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Enable("drift_check"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"drift_check", []string{"drift_check was enabled directly"}},
+		{"workspace_symlink", []string{
+			"workspace_symlink is in preset 'claude'",
+			"also required by: claude_integration",
+		}},
+		{"vault", []string{
+			"vault is in preset 'claude'",
+			"also required by: drift_check",
+		}},
+		{"shell", []string{
+			"shell is a core feature (always enabled)",
+			"part of the last applied preset 'claude'",
+		}},
+		{"templates", []string{"'templates' is disabled"}},
+	}
+	for _, tt := range tests {
+		if got := featureWhy(reg, tt.name); !slices.Equal(got, tt.expected) {
+			t.Errorf("featureWhy(%s) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+
+	// A dependency is traced to what pulled it in
+	reg = feature.NewRegistry()
+	if err := reg.Enable("dotclaude"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"workspace_symlink is a dependency of claude_integration",
+		"claude_integration is a dependency of dotclaude",
+		"dotclaude was enabled directly",
+	}
+	if got := featureWhy(reg, "workspace_symlink"); !slices.Equal(got, expected) {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+	reg.Disable("dotclaude")
+	expected = []string{"claude_integration is a dependency of dotclaude, which is now disabled"}
+	if got := featureWhy(reg, "claude_integration"); !slices.Equal(got, expected) {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+// TestSaveCurrentPreset verifies --save writes presets.json and protects
+// built-in and existing presets
+func TestSaveCurrentPreset(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer feature.SetCustomPresets(nil)
+
+	saved := registry
+	defer func() { registry = saved }()
+	registry = feature.NewRegistry()
+	if err := registry.ApplyPreset("minimal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Enable("vault"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveCurrentPreset("full", ""); err == nil {
+		t.Error("expected error saving over a built-in preset")
+	}
+	if err := saveCurrentPreset("bad name", ""); err == nil {
+		t.Error("expected error for invalid preset name")
+	}
+
+	if err := saveCurrentPreset("work", "Work laptop"); err != nil {
+		t.Fatalf("saveCurrentPreset failed: %v", err)
+	}
+	file, err := readCustomPresets(customPresetsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := file.Presets["work"]
+	if def.Description != "Work laptop" || !slices.Contains(def.Features, "vault") || !slices.Contains(def.Features, "shell") {
+		t.Errorf("unexpected saved preset: %+v", def)
+	}
+
+	// The loader picks it up, and the registry can apply it
+	if err := loadCustomPresets(); err != nil {
+		t.Fatal(err)
+	}
+	reg := feature.NewRegistry()
+	if err := reg.ApplyPreset("work"); err != nil || !reg.Enabled("vault") {
+		t.Errorf("applying saved preset: %v", err)
+	}
+
+	// Overwriting asks first; with no answer on stdin nothing changes
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	stdin := os.Stdin
+	os.Stdin = devNull
+	defer func() { os.Stdin = stdin }()
+
+	if err := registry.Disable("vault"); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveCurrentPreset("work", "Replaced"); err != nil {
+		t.Fatal(err)
+	}
+	file, _ = readCustomPresets(customPresetsPath())
+	if file.Presets["work"].Description != "Work laptop" {
+		t.Errorf("declined overwrite changed the preset: %+v", file.Presets["work"])
+	}
+}
+
+// TestPickPreset verifies the numbered preset prompt and that the picker
+// only lists presets when stdin isn't a terminal
+func TestPickPreset(t *testing.T) {
+	presets := feature.AllPresets()
+	var out bytes.Buffer
+	name, err := pickPreset(bufio.NewReader(strings.NewReader("2\n")), &out, presets)
+	if err != nil || name != presets[1].Name {
+		t.Errorf("expected %s, got %q (%v)", presets[1].Name, name, err)
+	}
+	if !strings.Contains(out.String(), presets[0].Description) || !strings.Contains(out.String(), fmt.Sprintf("(1-%d)", len(presets))) {
+		t.Errorf("expected numbered list with descriptions, got:\n%s", out.String())
+	}
+	for _, input := range []string{"0\n", "99\n", "developer\n", ""} {
+		if _, err := pickPreset(bufio.NewReader(strings.NewReader(input)), io.Discard, presets); err == nil {
+			t.Errorf("expected error for input %q", input)
+		}
+	}
+
+	saved := registry
+	defer func() { registry = saved }()
+	registry = feature.NewRegistry()
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	stdin := os.Stdin
+	os.Stdin = devNull
+	defer func() { os.Stdin = stdin }()
+
+	if err := runPresetPicker(false); err != nil {
+		t.Errorf("non-terminal stdin should list presets, got %v", err)
+	}
+	if registry.LastPreset() != "" {
+		t.Errorf("non-terminal picker applied preset %q", registry.LastPreset())
+	}
+}
+
+// TestPresetHistory verifies applying a preset appends an audit entry that
+// features history reads back, and that a failed write doesn't stop it
+func TestPresetHistory(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	saved := registry
+	defer func() { registry = saved }()
+	registry = feature.NewRegistry()
+
+	if err := applyPreset("minimal", false, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyPreset("developer", true, false); err != nil {
+		t.Fatal(err)
+	}
+
+	path := featuresAuditPath()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("audit log not written: %v", err)
+	}
+	f.WriteString("not json\n")
+	f.Close()
+
+	entries, skipped, err := readPresetAudit(path)
+	if err != nil || skipped != 1 || len(entries) != 2 {
+		t.Fatalf("expected 2 entries and 1 skipped line, got %d, %d (%v)", len(entries), skipped, err)
+	}
+	if entries[0].Preset != "minimal" || entries[0].Persisted {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	last := entries[1]
+	if last.Preset != "developer" || !last.Persisted || last.User == "" || last.Time.IsZero() {
+		t.Errorf("unexpected second entry: %+v", last)
+	}
+	if !slices.Contains(last.Features, "vault") || !slices.Contains(last.Features, "shell") {
+		t.Errorf("expected the enabled features, got %v", last.Features)
+	}
+
+	if err := showPresetHistory(1, true); err != nil {
+		t.Errorf("showPresetHistory failed: %v", err)
+	}
+
+	// An audit log that can't be written is ignored
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyPreset("claude", false, false); err != nil {
+		t.Errorf("apply should not fail when the audit log can't be written: %v", err)
+	}
+	if registry.LastPreset() != "claude" {
+		t.Errorf("expected claude applied, got %q", registry.LastPreset())
+	}
+
+	// -n means --dry-run elsewhere, so --limit has no shorthand
+	if f := newFeaturesHistoryCmd().Flags().Lookup("limit"); f == nil || f.Shorthand != "" {
+		t.Errorf("expected --limit without a shorthand, got %+v", f)
+	}
+}
+
+// TestSetupSteps verifies setup status reads progress without writing it
+func TestSetupSteps(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	cfg := &SetupConfig{
+		Version: 3,
+		Setup:   SetupState{Completed: []string{"workspace"}, Current: "symlinks"},
+		Paths:   PathsConfig{WorkspaceTarget: "/src"},
+		Vault:   VaultConfig{Backend: "none"},
+	}
+	steps := setupSteps(cfg)
+	if len(steps) != len(setupPhases) {
+		t.Fatalf("got %d steps, want %d", len(steps), len(setupPhases))
+	}
+
+	want := []setupStep{
+		{Step: 1, Name: "workspace", Status: setupStepComplete, Detail: "/src"},
+		{Step: 2, Name: "symlinks", Status: setupStepInProgress},
+		{Step: 3, Name: "packages", Status: setupStepPending},
+		{Step: 4, Name: "vault", Status: setupStepSkipped, Inferred: true},
+		{Step: 5, Name: "secrets", Status: setupStepPending},
+	}
+	for i, w := range want {
+		got := steps[i]
+		got.Description = ""
+		if got != w {
+			t.Errorf("step %d: got %+v, want %+v", i+1, got, w)
+		}
+	}
+
+	// Inference works on a copy
+	if !slices.Equal(cfg.Setup.Completed, []string{"workspace"}) {
+		t.Errorf("setupSteps changed the saved progress: %v", cfg.Setup.Completed)
+	}
+	if _, err := os.Stat(filepath.Join(ConfigDir(), "config.json")); !os.IsNotExist(err) {
+		t.Errorf("setupSteps wrote config.json: %v", err)
+	}
+}
+
+// TestExitCode verifies error classes map to exit statuses and keep their
+// messages
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"plain", fmt.Errorf("lint failed with 2 errors"), ExitFailure},
+		{"usage", classErrorf(ErrUsage, "--jobs must not be negative"), ExitUsage},
+		{"tool", classErrorf(ErrToolMissing, "age not installed"), ExitToolMissing},
+		{"file", classErrorf(ErrFileNotFound, "backup not found: x"), ExitFileNotFound},
+		{"config", classErrorf(ErrConfigInvalid, "failed to load config"), ExitConfigInvalid},
+		{"conflict", classErrorf(ErrConflict, "2 conflicts detected"), ExitConflict},
+		{"wrapped", fmt.Errorf("decrypting: %w", classErrorf(ErrToolMissing, "age not installed")), ExitToolMissing},
+		{"not exist", fmt.Errorf("reading: %w", os.ErrNotExist), ExitFileNotFound},
+		{"cobra", fmt.Errorf(`unknown command "x" for "blackdot"`), ExitUsage},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: ExitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	if err := classErrorf(ErrToolMissing, "age not installed"); err.Error() != "age not installed" {
+		t.Errorf("class changed the message: %q", err)
+	}
+	if withErrorClass(ErrUsage, nil) != nil {
+		t.Error("withErrorClass(nil) should be nil")
+	}
+
+	// Argument count and flag errors from any subcommand are usage errors
+	root := &cobra.Command{Use: "root"}
+	sub := &cobra.Command{Use: "sub", Args: cobra.ExactArgs(1), RunE: func(*cobra.Command, []string) error { return nil }}
+	sub.Flags().Int("n", 0, "")
+	root.AddCommand(sub)
+	root.SilenceErrors, root.SilenceUsage = true, true
+	classifyUsageErrors(root)
+	for _, args := range [][]string{{"sub"}, {"sub", "a", "--n", "x"}} {
+		root.SetArgs(args)
+		if err := root.Execute(); ExitCode(err) != ExitUsage {
+			t.Errorf("%v: got %v (exit %d), want a usage error", args, err, ExitCode(err))
+		}
+	}
+}
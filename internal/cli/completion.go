@@ -1,17 +1,74 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// completionFiles maps each shell to the file name written by --refresh
+var completionFiles = map[string]string{
+	"bash":       "blackdot.bash",
+	"zsh":        "_blackdot",
+	"fish":       "blackdot.fish",
+	"powershell": "blackdot.ps1",
+}
+
+// completionNamePattern limits --command-name to names that are safe in
+// shell function identifiers
+var completionNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// zshFpathTimeout bounds the interactive zsh started to read $fpath, in case
+// the user's zshrc prompts or hangs
+const zshFpathTimeout = 10 * time.Second
+
+// zshFirstFpath returns ${fpath[1]} as set up by the user's zsh config. A
+// variable so tests can avoid starting zsh.
+var zshFirstFpath = func() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), zshFpathTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "zsh", "-i", "-c", "print -r -- ${fpath[1]}").Output()
+	if err != nil {
+		return "", fmt.Errorf("reading $fpath from zsh: %w", err)
+	}
+	// zshrc may print its own output first; the answer is the last line
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	dir := strings.TrimSpace(lines[len(lines)-1])
+	if dir == "" {
+		return "", fmt.Errorf("zsh has an empty $fpath")
+	}
+	return dir, nil
+}
+
 func newCompletionCmd() *cobra.Command {
+	var refresh bool
+	var install bool
+	var commandName string
+
 	cmd := &cobra.Command{
 		Use:   "completion [bash|zsh|fish|powershell]",
 		Short: "Generate shell completion script",
 		Long: `Generate shell completion script for blackdot.
 
+Install:
+  # Write the script where your shell looks for it (shell from $SHELL)
+  blackdot completion --install
+
+  # Or name the shell; --force replaces an existing script
+  blackdot completion zsh --install --force
+
 Bash:
   # Add to ~/.bashrc or ~/.bash_profile
   source <(blackdot completion bash)
@@ -32,6 +89,13 @@ Zsh:
   # If shell completion is not already enabled:
   echo "autoload -U compinit; compinit" >> ~/.zshrc
 
+  # After upgrading blackdot, regenerate and clear the compinit cache
+  blackdot completion zsh --refresh
+
+Wrapper commands:
+  # Complete a function or alias that forwards its arguments to blackdot
+  source <(blackdot completion bash --command-name dotfiles)
+
 Fish:
   # Add to ~/.config/fish/completions/
   blackdot completion fish > ~/.config/fish/completions/blackdot.fish
@@ -41,24 +105,288 @@ PowerShell:
   blackdot completion powershell >> $PROFILE
 
   # Or load in current session
-  blackdot completion powershell | Out-String | Invoke-Expression`,
+  blackdot completion powershell | Out-String | Invoke-Expression
+
+Refresh:
+  --refresh writes the script to ~/.config/blackdot/completions/ along
+  with a version stamp. The blackdot zsh integration adds that directory
+  to fpath and re-runs the refresh when 'blackdot __complete-version'
+  no longer matches the stamp, so new commands appear after an upgrade.`,
 		DisableFlagsInUseLine: true,
 		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
-		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Args:                  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			switch args[0] {
-			case "bash":
-				return cmd.Root().GenBashCompletion(os.Stdout)
-			case "zsh":
-				return cmd.Root().GenZshCompletion(os.Stdout)
-			case "fish":
-				return cmd.Root().GenFishCompletion(os.Stdout, true)
-			case "powershell":
-				return cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+			if install {
+				if refresh || commandName != "" {
+					return fmt.Errorf("--install can't be combined with --refresh or --command-name")
+				}
+				shell := ""
+				if len(args) == 1 {
+					shell = args[0]
+				}
+				return installCompletion(cmd.Root(), shell)
 			}
-			return nil
+			if len(args) == 0 {
+				return fmt.Errorf("specify a shell: bash, zsh, fish, or powershell")
+			}
+			if commandName != "" {
+				if refresh {
+					return fmt.Errorf("--command-name can't be combined with --refresh")
+				}
+				if !completionNamePattern.MatchString(commandName) {
+					return fmt.Errorf("invalid command name: %q", commandName)
+				}
+				return genCompletionAs(cmd.Root(), args[0], commandName, os.Stdout)
+			}
+			if refresh {
+				return refreshCompletion(cmd.Root(), args[0])
+			}
+			return genCompletion(cmd.Root(), args[0], os.Stdout)
 		},
 	}
 
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Regenerate the installed script in ~/.config/blackdot/completions and clear caches")
+	cmd.Flags().BoolVar(&install, "install", false, "Write the script to the shell's completion directory (shell detected from $SHELL if not given)")
+	cmd.Flags().StringVar(&commandName, "command-name", "", "Generate completions for this command name instead of blackdot (e.g. a wrapper function)")
+
 	return cmd
 }
+
+// newCompleteVersionCmd prints a stamp that changes whenever the command tree
+// changes, so shells can tell when an installed completion script is stale
+func newCompleteVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "__complete-version",
+		Short:  "Print the completion script version stamp",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(completionVersion(cmd.Root()))
+		},
+	}
+}
+
+// genCompletion writes the completion script for shell to w
+func genCompletion(root *cobra.Command, shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(w, true)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(w)
+	}
+	return fmt.Errorf("unsupported shell: %s", shell)
+}
+
+// genCompletionAs writes a completion script registered under name. Cobra
+// derives every function name and the complete/compdef target from the root
+// command's name, so it is swapped for the duration of generation. Completion
+// requests then run "name __complete ...", which reaches blackdot through the
+// wrapper.
+func genCompletionAs(root *cobra.Command, shell, name string, w io.Writer) error {
+	use := root.Use
+	root.Use = name
+	defer func() { root.Use = use }()
+	return genCompletion(root, shell, w)
+}
+
+// completionDir returns where --refresh installs completion scripts
+func completionDir() string {
+	return filepath.Join(ConfigDir(), "completions")
+}
+
+// refreshCompletion regenerates the installed completion script and its
+// version stamp. For zsh it also removes compinit dump files, which otherwise
+// keep serving the old function list.
+func refreshCompletion(root *cobra.Command, shell string) error {
+	dir := completionDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating completion directory: %w", err)
+	}
+
+	path := filepath.Join(dir, completionFiles[shell])
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("writing completion script: %w", err)
+	}
+	if err := genCompletion(root, shell, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing completion script: %w", err)
+	}
+
+	stamp := filepath.Join(dir, "."+shell+"-version")
+	if err := os.WriteFile(stamp, []byte(completionVersion(root)+"\n"), 0644); err != nil {
+		return fmt.Errorf("writing version stamp: %w", err)
+	}
+
+	Pass("Wrote %s", path)
+
+	if shell == "zsh" {
+		zdotdir := os.Getenv("ZDOTDIR")
+		if zdotdir == "" {
+			zdotdir, _ = os.UserHomeDir()
+		}
+		dumps, _ := filepath.Glob(filepath.Join(zdotdir, ".zcompdump*"))
+		for _, dump := range dumps {
+			if err := os.Remove(dump); err == nil {
+				Debug("Removed %s", dump)
+			}
+		}
+		if len(dumps) > 0 {
+			Pass("Cleared compinit cache")
+		}
+		PrintHint("Restart your shell or run 'exec zsh' to load the new completions")
+	}
+
+	return nil
+}
+
+// detectShell returns the completion shell named by $SHELL
+func detectShell() (string, error) {
+	env := os.Getenv("SHELL")
+	name := filepath.Base(env)
+	switch name {
+	case "bash", "zsh", "fish":
+		return name, nil
+	case "pwsh", "powershell":
+		return "powershell", nil
+	}
+	if env == "" {
+		return "", fmt.Errorf("$SHELL is not set; specify a shell: bash, zsh, fish, or powershell")
+	}
+	return "", fmt.Errorf("unsupported shell in $SHELL: %s; specify a shell: bash, zsh, fish, or powershell", env)
+}
+
+// completionInstallPath returns where shell loads completion scripts from:
+// the first fpath entry for zsh, the bash-completion user directory for
+// bash, and fish's completions directory. PowerShell has no such directory,
+// so its script goes beside the --refresh scripts for $PROFILE to load.
+func completionInstallPath(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch shell {
+	case "zsh":
+		dir, err := zshFirstFpath()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "_blackdot"), nil
+	case "bash":
+		dir := os.Getenv("BASH_COMPLETION_USER_DIR")
+		if dir == "" {
+			data := os.Getenv("XDG_DATA_HOME")
+			if data == "" {
+				data = filepath.Join(home, ".local", "share")
+			}
+			dir = filepath.Join(data, "bash-completion")
+		}
+		return filepath.Join(dir, "completions", "blackdot"), nil
+	case "fish":
+		config := os.Getenv("XDG_CONFIG_HOME")
+		if config == "" {
+			config = filepath.Join(home, ".config")
+		}
+		return filepath.Join(config, "fish", "completions", "blackdot.fish"), nil
+	case "powershell":
+		return filepath.Join(completionDir(), completionFiles[shell]), nil
+	}
+	return "", fmt.Errorf("unsupported shell: %s", shell)
+}
+
+// installCompletion writes the completion script for shell (detected when
+// empty) to its conventional location, refusing to replace an existing
+// file unless --force is set
+func installCompletion(root *cobra.Command, shell string) error {
+	if shell == "" {
+		detected, err := detectShell()
+		if err != nil {
+			return err
+		}
+		shell = detected
+		Info("Detected shell: %s", shell)
+	}
+
+	path, err := completionInstallPath(shell)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return classErrorf(ErrConflict, "%s already exists (use --force to overwrite)", path)
+	}
+
+	var buf bytes.Buffer
+	if err := genCompletion(root, shell, &buf); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating completion directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing completion script: %w", err)
+	}
+
+	Pass("Installed %s completion to %s", shell, path)
+	switch shell {
+	case "zsh":
+		PrintHint("Restart your shell or run 'exec zsh'; if completions don't appear, run 'blackdot completion zsh --refresh' to clear the compinit cache")
+	case "bash":
+		PrintHint("New bash sessions load it through bash-completion")
+	case "fish":
+		PrintHint("New fish sessions load it automatically")
+	case "powershell":
+		PrintHint("Load it from $PROFILE by adding: . '%s'", path)
+	}
+	return nil
+}
+
+// completionVersion combines the binary version with a hash of every command
+// path and flag name, so dev builds with new commands also get a new stamp.
+// Cobra's lazily-added help command/flag and internal __ commands are skipped
+// so the stamp doesn't depend on which command is executing.
+func completionVersion(root *cobra.Command) string {
+	seen := make(map[string]bool)
+	var entries []string
+
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if strings.HasPrefix(c.Name(), "__") || (c.Name() == "help" && c.Parent() != nil) {
+			return
+		}
+		entries = append(entries, c.CommandPath())
+		// Flags() only holds inherited persistent flags once the command has
+		// executed, so skip anything an ancestor defines
+		addFlag := func(f *pflag.Flag) {
+			if f.Name == "help" {
+				return
+			}
+			for p := c.Parent(); p != nil; p = p.Parent() {
+				if p.PersistentFlags().Lookup(f.Name) == f {
+					return
+				}
+			}
+			entry := c.CommandPath() + " --" + f.Name
+			if !seen[entry] {
+				seen[entry] = true
+				entries = append(entries, entry)
+			}
+		}
+		c.PersistentFlags().VisitAll(addFlag)
+		c.Flags().VisitAll(addFlag)
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	sort.Strings(entries)
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return fmt.Sprintf("%s+%x", versionStr, sum[:4])
+}
@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -14,10 +16,10 @@ import (
 
 // DevcontainerImage represents a base image option
 type DevcontainerImage struct {
-	Name        string
-	Image       string
-	Description string
-	Extensions  []string // VS Code extensions to recommend
+	Name        string   `json:"name"`
+	Image       string   `json:"image"`
+	Description string   `json:"description"`
+	Extensions  []string `json:"extensions"` // VS Code extensions to recommend
 }
 
 // Common devcontainer base images from Microsoft
@@ -241,17 +243,20 @@ var serviceStacks = map[string][]string{
 
 // DevcontainerConfig represents the generated devcontainer.json
 type DevcontainerConfig struct {
-	Name              string                       `json:"name"`
-	Image             string                       `json:"image,omitempty"`
-	DockerComposeFile string                       `json:"dockerComposeFile,omitempty"`
-	Service           string                       `json:"service,omitempty"`
-	Features          map[string]map[string]string `json:"features"`
-	PostStartCommand  string                       `json:"postStartCommand"`
-	Customizations    *DevcontainerCustomizations  `json:"customizations,omitempty"`
-	RemoteUser        string                       `json:"remoteUser,omitempty"`
-	Mounts            []string                     `json:"mounts,omitempty"`
-	ContainerEnv      map[string]string            `json:"containerEnv,omitempty"`
-	WorkspaceFolder   string                       `json:"workspaceFolder,omitempty"`
+	Name                 string                       `json:"name"`
+	Image                string                       `json:"image,omitempty"`
+	DockerComposeFile    string                       `json:"dockerComposeFile,omitempty"`
+	Service              string                       `json:"service,omitempty"`
+	Features             map[string]map[string]string `json:"features"`
+	OnCreateCommand      string                       `json:"onCreateCommand,omitempty"`
+	UpdateContentCommand string                       `json:"updateContentCommand,omitempty"`
+	PostCreateCommand    string                       `json:"postCreateCommand,omitempty"`
+	PostStartCommand     string                       `json:"postStartCommand,omitempty"`
+	Customizations       *DevcontainerCustomizations  `json:"customizations,omitempty"`
+	RemoteUser           string                       `json:"remoteUser,omitempty"`
+	Mounts               []string                     `json:"mounts,omitempty"`
+	ContainerEnv         map[string]string            `json:"containerEnv,omitempty"`
+	WorkspaceFolder      string                       `json:"workspaceFolder,omitempty"`
 }
 
 type DevcontainerCustomizations struct {
@@ -279,20 +284,79 @@ configuration into containerized environments.`,
 		newDevcontainerInitCmd(),
 		newDevcontainerImagesCmd(),
 		newDevcontainerServicesCmd(),
+		newDevcontainerDoctorCmd(),
 	)
 
 	return cmd
 }
 
+// devcontainerInitOptions holds the resolved flags for devcontainer init
+type devcontainerInitOptions struct {
+	Image        string
+	Preset       string
+	OutputDir    string
+	Name         string // Write to OutputDir/Name/ as a named configuration
+	Force        bool
+	NoExtensions bool
+	NoGuard      bool // Skip the blackdot-on-PATH check in postStartCommand
+	Services     []string
+	Print        bool                // Write devcontainer.json to stdout instead of OutputDir
+	Images       []DevcontainerImage // Catalog to select from; built-in list if empty
+	CatalogInfo  string              // Where Images came from, shown in the header
+	FromProject  bool                // Pick the image from files in ProjectDir
+	ProjectDir   string              // Directory --from-project inspects; current directory if empty
+	Hooks        devcontainerLifecycleHooks
+	PinDigest    bool   // Reference the feature by the digest :1 resolves to now
+	MountRepo    string // Host blackdot checkout to bind-mount at devcontainerRepoTarget
+	MinimalJSON  bool   // Write only name, image, and features
+}
+
+// devcontainerLifecycleHooks are the extra lifecycle commands set with
+// --on-create, --post-create and --update-content; empty ones are omitted
+type devcontainerLifecycleHooks struct {
+	OnCreate      string
+	PostCreate    string
+	UpdateContent string
+}
+
+// devcontainerHookFlags are the init flags that set lifecycle hooks
+var devcontainerHookFlags = []string{"on-create", "post-create", "update-content"}
+
+// validateDevcontainerHookFlags rejects a lifecycle hook flag given an
+// empty command, which would otherwise be silently dropped
+func validateDevcontainerHookFlags(cmd *cobra.Command) error {
+	for _, name := range devcontainerHookFlags {
+		value, _ := cmd.Flags().GetString(name)
+		if cmd.Flags().Changed(name) && strings.TrimSpace(value) == "" {
+			return fmt.Errorf("--%s requires a command", name)
+		}
+	}
+	return nil
+}
+
+// projectImageSignals map files that identify a project's language to an
+// image short name, in priority order. package.json comes last because it
+// often sits alongside other languages just for tooling.
+var projectImageSignals = []struct {
+	file  string
+	image string
+}{
+	{"go.mod", "go"},
+	{"Cargo.toml", "rust"},
+	{"pyproject.toml", "python"},
+	{"requirements.txt", "python"},
+	{"pom.xml", "java"},
+	{"package.json", "node"},
+}
+
+// devcontainerNamePattern limits --name to a single directory name
+var devcontainerNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
 func newDevcontainerInitCmd() *cobra.Command {
 	var (
-		image    string
-		preset   string
-		output   string
-		force    bool
-		noVSExt  bool
-		services []string
-		stack    string
+		opts       devcontainerInitOptions
+		stack      string
+		catalogURL string
 	)
 
 	cmd := &cobra.Command{
@@ -316,10 +380,49 @@ Available stacks (predefined service combinations):
 Examples:
   blackdot devcontainer init                              # Interactive mode
   blackdot devcontainer init --image go --preset developer
+  blackdot devcontainer init --from-project               # Image from go.mod, Cargo.toml, ...
   blackdot devcontainer init --image go --stack web       # Use predefined stack
   blackdot devcontainer init --image go --services postgres,redis
-  blackdot devcontainer init --image node --services postgres,redis,localstack`,
+  blackdot devcontainer init --image node --services postgres,redis,localstack
+  blackdot devcontainer init --image go --preset minimal --print | jq .
+  blackdot devcontainer init --image go --post-create "go mod download"
+  blackdot devcontainer init --image go --preset minimal --pin-digest
+
+Pinning:
+  --pin-digest asks ghcr.io which manifest the feature's :1 tag points at
+  and writes ghcr.io/blackwell-systems/blackdot@sha256:... instead, so the
+  container builds with exactly that release until you regenerate. If the
+  registry can't be reached, the :1 tag is written with a warning.
+
+Working on blackdot:
+  --mount-repo <path> bind-mounts a blackdot checkout on this host at
+  /blackdot in the container and sets BLACKDOT_DIR there, so changes to
+  the repo show up in the container without a rebuild. The path is baked
+  into devcontainer.json, so the result only works on this host; don't
+  commit it.
+
+  blackdot devcontainer init --image go --preset minimal --mount-repo ~/.blackdot
+
+Minimal configuration:
+  --minimal-json writes only name, image, and the blackdot feature: no
+  SSH agent mount, containerEnv, remoteUser, VS Code extensions, or
+  postStartCommand, for base images or dotfiles that already handle
+  them. Run 'blackdot setup' yourself once the container is up. It can't
+  be combined with options that need the omitted fields (--services,
+  --stack, --mount-repo, --on-create, --update-content, --post-create).
+
+  blackdot devcontainer init --image go --preset minimal --minimal-json
+
+Named configurations:
+  --name writes .devcontainer/<name>/devcontainer.json instead, so a repo
+  can offer several containers (e.g. minimal and full) to pick from.
+
+  blackdot devcontainer init --image go --preset minimal --name minimal
+  blackdot devcontainer init --image go --preset full --stack web --name full`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateDevcontainerHookFlags(cmd); err != nil {
+				return err
+			}
 			// Expand stack to services if specified
 			if stack != "" {
 				stackServices, ok := serviceStacks[stack]
@@ -328,37 +431,58 @@ Examples:
 					for k := range serviceStacks {
 						validStacks = append(validStacks, k)
 					}
-					return fmt.Errorf("unknown stack: %s (valid: %s)", stack, strings.Join(validStacks, ", "))
+					return classErrorf(ErrUsage, "unknown stack: %s (valid: %s)", stack, strings.Join(validStacks, ", "))
 				}
-				services = append(services, stackServices...)
+				opts.Services = append(opts.Services, stackServices...)
 			}
-			return runDevcontainerInit(image, preset, output, force, noVSExt, services)
+			opts.Images, opts.CatalogInfo = loadImageCatalog(catalogURL)
+			return runDevcontainerInit(opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&image, "image", "", "Base image (go, rust, python, node, java, ubuntu, alpine, debian)")
-	cmd.Flags().StringVar(&preset, "preset", "", "Blackdot preset (minimal, developer, claude, full)")
-	cmd.Flags().StringVarP(&output, "output", "o", ".devcontainer", "Output directory")
-	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing configuration")
-	cmd.Flags().BoolVar(&noVSExt, "no-extensions", false, "Skip VS Code extension recommendations")
-	cmd.Flags().StringSliceVar(&services, "services", nil, "Supporting services (postgres, redis, mysql, mongo, sqlite, localstack, minio)")
+	cmd.Flags().StringVar(&opts.Image, "image", "", "Base image (go, rust, python, node, java, ubuntu, alpine, debian)")
+	cmd.Flags().BoolVar(&opts.FromProject, "from-project", false, "Pick the base image from project files (go.mod, Cargo.toml, package.json, ...)")
+	cmd.Flags().StringVar(&opts.Preset, "preset", "", "Blackdot preset (minimal, developer, claude, full)")
+	cmd.Flags().StringVarP(&opts.OutputDir, "output", "o", ".devcontainer", "Output directory")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Write a named configuration to <output>/<name>/devcontainer.json")
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Overwrite existing configuration")
+	cmd.Flags().BoolVar(&opts.NoExtensions, "no-extensions", false, "Skip VS Code extension recommendations")
+	cmd.Flags().BoolVar(&opts.NoGuard, "no-guard", false, "Don't check that blackdot is on PATH before postStartCommand runs setup")
+	cmd.Flags().StringSliceVar(&opts.Services, "services", nil, "Supporting services (postgres, redis, mysql, mongo, sqlite, localstack, minio)")
 	cmd.Flags().StringVar(&stack, "stack", "", "Predefined service stack (web, api, aws, full, mongo)")
+	cmd.Flags().BoolVar(&opts.Print, "print", false, "Print devcontainer.json to stdout without writing files (requires --image and --preset)")
+	cmd.Flags().StringVar(&opts.Hooks.OnCreate, "on-create", "", "Command to run once when the container is created (onCreateCommand)")
+	cmd.Flags().StringVar(&opts.Hooks.UpdateContent, "update-content", "", "Command to run after onCreateCommand and when content updates (updateContentCommand)")
+	cmd.Flags().StringVar(&opts.Hooks.PostCreate, "post-create", "", "Command to run once the container is assigned to a user (postCreateCommand)")
+	cmd.Flags().StringVar(&catalogURL, "catalog-url", defaultImageCatalogURL, "Image catalog URL (empty for built-in list)")
+	cmd.Flags().BoolVar(&opts.PinDigest, "pin-digest", false, "Reference the blackdot feature by its current digest instead of the :1 tag")
+	cmd.Flags().StringVar(&opts.MountRepo, "mount-repo", "", "Bind-mount this host blackdot checkout at "+devcontainerRepoTarget+" and set BLACKDOT_DIR to it")
+	cmd.Flags().BoolVar(&opts.MinimalJSON, "minimal-json", false, "Write only name, image, and the blackdot feature (no mounts, env, or customizations)")
 
 	return cmd
 }
 
 func newDevcontainerImagesCmd() *cobra.Command {
-	return &cobra.Command{
+	var catalogURL string
+
+	cmd := &cobra.Command{
 		Use:   "images",
 		Short: "List available base images",
-		Long:  `List all available Microsoft devcontainer base images.`,
+		Long: `List all available Microsoft devcontainer base images.
+
+The list is fetched from --catalog-url and cached for 24 hours under
+~/.cache/blackdot/images.json. When the catalog can't be fetched, the
+cached copy or the built-in list is used instead.`,
 		Run: func(cmd *cobra.Command, args []string) {
+			images, source := loadImageCatalog(catalogURL)
+
 			fmt.Println()
 			BoldCyan.Println("Available Devcontainer Base Images")
 			fmt.Println(strings.Repeat("─", 50))
+			Dim.Printf("Source: %s\n", source)
 			fmt.Println()
 
-			for i, img := range devcontainerImages {
+			for i, img := range images {
 				fmt.Printf("  %d. ", i+1)
 				Bold.Print(img.Name)
 				fmt.Println()
@@ -368,6 +492,10 @@ func newDevcontainerImagesCmd() *cobra.Command {
 			}
 		},
 	}
+
+	cmd.Flags().StringVar(&catalogURL, "catalog-url", defaultImageCatalogURL, "Image catalog URL (empty for built-in list)")
+
+	return cmd
 }
 
 func newDevcontainerServicesCmd() *cobra.Command {
@@ -412,31 +540,98 @@ func newDevcontainerServicesCmd() *cobra.Command {
 	}
 }
 
-func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt bool, servicesFlag []string) error {
-	fmt.Println()
-	BoldCyan.Println("Blackdot Devcontainer Setup")
-	fmt.Println(strings.Repeat("═", 30))
-	fmt.Println()
+func runDevcontainerInit(opts devcontainerInitOptions) error {
+	// In print mode stdout carries only the JSON, so prompts can't be shown
+	// and anything informational goes to stderr
+	var msgOut io.Writer = os.Stdout
+	if opts.MinimalJSON {
+		switch {
+		case len(opts.Services) > 0:
+			return classErrorf(ErrUsage, "--minimal-json can't be used with --services or --stack")
+		case opts.MountRepo != "":
+			return classErrorf(ErrUsage, "--minimal-json can't be used with --mount-repo")
+		case opts.Hooks != (devcontainerLifecycleHooks{}):
+			return classErrorf(ErrUsage, "--minimal-json can't be used with --on-create, --update-content, or --post-create")
+		}
+	}
+	if opts.Print {
+		if (opts.Image == "" && !opts.FromProject) || opts.Preset == "" {
+			return classErrorf(ErrUsage, "--print requires --image and --preset")
+		}
+		msgOut = os.Stderr
+	} else {
+		if opts.Name != "" {
+			if !devcontainerNamePattern.MatchString(opts.Name) {
+				return classErrorf(ErrUsage, "invalid configuration name: %q (use letters, digits, '.', '-' and '_')", opts.Name)
+			}
+			opts.OutputDir = filepath.Join(opts.OutputDir, opts.Name)
+		}
+
+		// Catch a bad --output before any prompts
+		dir, err := checkOutputDir(opts.OutputDir)
+		if err != nil {
+			return err
+		}
+		opts.OutputDir = dir
+
+		fmt.Println()
+		BoldCyan.Println("Blackdot Devcontainer Setup")
+		fmt.Println(strings.Repeat("═", 30))
+		fmt.Println()
+	}
+
+	if opts.MountRepo != "" {
+		hostPath, err := resolveRepoMount(opts.MountRepo)
+		if err != nil {
+			return err
+		}
+		opts.MountRepo = hostPath
+		Yellow.Fprintf(msgOut, "[WARN] ")
+		fmt.Fprintf(msgOut, "--mount-repo ties this configuration to %s on this host; don't commit it\n\n", hostPath)
+	}
+
+	if len(opts.Images) == 0 {
+		opts.Images = devcontainerImages
+	}
+	if opts.CatalogInfo != "" && !opts.Print {
+		Dim.Printf("Image catalog: %s\n\n", opts.CatalogInfo)
+	}
+
+	// Infer the image from the project unless one was given
+	if opts.FromProject && opts.Image == "" {
+		dir := opts.ProjectDir
+		if dir == "" {
+			dir = "."
+		}
+		if name, signal := detectProjectImage(dir, opts.Images); name != "" {
+			fmt.Fprintf(msgOut, "Found %s, using the %s image\n\n", signal, name)
+			opts.Image = name
+		} else if opts.Print {
+			return classErrorf(ErrUsage, "--from-project found no go.mod, Cargo.toml, pyproject.toml, requirements.txt, pom.xml, or package.json; pass --image")
+		} else {
+			Dim.Println("No project files recognized, choose an image")
+			fmt.Println()
+		}
+	}
 
 	// Select image
 	var selectedImage DevcontainerImage
-	if imageFlag != "" {
+	if opts.Image != "" {
 		// Find image by short name
 		found := false
-		for _, img := range devcontainerImages {
-			shortName := strings.ToLower(strings.Split(img.Name, " ")[0])
-			if strings.ToLower(imageFlag) == shortName {
+		for _, img := range opts.Images {
+			if strings.ToLower(opts.Image) == devcontainerImageShortName(img) {
 				selectedImage = img
 				found = true
 				break
 			}
 		}
 		if !found {
-			return fmt.Errorf("unknown image: %s (use 'blackdot devcontainer images' to list available images)", imageFlag)
+			return classErrorf(ErrUsage, "unknown image: %s (use 'blackdot devcontainer images' to list available images)", opts.Image)
 		}
 	} else {
 		// Interactive selection
-		img, err := selectImage()
+		img, err := selectImage(opts.Images)
 		if err != nil {
 			return err
 		}
@@ -445,18 +640,18 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 
 	// Select preset
 	var selectedPreset string
-	if presetFlag != "" {
+	if opts.Preset != "" {
 		// Validate preset
 		valid := false
 		for _, p := range devcontainerPresets {
-			if strings.ToLower(presetFlag) == p.Name {
+			if strings.ToLower(opts.Preset) == p.Name {
 				selectedPreset = p.Name
 				valid = true
 				break
 			}
 		}
 		if !valid {
-			return fmt.Errorf("unknown preset: %s (valid: minimal, developer, claude, full)", presetFlag)
+			return classErrorf(ErrUsage, "unknown preset: %s (valid: minimal, developer, claude, full)", opts.Preset)
 		}
 	} else {
 		// Interactive selection
@@ -470,8 +665,8 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 	// Validate and resolve services
 	var selectedServices []DevcontainerService
 	selectedServiceNames := make(map[string]bool)
-	if len(servicesFlag) > 0 {
-		for _, svcName := range servicesFlag {
+	if len(opts.Services) > 0 {
+		for _, svcName := range opts.Services {
 			// Skip duplicates
 			if selectedServiceNames[strings.ToLower(svcName)] {
 				continue
@@ -486,7 +681,7 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 				}
 			}
 			if !found {
-				return fmt.Errorf("unknown service: %s (use 'blackdot devcontainer services' to list available services)", svcName)
+				return classErrorf(ErrUsage, "unknown service: %s (use 'blackdot devcontainer services' to list available services)", svcName)
 			}
 		}
 
@@ -502,24 +697,60 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 					}
 					if !warnedPairs[pair] {
 						warnedPairs[pair] = true
-						Yellow.Printf("[WARN] ")
-						fmt.Printf("Services '%s' and '%s' both set DATABASE_URL\n", svc.Name, conflict)
-						fmt.Printf("       The last service's DATABASE_URL will be used.\n")
-						fmt.Println()
+						Yellow.Fprintf(msgOut, "[WARN] ")
+						fmt.Fprintf(msgOut, "Services '%s' and '%s' both set DATABASE_URL\n", svc.Name, conflict)
+						fmt.Fprintf(msgOut, "       The last service's DATABASE_URL will be used.\n")
+						fmt.Fprintln(msgOut)
 					}
 				}
 			}
 		}
 	}
 
+	logger.Debug("devcontainer init", "image", selectedImage.Image, "preset", selectedPreset,
+		"services", len(selectedServices), "output", opts.OutputDir, "print", opts.Print)
+
+	featureRef := devcontainerFeatureRef
+	if opts.PinDigest {
+		pinned, err := pinFeatureDigest(devcontainerFeatureRef)
+		if err != nil {
+			Warn("Could not resolve the feature digest, using %s: %v", devcontainerFeatureRef, err)
+		} else {
+			featureRef = pinned
+		}
+	}
+
+	if opts.Print {
+		var config DevcontainerConfig
+		if len(selectedServices) > 0 {
+			config = generateDevcontainerConfigWithCompose(selectedImage, selectedPreset, opts.NoExtensions, opts.NoGuard, opts.Hooks, selectedServices)
+			Dim.Fprintln(os.Stderr, "Note: docker-compose.yml and .env.example are not printed")
+		} else {
+			config = generateDevcontainerConfig(selectedImage, selectedPreset, opts.NoExtensions, opts.NoGuard, opts.Hooks)
+		}
+		config.setFeatureRef(featureRef)
+		if opts.MinimalJSON {
+			config.minimize()
+		}
+		if opts.MountRepo != "" {
+			config.mountRepo(opts.MountRepo)
+		}
+		jsonData, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling config: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
 	// Check output directory
-	devcontainerPath := filepath.Join(outputDir, "devcontainer.json")
-	if _, err := os.Stat(devcontainerPath); err == nil && !force {
-		return fmt.Errorf("devcontainer.json already exists (use --force to overwrite)")
+	devcontainerPath := filepath.Join(opts.OutputDir, "devcontainer.json")
+	if _, err := os.Stat(devcontainerPath); err == nil && !opts.Force {
+		return classErrorf(ErrConflict, "devcontainer.json already exists (use --force to overwrite)")
 	}
 
 	// Create output directory
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
 		return fmt.Errorf("creating output directory: %w", err)
 	}
 
@@ -527,10 +758,10 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 	var config DevcontainerConfig
 	if len(selectedServices) > 0 {
 		// Generate docker-compose based config
-		config = generateDevcontainerConfigWithCompose(selectedImage, selectedPreset, noVSExt, selectedServices)
+		config = generateDevcontainerConfigWithCompose(selectedImage, selectedPreset, opts.NoExtensions, opts.NoGuard, opts.Hooks, selectedServices)
 
 		// Generate docker-compose.yml
-		composePath := filepath.Join(outputDir, "docker-compose.yml")
+		composePath := filepath.Join(opts.OutputDir, "docker-compose.yml")
 		composeContent := generateDockerCompose(selectedImage, selectedServices)
 		if err := os.WriteFile(composePath, []byte(composeContent), 0644); err != nil {
 			return fmt.Errorf("writing docker-compose.yml: %w", err)
@@ -538,7 +769,7 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 		Pass("Generated %s", composePath)
 
 		// Generate .env.example
-		envPath := filepath.Join(outputDir, ".env.example")
+		envPath := filepath.Join(opts.OutputDir, ".env.example")
 		envContent := generateEnvExample(selectedServices)
 		if err := os.WriteFile(envPath, []byte(envContent), 0644); err != nil {
 			return fmt.Errorf("writing .env.example: %w", err)
@@ -546,7 +777,14 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 		Pass("Generated %s", envPath)
 	} else {
 		// Generate simple image-based config
-		config = generateDevcontainerConfig(selectedImage, selectedPreset, noVSExt)
+		config = generateDevcontainerConfig(selectedImage, selectedPreset, opts.NoExtensions, opts.NoGuard, opts.Hooks)
+	}
+	config.setFeatureRef(featureRef)
+	if opts.MinimalJSON {
+		config.minimize()
+	}
+	if opts.MountRepo != "" {
+		config.mountRepo(opts.MountRepo)
 	}
 
 	// Write devcontainer.json
@@ -568,8 +806,18 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 	Dim.Println("Configuration:")
 	fmt.Printf("  Image:  %s\n", selectedImage.Image)
 	fmt.Printf("  Preset: %s\n", selectedPreset)
-	fmt.Printf("  SSH agent forwarding: enabled\n")
-	if len(selectedImage.Extensions) > 0 && !noVSExt {
+	if featureRef != devcontainerFeatureRef {
+		fmt.Printf("  Feature: %s\n", featureRef)
+	}
+	if opts.MinimalJSON {
+		fmt.Printf("  Minimal JSON: no SSH agent forwarding, env, extensions, or postStartCommand\n")
+	} else {
+		fmt.Printf("  SSH agent forwarding: enabled\n")
+	}
+	if opts.MountRepo != "" {
+		fmt.Printf("  Repo mount: %s -> %s (BLACKDOT_DIR)\n", opts.MountRepo, devcontainerRepoTarget)
+	}
+	if len(selectedImage.Extensions) > 0 && !opts.NoExtensions && !opts.MinimalJSON {
 		fmt.Printf("  VS Code extensions: %s\n", strings.Join(selectedImage.Extensions, ", "))
 	}
 	if len(selectedServices) > 0 {
@@ -583,8 +831,16 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 
 	// Next steps
 	BoldCyan.Println("Next steps:")
-	fmt.Println("  1. Commit .devcontainer/ to your repository")
-	fmt.Println("  2. Open in VS Code or GitHub Codespaces")
+	if opts.MountRepo != "" {
+		fmt.Println("  1. Keep .devcontainer/ local: it mounts a path on this host")
+	} else {
+		fmt.Println("  1. Commit .devcontainer/ to your repository")
+	}
+	if opts.Name != "" {
+		fmt.Printf("  2. Open in VS Code or GitHub Codespaces and pick the '%s' configuration\n", opts.Name)
+	} else {
+		fmt.Println("  2. Open in VS Code or GitHub Codespaces")
+	}
 	fmt.Println("  3. Run 'blackdot setup' when the container starts")
 	if len(selectedServices) > 0 {
 		fmt.Println("  4. Copy .env.example to .env and customize if needed")
@@ -594,18 +850,77 @@ func runDevcontainerInit(imageFlag, presetFlag, outputDir string, force, noVSExt
 	return nil
 }
 
-func selectImage() (DevcontainerImage, error) {
+// checkOutputDir expands a leading ~ in dir, makes it absolute, and checks
+// that it (or the closest parent that exists, where it will be created) is
+// a writable directory
+func checkOutputDir(dir string) (string, error) {
+	abs, err := expandDirArg(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving output directory %s: %w", dir, err)
+	}
+
+	existing := abs
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("output directory %s: %s is not a directory", abs, existing)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("output directory %s: %w", abs, err)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", fmt.Errorf("output directory %s: no existing parent directory", abs)
+		}
+		existing = parent
+	}
+
+	probe, err := os.CreateTemp(existing, ".blackdot-write-test-*")
+	if err != nil {
+		return "", fmt.Errorf("output directory %s is not writable: cannot create files in %s", abs, existing)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return abs, nil
+}
+
+// devcontainerImageShortName is the name --image matches, e.g. "go" for "Go 1.23"
+func devcontainerImageShortName(img DevcontainerImage) string {
+	return strings.ToLower(strings.Split(img.Name, " ")[0])
+}
+
+// detectProjectImage returns the short name of the image for the first
+// project file in dir that the catalog has an image for, and that file's name
+func detectProjectImage(dir string, images []DevcontainerImage) (string, string) {
+	for _, sig := range projectImageSignals {
+		if _, err := os.Stat(filepath.Join(dir, sig.file)); err != nil {
+			continue
+		}
+		for _, img := range images {
+			if devcontainerImageShortName(img) == sig.image {
+				return sig.image, sig.file
+			}
+		}
+		logger.Debug("project file has no matching image in catalog", "file", sig.file, "image", sig.image)
+	}
+	return "", ""
+}
+
+func selectImage(images []DevcontainerImage) (DevcontainerImage, error) {
 	BoldCyan.Println("Select base image:")
 	fmt.Println()
 
-	for i, img := range devcontainerImages {
+	for i, img := range images {
 		fmt.Printf("  %d. ", i+1)
 		Yellow.Print(img.Name)
 		Dim.Printf(" - %s\n", img.Description)
 	}
 
 	fmt.Println()
-	fmt.Print("Enter selection (1-", len(devcontainerImages), "): ")
+	fmt.Print("Enter selection (1-", len(images), "): ")
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -615,12 +930,12 @@ func selectImage() (DevcontainerImage, error) {
 
 	input = strings.TrimSpace(input)
 	num, err := strconv.Atoi(input)
-	if err != nil || num < 1 || num > len(devcontainerImages) {
+	if err != nil || num < 1 || num > len(images) {
 		return DevcontainerImage{}, fmt.Errorf("invalid selection: %s", input)
 	}
 
 	fmt.Println()
-	return devcontainerImages[num-1], nil
+	return images[num-1], nil
 }
 
 func selectPreset() (string, error) {
@@ -653,18 +968,34 @@ func selectPreset() (string, error) {
 	return devcontainerPresets[num-1].Name, nil
 }
 
-func generateDevcontainerConfig(image DevcontainerImage, preset string, noVSExt bool) DevcontainerConfig {
+// devcontainerPathGuard fails postStartCommand with an actionable message
+// when the blackdot feature didn't put blackdot on PATH
+const devcontainerPathGuard = `command -v blackdot >/dev/null 2>&1 || { echo '[blackdot] blackdot is not on PATH - check that the ghcr.io/blackwell-systems/blackdot feature installed (see the container creation log), then rebuild the container' >&2; exit 1; }; `
+
+// devcontainerPostStartCommand runs setup for preset, guarded unless noGuard
+func devcontainerPostStartCommand(preset string, noGuard bool) string {
+	command := fmt.Sprintf("blackdot setup --preset %s && echo '[blackdot] ⚫💨📦 credentials loaded'", preset)
+	if noGuard {
+		return command
+	}
+	return devcontainerPathGuard + command
+}
+
+func generateDevcontainerConfig(image DevcontainerImage, preset string, noVSExt, noGuard bool, hooks devcontainerLifecycleHooks) DevcontainerConfig {
 	config := DevcontainerConfig{
 		Name:  "Development Container",
 		Image: image.Image,
 		Features: map[string]map[string]string{
-			"ghcr.io/blackwell-systems/blackdot:1": {
+			devcontainerFeatureRef: {
 				"preset":  preset,
 				"version": "latest",
 			},
 		},
-		PostStartCommand: fmt.Sprintf("blackdot setup --preset %s && echo '[blackdot] ⚫💨📦 credentials loaded'", preset),
-		RemoteUser:       "vscode",
+		OnCreateCommand:      hooks.OnCreate,
+		UpdateContentCommand: hooks.UpdateContent,
+		PostCreateCommand:    hooks.PostCreate,
+		PostStartCommand:     devcontainerPostStartCommand(preset, noGuard),
+		RemoteUser:           "vscode",
 		// SSH agent forwarding - mount host socket into container
 		Mounts: []string{
 			"source=${localEnv:SSH_AUTH_SOCK},target=/ssh-agent,type=bind,consistency=cached",
@@ -686,7 +1017,12 @@ func generateDevcontainerConfig(image DevcontainerImage, preset string, noVSExt
 	return config
 }
 
-func generateDevcontainerConfigWithCompose(image DevcontainerImage, preset string, noVSExt bool, services []DevcontainerService) DevcontainerConfig {
+// minimize keeps only the name, image, and features of c, for --minimal-json
+func (c *DevcontainerConfig) minimize() {
+	*c = DevcontainerConfig{Name: c.Name, Image: c.Image, Features: c.Features}
+}
+
+func generateDevcontainerConfigWithCompose(image DevcontainerImage, preset string, noVSExt, noGuard bool, hooks devcontainerLifecycleHooks, services []DevcontainerService) DevcontainerConfig {
 	// Collect environment variables from all services
 	envVars := map[string]string{
 		"SSH_AUTH_SOCK": "/ssh-agent",
@@ -703,14 +1039,17 @@ func generateDevcontainerConfigWithCompose(image DevcontainerImage, preset strin
 		Service:           "app",
 		WorkspaceFolder:   "/workspace",
 		Features: map[string]map[string]string{
-			"ghcr.io/blackwell-systems/blackdot:1": {
+			devcontainerFeatureRef: {
 				"preset":  preset,
 				"version": "latest",
 			},
 		},
-		PostStartCommand: fmt.Sprintf("blackdot setup --preset %s && echo '[blackdot] ⚫💨📦 credentials loaded'", preset),
-		RemoteUser:       "vscode",
-		ContainerEnv:     envVars,
+		OnCreateCommand:      hooks.OnCreate,
+		UpdateContentCommand: hooks.UpdateContent,
+		PostCreateCommand:    hooks.PostCreate,
+		PostStartCommand:     devcontainerPostStartCommand(preset, noGuard),
+		RemoteUser:           "vscode",
+		ContainerEnv:         envVars,
 	}
 
 	// Add VS Code extensions if available and not disabled
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// defaultImageCatalogURL is the catalog published from this repository
+	defaultImageCatalogURL = "https://raw.githubusercontent.com/blackwell-systems/blackdot/main/devcontainer-feature/images.json"
+
+	// imageCatalogTTL is how long a fetched catalog is used before refetching
+	imageCatalogTTL = 24 * time.Hour
+
+	// imageCatalogTimeout bounds each catalog download attempt
+	imageCatalogTimeout = 5 * time.Second
+
+	// imageCatalogDeadline bounds the whole fetch, retries included
+	imageCatalogDeadline = 15 * time.Second
+)
+
+// imageCatalog is the JSON format served at the catalog URL
+type imageCatalog struct {
+	Images []DevcontainerImage `json:"images"`
+}
+
+// imageCatalogCache is the on-disk cache of a fetched catalog
+type imageCatalogCache struct {
+	URL       string              `json:"url"`
+	FetchedAt time.Time           `json:"fetched_at"`
+	Images    []DevcontainerImage `json:"images"`
+}
+
+// getImageCatalogCachePath returns ~/.cache/blackdot/images.json
+func getImageCatalogCachePath() string {
+	return filepath.Join(CacheDir(), "images.json")
+}
+
+// loadImageCatalog returns the image list to offer and a description of its
+// source. A fresh cache is preferred, then the network, then a stale cache,
+// and finally the built-in devcontainerImages. An empty URL always uses the
+// built-in list.
+func loadImageCatalog(url string) ([]DevcontainerImage, string) {
+	if url == "" {
+		return devcontainerImages, "built-in"
+	}
+
+	cachePath := getImageCatalogCachePath()
+	cached, cacheErr := readImageCatalogCache(cachePath)
+	if cacheErr == nil && cached.URL == url && time.Since(cached.FetchedAt) < imageCatalogTTL {
+		return cached.Images, fmt.Sprintf("cached %s (fetched %s)", url, cached.FetchedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	images, err := fetchImageCatalog(url)
+	if err == nil {
+		cache := imageCatalogCache{URL: url, FetchedAt: time.Now(), Images: images}
+		if err := writeImageCatalogCache(cachePath, cache); err != nil {
+			logger.Debug("could not cache image catalog", "path", cachePath, "error", err)
+		}
+		return images, fmt.Sprintf("remote %s", url)
+	}
+	logger.Debug("image catalog fetch failed", "url", url, "error", err)
+
+	if cacheErr == nil && cached.URL == url {
+		return cached.Images, fmt.Sprintf("stale cache of %s (offline? fetched %s)", url, cached.FetchedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return devcontainerImages, "built-in (catalog unavailable)"
+}
+
+// fetchImageCatalog downloads and validates a catalog
+func fetchImageCatalog(url string) ([]DevcontainerImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), imageCatalogDeadline)
+	defer cancel()
+
+	client := &http.Client{Timeout: imageCatalogTimeout}
+	resp, err := httpDoWithRetry(ctx, client, http.MethodGet, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return parseImageCatalog(data)
+}
+
+// parseImageCatalog decodes a catalog, rejecting empty or incomplete entries
+func parseImageCatalog(data []byte) ([]DevcontainerImage, error) {
+	var catalog imageCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("invalid catalog: %w", err)
+	}
+	if len(catalog.Images) == 0 {
+		return nil, fmt.Errorf("catalog has no images")
+	}
+	for i, img := range catalog.Images {
+		if img.Name == "" || img.Image == "" {
+			return nil, fmt.Errorf("catalog image %d is missing name or image", i+1)
+		}
+	}
+	return catalog.Images, nil
+}
+
+func readImageCatalogCache(path string) (imageCatalogCache, error) {
+	var cache imageCatalogCache
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, err
+	}
+	if len(cache.Images) == 0 {
+		return cache, fmt.Errorf("empty cache")
+	}
+	return cache, nil
+}
+
+func writeImageCatalogCache(path string, cache imageCatalogCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// devcontainerFeatureRef is the blackdot feature as generated configs
+	// reference it, following the 1.x releases
+	devcontainerFeatureRef = "ghcr.io/blackwell-systems/blackdot:1"
+
+	// featureDigestTimeout bounds resolving the feature's digest, retries
+	// included
+	featureDigestTimeout = 20 * time.Second
+)
+
+// featureRegistryBase is the registry devcontainerFeatureRef lives in. A
+// variable so tests can use a local server.
+var featureRegistryBase = "https://ghcr.io"
+
+// featureManifestAccept lists the manifest types a feature can be
+// published as; features are OCI artifacts
+var featureManifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+var ociDigestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// pinFeatureDigest resolves ref (registry/repo:tag) to the digest its tag
+// points at now and returns registry/repo@sha256:...
+func pinFeatureDigest(ref string) (string, error) {
+	name, tag, ok := strings.Cut(ref, ":")
+	if !ok {
+		return "", fmt.Errorf("%s has no tag", ref)
+	}
+	_, repo, ok := strings.Cut(name, "/")
+	if !ok {
+		return "", fmt.Errorf("%s has no repository", ref)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), featureDigestTimeout)
+	defer cancel()
+
+	token, err := registryPullToken(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{Transport: headerTransport{
+		"Accept":        featureManifestAccept,
+		"Authorization": "Bearer " + token,
+	}}
+	resp, err := httpDoWithRetry(ctx, client, http.MethodGet, fmt.Sprintf("%s/v2/%s/manifests/%s", featureRegistryBase, repo, url.PathEscape(tag)))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("manifest %s: %s", ref, resp.Status)
+	}
+
+	// The registry names the digest; without that header it is the hash of
+	// the manifest exactly as served
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(body)
+		digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	if !ociDigestPattern.MatchString(digest) {
+		return "", fmt.Errorf("manifest %s: unexpected digest %q", ref, digest)
+	}
+	return name + "@" + digest, nil
+}
+
+// registryPullToken gets the anonymous pull token the registry requires
+// even for public repositories
+func registryPullToken(ctx context.Context, repo string) (string, error) {
+	tokenURL := fmt.Sprintf("%s/token?scope=%s", featureRegistryBase, url.QueryEscape("repository:"+repo+":pull"))
+	resp, err := httpDoWithRetry(ctx, http.DefaultClient, http.MethodGet, tokenURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token: %s", resp.Status)
+	}
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("registry token: %w", err)
+	}
+	if body.Token == "" {
+		return "", fmt.Errorf("registry token: empty response")
+	}
+	return body.Token, nil
+}
+
+// headerTransport adds fixed headers to every request, so the requests
+// httpDoWithRetry builds can carry them
+type headerTransport map[string]string
+
+func (h headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range h {
+		req.Header.Set(key, value)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// setFeatureRef moves the blackdot feature's options under ref
+func (c *DevcontainerConfig) setFeatureRef(ref string) {
+	if ref == devcontainerFeatureRef {
+		return
+	}
+	if options, ok := c.Features[devcontainerFeatureRef]; ok {
+		delete(c.Features, devcontainerFeatureRef)
+		c.Features[ref] = options
+	}
+}
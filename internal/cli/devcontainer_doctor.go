@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// featureProbeTimeout bounds each network check for a referenced feature
+const featureProbeTimeout = 5 * time.Second
+
+func newDevcontainerDoctorCmd() *cobra.Command {
+	var configDir string
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the host environment for devcontainer use",
+		Long: `Check that the host is ready to open a blackdot devcontainer.
+
+Checks:
+  - SSH_AUTH_SOCK is set and the agent socket exists (for SSH forwarding)
+  - Docker or Podman is installed and responding
+  - devcontainer.json parses and every referenced feature is reachable
+
+Named configurations in <output>/<name>/devcontainer.json are checked too.
+
+Exits non-zero if a hard prerequisite is missing.
+
+Examples:
+  blackdot devcontainer doctor
+  blackdot devcontainer doctor --output path/to/.devcontainer`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDevcontainerDoctor(configDir)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configDir, "output", "o", ".devcontainer", "Devcontainer directory to check")
+
+	return cmd
+}
+
+func runDevcontainerDoctor(configDir string) error {
+	state := &doctorState{
+		bold:   color.New(color.Bold).SprintFunc(),
+		dim:    color.New(color.Faint).SprintFunc(),
+		red:    color.New(color.FgRed).SprintFunc(),
+		green:  color.New(color.FgGreen).SprintFunc(),
+		yellow: color.New(color.FgYellow).SprintFunc(),
+		blue:   color.New(color.FgBlue).SprintFunc(),
+		cyan:   color.New(color.FgCyan).SprintFunc(),
+	}
+
+	fmt.Println()
+	BoldCyan.Println("Blackdot Devcontainer Doctor")
+	fmt.Println(strings.Repeat("═", 30))
+
+	state.section("SSH Agent Forwarding")
+	checkDevcontainerSSHAgent(state)
+
+	state.section("Container Runtime")
+	checkDevcontainerRuntime(state)
+
+	state.section("Devcontainer Configuration")
+	checkDevcontainerFeatures(state, configDir)
+
+	// Summary
+	fmt.Println()
+	fmt.Printf("%s passed, %s warnings, %s failed\n",
+		state.green(state.checksPassed), state.yellow(state.checksWarned), state.red(state.checksFailed))
+
+	if len(state.failedChecks) > 0 || len(state.warnChecks) > 0 {
+		fmt.Println()
+		for i, check := range state.failedChecks {
+			if fix := state.failedFixes[i]; fix != "" {
+				fmt.Printf("  %s %s\n    %s\n", state.red("✗"), check, state.dim("fix: "+fix))
+			}
+		}
+		for i, check := range state.warnChecks {
+			if fix := state.warnFixes[i]; fix != "" {
+				fmt.Printf("  %s %s\n    %s\n", state.yellow("!"), check, state.dim("fix: "+fix))
+			}
+		}
+	}
+	fmt.Println()
+
+	if state.checksFailed > 0 {
+		return fmt.Errorf("devcontainer doctor found %d problem(s)", state.checksFailed)
+	}
+	return nil
+}
+
+// checkDevcontainerSSHAgent verifies the host agent socket that the generated
+// config mounts via ${localEnv:SSH_AUTH_SOCK}
+func checkDevcontainerSSHAgent(state *doctorState) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		state.warn("SSH_AUTH_SOCK is not set (git over SSH won't work in the container)",
+			"eval \"$(ssh-agent -s)\" && ssh-add")
+		return
+	}
+
+	info, err := os.Stat(sock)
+	if err != nil {
+		state.fail(fmt.Sprintf("SSH_AUTH_SOCK points to a missing socket: %s", sock),
+			"restart your SSH agent or re-export SSH_AUTH_SOCK")
+		return
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		state.fail(fmt.Sprintf("SSH_AUTH_SOCK is not a socket: %s", sock),
+			"restart your SSH agent or re-export SSH_AUTH_SOCK")
+		return
+	}
+	state.pass(fmt.Sprintf("SSH agent socket: %s", sock))
+
+	if _, err := exec.LookPath("ssh-add"); err == nil {
+		if err := exec.Command("ssh-add", "-l").Run(); err != nil {
+			state.warn("SSH agent has no keys loaded", "ssh-add")
+		} else {
+			state.pass("SSH agent has keys loaded")
+		}
+	}
+}
+
+// checkDevcontainerRuntime verifies Docker or Podman is installed and responding
+func checkDevcontainerRuntime(state *doctorState) {
+	for _, runtime := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(runtime); err != nil {
+			continue
+		}
+		if out, err := exec.Command(runtime, "info").CombinedOutput(); err != nil {
+			logger.Debug("container runtime not responding", "runtime", runtime, "error", err, "output", string(out))
+			state.fail(fmt.Sprintf("%s is installed but not responding", runtime),
+				fmt.Sprintf("start the %s daemon/machine", runtime))
+			return
+		}
+		state.pass(fmt.Sprintf("Container runtime: %s", runtime))
+		return
+	}
+
+	state.fail("No container runtime found (docker or podman)",
+		"install Docker Desktop, OrbStack, or Podman")
+}
+
+// devcontainerConfigPaths returns the devcontainer.json in configDir, if
+// any, followed by the named configurations in its subdirectories
+func devcontainerConfigPaths(configDir string) []string {
+	var paths []string
+	top := filepath.Join(configDir, "devcontainer.json")
+	if _, err := os.Stat(top); err == nil {
+		paths = append(paths, top)
+	}
+	named, _ := filepath.Glob(filepath.Join(configDir, "*", "devcontainer.json"))
+	sort.Strings(named)
+	return append(paths, named...)
+}
+
+// checkDevcontainerFeatures checks every devcontainer.json under configDir
+func checkDevcontainerFeatures(state *doctorState, configDir string) {
+	paths := devcontainerConfigPaths(configDir)
+	if len(paths) == 0 {
+		state.warn(fmt.Sprintf("%s not found", filepath.Join(configDir, "devcontainer.json")), "blackdot devcontainer init")
+		return
+	}
+	for _, path := range paths {
+		checkDevcontainerConfig(state, path)
+	}
+}
+
+// checkDevcontainerConfig parses a devcontainer.json and checks every
+// feature reference, resolving local ones from the file's directory
+func checkDevcontainerConfig(state *doctorState, configPath string) {
+	configDir := filepath.Dir(configPath)
+	config, err := loadDevcontainerConfig(configPath)
+	if err != nil {
+		state.fail(fmt.Sprintf("%s is not valid JSONC: %v", configPath, err), "blackdot devcontainer init --force")
+		return
+	}
+	state.pass(fmt.Sprintf("Parsed %s", configPath))
+
+	if len(config.Features) == 0 {
+		state.warn(fmt.Sprintf("No features referenced in %s", configPath), "blackdot devcontainer init --force")
+		return
+	}
+
+	// Sort for stable output
+	var refs []string
+	for ref := range config.Features {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	for _, ref := range refs {
+		err := probeDevcontainerFeature(configDir, ref)
+		logger.Debug("probed feature", "ref", ref, "error", err)
+		if err != nil {
+			state.fail(fmt.Sprintf("Feature unreachable: %s (%v)", ref, err), "check network access or the feature reference")
+		} else {
+			state.pass(fmt.Sprintf("Feature reachable: %s", ref))
+		}
+	}
+}
+
+// probeDevcontainerFeature checks that a feature reference can be resolved.
+// Local references ("./feature") must exist relative to the config directory,
+// tarball URLs must answer a HEAD request, and OCI references must have a
+// registry that answers on /v2/ (an auth challenge counts as reachable).
+func probeDevcontainerFeature(configDir, ref string) error {
+	if strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+		if _, err := os.Stat(filepath.Join(configDir, ref)); err != nil {
+			return fmt.Errorf("local feature not found")
+		}
+		return nil
+	}
+
+	client := &http.Client{Timeout: featureProbeTimeout}
+
+	if strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://") {
+		resp, err := httpDoWithRetry(context.Background(), client, http.MethodHead, ref)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	registry := strings.SplitN(ref, "/", 2)[0]
+	if !strings.Contains(registry, ".") && !strings.Contains(registry, ":") {
+		return fmt.Errorf("not a registry reference")
+	}
+	if _, err := net.LookupHost(strings.Split(registry, ":")[0]); err != nil {
+		return fmt.Errorf("cannot resolve %s", registry)
+	}
+
+	resp, err := httpDoWithRetry(context.Background(), client, http.MethodGet, "https://"+registry+"/v2/")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("registry returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// loadDevcontainerConfig reads a devcontainer.json as VS Code writes it:
+// JSONC with comments and trailing commas. Output is always strict JSON.
+func loadDevcontainerConfig(path string) (DevcontainerConfig, error) {
+	var config DevcontainerConfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(stripJSONC(data), &config)
+	return config, err
+}
+
+// stripJSONC converts JSONC to JSON by removing // and /* */ comments and
+// commas that directly precede a closing } or ]. String contents are left
+// untouched. Removed comments are replaced with spaces (newlines are kept)
+// so json.Unmarshal error offsets still point at the right line.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	// Index in out of a comma that may turn out to be trailing
+	pendingComma := -1
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		switch {
+		case c == '"':
+			pendingComma = -1
+			out = append(out, c)
+			for i++; i < len(data); i++ {
+				out = append(out, data[i])
+				if data[i] == '\\' && i+1 < len(data) {
+					i++
+					out = append(out, data[i])
+				} else if data[i] == '"' {
+					break
+				}
+			}
+
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for ; i < len(data) && data[i] != '\n'; i++ {
+				out = append(out, ' ')
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			out = append(out, ' ', ' ')
+			for i += 2; i < len(data); i++ {
+				if data[i] == '*' && i+1 < len(data) && data[i+1] == '/' {
+					out = append(out, ' ', ' ')
+					i++
+					break
+				}
+				if data[i] == '\n' {
+					out = append(out, '\n')
+				} else {
+					out = append(out, ' ')
+				}
+			}
+
+		case c == ',':
+			pendingComma = len(out)
+			out = append(out, c)
+
+		case c == '}' || c == ']':
+			if pendingComma >= 0 {
+				out[pendingComma] = ' '
+				pendingComma = -1
+			}
+			out = append(out, c)
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			out = append(out, c)
+
+		default:
+			pendingComma = -1
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// devcontainerRepoTarget is where --mount-repo mounts the host's blackdot
+// checkout inside the container
+const devcontainerRepoTarget = "/blackdot"
+
+// resolveRepoMount expands and makes absolute the --mount-repo path, which
+// must be an existing directory on this host
+func resolveRepoMount(path string) (string, error) {
+	abs, err := expandDirArg(path)
+	if err != nil {
+		return "", fmt.Errorf("--mount-repo %s: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("--mount-repo: %w", err)
+	}
+	if !info.IsDir() {
+		return "", classErrorf(ErrUsage, "--mount-repo: %s is not a directory", abs)
+	}
+	return abs, nil
+}
+
+// mountRepo bind-mounts hostPath at devcontainerRepoTarget and points
+// BLACKDOT_DIR there, so blackdot in the container runs the host checkout
+func (c *DevcontainerConfig) mountRepo(hostPath string) {
+	c.Mounts = append(c.Mounts, fmt.Sprintf("source=%s,target=%s,type=bind,consistency=cached", hostPath, devcontainerRepoTarget))
+	if c.ContainerEnv == nil {
+		c.ContainerEnv = make(map[string]string)
+	}
+	c.ContainerEnv["BLACKDOT_DIR"] = devcontainerRepoTarget
+}
@@ -2,9 +2,16 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestDevcontainerCommand verifies command structure
@@ -28,7 +35,7 @@ func TestDevcontainerCommand(t *testing.T) {
 func TestDevcontainerSubcommands(t *testing.T) {
 	cmd := newDevcontainerCmd()
 
-	expectedSubcommands := []string{"init", "images", "services"}
+	expectedSubcommands := []string{"init", "images", "services", "doctor"}
 	subcommands := make(map[string]bool)
 	for _, sub := range cmd.Commands() {
 		subcommands[sub.Name()] = true
@@ -55,6 +62,16 @@ func TestDevcontainerInitFlags(t *testing.T) {
 		{"force", "f"},
 		{"no-extensions", ""},
 		{"services", ""},
+		{"print", ""},
+		{"no-guard", ""},
+		{"from-project", ""},
+		{"name", ""},
+		{"on-create", ""},
+		{"post-create", ""},
+		{"update-content", ""},
+		{"pin-digest", ""},
+		{"mount-repo", ""},
+		{"minimal-json", ""},
 	}
 
 	for _, f := range flags {
@@ -144,7 +161,7 @@ func TestGenerateDevcontainerConfig(t *testing.T) {
 		Extensions:  []string{"golang.go"},
 	}
 
-	config := generateDevcontainerConfig(image, "developer", false)
+	config := generateDevcontainerConfig(image, "developer", false, false, devcontainerLifecycleHooks{})
 
 	// Check basic fields
 	if config.Name != "Development Container" {
@@ -169,8 +186,8 @@ func TestGenerateDevcontainerConfig(t *testing.T) {
 		t.Errorf("expected preset='developer', got '%s'", blackdotFeature["preset"])
 	}
 
-	// Check postStartCommand (includes startup banner with fallback text)
-	expectedPostStart := "blackdot setup --preset developer && echo '[blackdot] ⚫💨📦 credentials loaded'"
+	// Check postStartCommand (PATH guard, then setup with startup banner)
+	expectedPostStart := devcontainerPathGuard + "blackdot setup --preset developer && echo '[blackdot] ⚫💨📦 credentials loaded'"
 	if config.PostStartCommand != expectedPostStart {
 		t.Errorf("unexpected PostStartCommand: %s", config.PostStartCommand)
 	}
@@ -199,13 +216,77 @@ func TestGenerateDevcontainerConfigNoExtensions(t *testing.T) {
 		Extensions: []string{"golang.go"},
 	}
 
-	config := generateDevcontainerConfig(image, "developer", true) // noVSExt = true
+	config := generateDevcontainerConfig(image, "developer", true, false, devcontainerLifecycleHooks{}) // noVSExt = true
 
 	if config.Customizations != nil {
 		t.Error("Customizations should be nil when noVSExt is true")
 	}
 }
 
+// TestGenerateDevcontainerConfigNoGuard verifies --no-guard drops the PATH check
+func TestGenerateDevcontainerConfigNoGuard(t *testing.T) {
+	image := DevcontainerImage{Name: "Go 1.23", Image: "mcr.microsoft.com/devcontainers/go:1.23"}
+
+	config := generateDevcontainerConfig(image, "developer", false, true, devcontainerLifecycleHooks{})
+	if strings.Contains(config.PostStartCommand, "command -v blackdot") {
+		t.Errorf("guard should be omitted with noGuard: %s", config.PostStartCommand)
+	}
+	if !strings.HasPrefix(config.PostStartCommand, "blackdot setup --preset developer") {
+		t.Errorf("unexpected PostStartCommand: %s", config.PostStartCommand)
+	}
+
+	compose := generateDevcontainerConfigWithCompose(image, "developer", false, false, devcontainerLifecycleHooks{}, nil)
+	if !strings.HasPrefix(compose.PostStartCommand, "command -v blackdot >/dev/null 2>&1 ||") {
+		t.Errorf("compose config should be guarded by default: %s", compose.PostStartCommand)
+	}
+}
+
+// TestGenerateDevcontainerConfigLifecycleHooks verifies lifecycle hooks are
+// set when given and left out of the JSON otherwise
+func TestGenerateDevcontainerConfigLifecycleHooks(t *testing.T) {
+	image := DevcontainerImage{Name: "Go 1.23", Image: "mcr.microsoft.com/devcontainers/go:1.23"}
+	hooks := devcontainerLifecycleHooks{
+		OnCreate:      "make tools",
+		PostCreate:    "go mod download",
+		UpdateContent: "go generate ./...",
+	}
+
+	for _, config := range []DevcontainerConfig{
+		generateDevcontainerConfig(image, "developer", false, false, hooks),
+		generateDevcontainerConfigWithCompose(image, "developer", false, false, hooks, nil),
+	} {
+		if config.OnCreateCommand != "make tools" || config.PostCreateCommand != "go mod download" || config.UpdateContentCommand != "go generate ./..." {
+			t.Errorf("hooks not set: %+v", config)
+		}
+	}
+
+	data, err := json.Marshal(generateDevcontainerConfig(image, "developer", false, false, devcontainerLifecycleHooks{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"onCreateCommand", "postCreateCommand", "updateContentCommand"} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("%s should be omitted when not set: %s", key, data)
+		}
+	}
+}
+
+// TestDevcontainerInitEmptyHook verifies an explicitly empty hook is rejected
+func TestDevcontainerInitEmptyHook(t *testing.T) {
+	for _, name := range devcontainerHookFlags {
+		t.Run(name, func(t *testing.T) {
+			cmd := newDevcontainerInitCmd()
+			cmd.SetArgs([]string{"--image", "go", "--preset", "minimal", "--print", "--" + name, "  "})
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), "--"+name) {
+				t.Errorf("expected error naming --%s, got %v", name, err)
+			}
+		})
+	}
+}
+
 // TestGenerateDevcontainerConfigBaseImage verifies base image without extensions
 func TestGenerateDevcontainerConfigBaseImage(t *testing.T) {
 	image := DevcontainerImage{
@@ -214,7 +295,7 @@ func TestGenerateDevcontainerConfigBaseImage(t *testing.T) {
 		Extensions: []string{}, // No extensions
 	}
 
-	config := generateDevcontainerConfig(image, "minimal", false)
+	config := generateDevcontainerConfig(image, "minimal", false, false, devcontainerLifecycleHooks{})
 
 	// Should not have Customizations when no extensions
 	if config.Customizations != nil {
@@ -230,7 +311,7 @@ func TestDevcontainerConfigJSON(t *testing.T) {
 		Extensions: []string{"ms-python.python"},
 	}
 
-	config := generateDevcontainerConfig(image, "claude", false)
+	config := generateDevcontainerConfig(image, "claude", false, false, devcontainerLifecycleHooks{})
 
 	jsonData, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
@@ -253,7 +334,7 @@ func TestRunDevcontainerInit(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, ".devcontainer")
 
-	err := runDevcontainerInit("go", "developer", outputDir, false, false, nil)
+	err := runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: "developer", OutputDir: outputDir})
 	if err != nil {
 		t.Fatalf("runDevcontainerInit failed: %v", err)
 	}
@@ -280,25 +361,73 @@ func TestRunDevcontainerInit(t *testing.T) {
 	}
 }
 
+// TestCheckOutputDir verifies ~ expansion and writability checks for --output
+func TestCheckOutputDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir, err := checkOutputDir("~/proj/.devcontainer")
+	if err != nil || dir != filepath.Join(home, "proj", ".devcontainer") {
+		t.Errorf("tilde: got %q, %v", dir, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "proj")); !os.IsNotExist(err) {
+		t.Error("checkOutputDir should not create directories")
+	}
+
+	t.Chdir(home)
+	if dir, err := checkOutputDir(".devcontainer"); err != nil || dir != filepath.Join(home, ".devcontainer") {
+		t.Errorf("relative: got %q, %v", dir, err)
+	}
+
+	file := filepath.Join(home, "file")
+	os.WriteFile(file, []byte("x"), 0644)
+	if _, err := checkOutputDir(filepath.Join(file, ".devcontainer")); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("expected not-a-directory error, got %v", err)
+	}
+
+	if os.Geteuid() != 0 {
+		locked := filepath.Join(home, "locked")
+		os.Mkdir(locked, 0555)
+		if _, err := checkOutputDir(filepath.Join(locked, "sub", ".devcontainer")); err == nil || !strings.Contains(err.Error(), "not writable") {
+			t.Errorf("expected not-writable error, got %v", err)
+		}
+	}
+
+	// End to end: the literal ~ directory is never created
+	if err := runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: "developer", OutputDir: "~/proj/.devcontainer"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "proj", ".devcontainer", "devcontainer.json")); err != nil {
+		t.Errorf("devcontainer.json not written under HOME: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "~")); !os.IsNotExist(err) {
+		t.Error("a literal ~ directory was created")
+	}
+}
+
 // TestRunDevcontainerInitOverwrite verifies --force flag
 func TestRunDevcontainerInitOverwrite(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, ".devcontainer")
 
 	// Create first config
-	err := runDevcontainerInit("go", "developer", outputDir, false, false, nil)
+	err := runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: "developer", OutputDir: outputDir})
 	if err != nil {
 		t.Fatalf("first runDevcontainerInit failed: %v", err)
 	}
 
 	// Try without force - should fail
-	err = runDevcontainerInit("rust", "claude", outputDir, false, false, nil)
+	err = runDevcontainerInit(devcontainerInitOptions{Image: "rust", Preset: "claude", OutputDir: outputDir})
 	if err == nil {
 		t.Error("expected error when overwriting without --force")
+	} else if !strings.Contains(err.Error(), "use --force to overwrite") {
+		t.Errorf("expected the error to name the --force flag, got %v", err)
+	} else if ExitCode(err) != ExitConflict {
+		t.Errorf("expected a conflict exit code, got %d", ExitCode(err))
 	}
 
 	// Try with force - should succeed
-	err = runDevcontainerInit("rust", "claude", outputDir, true, false, nil)
+	err = runDevcontainerInit(devcontainerInitOptions{Image: "rust", Preset: "claude", OutputDir: outputDir, Force: true})
 	if err != nil {
 		t.Fatalf("runDevcontainerInit with force failed: %v", err)
 	}
@@ -317,7 +446,7 @@ func TestRunDevcontainerInitInvalidImage(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, ".devcontainer")
 
-	err := runDevcontainerInit("invalid-image", "developer", outputDir, false, false, nil)
+	err := runDevcontainerInit(devcontainerInitOptions{Image: "invalid-image", Preset: "developer", OutputDir: outputDir})
 	if err == nil {
 		t.Error("expected error for invalid image")
 	}
@@ -328,7 +457,7 @@ func TestRunDevcontainerInitInvalidPreset(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, ".devcontainer")
 
-	err := runDevcontainerInit("go", "invalid-preset", outputDir, false, false, nil)
+	err := runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: "invalid-preset", OutputDir: outputDir})
 	if err == nil {
 		t.Error("expected error for invalid preset")
 	}
@@ -339,7 +468,7 @@ func TestRunDevcontainerInitWithServices(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, ".devcontainer")
 
-	err := runDevcontainerInit("go", "developer", outputDir, false, false, []string{"postgres", "redis"})
+	err := runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: "developer", OutputDir: outputDir, Services: []string{"postgres", "redis"}})
 	if err != nil {
 		t.Fatalf("runDevcontainerInit with services failed: %v", err)
 	}
@@ -379,6 +508,31 @@ func TestRunDevcontainerInitWithServices(t *testing.T) {
 	}
 }
 
+// TestRunDevcontainerInitNamed verifies --name writes a configuration subdirectory
+func TestRunDevcontainerInitNamed(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), ".devcontainer")
+
+	for _, name := range []string{"minimal", "full"} {
+		err := runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: name, OutputDir: outputDir, Name: name, Services: []string{"redis"}})
+		if err != nil {
+			t.Fatalf("runDevcontainerInit --name %s failed: %v", name, err)
+		}
+		for _, file := range []string{"devcontainer.json", "docker-compose.yml"} {
+			if _, err := os.Stat(filepath.Join(outputDir, name, file)); err != nil {
+				t.Errorf("expected %s/%s: %v", name, file, err)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "devcontainer.json")); err == nil {
+		t.Error("expected no top-level devcontainer.json with --name")
+	}
+
+	err := runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: "minimal", OutputDir: outputDir, Name: "../escape"})
+	if err == nil {
+		t.Error("expected error for a name that isn't a single directory")
+	}
+}
+
 // TestDevcontainerServices verifies services list
 func TestDevcontainerServices(t *testing.T) {
 	if len(devcontainerServices) == 0 {
@@ -414,3 +568,369 @@ func TestDevcontainerServicesSubcommand(t *testing.T) {
 		t.Error("expected subcommand 'services' not found")
 	}
 }
+
+// TestProbeDevcontainerFeatureLocal verifies local feature references resolve against the config dir
+func TestProbeDevcontainerFeatureLocal(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "my-feature"), 0755); err != nil {
+		t.Fatalf("failed to create feature dir: %v", err)
+	}
+
+	if err := probeDevcontainerFeature(tmpDir, "./my-feature"); err != nil {
+		t.Errorf("expected local feature to resolve, got %v", err)
+	}
+	if err := probeDevcontainerFeature(tmpDir, "./missing"); err == nil {
+		t.Error("expected error for missing local feature")
+	}
+	if err := probeDevcontainerFeature(tmpDir, "not-a-registry/feature"); err == nil {
+		t.Error("expected error for reference without a registry host")
+	}
+}
+
+// TestDevcontainerConfigPaths verifies doctor finds top-level and named configs
+func TestDevcontainerConfigPaths(t *testing.T) {
+	dir := t.TempDir()
+	if got := devcontainerConfigPaths(dir); len(got) != 0 {
+		t.Errorf("expected no configs, got %v", got)
+	}
+
+	for _, rel := range []string{"devcontainer.json", "full/devcontainer.json", "minimal/devcontainer.json", "other/readme.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("{}"), 0644)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "devcontainer.json"),
+		filepath.Join(dir, "full", "devcontainer.json"),
+		filepath.Join(dir, "minimal", "devcontainer.json"),
+	}
+	if got := devcontainerConfigPaths(dir); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+// TestPublishedImageCatalog verifies the catalog served from the repo parses
+func TestPublishedImageCatalog(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "devcontainer-feature", "images.json"))
+	if err != nil {
+		t.Fatalf("failed to read images.json: %v", err)
+	}
+
+	images, err := parseImageCatalog(data)
+	if err != nil {
+		t.Fatalf("parseImageCatalog failed: %v", err)
+	}
+	if len(images) != len(devcontainerImages) {
+		t.Errorf("published catalog has %d images, built-in list has %d", len(images), len(devcontainerImages))
+	}
+}
+
+// TestLoadImageCatalogFallback verifies cache use and the built-in fallback
+func TestLoadImageCatalogFallback(t *testing.T) {
+	original := os.Getenv("XDG_CACHE_HOME")
+	defer os.Setenv("XDG_CACHE_HOME", original)
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// Empty URL always uses the built-in list
+	images, source := loadImageCatalog("")
+	if source != "built-in" || len(images) != len(devcontainerImages) {
+		t.Errorf("expected built-in list, got %q with %d images", source, len(images))
+	}
+
+	// A fresh cache for the URL is used without fetching
+	url := "http://127.0.0.1:0/images.json"
+	cached := []DevcontainerImage{{Name: "Zig", Image: "example.com/zig:latest"}}
+	if err := writeImageCatalogCache(getImageCatalogCachePath(), imageCatalogCache{URL: url, FetchedAt: time.Now(), Images: cached}); err != nil {
+		t.Fatalf("writeImageCatalogCache failed: %v", err)
+	}
+	images, _ = loadImageCatalog(url)
+	if len(images) != 1 || images[0].Name != "Zig" {
+		t.Errorf("expected cached catalog, got %+v", images)
+	}
+
+	// An unreachable URL with no cache falls back to built-in
+	images, source = loadImageCatalog("http://127.0.0.1:0/other.json")
+	if len(images) != len(devcontainerImages) || !strings.HasPrefix(source, "built-in") {
+		t.Errorf("expected built-in fallback, got %q with %d images", source, len(images))
+	}
+}
+
+// TestRunDevcontainerInitPrint verifies --print writes JSON to stdout and nothing to disk
+func TestRunDevcontainerInitPrint(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, ".devcontainer")
+
+	stdoutPath := filepath.Join(tmpDir, "stdout")
+	stdout, err := os.Create(stdoutPath)
+	if err != nil {
+		t.Fatalf("failed to create stdout file: %v", err)
+	}
+	orig := os.Stdout
+	os.Stdout = stdout
+	err = runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: "developer", OutputDir: outputDir, Print: true})
+	os.Stdout = orig
+	stdout.Close()
+	if err != nil {
+		t.Fatalf("runDevcontainerInit failed: %v", err)
+	}
+
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Error("--print should not create the output directory")
+	}
+
+	data, err := os.ReadFile(stdoutPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	var config DevcontainerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("stdout is not just devcontainer.json: %v\n%s", err, data)
+	}
+	if config.Image != "mcr.microsoft.com/devcontainers/go:1.23" {
+		t.Errorf("unexpected image: %s", config.Image)
+	}
+
+	// Prompts can't be answered when stdout is piped
+	err = runDevcontainerInit(devcontainerInitOptions{Preset: "developer", Print: true})
+	if err == nil {
+		t.Error("expected error for --print without --image")
+	}
+}
+
+// TestDetectProjectImage verifies signal priority and catalog fallback
+func TestDetectProjectImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      []string
+		images     []DevcontainerImage
+		wantImage  string
+		wantSignal string
+	}{
+		{"go", []string{"go.mod"}, devcontainerImages, "go", "go.mod"},
+		{"rust", []string{"Cargo.toml"}, devcontainerImages, "rust", "Cargo.toml"},
+		{"python requirements", []string{"requirements.txt"}, devcontainerImages, "python", "requirements.txt"},
+		{"java", []string{"pom.xml"}, devcontainerImages, "java", "pom.xml"},
+		{"node", []string{"package.json"}, devcontainerImages, "node", "package.json"},
+		{"package.json is tooling", []string{"package.json", "pyproject.toml"}, devcontainerImages, "python", "pyproject.toml"},
+		{"not in catalog", []string{"go.mod", "package.json"}, devcontainerImages[3:], "node", "package.json"},
+		{"nothing", []string{"README.md"}, devcontainerImages, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			image, signal := detectProjectImage(dir, tt.images)
+			if image != tt.wantImage || signal != tt.wantSignal {
+				t.Errorf("detectProjectImage() = %q, %q, want %q, %q", image, signal, tt.wantImage, tt.wantSignal)
+			}
+		})
+	}
+
+	// --print has no prompt to fall back to
+	err := runDevcontainerInit(devcontainerInitOptions{Preset: "minimal", FromProject: true, ProjectDir: t.TempDir(), Print: true})
+	if err == nil || !strings.Contains(err.Error(), "pass --image") {
+		t.Errorf("expected a --from-project error, got %v", err)
+	}
+}
+
+// TestStripJSONC verifies comments and trailing commas are removed outside strings
+func TestStripJSONC(t *testing.T) {
+	input := `// Generated by VS Code
+{
+	"name": "dev", // trailing comment
+	/* block
+	   comment */
+	"image": "mcr.microsoft.com/devcontainers/go:1.23",
+	"containerEnv": {
+		"URL": "http://example.com/*not-a-comment*/",
+		"QUOTE": "say \"hi\" // still a string",
+	},
+	"features": {
+		"ghcr.io/blackwell-systems/blackdot:1": {},
+	},
+}
+`
+	var config DevcontainerConfig
+	if err := json.Unmarshal(stripJSONC([]byte(input)), &config); err != nil {
+		t.Fatalf("stripped JSONC did not parse: %v\n%s", err, stripJSONC([]byte(input)))
+	}
+	if config.Name != "dev" || config.Image != "mcr.microsoft.com/devcontainers/go:1.23" {
+		t.Errorf("unexpected config: %+v", config)
+	}
+	if config.ContainerEnv["URL"] != "http://example.com/*not-a-comment*/" {
+		t.Errorf("string containing comment markers was modified: %q", config.ContainerEnv["URL"])
+	}
+	if config.ContainerEnv["QUOTE"] != `say "hi" // still a string` {
+		t.Errorf("escaped string was modified: %q", config.ContainerEnv["QUOTE"])
+	}
+	if len(config.Features) != 1 {
+		t.Errorf("expected 1 feature, got %d", len(config.Features))
+	}
+
+	// Line numbers are preserved for error reporting
+	if got := strings.Count(string(stripJSONC([]byte(input))), "\n"); got != strings.Count(input, "\n") {
+		t.Errorf("expected %d lines, got %d", strings.Count(input, "\n"), got)
+	}
+}
+
+// TestLoadDevcontainerConfig verifies files written by VS Code load
+func TestLoadDevcontainerConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devcontainer.json")
+	content := "{\n  // comment\n  \"name\": \"dev\",\n  \"image\": \"alpine\",\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := loadDevcontainerConfig(path)
+	if err != nil {
+		t.Fatalf("loadDevcontainerConfig failed: %v", err)
+	}
+	if config.Image != "alpine" {
+		t.Errorf("unexpected image: %s", config.Image)
+	}
+
+	if _, err := loadDevcontainerConfig(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
+
+// TestRunDevcontainerInitPinDigest verifies --pin-digest writes the digest
+// the registry reports, and falls back to the tag when it can't
+func TestRunDevcontainerInitPinDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	reg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.URL.Query().Get("scope") != "repository:blackwell-systems/blackdot:pull" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"token": "anon"}`))
+		case "/v2/blackwell-systems/blackdot/manifests/1":
+			if r.Header.Get("Authorization") != "Bearer anon" || !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.manifest.v1+json") {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer reg.Close()
+
+	orig := featureRegistryBase
+	defer func() { featureRegistryBase = orig }()
+
+	features := func(registryURL string) map[string]map[string]string {
+		t.Helper()
+		featureRegistryBase = registryURL
+		outputDir := filepath.Join(t.TempDir(), ".devcontainer")
+		if err := runDevcontainerInit(devcontainerInitOptions{Image: "go", Preset: "minimal", OutputDir: outputDir, PinDigest: true}); err != nil {
+			t.Fatalf("runDevcontainerInit failed: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(outputDir, "devcontainer.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var config DevcontainerConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			t.Fatal(err)
+		}
+		return config.Features
+	}
+
+	pinned := features(reg.URL)
+	if options, ok := pinned["ghcr.io/blackwell-systems/blackdot@"+digest]; !ok || options["preset"] != "minimal" || len(pinned) != 1 {
+		t.Errorf("pinned features = %v", pinned)
+	}
+
+	// A registry that refuses the request leaves the tag in place
+	fallback := features(reg.URL + "/missing")
+	if _, ok := fallback[devcontainerFeatureRef]; !ok || len(fallback) != 1 {
+		t.Errorf("fallback features = %v", fallback)
+	}
+}
+
+// TestRunDevcontainerInitMountRepo verifies the repo bind mount and
+// BLACKDOT_DIR, and that a missing host path is refused
+func TestRunDevcontainerInitMountRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "blackdot")
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(tmpDir, ".devcontainer")
+
+	opts := devcontainerInitOptions{Image: "go", Preset: "minimal", OutputDir: outputDir, MountRepo: repo}
+	if err := runDevcontainerInit(opts); err != nil {
+		t.Fatalf("runDevcontainerInit failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "devcontainer.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config DevcontainerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	want := "source=" + repo + ",target=" + devcontainerRepoTarget + ",type=bind,consistency=cached"
+	if !slices.Contains(config.Mounts, want) {
+		t.Errorf("mounts %v missing %q", config.Mounts, want)
+	}
+	if len(config.Mounts) != 2 {
+		t.Errorf("expected the SSH agent mount to be kept: %v", config.Mounts)
+	}
+	if config.ContainerEnv["BLACKDOT_DIR"] != devcontainerRepoTarget || config.ContainerEnv["SSH_AUTH_SOCK"] == "" {
+		t.Errorf("unexpected containerEnv: %v", config.ContainerEnv)
+	}
+
+	opts.MountRepo = filepath.Join(tmpDir, "missing")
+	opts.Force = true
+	if err := runDevcontainerInit(opts); err == nil {
+		t.Error("expected error for a --mount-repo path that doesn't exist")
+	}
+}
+
+// TestRunDevcontainerInitMinimalJSON verifies --minimal-json writes only
+// name, image, and the feature, and rejects options that need more
+func TestRunDevcontainerInitMinimalJSON(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), ".devcontainer")
+
+	opts := devcontainerInitOptions{Image: "go", Preset: "minimal", OutputDir: outputDir, MinimalJSON: true}
+	if err := runDevcontainerInit(opts); err != nil {
+		t.Fatalf("runDevcontainerInit failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "devcontainer.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	keys := slices.Sorted(maps.Keys(fields))
+	if want := []string{"features", "image", "name"}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	if !strings.Contains(string(fields["features"]), devcontainerFeatureRef) {
+		t.Errorf("features missing the blackdot feature: %s", fields["features"])
+	}
+
+	for _, bad := range []devcontainerInitOptions{
+		{Services: []string{"postgres"}},
+		{MountRepo: t.TempDir()},
+		{Hooks: devcontainerLifecycleHooks{PostCreate: "make"}},
+	} {
+		bad.Image, bad.Preset, bad.OutputDir, bad.MinimalJSON, bad.Force = "go", "minimal", outputDir, true, true
+		if err := runDevcontainerInit(bad); !errors.Is(err, ErrUsage) {
+			t.Errorf("%+v: expected a usage error, got %v", bad, err)
+		}
+	}
+}
@@ -30,8 +30,13 @@ Modes:
   (default)       Show all differences
   --sync, -s      Preview what sync would push to vault
   --restore, -r   Preview what restore would change locally
+  --repo          Diff deployed config files against BLACKDOT_DIR
   [item]          Show diff for specific item only
 
+With --repo, each file bootstrap links into $HOME (zshrc, p10k, zellij, ...)
+is compared against its source. Symlinks into the repo can't differ; real
+copies get a unified diff. Exits 1 if any file differs.
+
 Items:
   SSH-Config, AWS-Config, AWS-Credentials, Git-Config, Environment-Secrets
 
@@ -39,12 +44,16 @@ Examples:
   blackdot diff              # Show all differences
   blackdot diff --sync       # Preview what sync would push
   blackdot diff --restore    # Preview what restore would change
-  blackdot diff SSH-Config   # Show diff for specific item`,
+  blackdot diff SSH-Config   # Show diff for specific item
+  blackdot diff --repo       # Diff deployed copies against the repo
+  blackdot diff --repo --name-only`,
 		RunE: runDiff,
 	}
 
 	cmd.Flags().BoolP("sync", "s", false, "Preview what sync would push to vault")
 	cmd.Flags().BoolP("restore", "r", false, "Preview what restore would change locally")
+	cmd.Flags().Bool("repo", false, "Diff deployed config files against BLACKDOT_DIR")
+	cmd.Flags().Bool("name-only", false, "With --repo, only list files that differ")
 
 	return cmd
 }
@@ -52,6 +61,8 @@ Examples:
 func runDiff(cmd *cobra.Command, args []string) error {
 	syncMode, _ := cmd.Flags().GetBool("sync")
 	restoreMode, _ := cmd.Flags().GetBool("restore")
+	repoMode, _ := cmd.Flags().GetBool("repo")
+	nameOnly, _ := cmd.Flags().GetBool("name-only")
 
 	// Check mutual exclusion
 	if syncMode && restoreMode {
@@ -59,14 +70,25 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--sync and --restore are mutually exclusive")
 	}
 
+	if nameOnly && !repoMode {
+		return fmt.Errorf("--name-only is only supported with --repo")
+	}
+	if repoMode && (syncMode || restoreMode || len(args) > 0) {
+		return fmt.Errorf("--repo can't be combined with --sync, --restore, or an item")
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("cannot determine home directory: %w", err)
 	}
 
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		blackdotDir = filepath.Join(home, ".blackdot")
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
+	}
+
+	if repoMode {
+		return runRepoDiff(home, blackdotDir, nameOnly)
 	}
 
 	// Colors
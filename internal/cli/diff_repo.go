@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// repoFileDiff is the comparison of one deployed file against its repo source
+type repoFileDiff struct {
+	Name    string
+	Link    string
+	Source  string
+	Linked  bool   // deployed path is a symlink to the source
+	Missing bool   // nothing deployed
+	Diff    string // unified diff, empty when identical
+}
+
+// diffManagedFile compares a deployed managed file with its source in
+// blackdotDir. A symlink that resolves to the source can't differ, so it is
+// reported as linked without reading either file.
+func diffManagedFile(l managedLink, blackdotDir, home string) (repoFileDiff, error) {
+	source := filepath.Join(blackdotDir, l.Source)
+	d := repoFileDiff{Name: l.Name, Link: l.Link, Source: source}
+
+	if _, err := os.Lstat(l.Link); os.IsNotExist(err) {
+		d.Missing = true
+		return d, nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(l.Link)
+	if err != nil {
+		// Dangling symlink: nothing to compare
+		d.Missing = true
+		return d, nil
+	}
+	if want, err := filepath.EvalSymlinks(source); err == nil && resolved == want {
+		d.Linked = true
+		return d, nil
+	}
+
+	repoContent, err := os.ReadFile(source)
+	if err != nil {
+		return d, fmt.Errorf("reading %s: %w", source, err)
+	}
+	localContent, err := os.ReadFile(l.Link)
+	if err != nil {
+		return d, fmt.Errorf("reading %s: %w", l.Link, err)
+	}
+	if string(repoContent) == string(localContent) {
+		return d, nil
+	}
+
+	d.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitDiffLines(string(repoContent)),
+		B:        splitDiffLines(string(localContent)),
+		FromFile: filepath.ToSlash(filepath.Join("$BLACKDOT_DIR", l.Source)),
+		ToFile:   tildePath(l.Link, home),
+		Context:  3,
+	})
+	return d, err
+}
+
+// splitDiffLines splits s keeping line endings. Unlike difflib.SplitLines it
+// doesn't add a phantom empty line after a trailing newline, and marks a
+// missing final newline the way diff(1) does.
+func splitDiffLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	last := len(lines) - 1
+	if lines[last] == "" {
+		return lines[:last]
+	}
+	lines[last] += "\n\\ No newline at end of file\n"
+	return lines
+}
+
+// tildePath abbreviates home to ~ for display
+func tildePath(path, home string) string {
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return "~/" + filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// runRepoDiff diffs each deployed managed file against BLACKDOT_DIR and
+// returns an error if any differ, so it can gate CI
+func runRepoDiff(home, blackdotDir string, nameOnly bool) error {
+	var differing int
+
+	for _, l := range managedLinks(home, blackdotDir) {
+		d, err := diffManagedFile(l, blackdotDir, home)
+		if err != nil {
+			return err
+		}
+		if d.Diff == "" {
+			if !nameOnly {
+				switch {
+				case d.Linked:
+					Pass("%s: symlinked to repo", tildePath(d.Link, home))
+				case d.Missing && l.Optional:
+					// Never deployed; bootstrap skips optional links
+				case d.Missing:
+					Dim.Printf("  %s: not deployed\n", tildePath(d.Link, home))
+				default:
+					Pass("%s: identical copy", tildePath(d.Link, home))
+				}
+			}
+			continue
+		}
+
+		differing++
+		if nameOnly {
+			fmt.Println(d.Link)
+			continue
+		}
+		fmt.Println()
+		printUnifiedDiff(d.Diff)
+		fmt.Println()
+	}
+
+	if differing > 0 {
+		return fmt.Errorf("%d managed file(s) differ from %s", differing, blackdotDir)
+	}
+	return nil
+}
+
+// printUnifiedDiff colors a unified diff the way git does
+func printUnifiedDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			Bold.Println(line)
+		case strings.HasPrefix(line, "@@"):
+			Cyan.Println(line)
+		case strings.HasPrefix(line, "+"):
+			Green.Println(line)
+		case strings.HasPrefix(line, "-"):
+			Red.Println(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -24,6 +25,9 @@ type doctorState struct {
 	warnChecks   []string
 	warnFixes    []string
 
+	fixedCount  int // issues repaired by --fix
+	autoFixable int // issues --fix would repair
+
 	// Colors
 	bold   func(a ...interface{}) string
 	dim    func(a ...interface{}) string
@@ -37,6 +41,8 @@ type doctorState struct {
 func newDoctorCmd() *cobra.Command {
 	var fixMode bool
 	var quickMode bool
+	var bundlePath string
+	var bundleStdout bool
 
 	cmd := &cobra.Command{
 		Use:     "doctor",
@@ -44,6 +50,12 @@ func newDoctorCmd() *cobra.Command {
 		Short:   "Comprehensive blackdot health check",
 		Long:    `Comprehensive blackdot health check`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if bundlePath != "" && bundleStdout {
+				return fmt.Errorf("--bundle and --stdout can't be used together")
+			}
+			if bundlePath != "" || bundleStdout {
+				return runDoctorBundle(bundlePath, bundleStdout)
+			}
 			return runDoctor(fixMode, quickMode)
 		},
 	}
@@ -53,8 +65,10 @@ func newDoctorCmd() *cobra.Command {
 		printDoctorHelp()
 	})
 
-	cmd.Flags().BoolVarP(&fixMode, "fix", "f", false, "Auto-fix permission issues")
+	cmd.Flags().BoolVarP(&fixMode, "fix", "f", false, "Fix safe issues (permissions, managed symlinks, missing directories and Brewfiles)")
 	cmd.Flags().BoolVarP(&quickMode, "quick", "q", false, "Run quick checks only (skip vault)")
+	cmd.Flags().StringVar(&bundlePath, "bundle", "", "Write a redacted JSON support bundle to this file instead of running checks")
+	cmd.Flags().BoolVar(&bundleStdout, "stdout", false, "Print the support bundle to stdout instead of running checks")
 
 	return cmd
 }
@@ -80,7 +94,9 @@ func printDoctorHelp() {
 	fmt.Print(", ")
 	Yellow.Print("-f")
 	fmt.Print("      ")
-	Dim.Println("Auto-fix permission issues")
+	Dim.Println("Fix safe issues: permissions, managed symlinks,")
+	fmt.Print("                 ")
+	Dim.Println("config directory, missing Brewfile tiers")
 	fmt.Print("  ")
 	Yellow.Print("--quick")
 	fmt.Print(", ")
@@ -88,6 +104,16 @@ func printDoctorHelp() {
 	fmt.Print("    ")
 	Dim.Println("Run quick checks only (skip vault)")
 	fmt.Print("  ")
+	Yellow.Print("--bundle FILE")
+	fmt.Print("  ")
+	Dim.Println("Write a redacted JSON support bundle (versions,")
+	fmt.Print("                 ")
+	Dim.Println("paths, features, last lint and health results)")
+	fmt.Print("  ")
+	Yellow.Print("--stdout")
+	fmt.Print("       ")
+	Dim.Println("Print the support bundle to stdout instead")
+	fmt.Print("  ")
 	Yellow.Print("--help")
 	fmt.Print(", ")
 	Yellow.Print("-h")
@@ -100,6 +126,7 @@ func printDoctorHelp() {
 	Dim.Println("  - Version & updates")
 	Dim.Println("  - Core components (symlinks)")
 	Dim.Println("  - Required commands")
+	Dim.Println("  - PATH conflicts (other blackdot/dotfiles binaries)")
 	Dim.Println("  - SSH configuration")
 	Dim.Println("  - AWS configuration")
 	Dim.Println("  - Vault status")
@@ -117,11 +144,15 @@ func printDoctorHelp() {
 	fmt.Print("  ")
 	Yellow.Print("blackdot doctor --fix")
 	fmt.Print("    ")
-	Dim.Println("# Auto-fix permissions")
+	Dim.Println("# Repair what can be fixed safely")
 	fmt.Print("  ")
 	Yellow.Print("blackdot doctor --quick")
 	fmt.Print("  ")
 	Dim.Println("# Fast checks only")
+	fmt.Print("  ")
+	Yellow.Print("blackdot doctor --bundle blackdot-support.json")
+	fmt.Print("  ")
+	Dim.Println("# Attach to an issue")
 	fmt.Println()
 }
 
@@ -158,12 +189,16 @@ func runDoctor(fixMode, quickMode bool) error {
 
 	// Section 2: Core Components
 	state.section("Core Components")
-	checkCoreComponents(state, home, blackdotDir)
+	checkCoreComponents(state, home, blackdotDir, fixMode)
 
 	// Section 3: Required Commands
 	state.section("Required Commands")
 	checkRequiredCommands(state)
 
+	// Section 3b: PATH conflicts
+	state.section("PATH")
+	checkPathConflicts(state)
+
 	// Section 4: SSH Configuration
 	state.section("SSH Configuration")
 	checkSSHConfiguration(state, home, fixMode)
@@ -248,6 +283,13 @@ func (s *doctorState) info(msg string) {
 	fmt.Printf("%s %s\n", s.blue("ℹ"), msg)
 }
 
+// fixed reports an issue repaired by --fix; it counts as a passed check
+func (s *doctorState) fixed(msg string) {
+	fmt.Printf("%s Fixed: %s\n", s.green("✓"), msg)
+	s.checksPassed++
+	s.fixedCount++
+}
+
 func checkVersionAndUpdates(state *doctorState, blackdotDir string) {
 	// Check version from CHANGELOG.md
 	changelogPath := filepath.Join(blackdotDir, "CHANGELOG.md")
@@ -300,11 +342,26 @@ func checkVersionAndUpdates(state *doctorState, blackdotDir string) {
 	}
 }
 
-func checkCoreComponents(state *doctorState, home, blackdotDir string) {
-	// Check symlinks
+func checkCoreComponents(state *doctorState, home, blackdotDir string, fixMode bool) {
+	// Check symlinks. Missing or mis-pointed links are re-created with --fix
+	// when the file they should point at exists; a regular file in the way
+	// may hold local edits, so it's only reported.
 	checkSymlink := func(name, link, target string) {
+		expectedFullPath := filepath.Join(blackdotDir, target)
+		_, srcErr := os.Stat(expectedFullPath)
+		canFix := srcErr == nil
+
 		info, err := os.Lstat(link)
 		if err != nil {
+			if fixMode && canFix {
+				if err := os.Symlink(expectedFullPath, link); err == nil {
+					state.fixed(fmt.Sprintf("%s symlink created -> %s", name, expectedFullPath))
+					return
+				}
+			}
+			if canFix {
+				state.autoFixable++
+			}
 			state.fail(fmt.Sprintf("%s symlink missing", name), fmt.Sprintf("ln -sf \"$BLACKDOT_DIR/%s\" \"%s\"", target, link))
 			return
 		}
@@ -312,14 +369,27 @@ func checkCoreComponents(state *doctorState, home, blackdotDir string) {
 		if info.Mode()&os.ModeSymlink != 0 {
 			actualTarget, _ := os.Readlink(link)
 			expectedTarget := target
-			expectedFullPath := filepath.Join(blackdotDir, target)
 
 			if actualTarget == expectedTarget || actualTarget == expectedFullPath {
-				state.pass(fmt.Sprintf("%s symlink OK", name))
-			} else {
-				state.fail(fmt.Sprintf("%s points to wrong target: %s", name, actualTarget),
-					fmt.Sprintf("rm \"%s\" && ln -sf \"$BLACKDOT_DIR/%s\" \"%s\"", link, target, link))
+				if canFix {
+					state.pass(fmt.Sprintf("%s symlink OK", name))
+				} else {
+					state.fail(fmt.Sprintf("%s points to a missing file: %s", name, expectedFullPath),
+						fmt.Sprintf("git -C \"$BLACKDOT_DIR\" checkout -- %s", target))
+				}
+				return
+			}
+			if fixMode && canFix {
+				if err := replaceSymlink(link, expectedFullPath); err == nil {
+					state.fixed(fmt.Sprintf("%s re-pointed from %s to %s", name, actualTarget, expectedFullPath))
+					return
+				}
 			}
+			if canFix {
+				state.autoFixable++
+			}
+			state.fail(fmt.Sprintf("%s points to wrong target: %s", name, actualTarget),
+				fmt.Sprintf("rm \"%s\" && ln -sf \"$BLACKDOT_DIR/%s\" \"%s\"", link, target, link))
 		} else {
 			state.warn(fmt.Sprintf("%s exists but is not a symlink", name),
 				fmt.Sprintf("mv \"%s\" \"%s.backup\" && ln -sf \"$BLACKDOT_DIR/%s\" \"%s\"", link, link, target, link))
@@ -329,6 +399,9 @@ func checkCoreComponents(state *doctorState, home, blackdotDir string) {
 	checkSymlink("~/.zshrc", filepath.Join(home, ".zshrc"), "zsh/zshrc")
 	checkSymlink("~/.p10k.zsh", filepath.Join(home, ".p10k.zsh"), "zsh/p10k.zsh")
 
+	checkConfigDir(state, fixMode)
+	checkBrewfileTiers(state, blackdotDir, fixMode)
+
 	// Check ~/.claude symlink (special case - not relative to blackdotDir)
 	workspaceTarget := filepath.Join(home, "workspace")
 	if wt := os.Getenv("WORKSPACE_TARGET"); wt != "" {
@@ -366,6 +439,87 @@ func checkCoreComponents(state *doctorState, home, blackdotDir string) {
 	}
 }
 
+// replaceSymlink points link at target, swapping the old link out
+// atomically via a temporary name
+func replaceSymlink(link, target string) error {
+	tmp := link + ".blackdot-tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// checkConfigDir checks that ~/.config/blackdot exists, creating it with --fix
+func checkConfigDir(state *doctorState, fixMode bool) {
+	dir := ConfigDir()
+	info, err := os.Stat(dir)
+	switch {
+	case err == nil && info.IsDir():
+		state.pass(fmt.Sprintf("Config directory exists (%s)", dir))
+	case err == nil:
+		state.fail(fmt.Sprintf("%s exists but is not a directory", dir), "")
+	case fixMode:
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			state.fail(fmt.Sprintf("Could not create %s: %v", dir, err), "")
+			return
+		}
+		state.fixed(fmt.Sprintf("created %s", dir))
+	default:
+		state.autoFixable++
+		state.warn(fmt.Sprintf("Config directory missing (%s)", dir), fmt.Sprintf("mkdir -p \"%s\"", dir))
+	}
+}
+
+// checkBrewfileTiers checks that every Brewfile tier exists. A missing tier
+// is regenerated from the 'blackdot init' starter with --fix. Repositories
+// without a brew/ directory are skipped.
+func checkBrewfileTiers(state *doctorState, blackdotDir string, fixMode bool) {
+	brewDir := filepath.Join(blackdotDir, "brew")
+	if info, err := os.Stat(brewDir); err != nil || !info.IsDir() {
+		return
+	}
+
+	starters := make(map[string]string)
+	for _, f := range scaffoldFiles() {
+		if filepath.Dir(f.path) == "brew" {
+			starters[filepath.Base(f.path)] = f.content
+		}
+	}
+
+	var missing []string
+	repaired := 0
+	for _, name := range []string{"Brewfile.minimal", "Brewfile.enhanced", "Brewfile"} {
+		path := filepath.Join(brewDir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if fixMode {
+			if err := os.WriteFile(path, []byte(starters[name]), 0644); err != nil {
+				state.fail(fmt.Sprintf("Could not write brew/%s: %v", name, err), "")
+				continue
+			}
+			state.fixed(fmt.Sprintf("regenerated brew/%s from the starter template", name))
+			repaired++
+			continue
+		}
+		missing = append(missing, name)
+	}
+
+	if len(missing) == 0 {
+		if repaired == 0 {
+			state.pass("Brewfile tiers present (minimal, enhanced, full)")
+		}
+		return
+	}
+	state.autoFixable += len(missing)
+	state.warn(fmt.Sprintf("Missing Brewfile tier(s): %s", strings.Join(missing, ", ")), "blackdot doctor --fix")
+}
+
 func checkRequiredCommands(state *doctorState) {
 	checkCommand := func(cmd, pkg string) {
 		if path, err := exec.LookPath(cmd); err == nil {
@@ -399,6 +553,97 @@ func checkRequiredCommands(state *doctorState) {
 	}
 }
 
+// pathConflictNames are the binaries that can shadow the running one;
+// dotfiles is blackdot's former name
+var pathConflictNames = []string{"blackdot", "dotfiles"}
+
+// pathVersionTimeout bounds each "<binary> version" run by the PATH check
+const pathVersionTimeout = 5 * time.Second
+
+// lookPathAll returns every executable called name in the directories of
+// pathList, in PATH order. exec.LookPath stops at the first match.
+func lookPathAll(name, pathList string) []string {
+	var matches []string
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" || seen[filepath.Clean(dir)] {
+			continue
+		}
+		seen[filepath.Clean(dir)] = true
+		if path, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+			matches = append(matches, path)
+		}
+	}
+	return matches
+}
+
+// binaryVersion returns the first line of "<path> version", or "unknown"
+func binaryVersion(path string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), pathVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "version").Output()
+	version := strings.TrimSpace(strings.TrimPrefix(strings.Split(strings.TrimSpace(string(out)), "\n")[0], "⚫"))
+	if err != nil || version == "" {
+		return "unknown"
+	}
+	if len(version) > 40 {
+		version = version[:40]
+	}
+	return version
+}
+
+// checkPathConflicts lists every blackdot and dotfiles binary on PATH with
+// its version, and warns when the running binary isn't the one a shell
+// would run
+func checkPathConflicts(state *doctorState) {
+	self, err := os.Executable()
+	if err != nil {
+		state.warn(fmt.Sprintf("Could not locate the running binary: %v", err), "")
+		return
+	}
+	selfInfo, selfErr := os.Stat(self)
+	isSelf := func(path string) bool {
+		info, err := os.Stat(path)
+		return selfErr == nil && err == nil && os.SameFile(info, selfInfo)
+	}
+	versions := make(map[string]string)
+	version := func(path string) string {
+		if _, ok := versions[path]; !ok {
+			if isSelf(path) {
+				versions[path] = "blackdot " + versionStr + ", running"
+			} else {
+				versions[path] = binaryVersion(path)
+			}
+		}
+		return versions[path]
+	}
+
+	pathList := os.Getenv("PATH")
+	blackdots := lookPathAll("blackdot", pathList)
+	for _, name := range pathConflictNames {
+		for _, path := range lookPathAll(name, pathList) {
+			state.info(fmt.Sprintf("%s %s", path, state.dim(fmt.Sprintf("(%s)", version(path)))))
+		}
+	}
+
+	switch {
+	case len(blackdots) == 0:
+		state.warn(fmt.Sprintf("blackdot is not on PATH (running %s)", self),
+			fmt.Sprintf("export PATH=\"%s:$PATH\"", filepath.Dir(self)))
+	case !isSelf(blackdots[0]):
+		state.warn(fmt.Sprintf("%s (%s) shadows the running binary %s (blackdot %s)", blackdots[0], version(blackdots[0]), self, versionStr),
+			fmt.Sprintf("Remove %s or put %s earlier in PATH", blackdots[0], filepath.Dir(self)))
+	default:
+		state.pass("Running blackdot is first on PATH")
+	}
+
+	for _, path := range lookPathAll("dotfiles", pathList) {
+		if !isSelf(path) {
+			state.warn(fmt.Sprintf("Legacy dotfiles binary on PATH: %s (%s)", path, version(path)), fmt.Sprintf("rm %s", path))
+		}
+	}
+}
+
 func checkSSHConfiguration(state *doctorState, home string, fixMode bool) {
 	sshDir := filepath.Join(home, ".ssh")
 
@@ -415,8 +660,9 @@ func checkSSHConfiguration(state *doctorState, home string, fixMode bool) {
 	} else {
 		if fixMode {
 			os.Chmod(sshDir, 0700)
-			state.pass("~/.ssh permissions fixed to 700")
+			state.fixed("~/.ssh permissions set to 700")
 		} else {
+			state.autoFixable++
 			state.fail(fmt.Sprintf("~/.ssh has permissions %04o (should be 700)", perms), "chmod 700 ~/.ssh")
 		}
 	}
@@ -436,8 +682,9 @@ func checkSSHConfiguration(state *doctorState, home string, fixMode bool) {
 			if keyPerms != 0600 {
 				if fixMode {
 					os.Chmod(keyPath, 0600)
-					state.pass(fmt.Sprintf("Fixed permissions on %s", name))
+					state.fixed(fmt.Sprintf("%s permissions set to 600", name))
 				} else {
+					state.autoFixable++
 					state.fail(fmt.Sprintf("%s has permissions %04o (should be 600)", name, keyPerms),
 						fmt.Sprintf("chmod 600 \"%s\"", keyPath))
 				}
@@ -450,6 +697,19 @@ func checkSSHConfiguration(state *doctorState, home string, fixMode bool) {
 	} else {
 		state.warn("No SSH keys found in ~/.ssh", "ssh-keygen -t ed25519 -C \"your_email@example.com\"")
 	}
+
+	// Weak algorithms need a new key, which is the user's call: report only,
+	// even with --fix. Missing passphrases are left to 'tools ssh audit'.
+	if audits, err := auditSSHDir(sshDir); err == nil {
+		for _, a := range audits {
+			for _, f := range a.Findings {
+				if f.Issue == sshIssueNoPassphrase {
+					continue
+				}
+				state.warn(fmt.Sprintf("%s: %s", a.Name, f.Issue), "blackdot tools ssh gen <name>  # then retire the old key")
+			}
+		}
+	}
 }
 
 func checkAWSConfiguration(state *doctorState, home string, fixMode bool) {
@@ -471,8 +731,9 @@ func checkAWSConfiguration(state *doctorState, home string, fixMode bool) {
 		} else {
 			if fixMode {
 				os.Chmod(credsPath, 0600)
-				state.pass("Fixed ~/.aws/credentials permissions")
+				state.fixed("~/.aws/credentials permissions set to 600")
 			} else {
+				state.autoFixable++
 				state.fail(fmt.Sprintf("~/.aws/credentials has permissions %04o (should be 600)", perms),
 					"chmod 600 ~/.aws/credentials")
 			}
@@ -767,13 +1028,7 @@ func printSummary(state *doctorState, fixMode bool) {
 
 		// Auto-fix suggestion
 		if !fixMode {
-			fixable := 0
-			for _, check := range state.failedChecks {
-				if strings.Contains(check, "permissions") {
-					fixable++
-				}
-			}
-			if fixable > 0 {
+			if fixable := state.autoFixable; fixable > 0 {
 				fmt.Printf("  %s\n", state.bold(fmt.Sprintf("Auto-fix available for %d issue(s):", fixable)))
 				fmt.Printf("    %s blackdot doctor --fix\n", state.green("→"))
 				fmt.Println()
@@ -841,7 +1096,7 @@ func saveMetrics(state *doctorState, blackdotDir, home string) {
 		"health_score": healthScore,
 		"errors":       state.checksFailed,
 		"warnings":     state.checksWarned,
-		"fixed":        0,
+		"fixed":        state.fixedCount,
 		"git_branch":   gitBranch,
 		"hostname":     hostname,
 		"os":           osName,
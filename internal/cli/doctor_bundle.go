@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+)
+
+// doctorBundle is the support bundle doctor --bundle writes: what a
+// maintainer needs to triage an issue, with secrets and the home
+// directory scrubbed
+type doctorBundle struct {
+	Generated  string            `json:"generated"`
+	Blackdot   versionInfo       `json:"blackdot"`
+	Paths      map[string]string `json:"paths"`
+	Tools      []bundleTool      `json:"tools"`
+	Features   []string          `json:"enabled_features"`
+	LastLint   *lintSummary      `json:"last_lint"`
+	LastHealth *MetricEntry      `json:"last_health"`
+	Env        map[string]string `json:"env"`
+}
+
+// bundleTool is an external command blackdot uses; Path is empty when it
+// isn't installed
+type bundleTool struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// bundleTools are reported whether or not they are installed, with the
+// arguments that print each one's version
+var bundleTools = []struct {
+	name string
+	args []string
+}{
+	{"zsh", []string{"--version"}},
+	{"bash", []string{"--version"}},
+	{"git", []string{"--version"}},
+	{"brew", []string{"--version"}},
+	{"jq", []string{"--version"}},
+	{"go", []string{"version"}},
+	{"shellcheck", []string{"--version"}},
+	{"pwsh", []string{"--version"}},
+	{"docker", []string{"--version"}},
+	{"bw", []string{"--version"}},
+	{"op", []string{"--version"}},
+	{"pass", []string{"version"}},
+}
+
+// bundleEnvNames are environment variables worth reporting, besides
+// anything starting with BLACKDOT_ or XDG_
+var bundleEnvNames = []string{"SHELL", "TERM", "LANG", "EDITOR"}
+
+// bundleSecretEnvPattern matches variable names whose values are never
+// included, such as BW_SESSION or a token someone exported as BLACKDOT_*
+var bundleSecretEnvPattern = regexp.MustCompile(`(?i)token|secret|pass|key|session|credential|auth`)
+
+const bundleRedacted = "[redacted]"
+
+// runDoctorBundle writes the support bundle to path, or to stdout when
+// toStdout is set, instead of running the health checks
+func runDoctorBundle(path string, toStdout bool) error {
+	home, _ := os.UserHomeDir()
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
+	}
+	bundle := collectDoctorBundle(home, blackdotDir)
+
+	if toStdout {
+		return writeDoctorBundle(os.Stdout, bundle)
+	}
+	// Only the user should read it until they've looked it over
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := writeDoctorBundle(f, bundle); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	Pass("Wrote support bundle to %s", path)
+	Info("Review it before attaching it to an issue")
+	return nil
+}
+
+// collectDoctorBundle gathers the bundle for home and blackdotDir
+func collectDoctorBundle(home, blackdotDir string) doctorBundle {
+	info := buildVersionInfo(debug.ReadBuildInfo())
+	info.Dependencies = nil
+
+	executable, _ := os.Executable()
+	bundle := doctorBundle{
+		Generated: time.Now().UTC().Format(time.RFC3339),
+		Blackdot:  info,
+		Paths: map[string]string{
+			"blackdot_dir": blackdotDir,
+			"config_dir":   ConfigDir(),
+			"cache_dir":    CacheDir(),
+			"executable":   executable,
+			"metrics":      filepath.Join(home, ".blackdot-metrics.jsonl"),
+			"lint_summary": lintSummaryPath(),
+		},
+		Features: enabledFeatureNames(),
+		Env:      bundleEnv(os.Environ()),
+	}
+	if bundle.Features == nil {
+		bundle.Features = []string{}
+	}
+
+	for _, tool := range bundleTools {
+		entry := bundleTool{Name: tool.name}
+		if path, err := exec.LookPath(tool.name); err == nil {
+			entry.Path = path
+			entry.Version = toolVersion(path, tool.args...)
+		}
+		bundle.Tools = append(bundle.Tools, entry)
+	}
+
+	if summary, err := loadLintSummary(); err != nil {
+		logger.Debug("could not read lint summary", "error", err)
+	} else {
+		bundle.LastLint = summary
+	}
+	if entries, err := loadMetrics(bundle.Paths["metrics"]); err == nil && len(entries) > 0 {
+		last := entries[len(entries)-1]
+		last.Hostname = bundleRedacted
+		bundle.LastHealth = &last
+	}
+
+	scrubBundleHome(&bundle, home)
+	return bundle
+}
+
+// toolVersion returns the first line a tool prints for its version, or
+// "unknown" when it prints nothing or doesn't finish in time
+func toolVersion(path string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), pathVersionTimeout)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, path, args...).Output()
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if len(line) > 80 {
+				line = line[:80]
+			}
+			return line
+		}
+	}
+	return "unknown"
+}
+
+// bundleEnv picks the reported variables out of environ, redacting any
+// whose name looks like it holds a secret
+func bundleEnv(environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		if !strings.HasPrefix(name, "BLACKDOT_") && !strings.HasPrefix(name, "XDG_") && !slices.Contains(bundleEnvNames, name) {
+			continue
+		}
+		if bundleSecretEnvPattern.MatchString(name) {
+			value = bundleRedacted
+		}
+		env[name] = value
+	}
+	return env
+}
+
+// scrubBundleHome replaces the home directory, which usually contains the
+// user's name, with ~ in every path and value
+func scrubBundleHome(bundle *doctorBundle, home string) {
+	if home == "" || home == "/" {
+		return
+	}
+	scrub := func(s string) string {
+		if s == home {
+			return "~"
+		}
+		return strings.ReplaceAll(s, home+string(filepath.Separator), "~"+string(filepath.Separator))
+	}
+	for _, m := range []map[string]string{bundle.Paths, bundle.Env} {
+		for k, v := range m {
+			m[k] = scrub(v)
+		}
+	}
+	for i := range bundle.Tools {
+		bundle.Tools[i].Path = scrub(bundle.Tools[i].Path)
+	}
+	if bundle.LastLint != nil {
+		bundle.LastLint.Dir = scrub(bundle.LastLint.Dir)
+	}
+}
+
+// writeDoctorBundle writes bundle as indented JSON
+func writeDoctorBundle(w io.Writer, bundle doctorBundle) error {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding bundle: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
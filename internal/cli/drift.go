@@ -47,23 +47,30 @@ func newDriftCmd() *cobra.Command {
 Modes:
   (default)   Full check - connects to vault and compares
   --quick, -q Fast check against cached state (no vault access)
+  --links, -l Check managed symlinks point into BLACKDOT_DIR
 
 The quick mode compares local files against the last vault pull.
 Full mode connects to vault and compares current vault contents.
 
 Examples:
   blackdot drift          # Full check (connects to vault)
-  blackdot drift --quick  # Fast check against cached state`,
+  blackdot drift --quick  # Fast check against cached state
+  blackdot drift --links  # Check symlinks (exits non-zero on drift)
+  blackdot drift --links --json`,
 		RunE: runDrift,
 	}
 
 	cmd.Flags().BoolP("quick", "q", false, "Fast check against cached state (no vault access)")
+	cmd.Flags().BoolP("links", "l", false, "Check managed symlinks instead of vault items")
+	cmd.Flags().Bool("json", false, "Output --links results as JSON")
 
 	return cmd
 }
 
 func runDrift(cmd *cobra.Command, args []string) error {
 	quickMode, _ := cmd.Flags().GetBool("quick")
+	linksMode, _ := cmd.Flags().GetBool("links")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
 
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -76,6 +83,13 @@ func runDrift(cmd *cobra.Command, args []string) error {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	dim := color.New(color.Faint).SprintFunc()
 
+	if jsonOutput && !linksMode {
+		return fmt.Errorf("--json is only supported with --links")
+	}
+	if linksMode {
+		return runDriftLinks(home, jsonOutput)
+	}
+
 	if quickMode {
 		return runDriftQuick(home, green, yellow, dim)
 	}
@@ -85,11 +99,7 @@ func runDrift(cmd *cobra.Command, args []string) error {
 
 // runDriftQuick performs a quick drift check against cached state
 func runDriftQuick(home string, green, yellow, dim func(a ...interface{}) string) error {
-	cacheDir := os.Getenv("XDG_CACHE_HOME")
-	if cacheDir == "" {
-		cacheDir = filepath.Join(home, ".cache")
-	}
-	stateFile := filepath.Join(cacheDir, "blackdot", "vault-state.json")
+	stateFile := getVaultDriftStatePath()
 
 	// Check if state file exists
 	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
@@ -150,9 +160,9 @@ func runDriftQuick(home string, green, yellow, dim func(a ...interface{}) string
 
 // runDriftFull performs a full drift check against vault
 func runDriftFull(home string, green, yellow, cyan, dim func(a ...interface{}) string) error {
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		blackdotDir = filepath.Join(home, ".blackdot")
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
 	}
 
 	// For full mode, we'd need to connect to vault
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Link drift statuses. Everything except ok and copy counts as drift.
+const (
+	linkOK          = "ok"
+	linkCopy        = "copy"         // regular file identical to the repo source
+	linkMissing     = "missing"      // nothing at the link path
+	linkBroken      = "broken"       // symlink whose target doesn't exist
+	linkWrongTarget = "wrong-target" // symlink to some other file
+	linkModified    = "modified"     // regular file that differs from the repo source
+)
+
+// managedLink is a symlink created by bootstrap-blackdot.sh
+type managedLink struct {
+	Name     string
+	Link     string
+	Source   string // relative to BLACKDOT_DIR
+	Optional bool   // bootstrap may skip it; absence isn't drift
+}
+
+// linkDrift is the result of checking one managed link
+type linkDrift struct {
+	Name     string `json:"name"`
+	Link     string `json:"link"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+	Status   string `json:"status"`
+}
+
+// linkDriftReport is the --json output of 'drift --links'
+type linkDriftReport struct {
+	BlackdotDir string      `json:"blackdot_dir"`
+	Drifted     int         `json:"drifted"`
+	Links       []linkDrift `json:"links"`
+}
+
+// managedLinks mirrors the safe_symlink calls in bootstrap/bootstrap-blackdot.sh
+func managedLinks(home, blackdotDir string) []managedLink {
+	links := []managedLink{
+		{Name: "zshrc", Link: filepath.Join(home, ".zshrc"), Source: "zsh/zshrc"},
+		{Name: "p10k", Link: filepath.Join(home, ".p10k.zsh"), Source: "zsh/p10k.zsh", Optional: true},
+		{Name: "zellij", Link: filepath.Join(home, ".config", "zellij", "config.kdl"), Source: "zellij/config.kdl", Optional: true},
+	}
+	if runtime.GOOS == "darwin" {
+		links = append(links, managedLink{
+			Name:     "ghostty",
+			Link:     filepath.Join(home, "Library", "Application Support", "com.mitchellh.ghostty", "config"),
+			Source:   "ghostty/config",
+			Optional: true,
+		})
+	}
+
+	if os.Getenv("SKIP_CLAUDE_SETUP") != "true" {
+		workspaceTarget := filepath.Join(home, "workspace")
+		if wt := os.Getenv("WORKSPACE_TARGET"); wt != "" {
+			workspaceTarget = wt
+		}
+		claudeShared := filepath.Join(workspaceTarget, ".claude")
+
+		links = append(links, managedLink{
+			Name:     "claude-settings",
+			Link:     filepath.Join(claudeShared, "settings.json"),
+			Source:   "claude/settings.json",
+			Optional: true,
+		})
+		commands, _ := filepath.Glob(filepath.Join(blackdotDir, "claude", "commands", "*.md"))
+		for _, cmd := range commands {
+			base := filepath.Base(cmd)
+			links = append(links, managedLink{
+				Name:     "claude-command:" + strings.TrimSuffix(base, ".md"),
+				Link:     filepath.Join(claudeShared, "commands", base),
+				Source:   "claude/commands/" + base,
+				Optional: true,
+			})
+		}
+	}
+
+	// Links whose source isn't in the repo are never created
+	var present []managedLink
+	for _, l := range links {
+		if _, err := os.Stat(filepath.Join(blackdotDir, l.Source)); err == nil {
+			present = append(present, l)
+		}
+	}
+	return present
+}
+
+// checkManagedLink classifies one link. ok is false when it isn't applicable
+// (an optional link that was never created).
+func checkManagedLink(l managedLink, blackdotDir string) (linkDrift, bool) {
+	expected := filepath.Join(blackdotDir, l.Source)
+	d := linkDrift{Name: l.Name, Link: l.Link, Expected: expected}
+
+	info, err := os.Lstat(l.Link)
+	if err != nil {
+		if l.Optional {
+			return d, false
+		}
+		d.Status = linkMissing
+		return d, true
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		if fileChecksum(l.Link) == fileChecksum(expected) {
+			d.Status = linkCopy
+		} else {
+			d.Status = linkModified
+		}
+		return d, true
+	}
+
+	d.Actual, _ = os.Readlink(l.Link)
+	resolved, err := filepath.EvalSymlinks(l.Link)
+	if err != nil {
+		d.Status = linkBroken
+		return d, true
+	}
+	// Resolve the expected side too so /tmp vs /private/tmp style aliases match
+	if want, err := filepath.EvalSymlinks(expected); err == nil && resolved == want {
+		d.Status = linkOK
+	} else {
+		d.Status = linkWrongTarget
+	}
+	return d, true
+}
+
+// checkManagedLinks checks every managed link and returns the results
+// and how many have drifted
+func checkManagedLinks(home, blackdotDir string) ([]linkDrift, int) {
+	results := []linkDrift{}
+	drifted := 0
+	for _, l := range managedLinks(home, blackdotDir) {
+		d, ok := checkManagedLink(l, blackdotDir)
+		if !ok {
+			continue
+		}
+		if d.Status != linkOK && d.Status != linkCopy {
+			drifted++
+		}
+		results = append(results, d)
+	}
+	return results, drifted
+}
+
+// runDriftLinks reports managed symlinks that no longer point into BLACKDOT_DIR
+func runDriftLinks(home string, jsonOutput bool) error {
+	dir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
+	}
+
+	results, drifted := checkManagedLinks(home, dir)
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(linkDriftReport{BlackdotDir: dir, Drifted: drifted, Links: results}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		PrintHeader("Symlink Drift")
+		for _, d := range results {
+			switch d.Status {
+			case linkOK:
+				Pass("%s: %s", d.Name, d.Link)
+			case linkCopy:
+				Warn("%s: regular file matching %s (not a symlink)", d.Name, d.Expected)
+			case linkMissing:
+				Fail("%s: missing %s", d.Name, d.Link)
+			case linkBroken:
+				Fail("%s: broken link -> %s", d.Name, d.Actual)
+			case linkWrongTarget:
+				Fail("%s: points to %s (expected %s)", d.Name, d.Actual, d.Expected)
+			case linkModified:
+				Fail("%s: %s is a regular file that differs from %s", d.Name, d.Link, d.Expected)
+			}
+		}
+		fmt.Println()
+		if drifted == 0 {
+			Pass("All %d managed links are in place", len(results))
+		} else {
+			PrintHint("Re-link with: %s", filepath.Join(dir, "bootstrap", "bootstrap-blackdot.sh"))
+		}
+	}
+
+	if drifted > 0 {
+		return fmt.Errorf("%d of %d managed links have drifted", drifted, len(results))
+	}
+	return nil
+}
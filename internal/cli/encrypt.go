@@ -57,14 +57,18 @@ func newEncryptCmd() *cobra.Command {
 	}
 	encryptCmd.Flags().BoolP("keep", "k", false, "Keep original file")
 	encryptCmd.Flags().BoolP("dry-run", "n", false, "Show what would be done")
+	encryptCmd.Flags().BoolP("passphrase", "p", false, "Encrypt with a passphrase to <file>.enc instead of the age key")
+	encryptCmd.Flags().BoolP("in-place", "i", false, "With --passphrase, overwrite the file instead of writing <file>.enc")
+	encryptCmd.Flags().StringSliceP("recipient", "r", nil, "Encrypt for this age public key instead of your own (repeatable)")
 
 	decryptCmd := &cobra.Command{
 		Use:   "decrypt <file>",
-		Short: "Decrypt a .age file",
+		Short: "Decrypt a .age or passphrase-encrypted file",
 		RunE:  runDecryptFile,
 	}
 	decryptCmd.Flags().BoolP("keep", "k", false, "Keep encrypted file")
 	decryptCmd.Flags().BoolP("dry-run", "n", false, "Show what would be done")
+	decryptCmd.Flags().String("identity", "", "Decrypt with the age private key in this file instead of your own")
 
 	editCmd := &cobra.Command{
 		Use:   "edit <file>",
@@ -141,7 +145,7 @@ func runEncryptInit(cmd *cobra.Command, args []string) error {
 	if !isAgeInstalled() {
 		fmt.Println(color.RedString("[FAIL]") + " 'age' is not installed")
 		fmt.Println("Install with: brew install age")
-		return fmt.Errorf("age not installed")
+		return classErrorf(ErrToolMissing, "age not installed")
 	}
 
 	if isEncryptionInitialized() && !force {
@@ -235,6 +239,9 @@ func runEncryptInit(cmd *cobra.Command, args []string) error {
 func runEncryptFile(cmd *cobra.Command, args []string) error {
 	keep, _ := cmd.Flags().GetBool("keep")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	usePassphrase, _ := cmd.Flags().GetBool("passphrase")
+	inPlace, _ := cmd.Flags().GetBool("in-place")
+	recipients, _ := cmd.Flags().GetStringSlice("recipient")
 
 	if len(args) == 0 {
 		fmt.Println(color.RedString("[FAIL]") + " No file specified")
@@ -243,6 +250,15 @@ func runEncryptFile(cmd *cobra.Command, args []string) error {
 	}
 
 	inputFile := args[0]
+	if len(recipients) > 0 {
+		if usePassphrase || inPlace {
+			return fmt.Errorf("--recipient can't be used with --passphrase or --in-place")
+		}
+		return runRecipientsEncrypt(inputFile, recipients, keep, dryRun)
+	}
+	if usePassphrase || inPlace {
+		return runPassphraseEncrypt(inputFile, inPlace, keep, dryRun)
+	}
 	outputFile := inputFile + ".age"
 
 	if dryRun {
@@ -252,7 +268,7 @@ func runEncryptFile(cmd *cobra.Command, args []string) error {
 
 	if !isAgeInstalled() {
 		fmt.Println(color.RedString("[FAIL]") + " 'age' is not installed")
-		return fmt.Errorf("age not installed")
+		return classErrorf(ErrToolMissing, "age not installed")
 	}
 
 	if !isEncryptionInitialized() {
@@ -263,7 +279,7 @@ func runEncryptFile(cmd *cobra.Command, args []string) error {
 
 	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
 		fmt.Printf("%s File not found: %s\n", color.RedString("[FAIL]"), inputFile)
-		return fmt.Errorf("file not found: %s", inputFile)
+		return classErrorf(ErrFileNotFound, "file not found: %s", inputFile)
 	}
 
 	if strings.HasSuffix(inputFile, ".age") {
@@ -292,6 +308,7 @@ func runEncryptFile(cmd *cobra.Command, args []string) error {
 func runDecryptFile(cmd *cobra.Command, args []string) error {
 	keep, _ := cmd.Flags().GetBool("keep")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	identity, _ := cmd.Flags().GetString("identity")
 
 	if len(args) == 0 {
 		fmt.Println(color.RedString("[FAIL]") + " No file specified")
@@ -300,6 +317,20 @@ func runDecryptFile(cmd *cobra.Command, args []string) error {
 	}
 
 	inputFile := args[0]
+	if isPassphraseEncrypted(inputFile) {
+		return runPassphraseDecrypt(inputFile, keep, dryRun)
+	}
+	if identity != "" {
+		if _, err := os.Stat(identity); err != nil {
+			return fmt.Errorf("--identity: %w", err)
+		}
+	}
+	if isRecipientsEncrypted(inputFile) {
+		if identity == "" {
+			identity = getAgeKeyFile()
+		}
+		return runRecipientsDecrypt(inputFile, identity, keep, dryRun)
+	}
 	outputFile := strings.TrimSuffix(inputFile, ".age")
 
 	if dryRun {
@@ -309,18 +340,21 @@ func runDecryptFile(cmd *cobra.Command, args []string) error {
 
 	if !isAgeInstalled() {
 		fmt.Println(color.RedString("[FAIL]") + " 'age' is not installed")
-		return fmt.Errorf("age not installed")
+		return classErrorf(ErrToolMissing, "age not installed")
 	}
 
-	if !isEncryptionInitialized() {
+	if identity == "" && !isEncryptionInitialized() {
 		fmt.Println(color.RedString("[FAIL]") + " Encryption not initialized")
 		fmt.Println("Run: blackdot encrypt init")
 		return fmt.Errorf("encryption not initialized")
 	}
+	if identity == "" {
+		identity = getAgeKeyFile()
+	}
 
 	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
 		fmt.Printf("%s File not found: %s\n", color.RedString("[FAIL]"), inputFile)
-		return fmt.Errorf("file not found: %s", inputFile)
+		return classErrorf(ErrFileNotFound, "file not found: %s", inputFile)
 	}
 
 	if !strings.HasSuffix(inputFile, ".age") {
@@ -329,7 +363,7 @@ func runDecryptFile(cmd *cobra.Command, args []string) error {
 	}
 
 	// Decrypt using private key
-	decryptCmd := exec.Command("age", "-d", "-i", getAgeKeyFile(), "-o", outputFile, inputFile)
+	decryptCmd := exec.Command("age", "-d", "-i", identity, "-o", outputFile, inputFile)
 	if err := decryptCmd.Run(); err != nil {
 		return fmt.Errorf("decrypting file: %w", err)
 	}
@@ -368,10 +402,18 @@ func runEncryptEdit(cmd *cobra.Command, args []string) error {
 		// Decrypt to temp file
 		tempFile := strings.TrimSuffix(file, ".age")
 
-		// Decrypt (keep encrypted)
-		decryptCmd := exec.Command("age", "-d", "-i", getAgeKeyFile(), "-o", tempFile, file)
-		if err := decryptCmd.Run(); err != nil {
-			return fmt.Errorf("decrypting for edit: %w", err)
+		// Decrypt (keep encrypted); a shared file keeps its recipients
+		var recipients []string
+		if isRecipientsEncrypted(file) {
+			var err error
+			if recipients, err = decryptRecipientsFile(file, tempFile, getAgeKeyFile()); err != nil {
+				return fmt.Errorf("decrypting for edit: %w", err)
+			}
+		} else {
+			decryptCmd := exec.Command("age", "-d", "-i", getAgeKeyFile(), "-o", tempFile, file)
+			if err := decryptCmd.Run(); err != nil {
+				return fmt.Errorf("decrypting for edit: %w", err)
+			}
 		}
 
 		// Edit
@@ -384,9 +426,19 @@ func runEncryptEdit(cmd *cobra.Command, args []string) error {
 		}
 
 		// Re-encrypt (removes temp file)
-		encryptCmd := exec.Command("age", "-R", getAgeRecipientsFile(), "-o", file, tempFile)
-		if err := encryptCmd.Run(); err != nil {
-			return fmt.Errorf("re-encrypting: %w", err)
+		if recipients != nil {
+			sealed, err := encryptToRecipients(tempFile, recipients)
+			if err == nil {
+				err = writeFileAtomic(file, sealed, 0600)
+			}
+			if err != nil {
+				return fmt.Errorf("re-encrypting: %w", err)
+			}
+		} else {
+			encryptCmd := exec.Command("age", "-R", getAgeRecipientsFile(), "-o", file, tempFile)
+			if err := encryptCmd.Run(); err != nil {
+				return fmt.Errorf("re-encrypting: %w", err)
+			}
 		}
 		os.Remove(tempFile)
 
@@ -416,10 +468,9 @@ func runEncryptEdit(cmd *cobra.Command, args []string) error {
 }
 
 func runEncryptList(cmd *cobra.Command, args []string) error {
-	home, _ := os.UserHomeDir()
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		blackdotDir = filepath.Join(home, ".blackdot")
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
 	}
 
 	bold := color.New(color.Bold).SprintFunc()
@@ -489,7 +540,7 @@ func runEncryptStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("age installed: %s\n", green(version))
 	} else {
 		fmt.Printf("age installed: %s (install with: brew install age)\n", red("NO"))
-		return fmt.Errorf("age not installed")
+		return classErrorf(ErrToolMissing, "age not installed")
 	}
 
 	if isEncryptionInitialized() {
@@ -507,10 +558,9 @@ func runEncryptStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 
 	// Count encrypted files
-	home, _ := os.UserHomeDir()
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		blackdotDir = filepath.Join(home, ".blackdot")
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
 	}
 
 	count := 0
@@ -582,7 +632,7 @@ func printEncryptHelp() {
 	BoldCyan.Println("Commands:")
 	printCmd("init", "Initialize age encryption (generate key pair)")
 	printCmd("file <file>", "Encrypt a file (creates <file>.age)")
-	printCmd("decrypt <file>", "Decrypt a .age file (restores original)")
+	printCmd("decrypt <file>", "Decrypt a .age or .enc file (restores original)")
 	printCmd("edit <file>", "Decrypt, open in $EDITOR, re-encrypt on save")
 	printCmd("list", "List encrypted and unencrypted sensitive files")
 	printCmd("status", "Show encryption status and key info")
@@ -603,6 +653,21 @@ func printEncryptHelp() {
 	Yellow.Print("-n, --dry-run")
 	fmt.Print("   ")
 	Dim.Println("Show what would be done")
+	fmt.Print("  ")
+	Yellow.Print("-p, --passphrase")
+	Dim.Println(" Encrypt with a passphrase (scrypt + secretbox) to <file>.enc")
+	fmt.Print("  ")
+	Yellow.Print("-i, --in-place")
+	fmt.Print("  ")
+	Dim.Println("With --passphrase, encrypt over the original file")
+	fmt.Print("  ")
+	Yellow.Print("-r, --recipient")
+	fmt.Print(" ")
+	Dim.Println("Encrypt for this age public key (repeatable) instead of your own")
+	fmt.Print("  ")
+	Yellow.Print("--identity")
+	fmt.Print("      ")
+	Dim.Println("Decrypt with this age private key file instead of your own")
 	fmt.Println()
 
 	// Examples
@@ -616,6 +681,14 @@ func printEncryptHelp() {
 	Dim.Println("  # Decrypt to view/use")
 	fmt.Println("  blackdot encrypt decrypt templates/_variables.local.sh.age")
 	fmt.Println()
+	Dim.Println("  # Passphrase instead of key (reads $BLACKDOT_PASSPHRASE or prompts)")
+	fmt.Println("  blackdot encrypt file secrets.env --passphrase")
+	fmt.Println("  blackdot encrypt decrypt secrets.env.enc")
+	fmt.Println()
+	Dim.Println("  # Share with teammates: any listed key can decrypt")
+	fmt.Println("  blackdot encrypt file team.env -r age1alice... -r age1bob...")
+	fmt.Println("  blackdot encrypt decrypt team.env.age --identity ~/keys/bob.txt")
+	fmt.Println()
 	Dim.Println("  # Edit encrypted file directly")
 	fmt.Println("  blackdot encrypt edit templates/_variables.local.sh.age")
 	fmt.Println()
@@ -0,0 +1,339 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+const (
+	// passphraseEnvVar supplies the passphrase non-interactively
+	passphraseEnvVar = "BLACKDOT_PASSPHRASE"
+
+	// passphraseMagic starts the header line of a passphrase-encrypted file
+	passphraseMagic = "blackdot-enc/v1"
+
+	// ageMagic starts every age-encrypted file
+	ageMagic = "age-encryption.org/v1"
+
+	// passphraseSuffix is appended when encrypting to a sibling file
+	passphraseSuffix = ".enc"
+
+	// scryptMaxMemory caps the 128*r*N bytes scrypt allocates for a file's
+	// header parameters; the defaults need 32 MiB
+	scryptMaxMemory = 256 << 20
+
+	// scryptMaxP caps parallelism, which multiplies the CPU time
+	scryptMaxP = 4
+)
+
+// scryptParams are the KDF parameters recorded in each file's header
+type scryptParams struct {
+	LogN uint8
+	R    int
+	P    int
+	Salt []byte
+}
+
+// defaultScryptParams follows the scrypt paper's interactive-use settings
+func defaultScryptParams() (scryptParams, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return scryptParams{}, err
+	}
+	return scryptParams{LogN: 15, R: 8, P: 1, Salt: salt}, nil
+}
+
+func (p scryptParams) header() string {
+	return fmt.Sprintf("%s scrypt logN=%d r=%d p=%d salt=%s\n",
+		passphraseMagic, p.LogN, p.R, p.P, base64.RawStdEncoding.EncodeToString(p.Salt))
+}
+
+func (p scryptParams) key(passphrase []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key(passphrase, p.Salt, 1<<p.LogN, p.R, p.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+// parseScryptHeader reads the KDF parameters from a header line. The header
+// is read before the passphrase can be checked, so its cost is bounded:
+// scryptMaxMemory and scryptMaxP keep a crafted file from making key
+// derivation take minutes or gigabytes.
+func parseScryptHeader(line string) (scryptParams, error) {
+	var p scryptParams
+	fields := strings.Fields(line)
+	if len(fields) != 6 || fields[0] != passphraseMagic || fields[1] != "scrypt" {
+		return p, fmt.Errorf("unsupported encryption header: %q", line)
+	}
+
+	values := make(map[string]string)
+	for _, f := range fields[2:] {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok {
+			return p, fmt.Errorf("malformed header field: %q", f)
+		}
+		values[k] = v
+	}
+
+	logN, err := strconv.ParseUint(values["logN"], 10, 8)
+	if err != nil || logN < 10 || logN > 20 {
+		return p, fmt.Errorf("invalid scrypt logN: %q", values["logN"])
+	}
+	r, err := strconv.Atoi(values["r"])
+	if err != nil || r < 1 || r > 32 {
+		return p, fmt.Errorf("invalid scrypt r: %q", values["r"])
+	}
+	par, err := strconv.Atoi(values["p"])
+	if err != nil || par < 1 || par > scryptMaxP {
+		return p, fmt.Errorf("invalid scrypt p: %q", values["p"])
+	}
+	if memory := 128 * r << logN; memory > scryptMaxMemory {
+		return p, fmt.Errorf("scrypt parameters need %d MiB, more than the %d MiB limit", memory>>20, scryptMaxMemory>>20)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(values["salt"])
+	if err != nil || len(salt) < 16 {
+		return p, fmt.Errorf("invalid salt")
+	}
+
+	return scryptParams{LogN: uint8(logN), R: r, P: par, Salt: salt}, nil
+}
+
+// isEncryptedContent reports whether data is already blackdot- or age-encrypted
+func isEncryptedContent(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(passphraseMagic)) || bytes.HasPrefix(data, []byte(ageMagic)) ||
+		bytes.HasPrefix(data, []byte(recipientsMagic))
+}
+
+// isPassphraseEncrypted reports whether path starts with a blackdot-enc header
+func isPassphraseEncrypted(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, len(passphraseMagic))
+	n, _ := f.Read(head)
+	return string(head[:n]) == passphraseMagic
+}
+
+// encryptWithPassphrase seals plaintext with NaCl secretbox under a
+// scrypt-derived key. The output is a text header line followed by the
+// nonce and the sealed box.
+func encryptWithPassphrase(plaintext, passphrase []byte) ([]byte, error) {
+	params, err := defaultScryptParams()
+	if err != nil {
+		return nil, err
+	}
+	key, err := params.key(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+
+	out := []byte(params.header())
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, plaintext, &nonce, key), nil
+}
+
+// decryptWithPassphrase reverses encryptWithPassphrase
+func decryptWithPassphrase(data, passphrase []byte) ([]byte, error) {
+	line, rest, ok := bytes.Cut(data, []byte("\n"))
+	if !ok || !bytes.HasPrefix(line, []byte(passphraseMagic)) {
+		return nil, fmt.Errorf("not a blackdot passphrase-encrypted file")
+	}
+	params, err := parseScryptHeader(string(line))
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < 24+secretbox.Overhead {
+		return nil, fmt.Errorf("encrypted file is truncated")
+	}
+
+	key, err := params.key(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], rest[:24])
+
+	plaintext, ok := secretbox.Open(nil, rest[24:], &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("wrong passphrase or corrupted file")
+	}
+	return plaintext, nil
+}
+
+// readPassphrase returns $BLACKDOT_PASSPHRASE or prompts on the terminal.
+// When confirm is set (encrypting), the passphrase must be typed twice.
+func readPassphrase(confirm bool) ([]byte, error) {
+	if p := os.Getenv(passphraseEnvVar); p != "" {
+		return []byte(p), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("no passphrase: set %s or run interactively", passphraseEnvVar)
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	pass, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("reading passphrase: %w", err)
+	}
+	if len(pass) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+
+	if confirm {
+		fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("reading passphrase: %w", err)
+		}
+		if !bytes.Equal(pass, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return pass, nil
+}
+
+// writeFileAtomic replaces path via a temp file in the same directory
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// runPassphraseEncrypt encrypts inputFile to inputFile.enc, or over itself
+// when inPlace is set
+func runPassphraseEncrypt(inputFile string, inPlace, keep, dryRun bool) error {
+	outputFile := inputFile + passphraseSuffix
+	if inPlace {
+		outputFile = inputFile
+	}
+
+	if dryRun {
+		DryRun("Would encrypt: %s -> %s", inputFile, outputFile)
+		return nil
+	}
+
+	plaintext, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", inputFile, err)
+	}
+	if isEncryptedContent(plaintext) {
+		Fail("File is already encrypted: %s", inputFile)
+		return fmt.Errorf("file already encrypted")
+	}
+	if !inPlace {
+		if _, err := os.Stat(outputFile); err == nil {
+			return classErrorf(ErrConflict, "%s already exists", outputFile)
+		}
+	}
+
+	passphrase, err := readPassphrase(true)
+	if err != nil {
+		return err
+	}
+	sealed, err := encryptWithPassphrase(plaintext, passphrase)
+	if err != nil {
+		return fmt.Errorf("encrypting %s: %w", inputFile, err)
+	}
+	if err := writeFileAtomic(outputFile, sealed, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", outputFile, err)
+	}
+
+	switch {
+	case inPlace:
+		Pass("Encrypted in place: %s", inputFile)
+	case keep:
+		Pass("Encrypted: %s -> %s (original kept)", inputFile, outputFile)
+	default:
+		if err := os.Remove(inputFile); err != nil {
+			return fmt.Errorf("removing original: %w", err)
+		}
+		Pass("Encrypted: %s -> %s (original removed)", inputFile, outputFile)
+	}
+	return nil
+}
+
+// runPassphraseDecrypt decrypts a .enc file to its original name, or
+// in place for a file encrypted with --in-place
+func runPassphraseDecrypt(inputFile string, keep, dryRun bool) error {
+	outputFile := strings.TrimSuffix(inputFile, passphraseSuffix)
+	inPlace := outputFile == inputFile
+
+	if dryRun {
+		DryRun("Would decrypt: %s -> %s", inputFile, outputFile)
+		return nil
+	}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", inputFile, err)
+	}
+	if !inPlace {
+		if _, err := os.Stat(outputFile); err == nil {
+			return classErrorf(ErrConflict, "%s already exists", outputFile)
+		}
+	}
+
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return err
+	}
+	plaintext, err := decryptWithPassphrase(data, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(outputFile, plaintext, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", outputFile, err)
+	}
+
+	switch {
+	case inPlace:
+		Pass("Decrypted in place: %s", inputFile)
+	case keep:
+		Pass("Decrypted: %s -> %s (encrypted kept)", inputFile, outputFile)
+	default:
+		if err := os.Remove(inputFile); err != nil {
+			return fmt.Errorf("removing encrypted: %w", err)
+		}
+		Pass("Decrypted: %s -> %s (encrypted removed)", inputFile, outputFile)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// recipientsMagic starts the header line of a file encrypted to --recipient
+// keys. The rest of the file is plain age output.
+const recipientsMagic = "blackdot-age/v1"
+
+// ageRecipientPattern matches an age public key: X25519 (age1 and 58
+// bech32 characters) or a plugin recipient such as age1yubikey1...
+var ageRecipientPattern = regexp.MustCompile(`^age1[0-9a-z]{20,}$`)
+
+// recipientsHeader is the first line of a recipients-encrypted file, so
+// anyone re-encrypting it knows whom it was shared with
+func recipientsHeader(recipients []string) string {
+	return fmt.Sprintf("%s recipients=%s\n", recipientsMagic, strings.Join(recipients, ","))
+}
+
+// parseRecipientsHeader reads the recipients from a header line
+func parseRecipientsHeader(line string) ([]string, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 || fields[0] != recipientsMagic {
+		return nil, fmt.Errorf("unsupported encryption header: %q", line)
+	}
+	list, ok := strings.CutPrefix(fields[1], "recipients=")
+	if !ok {
+		return nil, fmt.Errorf("malformed header field: %q", fields[1])
+	}
+	return normalizeRecipients(strings.Split(list, ","))
+}
+
+// normalizeRecipients validates age public keys and drops duplicates,
+// keeping the order given
+func normalizeRecipients(recipients []string) ([]string, error) {
+	var out []string
+	for _, r := range recipients {
+		r = strings.TrimSpace(r)
+		if !ageRecipientPattern.MatchString(r) {
+			return nil, fmt.Errorf("not an age public key: %q", r)
+		}
+		if !slices.Contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no recipients")
+	}
+	return out, nil
+}
+
+// isRecipientsEncrypted reports whether path starts with a blackdot-age header
+func isRecipientsEncrypted(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, len(recipientsMagic))
+	n, _ := f.Read(head)
+	return string(head[:n]) == recipientsMagic
+}
+
+// encryptToRecipients runs age with one -r per recipient and returns the
+// header followed by the ciphertext
+func encryptToRecipients(inputFile string, recipients []string) ([]byte, error) {
+	args := []string{"-o", "-"}
+	for _, r := range recipients {
+		args = append(args, "-r", r)
+	}
+	var stderr bytes.Buffer
+	ageCmd := exec.Command("age", append(args, inputFile)...)
+	ageCmd.Stderr = &stderr
+	ciphertext, err := ageCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("age: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return append([]byte(recipientsHeader(recipients)), ciphertext...), nil
+}
+
+// decryptRecipientsFile decrypts inputFile to outputFile with the private
+// key in identity, returning the recipients from its header
+func decryptRecipientsFile(inputFile, outputFile, identity string) ([]string, error) {
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	line, rest, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return nil, fmt.Errorf("encrypted file is truncated")
+	}
+	recipients, err := parseRecipientsHeader(string(line))
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	ageCmd := exec.Command("age", "-d", "-i", identity, "-o", outputFile)
+	ageCmd.Stdin = bytes.NewReader(rest)
+	ageCmd.Stderr = &stderr
+	if err := ageCmd.Run(); err != nil {
+		return nil, fmt.Errorf("age: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return recipients, nil
+}
+
+// runRecipientsEncrypt encrypts inputFile to <file>.age so that any of
+// recipients can decrypt it
+func runRecipientsEncrypt(inputFile string, recipients []string, keep, dryRun bool) error {
+	recipients, err := normalizeRecipients(recipients)
+	if err != nil {
+		return fmt.Errorf("--recipient: %w", err)
+	}
+	outputFile := inputFile + ".age"
+
+	if dryRun {
+		DryRun("Would encrypt for %d recipient(s): %s -> %s", len(recipients), inputFile, outputFile)
+		return nil
+	}
+
+	if !isAgeInstalled() {
+		Fail("'age' is not installed")
+		return classErrorf(ErrToolMissing, "age not installed")
+	}
+	plaintext, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", inputFile, err)
+	}
+	if isEncryptedContent(plaintext) || strings.HasSuffix(inputFile, ".age") {
+		Fail("File is already encrypted: %s", inputFile)
+		return fmt.Errorf("file already encrypted")
+	}
+	if _, err := os.Stat(outputFile); err == nil {
+		return classErrorf(ErrConflict, "%s already exists", outputFile)
+	}
+
+	sealed, err := encryptToRecipients(inputFile, recipients)
+	if err != nil {
+		return fmt.Errorf("encrypting %s: %w", inputFile, err)
+	}
+	if err := writeFileAtomic(outputFile, sealed, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", outputFile, err)
+	}
+
+	if keep {
+		Pass("Encrypted for %d recipient(s): %s -> %s (original kept)", len(recipients), inputFile, outputFile)
+	} else {
+		if err := os.Remove(inputFile); err != nil {
+			return fmt.Errorf("removing original: %w", err)
+		}
+		Pass("Encrypted for %d recipient(s): %s -> %s (original removed)", len(recipients), inputFile, outputFile)
+	}
+	// Easy to miss: encrypting for teammates doesn't include yourself
+	if own, err := getPublicKey(); err == nil && own != "" && !slices.Contains(recipients, own) {
+		Warn("Your own key (%s) is not a recipient; you won't be able to decrypt %s", own, outputFile)
+	}
+	return nil
+}
+
+// runRecipientsDecrypt decrypts a recipients-encrypted .age file with the
+// private key in identity
+func runRecipientsDecrypt(inputFile, identity string, keep, dryRun bool) error {
+	outputFile := strings.TrimSuffix(inputFile, ".age")
+	if outputFile == inputFile {
+		Fail("Expected .age file: %s", inputFile)
+		return fmt.Errorf("expected .age file")
+	}
+
+	if dryRun {
+		DryRun("Would decrypt: %s -> %s", inputFile, outputFile)
+		return nil
+	}
+
+	if !isAgeInstalled() {
+		Fail("'age' is not installed")
+		return classErrorf(ErrToolMissing, "age not installed")
+	}
+	if _, err := os.Stat(outputFile); err == nil {
+		return classErrorf(ErrConflict, "%s already exists", outputFile)
+	}
+
+	if _, err := decryptRecipientsFile(inputFile, outputFile, identity); err != nil {
+		return fmt.Errorf("decrypting %s: %w", inputFile, err)
+	}
+
+	if keep {
+		Pass("Decrypted: %s -> %s (encrypted kept)", inputFile, outputFile)
+	} else {
+		if err := os.Remove(inputFile); err != nil {
+			return fmt.Errorf("removing encrypted: %w", err)
+		}
+		Pass("Decrypted: %s -> %s (encrypted removed)", inputFile, outputFile)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestPassphraseRoundTrip verifies encrypt/decrypt and wrong-passphrase rejection
+func TestPassphraseRoundTrip(t *testing.T) {
+	plaintext := []byte("export API_TOKEN=hunter2\n")
+
+	sealed, err := encryptWithPassphrase(plaintext, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("hunter2")) {
+		t.Fatal("ciphertext contains plaintext")
+	}
+	header, _, _ := bytes.Cut(sealed, []byte("\n"))
+	if !strings.HasPrefix(string(header), passphraseMagic+" scrypt logN=15 r=8 p=1 salt=") {
+		t.Errorf("unexpected header: %q", header)
+	}
+
+	got, err := decryptWithPassphrase(sealed, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("decrypted %q, want %q", got, plaintext)
+	}
+
+	if _, err := decryptWithPassphrase(sealed, []byte("wrong")); err == nil {
+		t.Error("expected error for wrong passphrase")
+	}
+	if _, err := decryptWithPassphrase(sealed[:len(header)+10], []byte("correct horse")); err == nil {
+		t.Error("expected error for truncated file")
+	}
+}
+
+// TestParseScryptHeader verifies KDF parameters are validated
+func TestParseScryptHeader(t *testing.T) {
+	salt := "AAAAAAAAAAAAAAAAAAAAAA" // 16 zero bytes
+	tests := []struct {
+		name    string
+		line    string
+		wantErr bool
+	}{
+		{"valid", passphraseMagic + " scrypt logN=15 r=8 p=1 salt=" + salt, false},
+		{"wrong kdf", passphraseMagic + " argon2 logN=15 r=8 p=1 salt=" + salt, true},
+		{"huge N", passphraseMagic + " scrypt logN=30 r=8 p=1 salt=" + salt, true},
+		{"zero r", passphraseMagic + " scrypt logN=15 r=0 p=1 salt=" + salt, true},
+		{"at memory limit", passphraseMagic + " scrypt logN=18 r=8 p=1 salt=" + salt, false},
+		{"4 GiB", passphraseMagic + " scrypt logN=20 r=32 p=1 salt=" + salt, true},
+		{"1 GiB", passphraseMagic + " scrypt logN=20 r=8 p=1 salt=" + salt, true},
+		{"high p", passphraseMagic + " scrypt logN=15 r=8 p=16 salt=" + salt, true},
+		{"short salt", passphraseMagic + " scrypt logN=15 r=8 p=1 salt=AAAA", true},
+		{"missing field", passphraseMagic + " scrypt logN=15 r=8 salt=" + salt, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseScryptHeader(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseScryptHeader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRunPassphraseEncrypt verifies the file workflow and double-encryption refusal
+func TestRunPassphraseEncrypt(t *testing.T) {
+	t.Setenv(passphraseEnvVar, "test passphrase")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secrets.env")
+	if err := os.WriteFile(file, []byte("TOKEN=abc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPassphraseEncrypt(file, false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("original should be removed without --keep")
+	}
+	encFile := file + passphraseSuffix
+	if !isPassphraseEncrypted(encFile) {
+		t.Fatal("expected .enc file with header")
+	}
+
+	// Encrypting the output again must be refused, even in place
+	if err := runPassphraseEncrypt(encFile, true, false, false); err == nil {
+		t.Error("expected refusal to double-encrypt")
+	}
+
+	if err := runPassphraseDecrypt(encFile, false, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || string(data) != "TOKEN=abc\n" {
+		t.Errorf("decrypted content = %q, %v", data, err)
+	}
+
+	// In place round trip keeps the name
+	if err := runPassphraseEncrypt(file, true, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if !isPassphraseEncrypted(file) {
+		t.Fatal("expected file to be encrypted in place")
+	}
+	if err := runPassphraseDecrypt(file, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "TOKEN=abc\n" {
+		t.Errorf("in-place decrypted content = %q", data)
+	}
+}
+
+// TestRecipientsRoundTrip verifies the recipients header and the age
+// arguments for encrypt --recipient and decrypt --identity
+func TestRecipientsRoundTrip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake age")
+	}
+	// Encrypting echoes the arguments and the plaintext; decrypting checks
+	// the identity and drops those two lines
+	binDir := t.TempDir()
+	fake := `#!/bin/sh
+if [ "$1" = "-d" ]; then
+  grep -q AGE-SECRET-KEY "$3" || { echo "no identity matched" >&2; exit 1; }
+  sed 1,2d > "$5"
+  exit 0
+fi
+for a; do last=$a; done
+echo "age-encryption.org/v1"
+echo "$*"
+cat "$last"
+`
+	if err := os.WriteFile(filepath.Join(binDir, "age"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", t.TempDir())
+
+	alice := "age1" + strings.Repeat("a", 58)
+	bob := "age1" + strings.Repeat("b", 58)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "team.env")
+	if err := os.WriteFile(file, []byte("TOKEN=abc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRecipientsEncrypt(file, []string{"not-a-key"}, false, false); err == nil {
+		t.Error("expected an invalid recipient to be rejected")
+	}
+	if err := runRecipientsEncrypt(file, []string{alice, bob, alice}, false, false); err != nil {
+		t.Fatal(err)
+	}
+	encFile := file + ".age"
+	data, err := os.ReadFile(encFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := recipientsMagic + " recipients=" + alice + "," + bob + "\n" +
+		"age-encryption.org/v1\n-o - -r " + alice + " -r " + bob + " " + file + "\n"
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("encrypted file = %q, want prefix %q", data, want)
+	}
+	if got, err := parseRecipientsHeader(strings.SplitN(string(data), "\n", 2)[0]); err != nil || len(got) != 2 {
+		t.Errorf("parseRecipientsHeader = %v, %v", got, err)
+	}
+	if !isRecipientsEncrypted(encFile) || !isEncryptedContent(data) {
+		t.Error("expected the header to mark the file as encrypted")
+	}
+
+	wrongKey := filepath.Join(dir, "wrong.txt")
+	identity := filepath.Join(dir, "bob.txt")
+	os.WriteFile(wrongKey, []byte("# nothing here\n"), 0600)
+	os.WriteFile(identity, []byte("AGE-SECRET-KEY-1BOB\n"), 0600)
+	if err := runRecipientsDecrypt(encFile, wrongKey, true, false); err == nil || !strings.Contains(err.Error(), "no identity matched") {
+		t.Errorf("decrypt with the wrong key: %v", err)
+	}
+	os.Remove(file)
+	if err := runRecipientsDecrypt(encFile, identity, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "TOKEN=abc\n" {
+		t.Errorf("decrypted content = %q", data)
+	}
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// Error classes. Commands wrap errors in one with withErrorClass, and
+// ExitCode maps each class to its own exit status, so wrapper scripts can
+// tell a missing tool from a bad config without parsing messages.
+var (
+	ErrUsage         = errors.New("invalid usage")
+	ErrToolMissing   = errors.New("required tool not installed")
+	ErrFileNotFound  = errors.New("file not found")
+	ErrConfigInvalid = errors.New("invalid configuration")
+	ErrConflict      = errors.New("conflict")
+)
+
+// Exit statuses for the error classes; anything else exits 1
+const (
+	ExitFailure       = 1
+	ExitUsage         = 2
+	ExitToolMissing   = 3
+	ExitFileNotFound  = 4
+	ExitConfigInvalid = 5
+	ExitConflict      = 6
+)
+
+// classError tags err with an error class without changing its message
+type classError struct {
+	class error
+	err   error
+}
+
+func (e *classError) Error() string   { return e.err.Error() }
+func (e *classError) Unwrap() []error { return []error{e.class, e.err} }
+
+// withErrorClass tags err with class; errors.Is(err, class) then holds
+// for it and anything wrapping it with %w
+func withErrorClass(class, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classError{class: class, err: err}
+}
+
+// ExitCode returns the process exit status for an error from Execute.
+// Errors from the filesystem that a file doesn't exist count as
+// ErrFileNotFound even when no command tagged them.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrUsage) || isCobraUsageError(err):
+		return ExitUsage
+	case errors.Is(err, ErrToolMissing):
+		return ExitToolMissing
+	case errors.Is(err, ErrFileNotFound) || errors.Is(err, fs.ErrNotExist):
+		return ExitFileNotFound
+	case errors.Is(err, ErrConfigInvalid):
+		return ExitConfigInvalid
+	case errors.Is(err, ErrConflict):
+		return ExitConflict
+	}
+	return ExitFailure
+}
+
+// isCobraUsageError matches the unknown command and flag errors cobra
+// returns as plain strings
+func isCobraUsageError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "unknown command") ||
+		strings.Contains(msg, "unknown flag") ||
+		strings.Contains(msg, "unknown shorthand flag")
+}
+
+// classifyUsageOnce keeps Execute from wrapping Args validators twice
+var classifyUsageOnce sync.Once
+
+// classifyUsageErrors tags flag parsing and argument count errors from cmd
+// and its subcommands as ErrUsage
+func classifyUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return withErrorClass(ErrUsage, err)
+	})
+	var tagArgs func(*cobra.Command)
+	tagArgs = func(c *cobra.Command) {
+		if args := c.Args; args != nil {
+			c.Args = func(c *cobra.Command, a []string) error {
+				return withErrorClass(ErrUsage, args(c, a))
+			}
+		}
+		for _, sub := range c.Commands() {
+			tagArgs(sub)
+		}
+	}
+	tagArgs(cmd)
+}
+
+// classErrorf formats an error tagged with class
+func classErrorf(class error, format string, args ...any) error {
+	return withErrorClass(class, fmt.Errorf(format, args...))
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/blackwell-systems/blackdot/internal/config"
@@ -28,6 +29,13 @@ func initRegistry() *feature.Registry {
 	if err == nil && userConfig.Features != nil {
 		registry.LoadState(userConfig.Features)
 	}
+	if err == nil {
+		sources := make(map[string]feature.Provenance, len(userConfig.FeatureSources))
+		for name, src := range userConfig.FeatureSources {
+			sources[name] = feature.Provenance{Source: feature.Source(src.Source), By: src.By}
+		}
+		registry.LoadProvenance(userConfig.Preset, sources)
+	}
 
 	return registry
 }
@@ -62,6 +70,9 @@ categories: core, optional, and integration.`,
 		newFeaturesCheckCmd(),
 		newFeaturesShowCmd(),
 		newFeaturesValidateCmd(),
+		newFeaturesGraphCmd(),
+		newFeaturesWhyCmd(),
+		newFeaturesHistoryCmd(),
 	)
 
 	return cmd
@@ -96,16 +107,28 @@ func printFeaturesHelp() {
 	printFeaturesCmd("disable <feature>", "Disable a feature")
 	Dim.Println("                      --persist: Save to config file")
 	fmt.Println()
-	printFeaturesCmd("preset <name>", "Enable a preset (group of features)")
+	printFeaturesCmd("preset [name]", "Enable a preset (group of features); no name: pick one")
 	Dim.Println("                      --list: Show available presets")
+	Dim.Println("                      --save <name>: Save enabled features as a preset")
 	Dim.Println("                      --persist: Save to config file")
 	fmt.Println()
+	printFeaturesCmd("history", "Show when presets were applied and by whom")
+	Dim.Println("                      --limit <n>: Show at most n entries (0: all)")
+	Dim.Println("                      --json: Output as JSON")
+	fmt.Println()
+	printFeaturesCmd("why <feature>", "Explain why a feature is enabled")
+	Dim.Println("                      Traces dependencies back to what enabled them")
+	fmt.Println()
 	printFeaturesCmd("check <feature>", "Check if a feature is enabled (for scripts)")
 	Dim.Println("                      Returns exit code 0 if enabled, 1 if disabled")
 	fmt.Println()
 	printFeaturesCmd("validate", "Validate feature registry for circular dependencies")
 	Dim.Println("                      and conflicts. Returns exit code 0 if valid.")
 	fmt.Println()
+	printFeaturesCmd("graph", "Print the dependency graph (Graphviz DOT)")
+	Dim.Println("                      --format mermaid: Output Mermaid instead")
+	Dim.Println("                      --preset <name>: Only what a preset pulls in")
+	fmt.Println()
 	printFeaturesCmd("help", "Show this help")
 	fmt.Println()
 
@@ -261,6 +284,7 @@ func newFeaturesPresetCmd() *cobra.Command {
 	var listPresets bool
 	var persist bool
 	var dryRun bool
+	var saveName, description string
 
 	cmd := &cobra.Command{
 		Use:   "preset [name]",
@@ -271,12 +295,38 @@ Available presets:
   minimal   - Shell only (fastest startup)
   developer - Vault, AWS helpers, git hooks, modern CLI
   claude    - Workspace symlink, Claude integration, vault, git hooks
-  full      - All features enabled`,
+  full      - All features enabled
+
+Without a name, pick a preset from a numbered list; blackdot shows which
+features it would enable and disable, and applies and saves it once you
+confirm. When stdin isn't a terminal it only lists the presets.
+
+Custom presets are read from ~/.config/blackdot/presets.json and listed
+after the built-in ones. --save <name> records the features enabled now
+as a custom preset, asking before replacing one with the same name.
+
+Examples:
+  blackdot features preset                 # Choose interactively
+  blackdot features preset developer --persist
+  blackdot features preset --save work
+  blackdot features preset work`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if listPresets || len(args) == 0 {
+			if saveName != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("--save takes the preset name; don't also pass a preset to apply")
+				}
+				return saveCurrentPreset(saveName, description)
+			}
+			if err := loadCustomPresets(); err != nil {
+				Warn("Ignoring custom presets: %v", err)
+			}
+			if listPresets {
 				listPresetsCmd()
 				return nil
 			}
+			if len(args) == 0 {
+				return runPresetPicker(dryRun)
+			}
 			return applyPreset(args[0], persist, dryRun)
 		},
 	}
@@ -284,6 +334,8 @@ Available presets:
 	cmd.Flags().BoolVarP(&listPresets, "list", "l", false, "list available presets")
 	cmd.Flags().BoolVarP(&persist, "persist", "p", false, "save to config file")
 	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "preview what would be changed without making changes")
+	cmd.Flags().StringVar(&saveName, "save", "", "save the currently enabled features as a custom preset")
+	cmd.Flags().StringVar(&description, "description", "", "description for --save")
 
 	return cmd
 }
@@ -391,13 +443,7 @@ func enableFeature(name string, persist bool, dryRun bool) error {
 	reg := initRegistry()
 
 	if !reg.Exists(name) {
-		Fail("Unknown feature: %s", name)
-		fmt.Println()
-		fmt.Println("Available features:")
-		for _, n := range reg.List("") {
-			fmt.Printf("  %s\n", n)
-		}
-		return fmt.Errorf("unknown feature: %s", name)
+		return unknownFeature(reg, name)
 	}
 
 	// Check if core feature
@@ -453,6 +499,7 @@ func enableFeature(name string, persist bool, dryRun bool) error {
 		PrintHint("Use --persist to save to config file")
 	}
 
+	printFeatureStatus(reg, name)
 	return nil
 }
 
@@ -460,8 +507,7 @@ func disableFeature(name string, persist bool, dryRun bool) error {
 	reg := initRegistry()
 
 	if !reg.Exists(name) {
-		Fail("Unknown feature: %s", name)
-		return fmt.Errorf("unknown feature: %s", name)
+		return unknownFeature(reg, name)
 	}
 
 	// Check if core feature
@@ -501,15 +547,57 @@ func disableFeature(name string, persist bool, dryRun bool) error {
 		PrintHint("Use --persist to save to config file")
 	}
 
+	printFeatureStatus(reg, name)
 	return nil
 }
 
+// unknownFeature reports an unknown name with the valid choices and returns
+// a *feature.UnknownFeatureError
+func unknownFeature(reg *feature.Registry, name string) error {
+	err := &feature.UnknownFeatureError{Name: name, Valid: reg.List("")}
+	Fail("Unknown feature: %s", name)
+	fmt.Println()
+	fmt.Println("Available features:")
+	for _, n := range err.Valid {
+		fmt.Printf("  %s\n", n)
+	}
+	return err
+}
+
+// printFeatureStatus shows the state of a feature after a change, along with
+// its dependencies and any enabled features that depend on it
+func printFeatureStatus(reg *feature.Registry, name string) {
+	fmt.Println()
+	f, _ := reg.Get(name)
+	PrintFeature(f.Name, f.Description, reg.Enabled(f.Name))
+
+	for _, dep := range reg.Dependencies(name) {
+		if d, ok := reg.Get(dep); ok {
+			PrintFeature(d.Name, d.Description, reg.Enabled(d.Name))
+		}
+	}
+
+	if reg.Enabled(name) {
+		return
+	}
+	dependents := reg.Dependents(name)
+	sort.Strings(dependents)
+	for _, dependent := range dependents {
+		if reg.Enabled(dependent) {
+			Warn("'%s' is still enabled but depends on '%s'", dependent, name)
+		}
+	}
+}
+
 func listPresetsCmd() {
 	PrintHeader("Available Presets")
 
 	for _, preset := range feature.AllPresets() {
 		BoldCyan.Printf("  %s\n", preset.Name)
 		PrintHint("    %s", preset.Description)
+		if preset.Extends != "" {
+			Dim.Printf("    Extends: %s\n", preset.Extends)
+		}
 		Dim.Printf("    Features: %s\n", strings.Join(preset.Features, ", "))
 		fmt.Println()
 	}
@@ -558,8 +646,10 @@ func applyPreset(name string, persist bool, dryRun bool) error {
 			Fail("Failed to save config: %v", err)
 			return err
 		}
+		recordPresetApplied(reg, name, true)
 		Pass("Preset '%s' enabled and saved to config", name)
 	} else {
+		recordPresetApplied(reg, name, false)
 		Pass("Preset '%s' enabled (runtime only)", name)
 		PrintHint("Use --persist to save to config file")
 	}
@@ -581,8 +671,7 @@ func checkFeature(name string) error {
 	reg := initRegistry()
 
 	if !reg.Exists(name) {
-		Fail("Unknown feature: %s", name)
-		return fmt.Errorf("unknown feature: %s", name)
+		return unknownFeature(reg, name)
 	}
 
 	if reg.Enabled(name) {
@@ -600,13 +689,7 @@ func showFeature(name string) error {
 
 	f, ok := reg.Get(name)
 	if !ok {
-		Fail("Unknown feature: %s", name)
-		fmt.Println()
-		fmt.Println("Available features:")
-		for _, n := range reg.List("") {
-			fmt.Printf("  %s\n", n)
-		}
-		return fmt.Errorf("unknown feature: %s", name)
+		return unknownFeature(reg, name)
 	}
 
 	enabled := reg.Enabled(name)
@@ -658,6 +741,13 @@ func persistFeatureState(reg *feature.Registry) error {
 
 	userConfig.Features = reg.SaveState()
 
+	preset, sources := reg.SaveProvenance()
+	userConfig.Preset = preset
+	userConfig.FeatureSources = make(map[string]config.FeatureSource, len(sources))
+	for name, prov := range sources {
+		userConfig.FeatureSources[name] = config.FeatureSource{Source: string(prov.Source), By: prov.By}
+	}
+
 	return cfg.Save(userConfig)
 }
 
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/blackwell-systems/blackdot/internal/feature"
+	"github.com/spf13/cobra"
+)
+
+// featureGraphFormats are the output formats 'features graph' supports
+var featureGraphFormats = []string{"dot", "mermaid"}
+
+// featureGraph is the subset of the registry and presets to draw
+type featureGraph struct {
+	features  []*feature.Feature // sorted by name
+	conflicts [][2]string        // each pair once, sorted
+	presets   []*feature.Preset  // display order
+	own       map[string][]string
+}
+
+func newFeaturesGraphCmd() *cobra.Command {
+	var format, preset string
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Print the feature dependency graph",
+		Long: `Print features, their dependencies and conflicts, and the presets that
+include them as a Graphviz DOT or Mermaid graph.
+
+Each preset points at the preset it extends and at the features it adds
+on top of it. Use --preset to draw only what one preset pulls in.
+
+Examples:
+  blackdot features graph | dot -Tsvg > features.svg
+  blackdot features graph --format mermaid
+  blackdot features graph --preset developer`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(featureGraphFormats, format) {
+				return fmt.Errorf("unknown format: %s (valid: %s)", format, strings.Join(featureGraphFormats, ", "))
+			}
+			if err := loadCustomPresets(); err != nil {
+				Warn("Ignoring custom presets: %v", err)
+			}
+			g, err := buildFeatureGraph(feature.NewRegistry(), preset)
+			if err != nil {
+				return err
+			}
+			if format == "mermaid" {
+				fmt.Print(g.mermaid())
+			} else {
+				fmt.Print(g.dot())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "dot", "Output format ("+strings.Join(featureGraphFormats, "/")+")")
+	cmd.Flags().StringVar(&preset, "preset", "", "Only show this preset, the presets it extends, and the features it pulls in")
+
+	return cmd
+}
+
+// buildFeatureGraph collects the graph for every feature and preset, or for
+// one preset's extends chain and the features it needs (dependencies included)
+func buildFeatureGraph(reg *feature.Registry, presetName string) (*featureGraph, error) {
+	g := &featureGraph{own: make(map[string][]string)}
+
+	include := func(string) bool { return true }
+	if presetName == "" {
+		g.presets = feature.AllPresets()
+	} else {
+		p, ok := feature.GetPreset(presetName)
+		if !ok {
+			return nil, &feature.PresetNotFoundError{Name: presetName}
+		}
+		for p != nil {
+			g.presets = append(g.presets, p)
+			p, _ = feature.GetPreset(p.Extends)
+		}
+
+		needed := make(map[string]bool)
+		var add func(name string)
+		add = func(name string) {
+			if needed[name] {
+				return
+			}
+			needed[name] = true
+			for _, dep := range reg.Dependencies(name) {
+				add(dep)
+			}
+		}
+		for _, name := range g.presets[0].Features {
+			add(name)
+		}
+		include = func(name string) bool { return needed[name] }
+	}
+
+	for _, f := range reg.All() {
+		if include(f.Name) {
+			g.features = append(g.features, f)
+		}
+	}
+
+	seen := make(map[[2]string]bool)
+	for _, f := range g.features {
+		for _, other := range reg.Conflicts(f.Name) {
+			pair := [2]string{f.Name, other}
+			if pair[1] < pair[0] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			if !include(other) || seen[pair] {
+				continue
+			}
+			seen[pair] = true
+			g.conflicts = append(g.conflicts, pair)
+		}
+	}
+	sort.Slice(g.conflicts, func(i, j int) bool {
+		return g.conflicts[i][0]+"\x00"+g.conflicts[i][1] < g.conflicts[j][0]+"\x00"+g.conflicts[j][1]
+	})
+
+	// A preset's own features are what it adds over the preset it extends
+	for _, p := range g.presets {
+		var inherited []string
+		if parent, ok := feature.GetPreset(p.Extends); ok {
+			inherited = parent.Features
+		}
+		for _, name := range p.Features {
+			if !slices.Contains(inherited, name) {
+				g.own[p.Name] = append(g.own[p.Name], name)
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// byCategory groups the graph's features in core, optional, integration order
+func (g *featureGraph) byCategory() ([]feature.Category, map[feature.Category][]string) {
+	groups := make(map[feature.Category][]string)
+	for _, f := range g.features {
+		groups[f.Category] = append(groups[f.Category], f.Name)
+	}
+	var cats []feature.Category
+	for _, c := range []feature.Category{feature.CategoryCore, feature.CategoryOptional, feature.CategoryIntegration} {
+		if len(groups[c]) > 0 {
+			cats = append(cats, c)
+		}
+	}
+	return cats, groups
+}
+
+// dot renders the graph in Graphviz DOT
+func (g *featureGraph) dot() string {
+	var b strings.Builder
+	b.WriteString("digraph features {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+
+	cats, groups := g.byCategory()
+	for _, c := range cats {
+		fmt.Fprintf(&b, "\n  subgraph cluster_%s {\n", c)
+		fmt.Fprintf(&b, "    label=%q;\n", string(c))
+		for _, name := range groups[c] {
+			fmt.Fprintf(&b, "    %q;\n", name)
+		}
+		b.WriteString("  }\n")
+	}
+
+	if len(g.presets) > 0 {
+		b.WriteString("\n  // presets\n")
+		for _, p := range g.presets {
+			fmt.Fprintf(&b, "  %q [label=%q, shape=ellipse, style=filled, fillcolor=lightblue];\n", "preset:"+p.Name, p.Name)
+		}
+	}
+
+	b.WriteString("\n  // dependencies\n")
+	for _, f := range g.features {
+		for _, dep := range f.Dependencies {
+			fmt.Fprintf(&b, "  %q -> %q;\n", f.Name, dep)
+		}
+	}
+
+	if len(g.conflicts) > 0 {
+		b.WriteString("\n  // conflicts\n")
+		for _, c := range g.conflicts {
+			fmt.Fprintf(&b, "  %q -> %q [dir=none, style=dashed, color=red, label=\"conflicts\"];\n", c[0], c[1])
+		}
+	}
+
+	for _, p := range g.presets {
+		fmt.Fprintf(&b, "\n  // preset %s\n", p.Name)
+		if p.Extends != "" {
+			fmt.Fprintf(&b, "  %q -> %q [style=bold, label=\"extends\"];\n", "preset:"+p.Name, "preset:"+p.Extends)
+		}
+		for _, name := range g.own[p.Name] {
+			fmt.Fprintf(&b, "  %q -> %q [style=dotted];\n", "preset:"+p.Name, name)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// mermaid renders the graph as a Mermaid flowchart
+func (g *featureGraph) mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	cats, groups := g.byCategory()
+	for _, c := range cats {
+		fmt.Fprintf(&b, "  subgraph %s\n", c)
+		for _, name := range groups[c] {
+			fmt.Fprintf(&b, "    %s\n", name)
+		}
+		b.WriteString("  end\n")
+	}
+
+	for _, p := range g.presets {
+		fmt.Fprintf(&b, "  preset_%s([\"%s\"])\n", p.Name, p.Name)
+	}
+
+	for _, f := range g.features {
+		for _, dep := range f.Dependencies {
+			fmt.Fprintf(&b, "  %s --> %s\n", f.Name, dep)
+		}
+	}
+	for _, c := range g.conflicts {
+		fmt.Fprintf(&b, "  %s -. conflicts .- %s\n", c[0], c[1])
+	}
+	for _, p := range g.presets {
+		if p.Extends != "" {
+			fmt.Fprintf(&b, "  preset_%s ==>|extends| preset_%s\n", p.Name, p.Extends)
+		}
+		for _, name := range g.own[p.Name] {
+			fmt.Fprintf(&b, "  preset_%s -.-> %s\n", p.Name, name)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blackwell-systems/blackdot/internal/feature"
+	"github.com/spf13/cobra"
+)
+
+// featuresAuditFile records applied presets one JSON object per line, in
+// ConfigDir
+const featuresAuditFile = "audit.jsonl"
+
+// presetAuditEntry is one line of audit.jsonl
+type presetAuditEntry struct {
+	Time      time.Time `json:"time"`
+	Preset    string    `json:"preset"`
+	Features  []string  `json:"features"`
+	User      string    `json:"user"`
+	Persisted bool      `json:"persisted"`
+}
+
+// featuresAuditPath returns ~/.config/blackdot/audit.jsonl
+func featuresAuditPath() string {
+	return filepath.Join(ConfigDir(), featuresAuditFile)
+}
+
+func newFeaturesHistoryCmd() *cobra.Command {
+	var limit int
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show when presets were applied and by whom",
+		Long: `Show the presets applied with 'blackdot features preset', newest first:
+when, by which OS user, whether it was saved to the config file, and the
+features enabled afterwards.
+
+Entries are appended to ~/.config/blackdot/audit.jsonl, one JSON object
+per line. Writing it is best-effort: a preset is still applied if the
+entry can't be recorded.
+
+Examples:
+  blackdot features history
+  blackdot features history --limit 0      # Every entry
+  blackdot features history --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 0 {
+				return classErrorf(ErrUsage, "--limit must be 0 or more, got %d", limit)
+			}
+			return showPresetHistory(limit, jsonOutput)
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "show at most this many entries (0 for all)")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "output as JSON")
+
+	return cmd
+}
+
+// showPresetHistory prints the newest limit entries of the audit log
+func showPresetHistory(limit int, jsonOutput bool) error {
+	path := featuresAuditPath()
+	entries, skipped, err := readPresetAudit(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if skipped > 0 {
+		Warn("Skipped %d unreadable lines in %s", skipped, path)
+	}
+
+	// Newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	if jsonOutput {
+		if entries == nil {
+			entries = []presetAuditEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		Info("No presets applied yet")
+		PrintHint("Apply one with: blackdot features preset <name>")
+		return nil
+	}
+	PrintHeader("Preset History")
+	for _, e := range entries {
+		saved := "runtime only"
+		if e.Persisted {
+			saved = "saved"
+		}
+		fmt.Printf("%s  ", e.Time.Local().Format("2006-01-02 15:04:05"))
+		Yellow.Printf("%-12s", e.Preset)
+		fmt.Printf(" %-12s ", e.User)
+		Dim.Printf("%s, %d features\n", saved, len(e.Features))
+		Dim.Printf("    %s\n", strings.Join(e.Features, ", "))
+	}
+	return nil
+}
+
+// recordPresetApplied appends an entry for preset to the audit log. It is
+// best-effort: failures are only logged, never returned, so a broken or
+// read-only config directory can't stop a preset from applying.
+func recordPresetApplied(reg *feature.Registry, preset string, persisted bool) {
+	var enabled []string
+	for _, name := range reg.List("") {
+		if reg.Enabled(name) {
+			enabled = append(enabled, name)
+		}
+	}
+	entry := presetAuditEntry{
+		Time:      time.Now().UTC(),
+		Preset:    preset,
+		Features:  enabled,
+		User:      auditUser(),
+		Persisted: persisted,
+	}
+	path := featuresAuditPath()
+	if err := appendPresetAudit(path, entry); err != nil {
+		logger.Warn("could not record preset in audit log", "path", path, "error", err)
+	}
+}
+
+// appendPresetAudit writes entry as one line at the end of path
+func appendPresetAudit(path string, entry presetAuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	// A single write keeps concurrent appends from interleaving mid-line
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readPresetAudit returns the entries in path, oldest first, and how many
+// lines couldn't be parsed; a missing file has no entries
+func readPresetAudit(path string) (entries []presetAuditEntry, skipped int, err error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry presetAuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Preset == "" {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, skipped, scanner.Err()
+}
+
+// auditUser names the OS user running blackdot, falling back to the
+// environment where the user database can't be read
+func auditUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, env := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(env); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackwell-systems/blackdot/internal/feature"
+	"golang.org/x/term"
+)
+
+// customPresetsFile holds user-defined presets, in ConfigDir
+const customPresetsFile = "presets.json"
+
+// customPresetNamePattern keeps preset names usable as graph node IDs
+var customPresetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// customPresetFile is the format of presets.json
+type customPresetFile struct {
+	Presets map[string]customPresetDef `json:"presets"`
+}
+
+// customPresetDef is one preset in presets.json
+type customPresetDef struct {
+	Description string   `json:"description,omitempty"`
+	Extends     string   `json:"extends,omitempty"`
+	Features    []string `json:"features"`
+}
+
+// customPresetsPath returns ~/.config/blackdot/presets.json
+func customPresetsPath() string {
+	return filepath.Join(ConfigDir(), customPresetsFile)
+}
+
+// readCustomPresets parses path; a missing file has no presets
+func readCustomPresets(path string) (*customPresetFile, error) {
+	file := &customPresetFile{Presets: make(map[string]customPresetDef)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if file.Presets == nil {
+		file.Presets = make(map[string]customPresetDef)
+	}
+	return file, nil
+}
+
+// presets converts the file to feature presets, sorted by name
+func (f *customPresetFile) presets() []*feature.Preset {
+	var defs []*feature.Preset
+	for name, def := range f.Presets {
+		defs = append(defs, &feature.Preset{
+			Name:        name,
+			Description: def.Description,
+			Extends:     def.Extends,
+			Features:    def.Features,
+		})
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// loadCustomPresets makes the presets in presets.json available alongside
+// the built-in ones
+func loadCustomPresets() error {
+	path := customPresetsPath()
+	file, err := readCustomPresets(path)
+	if err != nil {
+		return err
+	}
+	if err := feature.SetCustomPresets(file.presets()); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// saveCurrentPreset writes the currently enabled features to presets.json
+// as preset name, asking before replacing an existing custom preset
+func saveCurrentPreset(name, description string) error {
+	if feature.IsBuiltinPreset(name) {
+		return fmt.Errorf("'%s' is a built-in preset; choose another name", name)
+	}
+	if !customPresetNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid preset name: %s (use letters, digits, '-' and '_')", name)
+	}
+
+	path := customPresetsPath()
+	file, err := readCustomPresets(path)
+	if err != nil {
+		return err
+	}
+	if _, exists := file.Presets[name]; exists {
+		if !Confirm(fmt.Sprintf("Custom preset '%s' already exists. Overwrite?", name)) {
+			Info("Cancelled")
+			return nil
+		}
+	}
+
+	reg := initRegistry()
+	var enabled []string
+	for _, f := range reg.All() {
+		if reg.Enabled(f.Name) {
+			enabled = append(enabled, f.Name)
+		}
+	}
+	if description == "" {
+		description = "Saved from enabled features on " + time.Now().Format("2006-01-02")
+	}
+	file.Presets[name] = customPresetDef{Description: description, Features: enabled}
+
+	// Refuse to write a file the loader would reject
+	if err := feature.SetCustomPresets(file.presets()); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	Pass("Saved preset '%s' with %d features to %s", name, len(enabled), path)
+	PrintHint("Apply it with: blackdot features preset %s", name)
+	return nil
+}
+
+// runPresetPicker asks which preset to apply, shows what it would change,
+// and applies and persists it once confirmed. Without a terminal on stdin
+// there is nobody to ask, so it lists the presets instead.
+func runPresetPicker(dryRun bool) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		listPresetsCmd()
+		PrintHint("Apply one with: blackdot features preset <name>")
+		return nil
+	}
+
+	name, err := pickPreset(bufio.NewReader(os.Stdin), os.Stdout, feature.AllPresets())
+	if err != nil {
+		return err
+	}
+
+	reg := initRegistry()
+	diff, err := reg.PresetDiff(name)
+	if err != nil {
+		Fail("Failed to apply preset: %v", err)
+		return err
+	}
+	if diff.Empty() {
+		Info("Preset '%s' matches the enabled features; nothing to change", name)
+		return nil
+	}
+	printPresetDiff(os.Stdout, diff)
+
+	if dryRun {
+		Yellow.Println("Run without --dry-run to actually apply")
+		return nil
+	}
+	if !Confirm(fmt.Sprintf("Apply preset '%s' and save to config?", name)) {
+		Info("Cancelled")
+		return nil
+	}
+	fmt.Println()
+	return applyPreset(name, true, false)
+}
+
+// pickPreset prints presets as a numbered list and reads the number of the
+// one to use
+func pickPreset(in *bufio.Reader, out io.Writer, presets []*feature.Preset) (string, error) {
+	width := 0
+	for _, p := range presets {
+		width = max(width, len(p.Name))
+	}
+
+	BoldCyan.Fprintln(out, "Select feature preset:")
+	fmt.Fprintln(out)
+	for i, p := range presets {
+		fmt.Fprintf(out, "  %d. ", i+1)
+		Yellow.Fprint(out, p.Name)
+		fmt.Fprint(out, strings.Repeat(" ", width-len(p.Name)+2))
+		Dim.Fprintf(out, "- %s\n", p.Description)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Enter selection (1-%d): ", len(presets))
+
+	input, err := in.ReadString('\n')
+	if err != nil && input == "" {
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+	input = strings.TrimSpace(input)
+	num, err := strconv.Atoi(input)
+	if err != nil || num < 1 || num > len(presets) {
+		return "", fmt.Errorf("invalid selection: %s", input)
+	}
+
+	fmt.Fprintln(out)
+	return presets[num-1].Name, nil
+}
+
+// printPresetDiff lists the features a preset would turn on and off
+func printPresetDiff(out io.Writer, diff *feature.PresetDiff) {
+	fmt.Fprintf(out, "Applying '%s' would:\n", diff.Preset)
+	for _, name := range diff.Enable {
+		Green.Fprintf(out, "  + enable  %s\n", name)
+	}
+	for _, name := range diff.Disable {
+		Red.Fprintf(out, "  - disable %s\n", name)
+	}
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/blackwell-systems/blackdot/internal/feature"
+	"github.com/spf13/cobra"
+)
+
+func newFeaturesWhyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "why <feature>",
+		Short: "Explain why a feature is enabled",
+		Long: `Explain why a feature is enabled: by name, as a dependency of another
+enabled feature, by a preset, by default, or by an environment variable.
+Dependencies are traced back to the feature or preset that pulled them in.
+
+Reasons are recorded when features are enabled with --persist or a preset
+is applied with --persist; features enabled before that show as set in
+the config file.
+
+Examples:
+  blackdot features why vault
+  blackdot features why workspace_symlink`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadCustomPresets(); err != nil {
+				Warn("Ignoring custom presets: %v", err)
+			}
+			return explainFeature(args[0])
+		},
+	}
+}
+
+// explainFeature prints why a feature is enabled or that it is disabled
+func explainFeature(name string) error {
+	reg := initRegistry()
+	if !reg.Exists(name) {
+		return unknownFeature(reg, name)
+	}
+
+	lines := featureWhy(reg, name)
+	if !reg.Enabled(name) {
+		Info("%s", lines[0])
+		return nil
+	}
+	Pass("Feature '%s' is enabled", name)
+	for _, line := range lines {
+		fmt.Printf("  %s\n", line)
+	}
+	return nil
+}
+
+// featureWhy describes why name is enabled, one reason per line: the chain
+// of dependencies up to the feature or preset that started it, then other
+// enabled features that need it and the last applied preset if it lists it
+func featureWhy(reg *feature.Registry, name string) []string {
+	if !reg.Enabled(name) {
+		return []string{fmt.Sprintf("'%s' is disabled", name)}
+	}
+
+	var lines []string
+	root := feature.Provenance{}
+	seen := map[string]bool{name: true}
+	for current := name; current != ""; {
+		prov := reg.Provenance(current)
+		next := ""
+		switch prov.Source {
+		case feature.SourceDependency:
+			if !reg.Enabled(prov.By) {
+				lines = append(lines, fmt.Sprintf("%s is a dependency of %s, which is now disabled", current, prov.By))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s is a dependency of %s", current, prov.By))
+				if !seen[prov.By] {
+					seen[prov.By] = true
+					next = prov.By
+				}
+			}
+		case feature.SourceDirect:
+			lines = append(lines, fmt.Sprintf("%s was enabled directly", current))
+		case feature.SourcePreset:
+			lines = append(lines, fmt.Sprintf("%s is in preset '%s'", current, prov.By))
+		case feature.SourceEnv:
+			lines = append(lines, fmt.Sprintf("%s is enabled by an environment variable (BLACKDOT_FEATURE_%s or a SKIP_* variable)", current, strings.ToUpper(current)))
+		case feature.SourceDefault:
+			if f, _ := reg.Get(current); f.Category == feature.CategoryCore {
+				lines = append(lines, fmt.Sprintf("%s is a core feature (always enabled)", current))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s is enabled by default", current))
+			}
+		default:
+			lines = append(lines, fmt.Sprintf("%s is enabled in the config file (no reason recorded)", current))
+		}
+		root = prov
+		current = next
+	}
+
+	// Other enabled features that would keep it on
+	direct := reg.Provenance(name)
+	var dependents []string
+	for _, d := range reg.Dependents(name) {
+		if reg.Enabled(d) && !(direct.Source == feature.SourceDependency && direct.By == d) {
+			dependents = append(dependents, d)
+		}
+	}
+	sort.Strings(dependents)
+	if len(dependents) > 0 {
+		lines = append(lines, "also required by: "+strings.Join(dependents, ", "))
+	}
+
+	if last := reg.LastPreset(); last != "" && !(root.Source == feature.SourcePreset && root.By == last) {
+		if p, ok := feature.GetPreset(last); ok && slices.Contains(p.Features, name) {
+			lines = append(lines, fmt.Sprintf("part of the last applied preset '%s'", last))
+		}
+	}
+	return lines
+}
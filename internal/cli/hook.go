@@ -533,7 +533,7 @@ func runHookAdd(args []string) error {
 	// Check script exists
 	if _, err := os.Stat(script); os.IsNotExist(err) {
 		fmt.Printf("%s Script not found: %s\n", color.RedString("[FAIL]"), script)
-		return fmt.Errorf("script not found: %s", script)
+		return classErrorf(ErrFileNotFound, "script not found: %s", script)
 	}
 
 	// Create hooks directory
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// httpRetries is how many times a failed request is retried (--retries)
+var httpRetries = 2
+
+// httpRetryBaseDelay is the wait before the first retry; it doubles on each
+// retry up to httpRetryMaxDelay. A variable so tests can shorten it.
+var httpRetryBaseDelay = 500 * time.Millisecond
+
+// httpRetryMaxDelay caps the wait between retries
+const httpRetryMaxDelay = 8 * time.Second
+
+// httpDoWithRetry sends a body-less request, retrying 5xx responses,
+// timeouts, and dropped connections with exponential backoff and jitter.
+// Other responses, 4xx included, are returned at once, as are errors once
+// ctx is done or its deadline is too close to wait out the next backoff.
+// Only getting a response is retried; the caller reads the body.
+func httpDoWithRetry(ctx context.Context, client *http.Client, method, url string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+
+		var reason string
+		switch {
+		case err != nil && ctx.Err() == nil && retryableHTTPError(err):
+			reason = err.Error()
+		case err == nil && resp.StatusCode >= 500:
+			reason = resp.Status
+		default:
+			return resp, err
+		}
+
+		delay := httpRetryDelay(attempt)
+		if deadline, ok := ctx.Deadline(); attempt >= httpRetries || (ok && time.Until(deadline) < delay) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		logger.Debug("retrying request", "method", method, "url", url, "attempt", attempt+1, "reason", reason, "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s %s: %w", method, url, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// httpRetryDelay is the backoff before retry attempt+1: the doubled base
+// delay, capped, with its upper half randomized
+func httpRetryDelay(attempt int) time.Duration {
+	delay := httpRetryMaxDelay
+	if attempt < 16 {
+		delay = min(httpRetryBaseDelay<<attempt, httpRetryMaxDelay)
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half+1)
+}
+
+// retryableHTTPError reports whether a transport error is likely transient:
+// a timeout or a connection the server reset or dropped
+func retryableHTTPError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
@@ -115,7 +115,7 @@ func runImportChezmoi(sourceDir, configFile string, dryRun, verbose bool) error
 
 	// Verify source exists
 	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
-		return fmt.Errorf("chezmoi source directory not found: %s", sourceDir)
+		return classErrorf(ErrFileNotFound, "chezmoi source directory not found: %s", sourceDir)
 	}
 
 	fmt.Printf("Source: %s\n", cyan(sourceDir))
@@ -126,10 +126,9 @@ func runImportChezmoi(sourceDir, configFile string, dryRun, verbose bool) error
 	fmt.Println()
 
 	// Get target directory
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		home, _ := os.UserHomeDir()
-		blackdotDir = filepath.Join(home, ".blackdot")
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
 	}
 
 	importer := &chezmoiImporter{
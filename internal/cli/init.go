@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// scaffoldFile is a starter file created by 'blackdot init'
+type scaffoldFile struct {
+	path    string // Relative to the target directory
+	content string
+}
+
+// scaffoldDirs are the directories 'blackdot lint' and setup expect to find
+var scaffoldDirs = []string{
+	filepath.Join("zsh", "zsh.d"),
+	"bootstrap",
+	"lib",
+	"brew",
+	"powershell",
+	filepath.Join(".github", "workflows"),
+}
+
+func newInitCmd() *cobra.Command {
+	var forceInit bool
+
+	cmd := &cobra.Command{
+		Use:   "init <dir>",
+		Short: "Scaffold a new blackdot repository",
+		Long: `Create the directory layout blackdot expects, with starter files.
+
+Creates:
+  zsh/zshrc, zsh/zsh.d/      Shell configuration
+  bootstrap/, lib/           Bootstrap and helper scripts
+  brew/Brewfile[.tier]       Brewfiles for the minimal, enhanced, and full tiers
+  powershell/packages.json   Windows (winget) package list
+  .github/workflows/         CI workflows
+
+Refuses to write into a non-empty directory unless --force is given.
+Even then, starter files that already exist are kept, not overwritten,
+so --force on an existing checkout only adds what is missing.
+
+Examples:
+  blackdot init ~/.blackdot
+  blackdot init ./my-config --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInit(args[0], forceInit)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&forceInit, "force", "f", false, "Write into a non-empty directory, keeping existing files")
+
+	return cmd
+}
+
+func runInit(dir string, forceInit bool) error {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 && !forceInit {
+		return classErrorf(ErrConflict, "%s is not empty (use --force to scaffold anyway)", dir)
+	}
+
+	for _, d := range scaffoldDirs {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			return fmt.Errorf("creating %s: %w", d, err)
+		}
+	}
+
+	for _, f := range scaffoldFiles() {
+		created, err := writeScaffoldFile(filepath.Join(dir, f.path), f.content)
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", f.path, err)
+		}
+		if created {
+			Pass("Created %s", f.path)
+		} else {
+			Info("Kept %s (already exists)", f.path)
+		}
+	}
+
+	fmt.Println()
+	BoldCyan.Println("Next steps:")
+	fmt.Printf("  1. export BLACKDOT_DIR=%s\n", dir)
+	fmt.Println("  2. Add shell modules to zsh/zsh.d/ and packages to brew/Brewfile*")
+	fmt.Println("  3. Run 'blackdot lint' to validate the layout")
+	fmt.Println("  4. Run 'blackdot setup' to link it into your home directory")
+	fmt.Println()
+
+	return nil
+}
+
+// writeScaffoldFile creates path with content unless something is already
+// there, reporting whether it wrote the file
+func writeScaffoldFile(path, content string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// scaffoldFiles returns the starter files for a new repository. Empty
+// directories get a .gitkeep so they survive the first commit.
+func scaffoldFiles() []scaffoldFile {
+	return []scaffoldFile{
+		{filepath.Join("zsh", "zshrc"), ""},
+		{filepath.Join("zsh", "zsh.d", ".gitkeep"), ""},
+		{filepath.Join("bootstrap", ".gitkeep"), ""},
+		{filepath.Join("lib", ".gitkeep"), ""},
+		{filepath.Join(".github", "workflows", ".gitkeep"), ""},
+		{filepath.Join("brew", "Brewfile.minimal"), `# Brewfile.minimal - Essentials only
+# Install: brew bundle --file=Brewfile.minimal
+
+brew "git"
+brew "zsh"
+brew "jq"
+`},
+		{filepath.Join("brew", "Brewfile.enhanced"), `# Brewfile.enhanced - Minimal tier plus modern CLI tools
+# Install: brew bundle --file=Brewfile.enhanced
+
+brew "git"
+brew "zsh"
+brew "jq"
+`},
+		{filepath.Join("brew", "Brewfile"), `# Brewfile - Full install
+# Install: brew bundle --file=Brewfile
+
+brew "git"
+brew "zsh"
+brew "jq"
+`},
+		{filepath.Join("powershell", "packages.json"), fmt.Sprintf(`{
+  "$schema": "https://aka.ms/winget-packages.schema.2.0.json",
+  "description": "Blackdot Windows packages - equivalent to Brewfile",
+  "creationDate": "%s",
+  "sources": [
+    {
+      "packages": [],
+      "sourceDetails": {
+        "name": "winget",
+        "type": "Microsoft.Winget.Source",
+        "argument": "https://cdn.winget.microsoft.com/cache"
+      }
+    }
+  ]
+}
+`, time.Now().Format("2006-01-02"))},
+	}
+}
@@ -1,13 +1,24 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/blackwell-systems/blackdot/internal/config"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -25,6 +36,54 @@ type lintStats struct {
 	warnings int
 }
 
+// lintSkippableChecks are the check names accepted by --skip and --only
+var lintSkippableChecks = []string{"brew-tiers", "zsh-duplicates", "exec-bit", "shebang", "env-vars", "secrets", "dangerous", "unquoted", "idempotent", "source-order", "sources", "symlinks", "config-layers", "go"}
+
+// lintSourcedMarker exempts a script with a shebang from the executable check
+const lintSourcedMarker = "# blackdot: sourced"
+
+// lintDefaultShebang is suggested (and inserted by --fix-apply) for
+// executable scripts that have none
+const lintDefaultShebang = "#!/usr/bin/env bash"
+
+// Patterns for alias and function definitions in zsh files
+var (
+	zshAliasPattern    = regexp.MustCompile(`^\s*alias\s+(?:-[gs]\s+)?([^\s=]+)=`)
+	zshFunctionPattern = regexp.MustCompile(`^\s*function\s+([^\s(){}]+)`)
+	zshFuncDefPattern  = regexp.MustCompile(`^\s*([A-Za-z_][\w:.+-]*)\s*\(\)`)
+)
+
+// goErrorPattern matches compiler diagnostics: file.go:line:col: message
+var goErrorPattern = regexp.MustCompile(`^(\S+\.go):(\d+):(\d+):\s*(.*)$`)
+
+// lintCommandTimeout bounds each external tool run by the lint checkers
+var lintCommandTimeout = 30 * time.Second
+
+// lintJobs bounds concurrent shellcheck runs; 0 means one per CPU
+var lintJobs int
+
+// lintShellcheckSeverities are the levels --shellcheck-severity accepts,
+// most severe first
+var lintShellcheckSeverities = []string{"error", "warning", "info", "style"}
+
+// lintShellcheckSeverity is the lowest level shellcheck reports
+// (--shellcheck-severity)
+var lintShellcheckSeverity = "style"
+
+// lintShellcheckExclude are the codes shellcheck skips (--shellcheck-exclude)
+var lintShellcheckExclude []string
+
+// lintTimeoutError reports an external tool killed for exceeding lintCommandTimeout
+type lintTimeoutError struct {
+	tool    string
+	target  string
+	timeout time.Duration
+}
+
+func (e *lintTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s on %s", e.tool, e.timeout, e.target)
+}
+
 func newLintCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "lint",
@@ -33,38 +92,386 @@ func newLintCmd() *cobra.Command {
 
 Checks:
   - ZSH syntax in zsh/zsh.d/*.zsh
+  - Aliases/functions defined in more than one zsh.d file
+    (skip with --skip zsh-duplicates)
+  - zsh.d modules that run a function or alias at load time before
+    zshrc sources the module defining it. Add
+    "# blackdot: allow-source-order" to a line to accept it
+    (--skip source-order)
+  - Files named by source and . in zsh and shell files exist, resolving
+    $BLACKDOT_DIR, $HOME, ~, and the script's own directory (${0:A:h},
+    $(dirname "$0")). Missing files are errors; paths with other
+    variables are skipped with a note (listed with -v), and sources
+    behind a file test, 2>/dev/null, or || are optional. Add
+    "# blackdot: allow-missing-source" to a line to accept it
+    (--skip sources)
   - Bash syntax in lib/*.sh, bootstrap/*.sh
-  - Go code (go vet, go fmt)
-  - JSON files (config, packages.json)
+  - Go code (go vet, go fmt; go build with --with-build), when
+    BLACKDOT_DIR has a go.mod or go.work (--skip go); --only go without
+    a module is an error
+  - JSON files (config.json, vault-items.json, packages.json); keys in
+    config.json and vault-items.json that their schema doesn't know are
+    warnings, with a suggestion for likely typos. --fix-json rewrites
+    the ones that are valid with 2-space indentation (--sort-keys also
+    sorts object keys); with --fix it shows the diff instead
+  - Config layers: keys in machine.json or the project .blackdot.json
+    that override nothing in a lower layer or the config schema, and
+    layer files that aren't JSON objects. The layers and their order are
+    fixed, so missing layer files are fine (--skip config-layers)
   - YAML files (GitHub workflows)
   - PowerShell syntax (if pwsh available)
-  - Brewfile tiers existence
+  - Brewfile tiers existence and inheritance
+    (minimal ⊆ enhanced ⊆ Brewfile; skip with --skip brew-tiers)
   - Shellcheck warnings (if installed)
+  - Scripts with a shebang are executable (--fix-apply runs chmod +x;
+    add "# blackdot: sourced" to exempt libraries; --skip exec-bit)
+  - Executable scripts start with a shebang (--fix shows the line,
+    --fix-apply inserts #!/usr/bin/env bash; --skip shebang)
+  - Variables expanded but never set in the file (allowlist in
+    .blackdot-lint-envvars; --skip env-vars)
+  - Likely secrets in shell, zsh, and PowerShell files: AWS access keys,
+    bearer tokens, exported *_TOKEN/*_SECRET literals, private keys.
+    Add "# blackdot: allow-secret" to a line to accept it; extra
+    patterns go in .blackdot-lint-secrets (--skip secrets)
+  - Dangerous commands in shell and zsh files: rm -rf on a variable
+    path, curl | sh, sudo without a prompt before it, eval of a variable.
+    Add "# blackdot: allow-dangerous" to a line to accept it; add or
+    drop rules in .blackdot-lint-dangerous (--skip dangerous)
+  - Unquoted $var in command arguments and [ ] tests of bash and sh
+    scripts, which split on whitespace and glob; works without
+    shellcheck. [[ ]], arithmetic, assignments, case subjects, and for
+    lists are not reported. Add "# blackdot: allow-unquoted" to a line,
+    or disable SC2086 for it, to accept it (--skip unquoted)
+  - Bootstrap scripts are safe to re-run: echo >> file with no grep or
+    if guard in the lines before, mkdir without -p. Add
+    "# blackdot: allow-non-idempotent" to a line to accept it; add or
+    drop rules in .blackdot-lint-idempotent (--skip idempotent)
+  - Indentation of shell and zsh files (with --indent tabs|spaces|2|4):
+    lines indented with the other style, or with tabs and spaces mixed.
+    --fix-apply rewrites them ("spaces" is 2; converting to tabs uses the
+    file's own indent width). Here-document bodies and multi-line strings
+    are left alone
+  - Symlinks under BLACKDOT_DIR that point at nothing, with the
+    target they were left pointing at (--skip symlinks)
+  - File hygiene (with --hygiene): trailing whitespace,
+    missing final newline, CRLF line endings
+  - Brewfile formulae and casks exist in Homebrew (with
+    --verify-formulae; uses brew info, or formulae.brew.sh without brew;
+    results cached for a day)
+  - Markdown links (with --docs): relative links and images in *.md
+    files that point to missing files; --check-external also requests
+    each http(s) link and warns about ones that fail
+  - TODO, FIXME, and XXX comments in shell and Go files (with --todos),
+    listed as file:line for information; --max-todos N fails the run
+    when there are more than N. A comment containing
+    "blackdot: allow-todo" isn't counted
 
 Examples:
   blackdot lint              # Check all files
   blackdot lint --verbose    # Show all files checked
-  blackdot lint --fix        # Show fix suggestions`,
+  blackdot lint --fix        # Show fix suggestions
+  blackdot lint --hygiene    # Also check whitespace and line endings
+  blackdot lint --indent 2 --fix-apply  # Normalize indentation to 2 spaces
+  blackdot lint --fix-json --sort-keys --fix  # Preview reformatted JSON
+  blackdot lint --docs       # Also check links in markdown files
+  blackdot lint --verify-formulae  # Catch Brewfile typos (needs network)
+  blackdot lint --quiet      # For git hooks: silent unless something fails
+  blackdot lint --staged     # Pre-commit: lint what is about to be committed
+  blackdot lint --write-baseline  # Record current issues as known
+  blackdot lint --timeout 2m # Allow slow tools more time
+  blackdot lint --explain SC2155  # What a shellcheck code means
+  blackdot lint --shellcheck-severity warning  # Hide info and style notes
+  blackdot lint --shellcheck-exclude SC2086,SC2164
+  blackdot lint --plugins    # Also run blackdot-lint-* checkers on PATH
+  blackdot lint --group-by code  # Bulk-fix one shellcheck rule at a time
+  blackdot lint --strict     # Warnings fail the run too
+  blackdot lint --preset ci  # Every offline check; any warning fails
+  blackdot lint --preset fast  # Skip go build, docs, and formula lookups
+  blackdot lint --max-warnings 5  # Fail only if warnings creep past 5
+  blackdot lint --max-todos 20  # Cap technical debt markers at 20
+  blackdot lint --summary-json lint.json  # Terminal output, plus JSON for CI
+  blackdot lint --diff       # Vet only Go packages changed since HEAD
+  blackdot lint --diff=main  # ... or since another ref
+  blackdot lint merge-sarif a.sarif b.sarif -o lint.sarif
+
+Each external tool (zsh, bash, go, pwsh, shellcheck) is killed if it
+runs longer than --timeout, and the check is reported as an error.
+
+Missing tools:
+  Checks that need an optional tool (shellcheck, pwsh, or go for a
+  BLACKDOT_DIR with a go.mod) are skipped when it isn't installed, and
+  that doesn't change the result. --warn-missing-tools counts each tool
+  that had files to check as a warning, listed in the summary, so
+  coverage gaps show without failing the run. --require-tools makes them
+  errors and exits with status 3 (and wins if both are set).
+
+Files larger than --max-file-size (default 5MB) and binary files (a NUL
+byte in the first 8KB) are skipped by every check, with one warning each.
+
+Changed packages:
+  --diff[=REF] runs go vet (and go build with --with-build) only on
+  packages whose files differ from REF (default HEAD), including
+  uncommitted and untracked files. Packages that import them are not
+  rechecked. Changes to go.mod, go.sum, go.work, or vendor/, a deleted
+  package, or a tree without git fall back to ./...
+
+Staged files:
+  --staged lints only files staged for commit, reading each one from the
+  index (git show :path) into a temporary copy, so unstaged edits in the
+  working tree don't hide or add issues. Issues are reported against the
+  original paths. Checks that span several files (zsh duplicates, source
+  order, sourced files, Brewfile tiers, go vet and go build, markdown
+  links) are skipped;
+  go fmt runs on the staged .go files. Can't be combined with --fix-apply,
+  --fix-json, --write-baseline, or --diff.
+
+Baseline:
+  If .blackdot-lint-baseline.json exists in BLACKDOT_DIR, issues recorded
+  in it are not reported and don't count toward errors or warnings. Line
+  numbers are ignored when matching, so edits elsewhere in a file don't
+  resurface old issues. Use --no-baseline to report everything.
+
+Plugins:
+  --plugins runs every executable named blackdot-lint-<name> in
+  --plugin-dir (relative to BLACKDOT_DIR) or on PATH, first match per name
+  winning, like git's subcommands. Each is run from BLACKDOT_DIR with the
+  files lint enumerated as arguments and reports on stdout in gcc format,
+  one issue per line:
+    path:line[:col]: error|warning|note: message
+  Relative paths are taken from BLACKDOT_DIR; other lines are ignored.
+  Exit status 0 or 1 means the plugin ran; anything else is an error.
+
+Config file:
+  Defaults for verbose, fix, quiet, hygiene, docs, verify-formulae, with-build, strict,
+  warn-missing-tools, require-tools, max-warnings, todos, max-todos, skip, only, jobs, timeout, max-file-size, group-by, indent, plugins,
+  plugin-dir, shellcheck-severity, and shellcheck-exclude can be set under "lint:" in .blackdot.yml in
+  BLACKDOT_DIR (or the file given with --config). Flags on the command
+  line override the file; unknown keys are an error.
+
+Presets:
+  --preset applies a named bundle of those settings on top of the file:
+    ci    hygiene, docs, with-build, strict, and max-warnings 0
+    fast  no hygiene, docs, verify-formulae, or with-build
+    full  hygiene, docs, verify-formulae, with-build, and todos
+  Define your own, or replace these, under "lint: presets: <name>:" with
+  the same keys. Flags on the command line still override the preset.`,
 		RunE: runLint,
 	}
 
 	cmd.Flags().BoolP("verbose", "v", false, "Show all files checked")
 	cmd.Flags().BoolP("fix", "f", false, "Show fix suggestions (requires shellcheck)")
+	cmd.Flags().Bool("fix-apply", false, "Apply safe automatic fixes (chmod +x on scripts with a shebang, add missing shebangs, --indent)")
+	cmd.Flags().Bool("fix-json", false, "Rewrite valid JSON config files with 2-space indentation (with --fix, show the diff instead)")
+	cmd.Flags().Bool("sort-keys", false, "With --fix-json, also sort object keys")
+	cmd.Flags().BoolP("quiet", "q", false, "Only print issues and a summary line; silent when clean")
+	cmd.Flags().Bool("with-build", false, "Also run go build ./... (slow; may need a longer --timeout)")
+	cmd.Flags().Bool("hygiene", false, "Check trailing whitespace, final newline, and line endings")
+	cmd.Flags().String("indent", "", "Check shell indentation: tabs, spaces (2), 2, or 4")
+	cmd.Flags().Bool("docs", false, "Check relative links and images in markdown files")
+	cmd.Flags().Bool("check-external", false, "With --docs, also request http(s) links and warn about failures (slow)")
+	cmd.Flags().Bool("verify-formulae", false, "Check that Brewfile formulae and casks exist in Homebrew (needs brew or network)")
+	cmd.Flags().Bool("write-baseline", false, "Record all current issues to "+lintBaselineFile)
+	cmd.Flags().Bool("no-baseline", false, "Ignore the baseline file and report all issues")
+	cmd.Flags().StringSlice("skip", nil, "Skip checks by name ("+strings.Join(lintSkippableChecks, ", ")+")")
+	cmd.Flags().StringSlice("only", nil, "Run only these of the skippable checks; the rest are skipped")
+	cmd.Flags().Duration("timeout", 30*time.Second, "Timeout for each external command")
+	cmd.Flags().String("explain", "", "Explain a shellcheck code (e.g. SC2155) and exit")
+	cmd.Flags().String("summary-json", "", "Also write the summary and every issue as JSON to this file")
+	cmd.Flags().Bool("timings", false, "Print time spent per phase and per file to stderr")
+	cmd.Flags().Bool("strict", false, "Fail on warnings as well as errors")
+	cmd.Flags().Bool("warn-missing-tools", false, "Count each optional tool that isn't installed (shellcheck, pwsh, go) as a warning")
+	cmd.Flags().Bool("require-tools", false, "Fail when an optional tool that has files to check isn't installed")
+	cmd.Flags().Int("max-warnings", -1, "Fail when there are more than this many warnings (-1: no limit)")
+	cmd.Flags().Bool("todos", false, "List TODO, FIXME, and XXX comments in shell and Go files")
+	cmd.Flags().Int("max-todos", -1, "Fail when there are more than this many TODO/FIXME/XXX comments (-1: no limit; implies --todos)")
+	cmd.Flags().IntP("jobs", "j", 0, "Parallel shellcheck runs (default: number of CPUs)")
+	cmd.Flags().String("group-by", "file", "Group the issues report by file, severity, or code (shellcheck code)")
+	cmd.Flags().String("max-file-size", lintDefaultMaxFileSize, "Skip files larger than this, with a warning (e.g. 512KB; 0 for no limit)")
+	cmd.Flags().String("shellcheck-severity", "style", "Lowest shellcheck level to report ("+strings.Join(lintShellcheckSeverities, ", ")+")")
+	cmd.Flags().StringSlice("shellcheck-exclude", nil, "Shellcheck codes to skip (e.g. SC2086,SC2164)")
+	cmd.Flags().String("diff", "", "Only go vet/build packages with changes since this git ref")
+	cmd.Flags().Bool("plugins", false, "Also run "+lintPluginPrefix+"* executables from --plugin-dir and PATH")
+	cmd.Flags().String("plugin-dir", "", "Look for lint plugins here before PATH (relative to BLACKDOT_DIR)")
+	cmd.Flags().Bool("staged", false, "Lint the staged content of staged files (for pre-commit hooks)")
+	cmd.Flags().Lookup("diff").NoOptDefVal = "HEAD"
+	cmd.Flags().String("config", "", "Read flag defaults from this file instead of "+lintConfigFile+" in BLACKDOT_DIR")
+	// Not --profile: that global flag picks the blackdot directory to lint
+	cmd.Flags().String("preset", "", "Apply a named set of defaults: ci, fast, full, or one under lint.presets")
+
+	cmd.AddCommand(newLintMergeSARIFCmd())
 
 	return cmd
 }
 
+// lintLayoutDirs are the directories blackdot init creates; a blackdot
+// directory without any of them is almost certainly the wrong path
+var lintLayoutDirs = []string{"zsh", "bootstrap", "lib", "brew", "powershell"}
+
+// checkLintDir returns an error when dir doesn't exist or doesn't look like
+// a blackdot repository
+func checkLintDir(dir string) error {
+	hint := "run 'blackdot init " + dir + "' to create one, or set BLACKDOT_DIR to your dotfiles checkout"
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return classErrorf(ErrFileNotFound, "blackdot directory %s does not exist; %s", dir, hint)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return classErrorf(ErrConfigInvalid, "blackdot directory %s is not a directory; %s", dir, hint)
+	}
+	for _, d := range lintLayoutDirs {
+		if info, err := os.Stat(filepath.Join(dir, d)); err == nil && info.IsDir() {
+			return nil
+		}
+	}
+	return classErrorf(ErrConfigInvalid, "%s has none of %s/, so there is nothing to lint; %s", dir, strings.Join(lintLayoutDirs, "/, "), hint)
+}
+
+// lintSkipSet resolves --skip and --only into the set of checks to skip.
+// --only names the skippable checks to keep and skips the others; checks
+// that can't be skipped (syntax, shellcheck, JSON, ...) always run. --skip
+// then removes checks from what's left.
+func lintSkipSet(skipNames, onlyNames []string) (map[string]bool, error) {
+	skip := make(map[string]bool)
+	for _, name := range onlyNames {
+		if !slices.Contains(lintSkippableChecks, name) {
+			return nil, fmt.Errorf("unknown check for --only: %s (valid: %s)", name, strings.Join(lintSkippableChecks, ", "))
+		}
+	}
+	if len(onlyNames) > 0 {
+		for _, name := range lintSkippableChecks {
+			skip[name] = !slices.Contains(onlyNames, name)
+		}
+	}
+	for _, name := range skipNames {
+		if !slices.Contains(lintSkippableChecks, name) {
+			return nil, fmt.Errorf("unknown check for --skip: %s (valid: %s)", name, strings.Join(lintSkippableChecks, ", "))
+		}
+		skip[name] = true
+	}
+	return skip, nil
+}
+
 func runLint(cmd *cobra.Command, args []string) error {
+	if code, _ := cmd.Flags().GetString("explain"); code != "" {
+		return runLintExplain(code)
+	}
+
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
+	}
+	// Globbing a wrong directory finds nothing, which would pass
+	if err := checkLintDir(blackdotDir); err != nil {
+		return err
+	}
+
+	// Defaults from .blackdot.yml for flags not given on the command line
+	if err := applyLintConfig(cmd, blackdotDir); err != nil {
+		return err
+	}
+
 	verbose, _ := cmd.Flags().GetBool("verbose")
 	showFix, _ := cmd.Flags().GetBool("fix")
+	hygiene, _ := cmd.Flags().GetBool("hygiene")
+	checkDocs, _ := cmd.Flags().GetBool("docs")
+	checkExternal, _ := cmd.Flags().GetBool("check-external")
+	verifyFormulae, _ := cmd.Flags().GetBool("verify-formulae")
+	fixApply, _ := cmd.Flags().GetBool("fix-apply")
+	fixJSON, _ := cmd.Flags().GetBool("fix-json")
+	sortKeys, _ := cmd.Flags().GetBool("sort-keys")
+	withBuild, _ := cmd.Flags().GetBool("with-build")
+	writeBaseline, _ := cmd.Flags().GetBool("write-baseline")
+	noBaseline, _ := cmd.Flags().GetBool("no-baseline")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	showTimings, _ := cmd.Flags().GetBool("timings")
+	strict, _ := cmd.Flags().GetBool("strict")
+	maxWarnings, _ := cmd.Flags().GetInt("max-warnings")
+	listTodos, _ := cmd.Flags().GetBool("todos")
+	maxTodos, _ := cmd.Flags().GetInt("max-todos")
+	warnMissingTools, _ := cmd.Flags().GetBool("warn-missing-tools")
+	summaryJSON, _ := cmd.Flags().GetString("summary-json")
+	requireTools, _ := cmd.Flags().GetBool("require-tools")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	diffRef, _ := cmd.Flags().GetString("diff")
+	lintIndex, _ := cmd.Flags().GetBool("staged")
+	usePlugins, _ := cmd.Flags().GetBool("plugins")
+	pluginDir, _ := cmd.Flags().GetString("plugin-dir")
+	if timeout <= 0 {
+		return classErrorf(ErrUsage, "--timeout must be positive")
+	}
+	if checkExternal && !checkDocs {
+		return classErrorf(ErrUsage, "--check-external requires --docs")
+	}
+	if sortKeys && !fixJSON {
+		return classErrorf(ErrUsage, "--sort-keys requires --fix-json")
+	}
+	if lintIndex {
+		for _, name := range []string{"fix-apply", "fix-json", "write-baseline", "diff"} {
+			if cmd.Flags().Changed(name) {
+				return classErrorf(ErrUsage, "--%s can't be used with --staged", name)
+			}
+		}
+	}
+	lintCommandTimeout = timeout
+	if jobs < 0 {
+		return classErrorf(ErrUsage, "--jobs must not be negative")
+	}
+	if maxWarnings < -1 {
+		return classErrorf(ErrUsage, "--max-warnings must be -1 (no limit) or more")
+	}
+	if maxTodos < -1 {
+		return classErrorf(ErrUsage, "--max-todos must be -1 (no limit) or more")
+	}
+	listTodos = listTodos || maxTodos >= 0
+	lintJobs = jobs
 
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		home, err := os.UserHomeDir()
+	severity, _ := cmd.Flags().GetString("shellcheck-severity")
+	if !slices.Contains(lintShellcheckSeverities, severity) {
+		return classErrorf(ErrUsage, "unknown --shellcheck-severity: %s (valid: %s)", severity, strings.Join(lintShellcheckSeverities, ", "))
+	}
+	lintShellcheckSeverity = severity
+	excludeArgs, _ := cmd.Flags().GetStringSlice("shellcheck-exclude")
+	lintShellcheckExclude = nil
+	for _, arg := range excludeArgs {
+		code, err := normalizeShellcheckCode(arg)
 		if err != nil {
-			return fmt.Errorf("cannot determine home directory: %w", err)
+			return classErrorf(ErrUsage, "--shellcheck-exclude: %w", err)
 		}
-		blackdotDir = filepath.Join(home, ".blackdot")
+		lintShellcheckExclude = append(lintShellcheckExclude, code)
+	}
+
+	indent, _ := cmd.Flags().GetString("indent")
+	if indent != "" && !slices.Contains(lintIndentStyles, indent) {
+		return classErrorf(ErrUsage, "--indent must be one of %s, got %q", strings.Join(lintIndentStyles, ", "), indent)
+	}
+
+	groupBy, _ := cmd.Flags().GetString("group-by")
+	if !slices.Contains(lintGroupings, groupBy) {
+		return classErrorf(ErrUsage, "--group-by must be one of %s, got %q", strings.Join(lintGroupings, ", "), groupBy)
+	}
+
+	maxFileSizeArg, _ := cmd.Flags().GetString("max-file-size")
+	maxFileSize, err := parseLintFileSize(maxFileSizeArg)
+	if err != nil {
+		return classErrorf(ErrUsage, "--max-file-size: %w", err)
+	}
+	// Oversized and binary files are kept out of every check below
+	guard := newLintFileGuard(maxFileSize)
+
+	skipNames, _ := cmd.Flags().GetStringSlice("skip")
+	onlyNames, _ := cmd.Flags().GetStringSlice("only")
+	skip, err := lintSkipSet(skipNames, onlyNames)
+	if err != nil {
+		return withErrorClass(ErrUsage, err)
+	}
+	// Asking for the Go checks by name where they can't run is a mistake,
+	// not a clean pass
+	if slices.Contains(onlyNames, "go") && !skip["go"] && !goModuleRoot(blackdotDir) {
+		return classErrorf(ErrUsage, "no Go module in %s, nothing to check", blackdotDir)
 	}
 
 	green := color.New(color.FgGreen).SprintFunc()
@@ -73,245 +480,908 @@ func runLint(cmd *cobra.Command, args []string) error {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	dim := color.New(color.Faint).SprintFunc()
 
-	fmt.Println()
-	fmt.Println(color.New(color.Bold).Sprint("Blackdot Configuration Linter"))
-	fmt.Println("==============================")
-	fmt.Println()
+	// Progress output; --quiet leaves only the issues block and summary line
+	var out io.Writer = os.Stdout
+	if quiet {
+		out = io.Discard
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, color.New(color.Bold).Sprint("Blackdot Configuration Linter"))
+	fmt.Fprintln(out, "==============================")
+	fmt.Fprintln(out)
 
 	stats := lintStats{}
-	var results []lintResult
+	results := lintResults{}
+
+	// --staged checks copies of the staged blobs instead of the working tree
+	var staged *lintStaged
+	if lintIndex {
+		staged, err = newLintStaged(blackdotDir)
+		if err != nil {
+			return err
+		}
+		defer staged.cleanup()
+		if staged.count() == 0 {
+			fmt.Fprintf(out, "%s No staged files to lint\n", dim("ℹ"))
+			return nil
+		}
+		fmt.Fprintf(out, "%s Linting %d staged file(s); cross-file checks (zsh duplicates, source order, sourced files, Brewfile tiers, go vet/build, markdown links) are skipped\n\n", dim("ℹ"), staged.count())
+	}
+
+	// --timings goes to stderr so it never mixes with the report on stdout
+	var timings *lintTimings
+	if showTimings {
+		timings = newLintTimings()
+		defer timings.report(os.Stderr, blackdotDir, verbose)
+	}
+
+	// Every text file enumerated below, in order, for the hygiene phase
+	var textFiles []string
 
 	// Check for available tools
 	hasShellcheck := commandExists("shellcheck")
 	hasPwsh := commandExists("pwsh")
 	hasGo := commandExists("go")
+	logger.Debug("lint starting", "dir", blackdotDir, "shellcheck", hasShellcheck, "pwsh", hasPwsh, "go", hasGo)
+
+	// Optional tools that were needed but not installed, for
+	// --warn-missing-tools and --require-tools
+	var missingTools []string
 
 	// 1. Check ZSH files in zsh.d/
-	fmt.Printf("%s Checking ZSH syntax...\n", cyan("→"))
-	zshFiles, _ := filepath.Glob(filepath.Join(blackdotDir, "zsh", "zsh.d", "*.zsh"))
+	fmt.Fprintf(out, "%s Checking ZSH syntax...\n", cyan("→"))
+	timings.startPhase("zsh syntax")
+	zshFiles := guard.files(staged.files(globLogged(filepath.Join(blackdotDir, "zsh", "zsh.d", "*.zsh"))))
+	textFiles = append(textFiles, zshFiles...)
 	for _, file := range zshFiles {
+		done := timings.file(file)
 		result := checkZshSyntax(file)
+		done()
 		stats.checked++
 		if len(result.errors) > 0 {
 			stats.errors += len(result.errors)
-			results = append(results, result)
-			fmt.Printf("  %s %s\n", red("✗"), filepath.Base(file))
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s\n", red("✗"), filepath.Base(file))
 		} else if verbose {
-			fmt.Printf("  %s %s\n", green("✓"), filepath.Base(file))
+			fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(file))
 		}
 	}
 
 	// Check main zshrc
-	zshrcPath := filepath.Join(blackdotDir, "zsh", "zshrc")
+	zshrcPath := guard.path(staged.path(filepath.Join(blackdotDir, "zsh", "zshrc")))
 	if _, err := os.Stat(zshrcPath); err == nil {
+		textFiles = append(textFiles, zshrcPath)
+		done := timings.file(zshrcPath)
 		result := checkZshSyntax(zshrcPath)
+		done()
 		stats.checked++
 		if len(result.errors) > 0 {
 			stats.errors += len(result.errors)
-			results = append(results, result)
-			fmt.Printf("  %s %s\n", red("✗"), "zshrc")
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s\n", red("✗"), "zshrc")
 		} else if verbose {
-			fmt.Printf("  %s %s\n", green("✓"), "zshrc")
+			fmt.Fprintf(out, "  %s %s\n", green("✓"), "zshrc")
 		}
 	}
 
 	// Check p10k.zsh
-	p10kPath := filepath.Join(blackdotDir, "zsh", "p10k.zsh")
+	p10kPath := guard.path(staged.path(filepath.Join(blackdotDir, "zsh", "p10k.zsh")))
 	if _, err := os.Stat(p10kPath); err == nil {
+		textFiles = append(textFiles, p10kPath)
+		done := timings.file(p10kPath)
 		result := checkZshSyntax(p10kPath)
+		done()
 		stats.checked++
 		if len(result.errors) > 0 {
 			stats.errors += len(result.errors)
-			results = append(results, result)
-			fmt.Printf("  %s %s\n", red("✗"), "p10k.zsh")
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s\n", red("✗"), "p10k.zsh")
 		} else if verbose {
-			fmt.Printf("  %s %s\n", green("✓"), "p10k.zsh")
+			fmt.Fprintf(out, "  %s %s\n", green("✓"), "p10k.zsh")
+		}
+	}
+
+	// Cross-file: the last-loaded definition silently wins
+	if !skip["zsh-duplicates"] && staged == nil {
+		timings.startPhase("zsh duplicates")
+		stats.checked++
+		dupResults := findDuplicateZshDefinitions(zshFiles)
+		for _, result := range dupResults {
+			stats.warnings += len(result.warnings)
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(result.file), dim(fmt.Sprintf("(%d duplicate definitions)", len(result.warnings))))
+		}
+		if len(dupResults) == 0 && verbose {
+			fmt.Fprintf(out, "  %s no duplicate aliases or functions\n", green("✓"))
+		}
+	}
+
+	// Cross-file: zshrc must source a definition before a module runs it
+	if !skip["source-order"] && staged == nil && lintFileExists(zshrcPath) {
+		timings.startPhase("source order")
+		stats.checked++
+		order, err := zshrcLoadOrder(zshrcPath, zshFiles)
+		if err != nil {
+			logger.Debug("skipping source order check", "file", zshrcPath, "error", err)
+		}
+		orderResults := checkSourceOrder(order)
+		for _, result := range orderResults {
+			stats.warnings += len(result.warnings)
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(result.file), dim(fmt.Sprintf("(%d used before sourced)", len(result.warnings))))
+		}
+		if len(orderResults) == 0 && verbose {
+			fmt.Fprintf(out, "  %s zshrc source order\n", green("✓"))
 		}
 	}
 
 	// 2. Check Bash/Shell files
-	fmt.Printf("%s Checking Bash syntax...\n", cyan("→"))
+	fmt.Fprintf(out, "%s Checking Bash syntax...\n", cyan("→"))
+	timings.startPhase("bash syntax")
 
 	// Collect all shell script paths to check
 	var shellFiles []string
 
 	// bootstrap/*.sh
-	bootstrapFiles, _ := filepath.Glob(filepath.Join(blackdotDir, "bootstrap", "*.sh"))
+	bootstrapFiles := guard.files(staged.files(globLogged(filepath.Join(blackdotDir, "bootstrap", "*.sh"))))
 	shellFiles = append(shellFiles, bootstrapFiles...)
 
 	// lib/*.sh
-	libFiles, _ := filepath.Glob(filepath.Join(blackdotDir, "lib", "*.sh"))
+	libFiles := guard.files(staged.files(globLogged(filepath.Join(blackdotDir, "lib", "*.sh"))))
 	shellFiles = append(shellFiles, libFiles...)
+	textFiles = append(textFiles, shellFiles...)
 
 	for _, file := range shellFiles {
+		done := timings.file(file)
 		result := checkBashSyntax(file)
+		done()
 		stats.checked++
 		if len(result.errors) > 0 {
 			stats.errors += len(result.errors)
-			results = append(results, result)
-			fmt.Printf("  %s %s\n", red("✗"), filepath.Base(file))
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s\n", red("✗"), filepath.Base(file))
 		} else if verbose {
-			fmt.Printf("  %s %s\n", green("✓"), filepath.Base(file))
+			fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(file))
 		}
 	}
 
-	// 3. Check Go code (if go is available)
-	if hasGo {
-		fmt.Printf("%s Checking Go code...\n", cyan("→"))
+	// Scripts with a shebang must be executable unless marked as sourced
+	if !skip["exec-bit"] && runtime.GOOS != "windows" {
+		timings.startPhase("exec bit")
+		for _, file := range shellFiles {
+			result, fixed := checkExecutableBit(file, fixApply)
+			if fixed {
+				fmt.Fprintf(out, "  %s %s %s\n", green("✓"), filepath.Base(file), dim("(chmod +x applied)"))
+			}
+			if len(result.warnings) > 0 {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim("(not executable)"))
+			}
+		}
+	}
 
-		// Run go vet
-		vetResult := runGoVet(blackdotDir)
+	// Executable scripts without a shebang run under whatever shell execs them
+	if !skip["shebang"] && runtime.GOOS != "windows" {
+		timings.startPhase("shebang")
+		for _, file := range shellFiles {
+			result, fixed := checkShebang(file, showFix, fixApply)
+			if fixed {
+				fmt.Fprintf(out, "  %s %s %s\n", green("✓"), filepath.Base(file), dim("(shebang added)"))
+			}
+			if len(result.warnings) > 0 {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim("(executable without shebang)"))
+			}
+		}
+	}
+
+	// Expansions of variables that nothing in the file sets
+	if !skip["env-vars"] {
+		timings.startPhase("env vars")
+		allowlist, err := loadLintEnvAllowlist(blackdotDir)
+		if err != nil {
+			return err
+		}
+		envFiles := slices.Concat(zshFiles, shellFiles)
+		if lintFileExists(zshrcPath) {
+			envFiles = append(envFiles, zshrcPath)
+		}
 		stats.checked++
-		if len(vetResult.errors) > 0 {
-			stats.errors += len(vetResult.errors)
-			results = append(results, vetResult)
-			fmt.Printf("  %s go vet\n", red("✗"))
-		} else if verbose {
-			fmt.Printf("  %s go vet\n", green("✓"))
+		for _, file := range envFiles {
+			done := timings.file(file)
+			result := checkEnvVarRefs(file, allowlist)
+			done()
+			if len(result.warnings) > 0 {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d unset variables)", len(result.warnings))))
+			}
+		}
+	}
+
+	// Credentials pasted into dotfiles end up in git history
+	if !skip["secrets"] {
+		fmt.Fprintf(out, "%s Scanning for secrets...\n", cyan("→"))
+		timings.startPhase("secrets")
+		rules, err := loadLintPatternRules(blackdotDir, lintSecretsFile, lintBuiltinSecretRules)
+		if err != nil {
+			return err
+		}
+		secretFiles := slices.Concat(zshFiles, shellFiles,
+			guard.files(staged.files(globLogged(filepath.Join(blackdotDir, "powershell", "*.psm1")))),
+			guard.files(staged.files(globLogged(filepath.Join(blackdotDir, "powershell", "*.ps1")))))
+		for _, file := range []string{zshrcPath, p10kPath} {
+			if lintFileExists(file) {
+				secretFiles = append(secretFiles, file)
+			}
+		}
+		stats.checked++
+		for _, file := range secretFiles {
+			done := timings.file(file)
+			result := checkSecrets(file, rules)
+			done()
+			if len(result.errors) > 0 {
+				stats.errors += len(result.errors)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", red("✗"), filepath.Base(file), dim(fmt.Sprintf("(%d possible secrets)", len(result.errors))))
+			}
 		}
+	}
 
-		// Run go fmt check
-		fmtResult := runGoFmtCheck(blackdotDir)
+	// Commands that can wipe data or run untrusted code deserve a second look
+	if !skip["dangerous"] {
+		fmt.Fprintf(out, "%s Checking dangerous commands...\n", cyan("→"))
+		timings.startPhase("dangerous commands")
+		rules, err := loadLintPatternRules(blackdotDir, lintDangerousFile, lintBuiltinDangerousRules)
+		if err != nil {
+			return err
+		}
+		dangerFiles := slices.Concat(zshFiles, shellFiles)
+		if lintFileExists(zshrcPath) {
+			dangerFiles = append(dangerFiles, zshrcPath)
+		}
 		stats.checked++
-		if len(fmtResult.warnings) > 0 {
-			stats.warnings += len(fmtResult.warnings)
-			results = append(results, fmtResult)
-			fmt.Printf("  %s go fmt %s\n", yellow("⚠"), dim(fmt.Sprintf("(%d files need formatting)", len(fmtResult.warnings))))
-		} else if verbose {
-			fmt.Printf("  %s go fmt\n", green("✓"))
+		for _, file := range dangerFiles {
+			done := timings.file(file)
+			result := checkDangerous(file, rules)
+			done()
+			if len(result.warnings) > 0 {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d dangerous commands)", len(result.warnings))))
+			}
+		}
+	}
+
+	// Unquoted expansions split and glob in bash and sh (not zsh), with or without shellcheck
+	if !skip["unquoted"] {
+		timings.startPhase("unquoted expansions")
+		stats.checked++
+		for _, file := range shellFiles {
+			if dialect, _ := shellDialect(file); dialect == "zsh" {
+				continue
+			}
+			done := timings.file(file)
+			result := checkUnquoted(file)
+			done()
+			if len(result.warnings) > 0 {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d unquoted expansions)", len(result.warnings))))
+			}
+		}
+	}
+
+	// Cross-file: a wrong source path only fails at shell startup
+	if !skip["sources"] && staged == nil {
+		fmt.Fprintf(out, "%s Checking sourced files...\n", cyan("→"))
+		timings.startPhase("sourced files")
+		sourceFiles := slices.Concat(zshFiles, shellFiles)
+		if lintFileExists(zshrcPath) {
+			sourceFiles = append(sourceFiles, zshrcPath)
+		}
+		stats.checked++
+		var dynamic int
+		for _, file := range sourceFiles {
+			done := timings.file(file)
+			result, skipped := checkSourcedFiles(file, blackdotDir, os.Getenv("HOME"))
+			done()
+			dynamic += len(skipped)
+			if len(result.errors) > 0 {
+				stats.errors += len(result.errors)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", red("✗"), filepath.Base(file), dim(fmt.Sprintf("(%d missing sourced files)", len(result.errors))))
+			}
+			if verbose {
+				for _, note := range skipped {
+					fmt.Fprintf(out, "  %s %s %s\n", dim("ℹ"), filepath.Base(file), dim(fmt.Sprintf("(%s: dynamic path, skipped)", note)))
+				}
+			}
+		}
+		if dynamic > 0 && !verbose {
+			fmt.Fprintf(out, "  %s %s\n", dim("ℹ"), dim(fmt.Sprintf("%d dynamic source paths skipped (-v to list)", dynamic)))
+		}
+	}
+
+	// Bootstrap runs again on every install and upgrade
+	if !skip["idempotent"] {
+		fmt.Fprintf(out, "%s Checking re-run safety...\n", cyan("→"))
+		timings.startPhase("re-run safety")
+		rules, err := loadLintPatternRules(blackdotDir, lintIdempotentFile, lintBuiltinIdempotentRules)
+		if err != nil {
+			return err
+		}
+		stats.checked++
+		for _, file := range bootstrapFiles {
+			done := timings.file(file)
+			result := checkIdempotent(file, rules)
+			done()
+			if len(result.warnings) > 0 {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d non-idempotent commands)", len(result.warnings))))
+			}
+		}
+	}
+
+	// Mixed indentation (opt-in); the style guide picks one
+	if indent != "" {
+		fmt.Fprintf(out, "%s Checking indentation...\n", cyan("→"))
+		timings.startPhase("indentation")
+		indentFiles := slices.Concat(zshFiles, shellFiles)
+		if lintFileExists(zshrcPath) {
+			indentFiles = append(indentFiles, zshrcPath)
+		}
+		stats.checked++
+		for _, file := range indentFiles {
+			done := timings.file(file)
+			result, fixed := checkIndent(file, indent, fixApply)
+			done()
+			if fixed > 0 {
+				fmt.Fprintf(out, "  %s %s %s\n", green("✓"), filepath.Base(file), dim(fmt.Sprintf("(indentation fixed on %d lines)", fixed)))
+			}
+			if len(result.warnings) > 0 {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d lines with wrong indentation)", len(result.warnings))))
+			}
+		}
+	}
+
+	// 3. Check Go code (if go is available and there is a module to check;
+	// --skip go turns the whole phase off)
+	if !skip["go"] {
+		if hasGo && goModuleRoot(blackdotDir) {
+			fmt.Fprintf(out, "%s Checking Go code...\n", cyan("→"))
+
+			// With --diff, vet and build only the packages that changed
+			var goPkgs []string
+			vetLabel := "go vet"
+			noGoChanges := false
+			if diffRef != "" {
+				var scope string
+				goPkgs, scope, noGoChanges = lintGoDiffScope(blackdotDir, diffRef)
+				vetLabel += " " + dim(scope)
+			}
+
+			// Run go vet; packages can't be vetted from staged copies alone
+			timings.startPhase("go vet")
+			if staged != nil {
+				if verbose {
+					fmt.Fprintf(out, "  %s go vet %s\n", dim("-"), dim("(skipped with --staged)"))
+				}
+			} else if noGoChanges {
+				if verbose {
+					fmt.Fprintf(out, "  %s go vet %s\n", dim("-"), dim("(no Go changes)"))
+				}
+			} else {
+				vetResult := runGoVet(blackdotDir, goPkgs)
+				stats.checked++
+				if len(vetResult.errors) > 0 {
+					stats.errors += len(vetResult.errors)
+					results.add(vetResult)
+					fmt.Fprintf(out, "  %s %s\n", red("✗"), vetLabel)
+				} else if verbose {
+					fmt.Fprintf(out, "  %s %s\n", green("✓"), vetLabel)
+				}
+			}
+
+			// Run go build (opt-in, slow)
+			if withBuild && !noGoChanges && staged == nil {
+				timings.startPhase("go build")
+				buildResult := runGoBuild(blackdotDir, goPkgs)
+				stats.checked++
+				if len(buildResult.errors) > 0 {
+					stats.errors += len(buildResult.errors)
+					results.add(buildResult)
+					fmt.Fprintf(out, "  %s go build %s\n", red("✗"), dim(fmt.Sprintf("(%d errors)", len(buildResult.errors))))
+				} else if verbose {
+					fmt.Fprintf(out, "  %s go build\n", green("✓"))
+				}
+			}
+
+			// Run go fmt check
+			timings.startPhase("go fmt")
+			fmtDir := blackdotDir
+			if staged != nil {
+				fmtDir = staged.dir
+			}
+			fmtResult := runGoFmtCheck(fmtDir)
+			stats.checked++
+			if len(fmtResult.errors) > 0 {
+				stats.errors += len(fmtResult.errors)
+				results.add(fmtResult)
+				fmt.Fprintf(out, "  %s go fmt\n", red("✗"))
+			} else if len(fmtResult.warnings) > 0 {
+				stats.warnings += len(fmtResult.warnings)
+				results.add(fmtResult)
+				fmt.Fprintf(out, "  %s go fmt %s\n", yellow("⚠"), dim(fmt.Sprintf("(%d files need formatting)", len(fmtResult.warnings))))
+			} else if verbose {
+				fmt.Fprintf(out, "  %s go fmt\n", green("✓"))
+			}
+		} else if !hasGo {
+			fmt.Fprintf(out, "%s Go not installed, skipping Go checks\n", yellow("⚠"))
+			if goModuleRoot(blackdotDir) {
+				missingTools = append(missingTools, "go")
+			}
+		} else {
+			fmt.Fprintf(out, "%s No Go module (go.mod) in %s, skipping Go checks\n", dim("-"), blackdotDir)
 		}
-	} else {
-		fmt.Printf("%s Go not installed, skipping Go checks\n", yellow("⚠"))
 	}
 
 	// 4. Validate JSON files
-	fmt.Printf("%s Validating JSON files...\n", cyan("→"))
+	fmt.Fprintf(out, "%s Validating JSON files...\n", cyan("→"))
+	timings.startPhase("json")
 
 	jsonFiles := []string{
-		filepath.Join(blackdotDir, "powershell", "packages.json"),
+		staged.path(filepath.Join(blackdotDir, "powershell", "packages.json")),
 	}
 
-	// Also check config directory JSON files
+	// Also check config directory JSON files (not part of the repo, so not staged)
 	configDir := filepath.Join(os.Getenv("HOME"), ".config", "blackdot")
-	if configJSON := filepath.Join(configDir, "config.json"); lintFileExists(configJSON) {
-		jsonFiles = append(jsonFiles, configJSON)
+	for _, name := range []string{"config.json", "vault-items.json"} {
+		if configJSON := filepath.Join(configDir, name); staged == nil && lintFileExists(configJSON) {
+			jsonFiles = append(jsonFiles, configJSON)
+		}
 	}
+	schemas := lintJSONSchemas(blackdotDir, configDir)
 
-	for _, file := range jsonFiles {
+	for _, file := range guard.files(jsonFiles) {
 		if !lintFileExists(file) {
 			continue
 		}
+		textFiles = append(textFiles, file)
+		done := timings.file(file)
 		result := validateJSON(file)
+		// Files with a schema also get their keys checked for typos
+		if loadSchema, ok := schemas[file]; ok && len(result.errors) == 0 {
+			if schema, err := loadSchema(); err == nil {
+				result.warnings = checkJSONSchemaKeys(file, schema).warnings
+			} else {
+				logger.Debug("no schema for key check", "file", file, "error", err)
+			}
+		}
+		done()
 		stats.checked++
+		// Only files that parsed cleanly are reformatted
+		if fixJSON && len(result.errors) == 0 {
+			changed, diff, err := fixJSONFormat(file, sortKeys, showFix)
+			switch {
+			case err != nil:
+				result.errors = append(result.errors, fmt.Sprintf("--fix-json: %v", err))
+			case diff != "":
+				printUnifiedDiff(diff)
+			case changed:
+				fmt.Fprintf(out, "  %s %s %s\n", green("✓"), filepath.Base(file), dim("(reformatted)"))
+			}
+		}
 		if len(result.errors) > 0 {
 			stats.errors += len(result.errors)
-			results = append(results, result)
-			fmt.Printf("  %s %s\n", red("✗"), filepath.Base(file))
+			results.add(result)
+			if fixJSON {
+				fmt.Fprintf(out, "  %s %s %s\n", red("✗"), filepath.Base(file), dim("(invalid, not reformatted)"))
+			} else {
+				fmt.Fprintf(out, "  %s %s\n", red("✗"), filepath.Base(file))
+			}
+		} else if len(result.warnings) > 0 {
+			stats.warnings += len(result.warnings)
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d unknown keys)", len(result.warnings))))
 		} else if verbose {
-			fmt.Printf("  %s %s\n", green("✓"), filepath.Base(file))
+			fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(file))
+		}
+	}
+
+	// 4b. Config layers: keys in machine and project config that override
+	// nothing are most likely typos. The paths are the ones config get
+	// reads, which follow XDG_CONFIG_HOME.
+	if !skip["config-layers"] && staged == nil {
+		layers := []configLayerFile{
+			{name: "user", path: configLayerUser},
+			{name: "machine", path: configLayerMachine},
+			{name: "project", path: findProjectConfig()},
+		}
+		if lintFileExists(layers[1].path) || layers[2].path != "" {
+			fmt.Fprintf(out, "%s Checking config layers...\n", cyan("→"))
+			timings.startPhase("config layers")
+			stats.checked++
+			for _, result := range checkConfigLayers(layers, config.Schema) {
+				stats.errors += len(result.errors)
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				if len(result.errors) > 0 {
+					fmt.Fprintf(out, "  %s %s\n", red("✗"), filepath.Base(result.file))
+				} else {
+					fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(result.file), dim(fmt.Sprintf("(%d orphan keys)", len(result.warnings))))
+				}
+			}
 		}
 	}
 
 	// 5. Validate YAML files (GitHub workflows)
-	fmt.Printf("%s Validating YAML files...\n", cyan("→"))
+	fmt.Fprintf(out, "%s Validating YAML files...\n", cyan("→"))
+	timings.startPhase("yaml")
 
-	yamlFiles, _ := filepath.Glob(filepath.Join(blackdotDir, ".github", "workflows", "*.yml"))
-	yamlFiles2, _ := filepath.Glob(filepath.Join(blackdotDir, ".github", "workflows", "*.yaml"))
-	yamlFiles = append(yamlFiles, yamlFiles2...)
+	yamlFiles := staged.files(globLogged(filepath.Join(blackdotDir, ".github", "workflows", "*.yml")))
+	yamlFiles2 := staged.files(globLogged(filepath.Join(blackdotDir, ".github", "workflows", "*.yaml")))
+	yamlFiles = guard.files(append(yamlFiles, yamlFiles2...))
+	textFiles = append(textFiles, yamlFiles...)
 
 	for _, file := range yamlFiles {
+		done := timings.file(file)
 		result := validateYAML(file)
+		done()
 		stats.checked++
 		if len(result.errors) > 0 {
 			stats.errors += len(result.errors)
-			results = append(results, result)
-			fmt.Printf("  %s %s\n", red("✗"), filepath.Base(file))
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s\n", red("✗"), filepath.Base(file))
 		} else if verbose {
-			fmt.Printf("  %s %s\n", green("✓"), filepath.Base(file))
+			fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(file))
 		}
 	}
 
-	// 6. Check Brewfile tiers
-	fmt.Printf("%s Checking Brewfile tiers...\n", cyan("→"))
-
+	// 6. Check Brewfile tiers; staged Brewfiles only get the hygiene check,
+	// as the tiers are compared with each other
 	brewfileTiers := []string{
 		filepath.Join(blackdotDir, "brew", "Brewfile"),
 		filepath.Join(blackdotDir, "brew", "Brewfile.minimal"),
 		filepath.Join(blackdotDir, "brew", "Brewfile.enhanced"),
 	}
 
-	for _, file := range brewfileTiers {
+	if staged != nil {
+		textFiles = append(textFiles, guard.files(staged.files(brewfileTiers))...)
+	} else {
+		fmt.Fprintf(out, "%s Checking Brewfile tiers...\n", cyan("→"))
+		timings.startPhase("brewfile tiers")
+
+		for _, file := range brewfileTiers {
+			stats.checked++
+			if lintFileExists(file) {
+				textFiles = append(textFiles, guard.files([]string{file})...)
+				if verbose {
+					fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(file))
+				}
+			} else {
+				fmt.Fprintf(out, "  %s %s missing\n", yellow("⚠"), filepath.Base(file))
+				stats.warnings++
+				results.add(lintResult{file: file, warnings: []string{"Brewfile tier missing"}})
+			}
+		}
+
+		// Each tier must contain everything from the tier below it
+		if !skip["brew-tiers"] {
+			tiersInOrder := []string{
+				filepath.Join(blackdotDir, "brew", "Brewfile.minimal"),
+				filepath.Join(blackdotDir, "brew", "Brewfile.enhanced"),
+				filepath.Join(blackdotDir, "brew", "Brewfile"),
+			}
+			stats.checked++
+			tierResults := checkBrewfileInheritance(tiersInOrder)
+			for _, result := range tierResults {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(result.file), dim(fmt.Sprintf("(%d missing from lower tier)", len(result.warnings))))
+			}
+			if len(tierResults) == 0 && verbose {
+				fmt.Fprintf(out, "  %s tier inheritance\n", green("✓"))
+			}
+		}
+	}
+
+	// Typos in formula names otherwise only show up on a fresh machine
+	if verifyFormulae {
+		fmt.Fprintf(out, "%s Verifying Homebrew formulae...\n", cyan("→"))
+		timings.startPhase("brew formulae")
 		stats.checked++
-		if lintFileExists(file) {
-			if verbose {
-				fmt.Printf("  %s %s\n", green("✓"), filepath.Base(file))
+		formulaResults := verifyBrewfileFormulae(guard.files(staged.files(brewfileTiers)))
+		for _, result := range formulaResults {
+			stats.errors += len(result.errors)
+			stats.warnings += len(result.warnings)
+			results.add(result)
+			if len(result.errors) > 0 {
+				fmt.Fprintf(out, "  %s %s %s\n", red("✗"), filepath.Base(result.file), dim(fmt.Sprintf("(%d unknown)", len(result.errors))))
+			} else {
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(result.file), dim("(could not verify)"))
+			}
+		}
+		if len(formulaResults) == 0 && verbose {
+			fmt.Fprintf(out, "  %s formulae and casks exist\n", green("✓"))
+		}
+	}
+
+	// 7. Check PowerShell syntax (if pwsh available)
+	psFiles := staged.files(globLogged(filepath.Join(blackdotDir, "powershell", "*.psm1")))
+	psFiles2 := staged.files(globLogged(filepath.Join(blackdotDir, "powershell", "*.ps1")))
+	if hasPwsh {
+		fmt.Fprintf(out, "%s Checking PowerShell syntax...\n", cyan("→"))
+		timings.startPhase("powershell")
+
+		psFiles = guard.files(append(psFiles, psFiles2...))
+		textFiles = append(textFiles, psFiles...)
+
+		for _, file := range psFiles {
+			done := timings.file(file)
+			result := checkPowerShellSyntax(file)
+			done()
+			stats.checked++
+			if len(result.errors) > 0 {
+				stats.errors += len(result.errors)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s\n", red("✗"), filepath.Base(file))
+			} else if verbose {
+				fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(file))
+			}
+		}
+	} else {
+		if verbose {
+			fmt.Fprintf(out, "%s PowerShell (pwsh) not installed, skipping PS checks\n", dim("ℹ"))
+		}
+		if len(psFiles)+len(psFiles2) > 0 {
+			missingTools = append(missingTools, "pwsh")
+		}
+	}
+
+	// 8. Run shellcheck if available (on both bootstrap and lib)
+	if hasShellcheck {
+		fmt.Fprintf(out, "%s Running shellcheck...\n", cyan("→"))
+		timings.startPhase("shellcheck")
+
+		// shellcheck can't lint zsh; skip unless a directive overrides the dialect
+		var checkFiles []string
+		for _, file := range shellFiles {
+			if dialect, directive := shellDialect(file); dialect == "zsh" && !directive {
+				fmt.Fprintf(out, "  %s %s %s\n", dim("ℹ"), filepath.Base(file), dim("(zsh script, shellcheck skipped)"))
+				continue
+			}
+			checkFiles = append(checkFiles, file)
+		}
+
+		// Results come back in checkFiles order, so output is deterministic
+		for i, result := range runShellcheckAll(checkFiles, showFix, timings) {
+			file := checkFiles[i]
+			if len(result.errors) > 0 || len(result.warnings) > 0 {
+				stats.errors += len(result.errors)
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				if len(result.errors) > 0 {
+					fmt.Fprintf(out, "  %s %s\n", red("✗"), filepath.Base(file))
+				} else if verbose {
+					fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d warnings)", len(result.warnings))))
+				}
+			} else if verbose {
+				fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(file))
+			}
+		}
+	} else {
+		fmt.Fprintf(out, "%s Shellcheck not installed (optional)\n", yellow("⚠"))
+		fmt.Fprintln(out, "  Install with: brew install shellcheck")
+		if len(shellFiles) > 0 {
+			missingTools = append(missingTools, "shellcheck")
+		}
+	}
+
+	// 9. File hygiene checks (opt-in)
+	if hygiene {
+		fmt.Fprintf(out, "%s Checking file hygiene...\n", cyan("→"))
+		timings.startPhase("hygiene")
+
+		for _, file := range textFiles {
+			done := timings.file(file)
+			result := checkHygiene(file)
+			done()
+			if len(result.warnings) > 0 {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d warnings)", len(result.warnings))))
+			} else if verbose {
+				fmt.Fprintf(out, "  %s %s\n", green("✓"), filepath.Base(file))
+			}
+		}
+	}
+
+	// Technical debt markers (opt-in); informational unless --max-todos
+	// sets a budget
+	var todos []lintTodo
+	if listTodos {
+		fmt.Fprintf(out, "%s Counting TODO/FIXME/XXX comments...\n", cyan("→"))
+		timings.startPhase("todos")
+		todoFiles := slices.Concat(zshFiles, shellFiles)
+		if lintFileExists(zshrcPath) {
+			todoFiles = append(todoFiles, zshrcPath)
+		}
+		for _, file := range todoFiles {
+			todos = append(todos, findShellTodos(file)...)
+		}
+		for _, file := range guard.files(staged.files(goSourceFiles(blackdotDir))) {
+			todos = append(todos, findGoTodos(file)...)
+		}
+		stats.checked++
+		for _, todo := range todos {
+			fmt.Fprintf(out, "  %s %s:%d: %s\n", dim("ℹ"), baselineRelPath(blackdotDir, staged.original(todo.file)), todo.line, dim(todo.text))
+		}
+		if len(todos) == 0 && verbose {
+			fmt.Fprintf(out, "  %s No TODO/FIXME/XXX comments\n", green("✓"))
+		}
+	}
+
+	// 10. Markdown link checks (opt-in); links point at other files, so
+	// staged copies can't be checked on their own
+	if checkDocs && staged == nil {
+		fmt.Fprintf(out, "%s Checking markdown links...\n", cyan("→"))
+		timings.startPhase("docs")
+
+		external := make(map[string][]markdownLink)
+		for _, file := range guard.files(markdownFiles(blackdotDir)) {
+			done := timings.file(file)
+			result, links := checkMarkdownLinks(file)
+			done()
+			stats.checked++
+			if len(links) > 0 {
+				external[file] = links
+			}
+			if len(result.errors) > 0 {
+				stats.errors += len(result.errors)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", red("✗"), baselineRelPath(blackdotDir, file), dim(fmt.Sprintf("(%d broken links)", len(result.errors))))
+			} else if verbose {
+				fmt.Fprintf(out, "  %s %s\n", green("✓"), baselineRelPath(blackdotDir, file))
+			}
+		}
+
+		if checkExternal {
+			timings.startPhase("external links")
+			var urls []string
+			for _, links := range external {
+				for _, link := range links {
+					if !slices.Contains(urls, link.target) {
+						urls = append(urls, link.target)
+					}
+				}
+			}
+			fmt.Fprintf(out, "%s Checking %d external links...\n", cyan("→"), len(urls))
+			for _, result := range externalLinkResults(external, checkExternalLinks(urls)) {
+				stats.warnings += len(result.warnings)
+				results.add(result)
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), baselineRelPath(blackdotDir, result.file), dim(fmt.Sprintf("(%d failed links)", len(result.warnings))))
+			}
+		}
+	}
+
+	// 11. Dangling symlinks; the globs above skip them without a word
+	if !skip["symlinks"] && staged == nil {
+		fmt.Fprintf(out, "%s Checking symlinks...\n", cyan("→"))
+		timings.startPhase("symlinks")
+		stats.checked++
+		for _, result := range danglingSymlinks(blackdotDir) {
+			stats.warnings += len(result.warnings)
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), baselineRelPath(blackdotDir, result.file), dim("("+result.warnings[0]+")"))
+		}
+	}
+
+	// 12. External checkers (opt-in): blackdot-lint-* on PATH or --plugin-dir
+	if usePlugins {
+		fmt.Fprintf(out, "%s Running lint plugins...\n", cyan("→"))
+		timings.startPhase("plugins")
+		plugins := findLintPlugins(lintPluginDirs(blackdotDir, pluginDir))
+		if len(plugins) == 0 {
+			fmt.Fprintf(out, "  %s No %s* executables found\n", dim("ℹ"), lintPluginPrefix)
+		}
+		for _, plugin := range plugins {
+			done := timings.file(plugin.path)
+			pluginResults := runLintPlugin(plugin, blackdotDir, textFiles)
+			done()
+			stats.checked++
+			var errs, warns int
+			for _, result := range pluginResults {
+				errs += len(result.errors)
+				warns += len(result.warnings)
+				results.add(result)
+			}
+			stats.errors += errs
+			stats.warnings += warns
+			switch {
+			case errs > 0:
+				fmt.Fprintf(out, "  %s %s %s\n", red("✗"), plugin.name, dim(fmt.Sprintf("(%d errors, %d warnings)", errs, warns)))
+			case warns > 0:
+				fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), plugin.name, dim(fmt.Sprintf("(%d warnings)", warns)))
+			case verbose:
+				fmt.Fprintf(out, "  %s %s\n", green("✓"), plugin.name)
 			}
-		} else {
-			fmt.Printf("  %s %s missing\n", yellow("⚠"), filepath.Base(file))
-			stats.warnings++
 		}
 	}
 
-	// 7. Check PowerShell syntax (if pwsh available)
-	if hasPwsh {
-		fmt.Printf("%s Checking PowerShell syntax...\n", cyan("→"))
+	// Files the guard kept out of the checks above
+	if skipped := guard.results(); len(skipped) > 0 {
+		fmt.Fprintf(out, "%s Skipped %d large or binary file(s)\n", yellow("⚠"), len(skipped))
+		for _, result := range skipped {
+			stats.warnings += len(result.warnings)
+			results.add(result)
+			fmt.Fprintf(out, "  %s %s %s\n", yellow("⚠"), filepath.Base(result.file), dim("("+strings.TrimPrefix(result.warnings[0], "skipped: ")+")"))
+		}
+	}
 
-		psFiles, _ := filepath.Glob(filepath.Join(blackdotDir, "powershell", "*.psm1"))
-		psFiles2, _ := filepath.Glob(filepath.Join(blackdotDir, "powershell", "*.ps1"))
-		psFiles = append(psFiles, psFiles2...)
+	// Staged copies report as the files they were copied from
+	results = staged.restore(results)
 
-		for _, file := range psFiles {
-			result := checkPowerShellSyntax(file)
-			stats.checked++
-			if len(result.errors) > 0 {
-				stats.errors += len(result.errors)
-				results = append(results, result)
-				fmt.Printf("  %s %s\n", red("✗"), filepath.Base(file))
-			} else if verbose {
-				fmt.Printf("  %s %s\n", green("✓"), filepath.Base(file))
-			}
+	// Baseline: record current issues, or hide the ones already recorded
+	timings.startPhase("baseline")
+	baselinePath := filepath.Join(blackdotDir, lintBaselineFile)
+	if writeBaseline {
+		n, err := writeLintBaseline(baselinePath, blackdotDir, results.sorted())
+		if err != nil {
+			return err
 		}
-	} else if verbose {
-		fmt.Printf("%s PowerShell (pwsh) not installed, skipping PS checks\n", dim("ℹ"))
+		fmt.Println()
+		fmt.Printf("%s Wrote %d issue(s) to %s\n", green("[OK]"), n, baselinePath)
+		return nil
 	}
 
-	// 8. Run shellcheck if available (on both bootstrap and lib)
-	if hasShellcheck {
-		fmt.Printf("%s Running shellcheck...\n", cyan("→"))
+	// Report files in path order regardless of which phase found the issue
+	sortedResults := results.sorted()
 
-		// Run on all shell files
-		for _, file := range shellFiles {
-			result := runShellcheck(file, showFix)
-			if len(result.warnings) > 0 {
-				stats.warnings += len(result.warnings)
-				// Find existing result or add new
-				found := false
-				for i, r := range results {
-					if r.file == file {
-						results[i].warnings = append(results[i].warnings, result.warnings...)
-						found = true
-						break
-					}
-				}
-				if !found {
-					results = append(results, result)
-				}
-				if verbose {
-					fmt.Printf("  %s %s %s\n", yellow("⚠"), filepath.Base(file), dim(fmt.Sprintf("(%d warnings)", len(result.warnings))))
-				}
-			} else if verbose {
-				fmt.Printf("  %s %s\n", green("✓"), filepath.Base(file))
+	if !noBaseline {
+		baseline, err := loadLintBaseline(baselinePath)
+		if err != nil {
+			return err
+		}
+		if baseline != nil {
+			var suppressed int
+			sortedResults, suppressed = applyLintBaseline(sortedResults, baseline, blackdotDir)
+			stats.errors, stats.warnings = 0, 0
+			for _, r := range sortedResults {
+				stats.errors += len(r.errors)
+				stats.warnings += len(r.warnings)
+			}
+			logger.Debug("applied lint baseline", "path", baselinePath, "suppressed", suppressed)
+			if suppressed > 0 {
+				fmt.Fprintf(out, "%s %d known issue(s) suppressed by %s\n", dim("ℹ"), suppressed, lintBaselineFile)
 			}
 		}
-	} else {
-		fmt.Printf("%s Shellcheck not installed (optional)\n", yellow("⚠"))
-		fmt.Println("  Install with: brew install shellcheck")
+	}
+
+	timings.stopPhase()
+
+	// Missing tools aren't file issues, so they're counted after the
+	// baseline and never recorded in it. --require-tools wins over
+	// --warn-missing-tools.
+	if len(missingTools) > 0 {
+		switch {
+		case requireTools:
+			stats.errors += len(missingTools)
+		case warnMissingTools:
+			stats.warnings += len(missingTools)
+		}
 	}
 
 	// Print detailed results
-	if len(results) > 0 {
+	if len(sortedResults) > 0 {
 		hasIssues := false
-		for _, r := range results {
+		for _, r := range sortedResults {
 			if len(r.errors) > 0 || len(r.warnings) > 0 {
 				hasIssues = true
 				break
@@ -319,50 +1389,144 @@ func runLint(cmd *cobra.Command, args []string) error {
 		}
 
 		if hasIssues {
-			fmt.Println()
-			fmt.Println(color.New(color.Bold).Sprint("Issues Found:"))
-			fmt.Println()
-			for _, r := range results {
-				if len(r.errors) > 0 || len(r.warnings) > 0 {
-					fmt.Printf("%s:\n", cyan(r.file))
-					for _, e := range r.errors {
-						fmt.Printf("  %s %s\n", red("error:"), e)
-					}
-					for _, w := range r.warnings {
-						fmt.Printf("  %s %s\n", yellow("warning:"), w)
-					}
-					fmt.Println()
-				}
-			}
+			fmt.Fprintln(out)
+			printLintIssues(os.Stdout, sortedResults, groupBy)
 		}
 	}
 
 	// Summary
-	fmt.Println()
-	fmt.Println("==============================")
-	fmt.Printf("Files checked: %d\n", stats.checked)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "==============================")
+	fmt.Fprintf(out, "Files checked: %d\n", stats.checked)
+	// Missing tools count as errors or warnings, so like the counts below
+	// they go to stdout even with --quiet
+	if len(missingTools) > 0 {
+		switch {
+		case requireTools:
+			fmt.Printf("%s Required tools not installed: %s\n", red("✗"), strings.Join(missingTools, ", "))
+		case warnMissingTools:
+			fmt.Printf("%s Tools not installed, checks skipped: %s\n", yellow("⚠"), strings.Join(missingTools, ", "))
+		}
+	}
 
 	if stats.errors == 0 && stats.warnings == 0 {
-		fmt.Printf("%s All checks passed!\n", green("[OK]"))
+		fmt.Fprintf(out, "%s All checks passed!\n", green("[OK]"))
 	} else if stats.errors == 0 {
 		fmt.Printf("%s %d warning(s) found\n", yellow("[WARN]"), stats.warnings)
 	} else {
 		fmt.Printf("%s %d error(s), %d warning(s)\n", red("[FAIL]"), stats.errors, stats.warnings)
 	}
+	overWarnings := maxWarnings >= 0 && stats.warnings > maxWarnings
+	if overWarnings {
+		fmt.Printf("%s %d warning(s) exceed --max-warnings %d\n", red("[FAIL]"), stats.warnings, maxWarnings)
+	}
+	overTodos := maxTodos >= 0 && len(todos) > maxTodos
+	switch {
+	case overTodos:
+		fmt.Printf("%s %d TODO/FIXME/XXX comment(s) exceed --max-todos %d\n", red("[FAIL]"), len(todos), maxTodos)
+	case maxTodos >= 0:
+		fmt.Fprintf(out, "%s %d TODO/FIXME/XXX comment(s), budget %d\n", dim("ℹ"), len(todos), maxTodos)
+	case listTodos:
+		fmt.Fprintf(out, "%s %d TODO/FIXME/XXX comment(s)\n", dim("ℹ"), len(todos))
+	}
+	passed := stats.errors == 0 && !overWarnings && !overTodos && !(strict && stats.warnings > 0)
+	saveLintSummary(blackdotDir, stats, passed, staged != nil)
+	if summaryJSON != "" {
+		if err := writeLintSummaryReport(summaryJSON, newLintSummary(blackdotDir, stats, passed, staged != nil), sortedResults); err != nil {
+			return err
+		}
+	}
 
+	if requireTools && len(missingTools) > 0 {
+		return classErrorf(ErrToolMissing, "lint failed: %s not installed (--require-tools)", strings.Join(missingTools, ", "))
+	}
 	if stats.errors > 0 {
 		return fmt.Errorf("lint failed with %d errors", stats.errors)
 	}
+	if overWarnings {
+		return fmt.Errorf("lint failed with %d warnings (--max-warnings %d)", stats.warnings, maxWarnings)
+	}
+	if overTodos {
+		return fmt.Errorf("lint failed with %d TODO/FIXME/XXX comments (--max-todos %d)", len(todos), maxTodos)
+	}
+	if strict && stats.warnings > 0 {
+		return fmt.Errorf("lint failed with %d warnings (--strict)", stats.warnings)
+	}
 
 	return nil
 }
 
+// lintResults aggregates issues from every phase, keyed by file
+type lintResults map[string]*lintResult
+
+// add merges result's issues into the entry for its file
+func (m lintResults) add(result lintResult) {
+	if r, ok := m[result.file]; ok {
+		r.errors = append(r.errors, result.errors...)
+		r.warnings = append(r.warnings, result.warnings...)
+		return
+	}
+	m[result.file] = &result
+}
+
+// sorted returns the aggregated results ordered by file path
+func (m lintResults) sorted() []lintResult {
+	files := make([]string, 0, len(m))
+	for file := range m {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	out := make([]lintResult, 0, len(files))
+	for _, file := range files {
+		out = append(out, *m[file])
+	}
+	return out
+}
+
 // commandExists checks if a command is available in PATH
 func commandExists(cmd string) bool {
 	_, err := exec.LookPath(cmd)
 	return err == nil
 }
 
+// runLintCommand runs an external tool with lintCommandTimeout, returning a
+// *lintTimeoutError if it had to be killed. The whole process group is
+// killed so helpers spawned by the tool (go vet's analyzers) don't outlive it.
+func runLintCommand(target, dir, name string, args ...string) ([]byte, error) {
+	return runLintCommandOutput(target, dir, (*exec.Cmd).CombinedOutput, name, args...)
+}
+
+// runLintCommandOutput is runLintCommand with the output collected by run,
+// for tools whose stderr must stay out of what gets parsed
+func runLintCommandOutput(target, dir string, run func(*exec.Cmd) ([]byte, error), name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lintCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	configureLintCommand(cmd)
+	// Don't wait forever on pipes held open by orphaned grandchildren
+	cmd.WaitDelay = 2 * time.Second
+
+	output, err := run(cmd)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logger.Debug("lint command timed out", "tool", name, "target", target, "timeout", lintCommandTimeout)
+		return output, &lintTimeoutError{tool: name, target: target, timeout: lintCommandTimeout}
+	}
+	return output, err
+}
+
+// lintTimedOut records a timeout as an error on result and reports whether err was one
+func lintTimedOut(result *lintResult, err error) bool {
+	var timeoutErr *lintTimeoutError
+	if errors.As(err, &timeoutErr) {
+		result.errors = append(result.errors, timeoutErr.Error())
+		return true
+	}
+	return false
+}
+
 // lintFileExists checks if a file exists (local to lint)
 func lintFileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -373,8 +1537,10 @@ func lintFileExists(path string) bool {
 func checkZshSyntax(file string) lintResult {
 	result := lintResult{file: file}
 
-	cmd := exec.Command("zsh", "-n", file)
-	output, err := cmd.CombinedOutput()
+	output, err := runLintCommand(file, "", "zsh", "-n", file)
+	if lintTimedOut(&result, err) {
+		return result
+	}
 	if err != nil {
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
@@ -403,16 +1569,14 @@ func checkBashSyntax(file string) lintResult {
 
 	// Determine shell from shebang
 	shell := "bash"
-	lines := strings.Split(string(data), "\n")
-	if len(lines) > 0 && strings.HasPrefix(lines[0], "#!") {
-		shebang := lines[0]
-		if strings.Contains(shebang, "zsh") {
-			shell = "zsh"
-		}
+	if shebangShell(string(data)) == "zsh" {
+		shell = "zsh"
 	}
 
-	cmd := exec.Command(shell, "-n", file)
-	output, err := cmd.CombinedOutput()
+	output, err := runLintCommand(file, "", shell, "-n", file)
+	if lintTimedOut(&result, err) {
+		return result
+	}
 	if err != nil {
 		errLines := strings.Split(string(output), "\n")
 		for _, line := range errLines {
@@ -429,13 +1593,14 @@ func checkBashSyntax(file string) lintResult {
 	return result
 }
 
-// runGoVet runs go vet on the project
-func runGoVet(dir string) lintResult {
+// runGoVet runs go vet on pkgs, or on the whole project when pkgs is empty
+func runGoVet(dir string, pkgs []string) lintResult {
 	result := lintResult{file: "go vet"}
 
-	cmd := exec.Command("go", "vet", "./...")
-	cmd.Dir = dir
-	output, err := cmd.CombinedOutput()
+	output, err := runLintCommand(dir, dir, "go", append([]string{"vet"}, goPackageArgs(pkgs)...)...)
+	if lintTimedOut(&result, err) {
+		return result
+	}
 	if err != nil {
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
@@ -449,13 +1614,51 @@ func runGoVet(dir string) lintResult {
 	return result
 }
 
+// runGoBuild compiles pkgs (every package when empty), catching errors in
+// packages vet skips. Build output is discarded.
+func runGoBuild(dir string, pkgs []string) lintResult {
+	result := lintResult{file: "go build"}
+
+	output, err := runLintCommand(dir, dir, "go", append([]string{"build", "-o", os.DevNull}, goPackageArgs(pkgs)...)...)
+	if lintTimedOut(&result, err) {
+		return result
+	}
+	if err != nil {
+		result.errors = parseGoBuildOutput(string(output))
+		if len(result.errors) == 0 {
+			result.errors = append(result.errors, err.Error())
+		}
+	}
+
+	return result
+}
+
+// parseGoBuildOutput extracts compiler errors as "file:line:col: message",
+// dropping "# package" headers. Lines without a position are kept as-is.
+func parseGoBuildOutput(output string) []string {
+	var errs []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := goErrorPattern.FindStringSubmatch(line); m != nil {
+			file := filepath.ToSlash(strings.TrimPrefix(m[1], "./"))
+			line = fmt.Sprintf("%s:%s:%s: %s", file, m[2], m[3], m[4])
+		}
+		errs = append(errs, line)
+	}
+	return errs
+}
+
 // runGoFmtCheck checks if any Go files need formatting
 func runGoFmtCheck(dir string) lintResult {
 	result := lintResult{file: "go fmt"}
 
-	cmd := exec.Command("gofmt", "-l", ".")
-	cmd.Dir = dir
-	output, err := cmd.CombinedOutput()
+	output, err := runLintCommand(dir, dir, "gofmt", "-l", ".")
+	if lintTimedOut(&result, err) {
+		return result
+	}
 	if err != nil {
 		result.warnings = append(result.warnings, err.Error())
 		return result
@@ -485,12 +1688,77 @@ func validateJSON(file string) lintResult {
 
 	var js interface{}
 	if err := json.Unmarshal(data, &js); err != nil {
+		logger.Debug("JSON parse failed", "file", file, "error", err)
+		result.errors = append(result.errors, fmt.Sprintf("invalid JSON: %s", err.Error()))
+		return result
+	}
+
+	// Unmarshal keeps the last of repeated keys, hiding the earlier values
+	dups, err := jsonDuplicateKeys(data)
+	if err != nil {
+		logger.Debug("JSON token walk failed", "file", file, "error", err)
 		result.errors = append(result.errors, fmt.Sprintf("invalid JSON: %s", err.Error()))
 	}
+	result.errors = append(result.errors, dups...)
 
 	return result
 }
 
+// jsonDuplicateKeys walks the tokens of a JSON document and describes each
+// key repeated within the same object, with its line and the object's path
+func jsonDuplicateKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var dups []string
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			seen := make(map[string]bool)
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyTok.(string)
+				if seen[key] {
+					line := bytes.Count(data[:dec.InputOffset()], []byte("\n")) + 1
+					where := path
+					if where == "" {
+						where = "top level"
+					}
+					dups = append(dups, fmt.Sprintf("line %d: duplicate key %q in %s", line, key, where))
+				}
+				seen[key] = true
+				if err := walk(path + "." + key); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token() // }
+			return err
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token() // ]
+			return err
+		}
+		return nil
+	}
+
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return dups, nil
+}
+
 // validateYAML validates a YAML file
 func validateYAML(file string) lintResult {
 	result := lintResult{file: file}
@@ -503,6 +1771,7 @@ func validateYAML(file string) lintResult {
 
 	var yml interface{}
 	if err := yaml.Unmarshal(data, &yml); err != nil {
+		logger.Debug("YAML parse failed", "file", file, "error", err)
 		result.errors = append(result.errors, fmt.Sprintf("invalid YAML: %s", err.Error()))
 	}
 
@@ -525,8 +1794,10 @@ if ($errors) {
 }
 `, file)
 
-	cmd := exec.Command("pwsh", "-NoProfile", "-Command", script)
-	output, err := cmd.CombinedOutput()
+	output, err := runLintCommand(file, "", "pwsh", "-NoProfile", "-Command", script)
+	if lintTimedOut(&result, err) {
+		return result
+	}
 	if err != nil {
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
@@ -543,6 +1814,346 @@ if ($errors) {
 	return result
 }
 
+// checkBrewfileInheritance verifies each tier contains every entry of the
+// tier before it. tiers is ordered smallest first; missing files are skipped
+// so the remaining tiers are still compared. Warnings are reported against
+// the higher tier.
+//
+// The lint phase passes minimal, enhanced, then Brewfile: plain Brewfile is
+// the "full" tier (brew install, blackdot init), so it comes last rather
+// than between minimal and enhanced.
+func checkBrewfileInheritance(tiers []string) []lintResult {
+	var results []lintResult
+	var lower string
+	var lowerEntries []string
+
+	for _, tier := range tiers {
+		formulas, casks, err := parseBrewfile(tier)
+		if err != nil {
+			logger.Debug("skipping Brewfile tier", "file", tier, "error", err)
+			continue
+		}
+		var entries []string
+		for _, f := range formulas {
+			entries = append(entries, fmt.Sprintf("brew %q", f))
+		}
+		for _, c := range casks {
+			entries = append(entries, fmt.Sprintf("cask %q", c))
+		}
+
+		if lower != "" {
+			have := make(map[string]bool, len(entries))
+			for _, e := range entries {
+				have[e] = true
+			}
+			result := lintResult{file: tier}
+			for _, e := range lowerEntries {
+				if !have[e] {
+					result.warnings = append(result.warnings,
+						fmt.Sprintf("%s is in %s but missing from %s", e, filepath.Base(lower), filepath.Base(tier)))
+				}
+			}
+			if len(result.warnings) > 0 {
+				results = append(results, result)
+			}
+		}
+
+		lower, lowerEntries = tier, entries
+	}
+
+	return results
+}
+
+// zshDefinition is an alias or function definition site
+type zshDefinition struct {
+	file string
+	line int
+}
+
+// findDuplicateZshDefinitions reports aliases and functions defined in more
+// than one file. files must be in load order; each warning is attached to
+// the last file, whose definition wins, and lists every location.
+// Redefinitions within a single file (e.g. per-OS branches) are ignored.
+func findDuplicateZshDefinitions(files []string) []lintResult {
+	defs := make(map[string][]zshDefinition)
+	var order []string
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			logger.Debug("skipping zsh file", "file", file, "error", err)
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for i, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+
+			var key string
+			if m := zshAliasPattern.FindStringSubmatch(line); m != nil {
+				key = "alias " + m[1]
+			} else if m := zshFunctionPattern.FindStringSubmatch(line); m != nil {
+				key = "function " + m[1]
+			} else if m := zshFuncDefPattern.FindStringSubmatch(line); m != nil {
+				key = "function " + m[1]
+			} else {
+				continue
+			}
+
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, ok := defs[key]; !ok {
+				order = append(order, key)
+			}
+			defs[key] = append(defs[key], zshDefinition{file: file, line: i + 1})
+		}
+	}
+
+	byFile := make(map[string]*lintResult)
+	var results []*lintResult
+	for _, key := range order {
+		sites := defs[key]
+		if len(sites) < 2 {
+			continue
+		}
+
+		var locations []string
+		for _, d := range sites {
+			locations = append(locations, fmt.Sprintf("%s:%d", filepath.Base(d.file), d.line))
+		}
+		winner := sites[len(sites)-1].file
+		r, ok := byFile[winner]
+		if !ok {
+			r = &lintResult{file: winner}
+			byFile[winner] = r
+			results = append(results, r)
+		}
+		r.warnings = append(r.warnings, fmt.Sprintf("%s defined in multiple files: %s", key, strings.Join(locations, ", ")))
+	}
+
+	var out []lintResult
+	for _, r := range results {
+		out = append(out, *r)
+	}
+	return out
+}
+
+// checkExecutableBit warns when a file starting with a shebang lacks the
+// owner-execute bit, unless it carries lintSourcedMarker near the top. With
+// apply, the file is made executable instead and fixed is true.
+func checkExecutableBit(file string, apply bool) (result lintResult, fixed bool) {
+	result = lintResult{file: file}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return result, false
+	}
+	if info.Mode().Perm()&0100 != 0 {
+		return result, false
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil || !strings.HasPrefix(string(data), "#!") {
+		return result, false
+	}
+	for i, line := range strings.SplitN(string(data), "\n", 21) {
+		if i == 20 {
+			break
+		}
+		if strings.TrimSpace(line) == lintSourcedMarker {
+			return result, false
+		}
+	}
+
+	if apply {
+		// Like chmod +x: add execute wherever read is granted
+		mode := info.Mode().Perm()
+		if err := os.Chmod(file, mode|(mode&0444)>>2); err != nil {
+			result.warnings = append(result.warnings, fmt.Sprintf("could not chmod +x: %v", err))
+			return result, false
+		}
+		return result, true
+	}
+
+	result.warnings = append(result.warnings,
+		fmt.Sprintf("has a shebang but is not executable (chmod +x %s, or add %q if it is sourced)", file, lintSourcedMarker))
+	return result, false
+}
+
+// checkShebang warns when an executable file doesn't start with "#!", since
+// it then runs under whichever shell execs it. With showFix the warning shows
+// the line to insert; with apply, lintDefaultShebang is prepended and fixed
+// is true.
+func checkShebang(file string, showFix, apply bool) (result lintResult, fixed bool) {
+	result = lintResult{file: file}
+
+	info, err := os.Stat(file)
+	if err != nil || info.Mode().Perm()&0111 == 0 || info.Size() == 0 {
+		return result, false
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || strings.HasPrefix(string(data), "#!") {
+		return result, false
+	}
+
+	if apply {
+		// WriteFile on an existing file keeps its mode
+		if err := os.WriteFile(file, append([]byte(lintDefaultShebang+"\n"), data...), info.Mode().Perm()); err != nil {
+			result.warnings = append(result.warnings, fmt.Sprintf("could not add shebang: %v", err))
+			return result, false
+		}
+		return result, true
+	}
+
+	msg := fmt.Sprintf("is executable but has no shebang, so it runs under the caller's shell (add %q as line 1)", lintDefaultShebang)
+	if showFix {
+		first, _, _ := strings.Cut(string(data), "\n")
+		msg += fmt.Sprintf("\n    fix: @@ -1 +1,2 @@\n    + %s\n      %s", lintDefaultShebang, first)
+	}
+	result.warnings = append(result.warnings, msg)
+	return result, false
+}
+
+// checkHygiene reports trailing whitespace, CRLF line endings, and a
+// missing final newline. All findings are warnings.
+func checkHygiene(file string) lintResult {
+	result := lintResult{file: file}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		result.warnings = append(result.warnings, err.Error())
+		return result
+	}
+
+	if len(data) == 0 {
+		return result
+	}
+
+	content := string(data)
+	lines := strings.Split(content, "\n")
+	// A trailing newline leaves an empty final element; don't treat it as a line
+	if strings.HasSuffix(content, "\n") {
+		lines = lines[:len(lines)-1]
+	}
+
+	for i, line := range lines {
+		lineNum := i + 1
+		if strings.HasSuffix(line, "\r") {
+			result.warnings = append(result.warnings, fmt.Sprintf("line %d: CRLF line ending", lineNum))
+			line = strings.TrimSuffix(line, "\r")
+		}
+		if strings.TrimRight(line, " \t") != line {
+			result.warnings = append(result.warnings, fmt.Sprintf("line %d: trailing whitespace", lineNum))
+		}
+	}
+
+	if !strings.HasSuffix(content, "\n") {
+		result.warnings = append(result.warnings, fmt.Sprintf("line %d: missing final newline", len(lines)))
+	}
+
+	return result
+}
+
+// shellDialect returns the shell dialect of a script and whether it came from
+// an inline "# shellcheck shell=<dialect>" directive rather than the shebang
+func shellDialect(file string) (string, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", false
+	}
+
+	content := string(data)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		directive := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		if !strings.HasPrefix(directive, "shellcheck ") {
+			continue
+		}
+		for _, field := range strings.Fields(directive) {
+			if strings.HasPrefix(field, "shell=") {
+				return strings.TrimPrefix(field, "shell="), true
+			}
+		}
+	}
+
+	return shebangShell(content), false
+}
+
+// shebangShell returns the interpreter named in a script's shebang line
+// (sh, bash, dash, ksh, zsh), or "" if there is none
+func shebangShell(content string) string {
+	firstLine := strings.SplitN(content, "\n", 2)[0]
+	if !strings.HasPrefix(firstLine, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(firstLine, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	// #!/usr/bin/env bash -> bash
+	interp := filepath.Base(fields[0])
+	if interp == "env" {
+		interp = ""
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") {
+				interp = filepath.Base(f)
+				break
+			}
+		}
+	}
+
+	switch interp {
+	case "sh", "bash", "dash", "ksh", "zsh":
+		return interp
+	}
+	return ""
+}
+
+// runShellcheckAll runs shellcheck on files concurrently, bounded by
+// lintJobs, and returns results in the same order as files
+func runShellcheckAll(files []string, showFix bool, timings *lintTimings) []lintResult {
+	results := make([]lintResult, len(files))
+	jobs := lintJobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			done := timings.file(file)
+			results[i] = runShellcheck(file, showFix)
+			done()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// shellcheckFilterArgs passes --shellcheck-severity and --shellcheck-exclude
+// on to shellcheck
+func shellcheckFilterArgs() []string {
+	args := []string{"--severity=" + lintShellcheckSeverity}
+	if len(lintShellcheckExclude) > 0 {
+		args = append(args, "-e", strings.Join(lintShellcheckExclude, ","))
+	}
+	return args
+}
+
 // runShellcheck runs shellcheck on a file
 func runShellcheck(file string, showFix bool) lintResult {
 	result := lintResult{file: file}
@@ -552,8 +2163,20 @@ func runShellcheck(file string, showFix bool) lintResult {
 		args = []string{"-f", "diff", file}
 	}
 
-	cmd := exec.Command("shellcheck", args...)
-	output, _ := cmd.CombinedOutput()
+	// An inline "# shellcheck shell=" directive wins; otherwise pass the shebang dialect
+	if dialect, directive := shellDialect(file); dialect != "" && !directive {
+		args = append([]string{"--shell=" + dialect}, args...)
+	}
+	args = append(shellcheckFilterArgs(), args...)
+
+	output, err := runLintCommand(file, "", "shellcheck", args...)
+	if lintTimedOut(&result, err) {
+		return result
+	}
+	// shellcheck exits 1 when it finds issues; anything else is a failure to run
+	if exitErr, ok := err.(*exec.ExitError); err != nil && (!ok || exitErr.ExitCode() != 1) {
+		logger.Debug("shellcheck failed", "file", file, "args", args, "error", err)
+	}
 
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
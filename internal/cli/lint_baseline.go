@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// lintBaselineFile is written to the root of BLACKDOT_DIR
+const lintBaselineFile = ".blackdot-lint-baseline.json"
+
+// lintBaseline records known issues that lint should not report again
+type lintBaseline struct {
+	Version int                 `json:"version"`
+	Issues  []lintBaselineIssue `json:"issues"`
+}
+
+type lintBaselineIssue struct {
+	File     string `json:"file"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Message  string `json:"message"`
+}
+
+var (
+	// file:line:col: prefix from shellcheck/go vet gcc-style output
+	lintPositionPattern = regexp.MustCompile(`^.*?:\d+:(\d+:)?\s*`)
+	// "line N:" prefix from blackdot's own checks
+	lintLinePattern = regexp.MustCompile(`\bline \d+:\s*`)
+)
+
+// normalizeLintMessage strips paths and line numbers so an issue still
+// matches the baseline after unrelated edits shift it up or down
+func normalizeLintMessage(file, msg string) string {
+	msg = strings.ReplaceAll(msg, file, "")
+	msg = lintPositionPattern.ReplaceAllString(msg, "")
+	msg = lintLinePattern.ReplaceAllString(msg, "")
+	return strings.TrimSpace(msg)
+}
+
+// baselineKey identifies an issue independent of its position
+func baselineKey(file, severity, msg string) string {
+	return file + "\x00" + severity + "\x00" + msg
+}
+
+// baselineRelPath stores files relative to BLACKDOT_DIR so baselines are portable
+func baselineRelPath(dir, file string) string {
+	if rel, err := filepath.Rel(dir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(file)
+}
+
+// loadLintBaseline returns a count of each baselined issue, or nil if the
+// baseline file does not exist
+func loadLintBaseline(path string) (map[string]int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading lint baseline: %w", err)
+	}
+
+	var baseline lintBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("parsing lint baseline %s: %w", path, err)
+	}
+
+	counts := make(map[string]int)
+	for _, issue := range baseline.Issues {
+		counts[baselineKey(issue.File, issue.Severity, issue.Message)]++
+	}
+	return counts, nil
+}
+
+// writeLintBaseline records every issue in results and returns how many were written
+func writeLintBaseline(path, dir string, results []lintResult) (int, error) {
+	baseline := lintBaseline{Version: 1, Issues: []lintBaselineIssue{}}
+
+	for _, r := range results {
+		file := baselineRelPath(dir, r.file)
+		for _, e := range r.errors {
+			baseline.Issues = append(baseline.Issues, lintBaselineIssue{file, "error", normalizeLintMessage(r.file, e)})
+		}
+		for _, w := range r.warnings {
+			baseline.Issues = append(baseline.Issues, lintBaselineIssue{file, "warning", normalizeLintMessage(r.file, w)})
+		}
+	}
+
+	// Sort so the file diffs cleanly when regenerated
+	sort.SliceStable(baseline.Issues, func(i, j int) bool {
+		a, b := baseline.Issues[i], baseline.Issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Severity != b.Severity {
+			return a.Severity < b.Severity
+		}
+		return a.Message < b.Message
+	})
+
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("marshaling lint baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return 0, fmt.Errorf("writing lint baseline: %w", err)
+	}
+	return len(baseline.Issues), nil
+}
+
+// applyLintBaseline removes baselined issues from results. Each baseline
+// entry suppresses one occurrence, so a repeated issue beyond the recorded
+// count is still reported. Returns the filtered results and the number of
+// suppressed issues.
+func applyLintBaseline(results []lintResult, baseline map[string]int, dir string) ([]lintResult, int) {
+	remaining := make(map[string]int, len(baseline))
+	for k, v := range baseline {
+		remaining[k] = v
+	}
+
+	suppressed := 0
+	filter := func(file, severity string, msgs []string) []string {
+		var kept []string
+		for _, msg := range msgs {
+			key := baselineKey(baselineRelPath(dir, file), severity, normalizeLintMessage(file, msg))
+			if remaining[key] > 0 {
+				remaining[key]--
+				suppressed++
+				continue
+			}
+			kept = append(kept, msg)
+		}
+		return kept
+	}
+
+	var filtered []lintResult
+	for _, r := range results {
+		r.errors = filter(r.file, "error", r.errors)
+		r.warnings = filter(r.file, "warning", r.warnings)
+		if len(r.errors) > 0 || len(r.warnings) > 0 {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, suppressed
+}
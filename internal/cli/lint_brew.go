@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// brewFormulaCacheFile caches --verify-formulae lookups, in CacheDir
+	brewFormulaCacheFile = "brew-formulae.json"
+
+	// brewFormulaCacheTTL is how long a lookup is trusted before asking again
+	brewFormulaCacheTTL = 24 * time.Hour
+
+	// brewFormulaTimeout bounds each API lookup, retries included
+	brewFormulaTimeout = 15 * time.Second
+)
+
+// brewFormulaAPIBase serves a JSON document per formula and cask. A
+// variable so tests can use a local server.
+var brewFormulaAPIBase = "https://formulae.brew.sh/api"
+
+// brewFormulaLookup is a cached answer to "does Homebrew know this name"
+type brewFormulaLookup struct {
+	Exists    bool      `json:"exists"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// brewFormulaCache maps `brew "name"` / `cask "name"` to its last lookup
+type brewFormulaCache map[string]brewFormulaLookup
+
+// brewFormulaCachePath returns ~/.cache/blackdot/brew-formulae.json
+func brewFormulaCachePath() string {
+	return filepath.Join(CacheDir(), brewFormulaCacheFile)
+}
+
+// verifyBrewfileFormulae reports brew and cask entries that Homebrew doesn't
+// know as errors on the Brewfiles that list them. Each name is looked up
+// once, with `brew info` when brew is installed (which knows aliases and
+// renames) and the formulae.brew.sh API otherwise; answers are cached for
+// brewFormulaCacheTTL. Names from other taps (user/tap/name) are skipped,
+// and lookups that fail (offline, API down) become one warning per file.
+func verifyBrewfileFormulae(files []string) []lintResult {
+	entries := make(map[string][]string) // file -> entries, in file order
+	var pending []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		pkgs, err := parseBrewfilePackages(file)
+		if err != nil {
+			logger.Debug("skipping formula check", "file", file, "error", err)
+			continue
+		}
+		for _, p := range pkgs {
+			if (p.Kind != "brew" && p.Kind != "cask") || strings.Contains(p.Name, "/") {
+				continue
+			}
+			entry := fmt.Sprintf("%s %q", p.Kind, p.Name)
+			entries[file] = append(entries[file], entry)
+			if !seen[entry] {
+				seen[entry] = true
+				pending = append(pending, entry)
+			}
+		}
+	}
+
+	cachePath := brewFormulaCachePath()
+	cache := readBrewFormulaCache(cachePath)
+	exists, failures := lookupBrewEntries(pending, cache, commandExists("brew"))
+	if err := writeBrewFormulaCache(cachePath, cache); err != nil {
+		logger.Debug("could not cache formula lookups", "path", cachePath, "error", err)
+	}
+
+	var results []lintResult
+	for _, file := range files {
+		result := lintResult{file: file}
+		var unverified []string
+		for _, entry := range entries[file] {
+			if err, failed := failures[entry]; failed {
+				unverified = append(unverified, entry)
+				logger.Debug("could not verify", "entry", entry, "error", err)
+			} else if !exists[entry] {
+				result.errors = append(result.errors, fmt.Sprintf("%s is not a Homebrew %s", entry, brewKindNoun(entry)))
+			}
+		}
+		if len(unverified) > 0 {
+			names := strings.Join(unverified, ", ")
+			if len(unverified) > 3 {
+				names = fmt.Sprintf("%s, and %d more", strings.Join(unverified[:3], ", "), len(unverified)-3)
+			}
+			result.warnings = append(result.warnings, fmt.Sprintf("could not verify %d entries (%s): %v",
+				len(unverified), names, failures[unverified[0]]))
+		}
+		if len(result.errors) > 0 || len(result.warnings) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// lookupBrewEntries answers each entry from cache or Homebrew, lintJobs at
+// a time, recording new answers in cache. Entries that couldn't be looked
+// up are in failures instead of exists.
+func lookupBrewEntries(entries []string, cache brewFormulaCache, useBrew bool) (exists map[string]bool, failures map[string]error) {
+	exists = make(map[string]bool)
+	failures = make(map[string]error)
+	jobs := lintJobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	client := &http.Client{}
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	// Answer cache hits before any worker starts writing to both maps
+	var misses []string
+	for _, entry := range entries {
+		if hit, ok := cache[entry]; ok && time.Since(hit.CheckedAt) < brewFormulaCacheTTL {
+			exists[entry] = hit.Exists
+		} else {
+			misses = append(misses, entry)
+		}
+	}
+
+	for _, entry := range misses {
+		kind, name := brewEntryParts(entry)
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var found bool
+			var err error
+			if useBrew {
+				found, err = brewInfoExists(kind, name)
+			} else {
+				found, err = brewAPIExists(client, kind, name)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[entry] = err
+				return
+			}
+			exists[entry] = found
+			cache[entry] = brewFormulaLookup{Exists: found, CheckedAt: time.Now()}
+		}()
+	}
+	wg.Wait()
+	return exists, failures
+}
+
+// brewInfoExists asks the local brew. A missing name is a normal answer;
+// any other failure is an error.
+func brewInfoExists(kind, name string) (bool, error) {
+	flag := "--formula"
+	if kind == "cask" {
+		flag = "--cask"
+	}
+	output, err := runLintCommand(name, "", "brew", "info", "--json=v2", flag, name)
+	if err == nil {
+		return true, nil
+	}
+	msg := strings.ToLower(string(output))
+	if strings.Contains(msg, "no available formula") || strings.Contains(msg, "no cask with this name") || strings.Contains(msg, "is unavailable") {
+		return false, nil
+	}
+	return false, fmt.Errorf("brew info %s: %w", name, err)
+}
+
+// brewAPIExists asks formulae.brew.sh, which only covers homebrew/core and
+// homebrew/cask
+func brewAPIExists(client *http.Client, kind, name string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), brewFormulaTimeout)
+	defer cancel()
+
+	path := "formula"
+	if kind == "cask" {
+		path = "cask"
+	}
+	resp, err := httpDoWithRetry(ctx, client, http.MethodGet, fmt.Sprintf("%s/%s/%s.json", brewFormulaAPIBase, path, url.PathEscape(name)))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, fmt.Errorf("%s", resp.Status)
+}
+
+// brewEntryParts splits `brew "name"` into its kind and name
+func brewEntryParts(entry string) (kind, name string) {
+	kind, quoted, _ := strings.Cut(entry, " ")
+	return kind, strings.Trim(quoted, `"`)
+}
+
+// brewKindNoun names what a Brewfile entry should be
+func brewKindNoun(entry string) string {
+	if kind, _ := brewEntryParts(entry); kind == "cask" {
+		return "cask"
+	}
+	return "formula"
+}
+
+// readBrewFormulaCache loads cached lookups; a missing or corrupt cache is empty
+func readBrewFormulaCache(path string) brewFormulaCache {
+	cache := make(brewFormulaCache)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		logger.Debug("ignoring formula cache", "path", path, "error", err)
+		return make(brewFormulaCache)
+	}
+	return cache
+}
+
+func writeBrewFormulaCache(path string, cache brewFormulaCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// lintConfigFile holds per-repository defaults, read from BLACKDOT_DIR
+const lintConfigFile = ".blackdot.yml"
+
+// blackdotFileConfig is the layout of .blackdot.yml. Only the lint section
+// exists today; unknown keys anywhere are rejected.
+type blackdotFileConfig struct {
+	Lint lintFileConfig `yaml:"lint"`
+}
+
+// lintFileConfig sets defaults for lint flags. Pointers distinguish "not in
+// the file" from a zero value.
+type lintFileConfig struct {
+	Verbose            *bool    `yaml:"verbose"`
+	Fix                *bool    `yaml:"fix"`
+	Quiet              *bool    `yaml:"quiet"`
+	Hygiene            *bool    `yaml:"hygiene"`
+	Docs               *bool    `yaml:"docs"`
+	VerifyFormulae     *bool    `yaml:"verify-formulae"`
+	WithBuild          *bool    `yaml:"with-build"`
+	Strict             *bool    `yaml:"strict"`
+	WarnMissingTools   *bool    `yaml:"warn-missing-tools"`
+	RequireTools       *bool    `yaml:"require-tools"`
+	MaxWarnings        *int     `yaml:"max-warnings"`
+	Todos              *bool    `yaml:"todos"`
+	MaxTodos           *int     `yaml:"max-todos"`
+	Skip               []string `yaml:"skip"`
+	Only               []string `yaml:"only"`
+	Jobs               *int     `yaml:"jobs"`
+	Timeout            *string  `yaml:"timeout"`
+	MaxFileSize        *string  `yaml:"max-file-size"`
+	GroupBy            *string  `yaml:"group-by"`
+	Indent             *string  `yaml:"indent"`
+	Plugins            *bool    `yaml:"plugins"`
+	PluginDir          *string  `yaml:"plugin-dir"`
+	ShellcheckSeverity *string  `yaml:"shellcheck-severity"`
+	ShellcheckExclude  []string `yaml:"shellcheck-exclude"`
+
+	// Presets are named bundles of the settings above, picked with
+	// --preset. They replace built-in presets of the same name.
+	Presets map[string]lintFileConfig `yaml:"presets"`
+}
+
+// lintBuiltinPresets are available to --preset without a config file
+var lintBuiltinPresets = map[string]lintFileConfig{
+	// Everything except network lookups, and any warning fails
+	"ci": {
+		Hygiene:     lintBool(true),
+		Docs:        lintBool(true),
+		WithBuild:   lintBool(true),
+		Strict:      lintBool(true),
+		MaxWarnings: lintInt(0),
+	},
+	// The essentials for an edit-lint loop
+	"fast": {
+		Hygiene:        lintBool(false),
+		Docs:           lintBool(false),
+		VerifyFormulae: lintBool(false),
+		WithBuild:      lintBool(false),
+	},
+	// Every optional check, without failing on warnings
+	"full": {
+		Hygiene:        lintBool(true),
+		Docs:           lintBool(true),
+		VerifyFormulae: lintBool(true),
+		WithBuild:      lintBool(true),
+		Todos:          lintBool(true),
+	},
+}
+
+func lintBool(v bool) *bool { return &v }
+func lintInt(v int) *int    { return &v }
+
+// loadLintConfig reads a .blackdot.yml. A missing file is only an error
+// when required (an explicit --config).
+func loadLintConfig(path string, required bool) (*lintFileConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg blackdotFileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Lint.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg.Lint, nil
+}
+
+// validate checks values the YAML types can't
+func (c *lintFileConfig) validate() error {
+	for _, name := range c.Skip {
+		if !slices.Contains(lintSkippableChecks, name) {
+			return fmt.Errorf("lint.skip: unknown check %q (valid: %s)", name, strings.Join(lintSkippableChecks, ", "))
+		}
+	}
+	for _, name := range c.Only {
+		if !slices.Contains(lintSkippableChecks, name) {
+			return fmt.Errorf("lint.only: unknown check %q (valid: %s)", name, strings.Join(lintSkippableChecks, ", "))
+		}
+	}
+	if c.Jobs != nil && *c.Jobs < 0 {
+		return fmt.Errorf("lint.jobs must not be negative")
+	}
+	if c.MaxWarnings != nil && *c.MaxWarnings < -1 {
+		return fmt.Errorf("lint.max-warnings must be -1 (no limit) or more")
+	}
+	if c.MaxTodos != nil && *c.MaxTodos < -1 {
+		return fmt.Errorf("lint.max-todos must be -1 (no limit) or more")
+	}
+	if c.Timeout != nil {
+		d, err := time.ParseDuration(*c.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("lint.timeout: invalid duration %q", *c.Timeout)
+		}
+	}
+	if c.MaxFileSize != nil {
+		if _, err := parseLintFileSize(*c.MaxFileSize); err != nil {
+			return fmt.Errorf("lint.max-file-size: %w", err)
+		}
+	}
+	if c.GroupBy != nil && !slices.Contains(lintGroupings, *c.GroupBy) {
+		return fmt.Errorf("lint.group-by: unknown grouping %q (valid: %s)", *c.GroupBy, strings.Join(lintGroupings, ", "))
+	}
+	if c.Indent != nil && *c.Indent != "" && !slices.Contains(lintIndentStyles, *c.Indent) {
+		return fmt.Errorf("lint.indent: unknown style %q (valid: %s)", *c.Indent, strings.Join(lintIndentStyles, ", "))
+	}
+	if c.ShellcheckSeverity != nil && !slices.Contains(lintShellcheckSeverities, *c.ShellcheckSeverity) {
+		return fmt.Errorf("lint.shellcheck-severity: unknown level %q (valid: %s)", *c.ShellcheckSeverity, strings.Join(lintShellcheckSeverities, ", "))
+	}
+	for _, code := range c.ShellcheckExclude {
+		if _, err := normalizeShellcheckCode(code); err != nil {
+			return fmt.Errorf("lint.shellcheck-exclude: %w", err)
+		}
+	}
+	for name, preset := range c.Presets {
+		if preset.Presets != nil {
+			return fmt.Errorf("lint.presets.%s: presets can't be nested", name)
+		}
+		if err := preset.validate(); err != nil {
+			return fmt.Errorf("lint.presets.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// lintPreset finds a preset in cfg (which may be nil) or the built-ins
+func lintPreset(cfg *lintFileConfig, name string) (lintFileConfig, error) {
+	if cfg != nil {
+		if preset, ok := cfg.Presets[name]; ok {
+			return preset, nil
+		}
+	}
+	if preset, ok := lintBuiltinPresets[name]; ok {
+		return preset, nil
+	}
+
+	var names []string
+	for n := range lintBuiltinPresets {
+		names = append(names, n)
+	}
+	if cfg != nil {
+		for n := range cfg.Presets {
+			if !slices.Contains(names, n) {
+				names = append(names, n)
+			}
+		}
+	}
+	slices.Sort(names)
+	return lintFileConfig{}, fmt.Errorf("unknown lint preset %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// flagValues maps the file's settings to lint flag names and values
+func (c *lintFileConfig) flagValues() map[string]string {
+	values := make(map[string]string)
+	bools := map[string]*bool{
+		"verbose":            c.Verbose,
+		"fix":                c.Fix,
+		"quiet":              c.Quiet,
+		"hygiene":            c.Hygiene,
+		"docs":               c.Docs,
+		"verify-formulae":    c.VerifyFormulae,
+		"with-build":         c.WithBuild,
+		"strict":             c.Strict,
+		"warn-missing-tools": c.WarnMissingTools,
+		"require-tools":      c.RequireTools,
+		"todos":              c.Todos,
+		"plugins":            c.Plugins,
+	}
+	for name, v := range bools {
+		if v != nil {
+			values[name] = strconv.FormatBool(*v)
+		}
+	}
+	if c.Skip != nil {
+		values["skip"] = strings.Join(c.Skip, ",")
+	}
+	if c.Only != nil {
+		values["only"] = strings.Join(c.Only, ",")
+	}
+	if c.Jobs != nil {
+		values["jobs"] = strconv.Itoa(*c.Jobs)
+	}
+	if c.MaxWarnings != nil {
+		values["max-warnings"] = strconv.Itoa(*c.MaxWarnings)
+	}
+	if c.MaxTodos != nil {
+		values["max-todos"] = strconv.Itoa(*c.MaxTodos)
+	}
+	if c.Timeout != nil {
+		values["timeout"] = *c.Timeout
+	}
+	if c.MaxFileSize != nil {
+		values["max-file-size"] = *c.MaxFileSize
+	}
+	if c.GroupBy != nil {
+		values["group-by"] = *c.GroupBy
+	}
+	if c.Indent != nil {
+		values["indent"] = *c.Indent
+	}
+	if c.PluginDir != nil {
+		values["plugin-dir"] = *c.PluginDir
+	}
+	if c.ShellcheckSeverity != nil {
+		values["shellcheck-severity"] = *c.ShellcheckSeverity
+	}
+	if c.ShellcheckExclude != nil {
+		values["shellcheck-exclude"] = strings.Join(c.ShellcheckExclude, ",")
+	}
+	return values
+}
+
+// applyLintConfig loads --config, or .blackdot.yml in blackdotDir, and uses
+// it and the --preset it selects for every lint flag not given on the
+// command line. Precedence is: flag, then preset, then file, then built-in
+// default.
+func applyLintConfig(cmd *cobra.Command, blackdotDir string) error {
+	path, _ := cmd.Flags().GetString("config")
+	required := path != ""
+	if !required {
+		path = filepath.Join(blackdotDir, lintConfigFile)
+	}
+	presetName, _ := cmd.Flags().GetString("preset")
+
+	cfg, err := loadLintConfig(path, required)
+	if err != nil {
+		return withErrorClass(ErrConfigInvalid, err)
+	}
+	values := make(map[string]string)
+	if cfg != nil {
+		logger.Debug("loaded lint config", "path", path)
+		values = cfg.flagValues()
+	}
+	if presetName != "" {
+		preset, err := lintPreset(cfg, presetName)
+		if err != nil {
+			return withErrorClass(ErrUsage, err)
+		}
+		logger.Debug("applying lint preset", "preset", presetName)
+		maps.Copy(values, preset.flagValues())
+	}
+
+	for name, value := range values {
+		if cmd.Flags().Changed(name) {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return classErrorf(ErrConfigInvalid, "%s: lint.%s: %w", path, name, err)
+		}
+	}
+	return nil
+}
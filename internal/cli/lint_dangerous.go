@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// lintDangerousFile adds or drops dangerous-command patterns, in the format
+// read by loadLintPatternRules
+const lintDangerousFile = ".blackdot-lint-dangerous"
+
+// lintAllowDangerousMarker on a line suppresses the dangerous-command check
+// for that line
+const lintAllowDangerousMarker = "# blackdot: allow-dangerous"
+
+// lintConfirmWindow is how many lines before a sudo call are searched for a
+// confirmation prompt
+const lintConfirmWindow = 10
+
+// lintConfirmPattern matches lines that ask the user before going ahead
+var lintConfirmPattern = regexp.MustCompile(`\b(?:read|select|confirm\w*|prompt\w*|ask\w*|gum\s+confirm)\b`)
+
+// Pieces of the rm -rf pattern: any options, and the recursive and force
+// flags given separately
+const (
+	lintRmOpts      = `(?:-{1,2}[\w-]+\s+)*`
+	lintRmRecursive = `(?:-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)`
+	lintRmForce     = `(?:-[a-zA-Z]*f[a-zA-Z]*|--force)`
+)
+
+// lintBuiltinDangerousRules are the default dangerous-command checks. They
+// run on shellCommandLines output, so comments and quoted text never match;
+// expansions inside double quotes, like "$(curl ...)", still do.
+var lintBuiltinDangerousRules = []lintPatternRule{
+	// rm -rf "$DIR" deletes the wrong tree (or /) when DIR is empty or wrong
+	{name: "rm-rf-variable", pattern: regexp.MustCompile(`\brm\s+` + lintRmOpts + `(?:-(?:[a-zA-Z]*[rR][a-zA-Z]*f|[a-zA-Z]*f[a-zA-Z]*[rR])[a-zA-Z]*\s+|` +
+		lintRmRecursive + `\s+` + lintRmOpts + lintRmForce + `\s+|` + lintRmForce + `\s+` + lintRmOpts + lintRmRecursive + `\s+)` + lintRmOpts + `"?\$`)},
+	// Running a script straight off the network
+	{name: "curl-pipe-shell", pattern: regexp.MustCompile(`\b(?:curl|wget)\b[^|]*\|\s*(?:sudo\s+(?:-\S+\s+)*)?(?:ba|z|da|k)?sh\b`)},
+	{name: "curl-pipe-shell", pattern: regexp.MustCompile(`\b(?:ba|z|da|k)?sh\s+(?:-c\s+)?"?(?:\$\(|<\()\s*(?:curl|wget)\b`)},
+	// Escalating without asking first
+	{name: "sudo-unconfirmed", pattern: regexp.MustCompile(`(?:^|[;&|({]|\bthen|\bdo|\belse)\s*sudo\s`), unlessConfirmed: true},
+	// eval of a variable, argument, or downloaded text runs whatever it holds
+	{name: "eval-untrusted", pattern: regexp.MustCompile(`\beval\s+"?\$(?:\{?[A-Za-z_@*0-9]|\(\s*(?:curl|wget)\b)`)},
+}
+
+// checkDangerous warns about shell constructs that can destroy data or run
+// untrusted code. Lines carrying lintAllowDangerousMarker are skipped.
+func checkDangerous(file string, rules []lintPatternRule) lintResult {
+	result := lintResult{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping dangerous command check", "file", file, "error", err)
+		return result
+	}
+	raw := strings.Split(string(data), "\n")
+	code := shellCommandLines(string(data))
+
+	for i, line := range code {
+		if strings.Contains(raw[i], lintAllowDangerousMarker) {
+			continue
+		}
+		for _, rule := range rules {
+			if !rule.pattern.MatchString(line) {
+				continue
+			}
+			if rule.unlessConfirmed && confirmedBefore(code, i) {
+				continue
+			}
+			result.warnings = append(result.warnings,
+				fmt.Sprintf("line %d: dangerous command (%s): %s", i+1, rule.name, lintSnippet(raw[i])))
+			break
+		}
+	}
+	return result
+}
+
+// confirmedBefore reports whether one of the lintConfirmWindow lines before
+// line n (or line n itself) prompts the user
+func confirmedBefore(lines []string, n int) bool {
+	for i := max(0, n-lintConfirmWindow); i <= n; i++ {
+		if lintConfirmPattern.MatchString(lines[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lintExternalLinkTimeout bounds each --check-external request, retries
+// included
+const lintExternalLinkTimeout = 15 * time.Second
+
+// lintDocsSkipDirs are directories never searched for markdown files
+var lintDocsSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+var (
+	// markdownInlineLink matches the target of [text](target) and
+	// ![alt](target), allowing one level of parentheses in the target
+	markdownInlineLink = regexp.MustCompile(`\]\(\s*(<[^>]+>|[^\s()<>]+(?:\([^\s()]*\)[^\s()]*)*)`)
+	// markdownRefDefinition matches a reference definition: [id]: target
+	markdownRefDefinition = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*(<[^>]+>|\S+)`)
+	// markdownHTMLLink matches src= and href= on the HTML tags docs embed
+	markdownHTMLLink = regexp.MustCompile(`<(?:a|img|source)\b[^>]*?\s(?:src|href)\s*=\s*["']([^"']+)["']`)
+	// markdownCodeSpan matches inline code, whose contents aren't links
+	markdownCodeSpan = regexp.MustCompile("`+[^`]*`+")
+	// markdownURLScheme matches a target with a scheme (https:, mailto:, ...)
+	markdownURLScheme = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+)
+
+// markdownLink is a link target found in a markdown file
+type markdownLink struct {
+	target string
+	line   int
+}
+
+// markdownFiles lists the *.md files under root, skipping lintDocsSkipDirs
+func markdownFiles(root string) []string {
+	var files []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			logger.Debug("skipping unreadable path", "path", path, "error", err)
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && lintDocsSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// markdownLinks returns the link and image targets in content, skipping
+// fenced code blocks and inline code
+func markdownLinks(content string) []markdownLink {
+	var links []markdownLink
+	fence := ""
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		line = markdownCodeSpan.ReplaceAllString(line, "")
+		var targets []string
+		if m := markdownRefDefinition.FindStringSubmatch(line); m != nil {
+			targets = append(targets, m[1])
+		}
+		for _, m := range markdownInlineLink.FindAllStringSubmatch(line, -1) {
+			targets = append(targets, m[1])
+		}
+		for _, m := range markdownHTMLLink.FindAllStringSubmatch(line, -1) {
+			targets = append(targets, m[1])
+		}
+		for _, target := range targets {
+			target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			links = append(links, markdownLink{target: target, line: i + 1})
+		}
+	}
+	return links
+}
+
+// markdownLinkPath returns the file a relative link points to, or "" for
+// links that aren't checked: anchors, URLs, root-relative paths (whose root
+// depends on how the docs are served), and templated targets
+func markdownLinkPath(file, target string) string {
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if target == "" || markdownURLScheme.MatchString(target) || strings.HasPrefix(target, "/") ||
+		strings.Contains(target, "{{") || strings.Contains(target, "$") {
+		return ""
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	return filepath.Join(filepath.Dir(file), filepath.FromSlash(target))
+}
+
+// checkMarkdownLinks reports relative links and images in a markdown file
+// that point to files that don't exist, and returns its http(s) links for
+// --check-external. A link without an extension may also name a .md file,
+// as docs sites drop the extension.
+func checkMarkdownLinks(file string) (lintResult, []markdownLink) {
+	result := lintResult{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping markdown link check", "file", file, "error", err)
+		return result, nil
+	}
+
+	var external []markdownLink
+	for _, link := range markdownLinks(string(data)) {
+		if strings.HasPrefix(link.target, "http://") || strings.HasPrefix(link.target, "https://") {
+			external = append(external, link)
+			continue
+		}
+		path := markdownLinkPath(file, link.target)
+		if path == "" || lintFileExists(path) {
+			continue
+		}
+		if filepath.Ext(path) == "" && lintFileExists(path+".md") {
+			continue
+		}
+		result.errors = append(result.errors, fmt.Sprintf("line %d: broken link: %s (no such file)", link.line, link.target))
+	}
+	return result, external
+}
+
+// checkExternalLinks requests each distinct URL once, lintJobs at a time,
+// and returns the problem with each one that failed. HEAD is tried first;
+// servers that don't allow it get a GET.
+func checkExternalLinks(urls []string) map[string]string {
+	jobs := lintJobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	client := &http.Client{}
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	problems := make(map[string]string)
+
+	for _, u := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if problem := probeExternalLink(client, u); problem != "" {
+				mu.Lock()
+				problems[u] = problem
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return problems
+}
+
+// probeExternalLink returns why u is unreachable, or "" if it answers
+func probeExternalLink(client *http.Client, u string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), lintExternalLinkTimeout)
+	defer cancel()
+
+	resp, err := httpDoWithRetry(ctx, client, http.MethodHead, u)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = httpDoWithRetry(ctx, client, http.MethodGet, u)
+	}
+	if err != nil {
+		logger.Debug("external link failed", "url", u, "error", err)
+		return err.Error()
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 400 {
+		return resp.Status
+	}
+	return ""
+}
+
+// externalLinkResults turns checkExternalLinks problems into warnings on
+// the files that link to them, in file order
+func externalLinkResults(links map[string][]markdownLink, problems map[string]string) []lintResult {
+	var files []string
+	for file := range links {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var results []lintResult
+	for _, file := range files {
+		result := lintResult{file: file}
+		for _, link := range links[file] {
+			if problem, ok := problems[link.target]; ok {
+				result.warnings = append(result.warnings, fmt.Sprintf("line %d: external link failed: %s (%s)", link.line, link.target, problem))
+			}
+		}
+		if len(result.warnings) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results
+}
@@ -0,0 +1,369 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// lintEnvAllowlistFile lists project-specific variables that are provided
+// from outside the file that uses them. One name or glob per line.
+const lintEnvAllowlistFile = ".blackdot-lint-envvars"
+
+// lintKnownEnvVars are set by the OS, the shell, or common tooling. Entries
+// may be glob patterns.
+var lintKnownEnvVars = []string{
+	// POSIX / login environment
+	"HOME", "PATH", "USER", "LOGNAME", "SHELL", "PWD", "OLDPWD", "TERM", "LANG", "LC_*",
+	"TMPDIR", "EDITOR", "VISUAL", "PAGER", "MANPATH", "INFOPATH", "TZ", "MAIL", "DISPLAY",
+	"COLUMNS", "LINES", "HOSTNAME", "HOST", "UID", "EUID", "GID", "PPID", "IFS", "PS1", "PS2",
+	"PS3", "PS4", "OPTARG", "OPTIND", "REPLY", "RANDOM", "LINENO", "SECONDS", "COLORTERM",
+	"CI", "SUDO_USER", "XDG_*", "SSH_*", "GPG_TTY", "TERM_PROGRAM", "TERM_PROGRAM_VERSION",
+	"TMUX", "TMUX_*", "ZELLIJ", "ZELLIJ_*", "WSL_DISTRO_NAME", "WSLENV", "GITHUB_*", "RUNNER_*",
+	// bash
+	"BASH", "BASH_*", "BASHPID", "FUNCNAME", "PIPESTATUS", "OSTYPE", "MACHTYPE", "HOSTTYPE",
+	"SHLVL", "HISTFILE", "HISTSIZE", "HISTFILESIZE", "HISTCONTROL", "PROMPT_COMMAND", "COMP_*",
+	"COMPREPLY", "EPOCHSECONDS", "EPOCHREALTIME",
+	// zsh
+	"ZSH_*", "ZDOTDIR", "ZLE_*", "SAVEHIST", "PROMPT", "RPROMPT", "RPS1", "CPUTYPE", "VENDOR",
+	"path", "fpath", "cdpath", "manpath", "argv", "status", "pipestatus", "options", "commands",
+	"functions", "aliases", "parameters", "precmd_functions", "preexec_functions",
+	"chpwd_functions", "terminfo", "fg", "bg", "fg_bold", "bg_bold", "reset_color", "match",
+	"mbegin", "mend", "MATCH", "MBEGIN", "MEND", "BUFFER", "LBUFFER", "RBUFFER", "CURSOR",
+	"WIDGET", "KEYMAP", "words", "CURRENT", "state", "line", "opt_args", "reply", "PREFIX",
+	"SUFFIX", "compstate", "funcstack", "funcfiletrace", "zsh_eval_context", "modules",
+	// tooling
+	"HOMEBREW_*", "GOPATH", "GOROOT", "GOBIN", "CARGO_HOME", "RUSTUP_HOME", "VIRTUAL_ENV",
+	"PYENV_ROOT", "NVM_DIR", "AWS_*", "DOCKER_*", "KUBECONFIG", "P9K_*", "POWERLEVEL9K_*",
+}
+
+var (
+	// $NAME and ${...}
+	shellVarRefPattern    = regexp.MustCompile(`\$([A-Za-z_]\w*)`)
+	shellBracedRefPattern = regexp.MustCompile(`\$\{([^{}]*)\}`)
+	// NAME= / NAME+= / NAME[i]= at the start of a command word
+	shellAssignPattern = regexp.MustCompile(`(?:^|[\s;&|(!{])([A-Za-z_]\w*)(?:\[[^\]]*\])?\+?=`)
+	// export/local/... NAME [NAME ...], read/for/select NAME
+	shellDeclarePattern = regexp.MustCompile(`\b(?:export|local|declare|typeset|readonly|integer|float|read|mapfile|readarray|vared)\s+([^;&|]*)`)
+	shellForPattern     = regexp.MustCompile(`\b(?:for|select)\s+((?:[A-Za-z_]\w*\s+)+)(?:in\b|$)|\bfor\s+([A-Za-z_]\w*)\s*;`)
+	shellGetoptsPattern = regexp.MustCompile(`\b(?:getopts\s+\S+|printf\s+-v)\s+([A-Za-z_]\w*)`)
+	shellArithPattern   = regexp.MustCompile(`\(\(\s*([A-Za-z_]\w*)\s*(?:[-+*/%]?=[^=]|\+\+|--)`)
+	shellDefaultAssign  = regexp.MustCompile(`\$\{([A-Za-z_]\w*):?=`)
+	shellNamePattern    = regexp.MustCompile(`^[A-Za-z_]\w*`)
+)
+
+// loadLintEnvAllowlist reads the allowlist from the root of dir. A missing
+// file is an empty allowlist.
+func loadLintEnvAllowlist(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, lintEnvAllowlistFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		for _, name := range strings.Fields(line) {
+			if _, err := path.Match(name, ""); err != nil {
+				return nil, fmt.Errorf("%s: invalid pattern %q", lintEnvAllowlistFile, name)
+			}
+			names = append(names, name)
+		}
+	}
+	return names, scanner.Err()
+}
+
+// matchesEnvPattern reports whether name matches any name or glob in patterns
+func matchesEnvPattern(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEnvVarRefs warns about variables a shell file expands without ever
+// setting them. A variable counts as set if it is assigned, exported,
+// declared, or read into anywhere in the file, since functions routinely use
+// globals assigned further down. Expansions with a default or set-check
+// (${VAR:-x}, ${VAR+x}, ...) already handle the unset case and are skipped.
+func checkEnvVarRefs(file string, allowlist []string) lintResult {
+	result := lintResult{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping env var check", "file", file, "error", err)
+		return result
+	}
+	lines := shellCodeLines(string(data))
+
+	defined := make(map[string]bool)
+	for _, line := range lines {
+		for _, name := range shellDefinedNames(line) {
+			defined[name] = true
+		}
+	}
+
+	reported := make(map[string]bool)
+	for i, line := range lines {
+		for _, name := range shellReferencedNames(line) {
+			if defined[name] || reported[name] ||
+				matchesEnvPattern(name, lintKnownEnvVars) || matchesEnvPattern(name, allowlist) {
+				continue
+			}
+			reported[name] = true
+			result.warnings = append(result.warnings,
+				fmt.Sprintf("line %d: $%s is never set in this file and isn't a known environment variable", i+1, name))
+		}
+	}
+	return result
+}
+
+// shellDefinedNames returns the variables a line assigns or declares
+func shellDefinedNames(line string) []string {
+	var names []string
+	for _, m := range shellAssignPattern.FindAllStringSubmatch(line, -1) {
+		names = append(names, m[1])
+	}
+	for _, m := range shellDeclarePattern.FindAllStringSubmatch(line, -1) {
+		for _, word := range strings.Fields(m[1]) {
+			if n := shellNamePattern.FindString(word); n != "" {
+				names = append(names, n)
+			}
+		}
+	}
+	for _, m := range shellForPattern.FindAllStringSubmatch(line, -1) {
+		names = append(names, strings.Fields(m[1]+" "+m[2])...)
+	}
+	for _, re := range []*regexp.Regexp{shellGetoptsPattern, shellArithPattern, shellDefaultAssign} {
+		for _, m := range re.FindAllStringSubmatch(line, -1) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// shellReferencedNames returns the variables a line expands without a
+// default
+func shellReferencedNames(line string) []string {
+	var names []string
+	for _, m := range shellBracedRefPattern.FindAllStringSubmatch(line, -1) {
+		expr := m[1]
+		name := shellNamePattern.FindString(expr)
+		if name == "" {
+			// ${#VAR}, ${!VAR}, ${+VAR}, ${(flags)VAR}, ${1}, ...
+			continue
+		}
+		if rest := strings.TrimPrefix(expr[len(name):], ":"); rest != "" && strings.ContainsRune("-=+?", rune(rest[0])) {
+			continue
+		}
+		names = append(names, name)
+	}
+	// Blank out braced expansions so $NAME doesn't re-match inside them
+	line = shellBracedRefPattern.ReplaceAllString(line, "")
+	for _, m := range shellVarRefPattern.FindAllStringSubmatch(line, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// shellCodeLines returns content split into lines with comments, single-quoted
+// text, escaped characters, and quoted here-document bodies blanked, so only
+// text the shell would expand remains. Line numbers are preserved.
+func shellCodeLines(content string) []string {
+	code, _ := scanShellLines(content)
+	return code
+}
+
+// shellCommandLines is shellCodeLines with the literal text of double-quoted
+// strings blanked too, keeping the quotes and the expansions inside them
+// ($VAR, ${...}, $(...), `...`). Checks that match commands use it, so a
+// command in an echo'd hint doesn't look like one that runs.
+func shellCommandLines(content string) []string {
+	code, _ := scanShell(content, true)
+	return code
+}
+
+// scanShellLines returns shellCodeLines and, for each line, whether its
+// text is data rather than code: a here-document body or delimiter, or a
+// line that starts inside a multi-line quoted string
+func scanShellLines(content string) (code []string, verbatim []bool) {
+	return scanShell(content, false)
+}
+
+// scanShell is scanShellLines, blanking double-quoted literal text when
+// blankDouble is set
+func scanShell(content string, blankDouble bool) (code []string, verbatim []bool) {
+	lines := strings.Split(content, "\n")
+	out := make([]string, len(lines))
+	verbatim = make([]bool, len(lines))
+
+	var inSingle, ansiC, inDouble bool
+	var heredocs []shellHeredoc // pending, in order of appearance
+	var current *shellHeredoc
+
+	for n, line := range lines {
+		if current != nil {
+			verbatim[n] = true
+			body := line
+			if current.stripTabs {
+				body = strings.TrimLeft(body, "\t")
+			}
+			if body == current.delim {
+				current = nil
+				if len(heredocs) > 0 {
+					current, heredocs = &heredocs[0], heredocs[1:]
+				}
+			} else if !current.quoted {
+				out[n] = line
+			}
+			continue
+		}
+
+		verbatim[n] = inSingle || inDouble
+		var b strings.Builder
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case inSingle:
+				if ansiC && c == '\\' {
+					i++
+				} else if c == '\'' {
+					inSingle = false
+				}
+				b.WriteByte(' ')
+				continue
+			case c == '\\':
+				b.WriteString("  ")
+				i++
+				continue
+			case !inDouble && c == '\'':
+				inSingle = true
+				ansiC = i > 0 && line[i-1] == '$'
+				b.WriteByte(' ')
+				continue
+			case c == '"':
+				inDouble = !inDouble
+			case inDouble && blankDouble:
+				if n := shellExpansionLen(line[i:]); n > 0 {
+					b.WriteString(line[i : i+n])
+					i += n - 1
+				} else {
+					b.WriteByte(' ')
+				}
+				continue
+			case !inDouble && c == '#' && (i == 0 || strings.ContainsRune(" \t;(", rune(line[i-1]))):
+				i = len(line)
+				continue
+			case !inDouble && strings.HasPrefix(line[i:], "<<") && !strings.HasPrefix(line[i:], "<<<"):
+				if h, width := parseShellHeredoc(line[i+2:]); width > 0 {
+					heredocs = append(heredocs, h)
+					b.WriteString(strings.Repeat(" ", width+2))
+					i += width + 1
+					continue
+				}
+			}
+			b.WriteByte(c)
+		}
+		out[n] = b.String()
+
+		if len(heredocs) > 0 {
+			current, heredocs = &heredocs[0], heredocs[1:]
+		}
+	}
+	return out, verbatim
+}
+
+// shellExpansionLen returns the length of the expansion s starts with
+// ($NAME, $1, ${...}, $(...), or `...`), or 0 if it doesn't start with one.
+// An expansion left open runs to the end of s.
+func shellExpansionLen(s string) int {
+	var open, close byte
+	switch {
+	case strings.HasPrefix(s, "$("):
+		open, close = '(', ')'
+	case strings.HasPrefix(s, "${"):
+		open, close = '{', '}'
+	case s[0] == '`':
+		if end := strings.IndexByte(s[1:], '`'); end >= 0 {
+			return end + 2
+		}
+		return len(s)
+	case s[0] == '$':
+		if m := shellNamePattern.FindString(s[1:]); m != "" {
+			return len(m) + 1
+		}
+		if len(s) > 1 && strings.IndexByte("0123456789@*#?!$-", s[1]) >= 0 {
+			return 2
+		}
+		return 0
+	default:
+		return 0
+	}
+
+	depth := 0
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case open:
+			depth++
+		case close:
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// shellHeredoc is a here-document whose body starts on the next line
+type shellHeredoc struct {
+	delim     string
+	quoted    bool // <<'EOF', <<"EOF", or <<\EOF: the body isn't expanded
+	stripTabs bool // <<-
+}
+
+// parseShellHeredoc parses the operator text after "<<" and returns the
+// here-document and how many bytes it spans, or 0 if it isn't one
+func parseShellHeredoc(s string) (shellHeredoc, int) {
+	var h shellHeredoc
+	i := 0
+	if strings.HasPrefix(s, "-") {
+		h.stripTabs = true
+		i++
+	}
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	if i < len(s) && (s[i] == '\'' || s[i] == '"') {
+		quote := s[i]
+		end := strings.IndexByte(s[i+1:], quote)
+		if end < 0 {
+			return h, 0
+		}
+		h.delim, h.quoted = s[i+1:i+1+end], true
+		return h, i + end + 2
+	}
+	if i < len(s) && s[i] == '\\' {
+		h.quoted = true
+		i++
+	}
+	start := i
+	for i < len(s) && (s[i] == '_' || s[i] == '-' || s[i] == '.' ||
+		(s[i] >= 'A' && s[i] <= 'Z') || (s[i] >= 'a' && s[i] <= 'z') || (s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	if i == start {
+		return h, 0
+	}
+	h.delim = s[start:i]
+	return h, i
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package cli
+
+import "os/exec"
+
+// configureLintCommand relies on exec.CommandContext's default kill on
+// Windows, which has no process groups to signal
+func configureLintCommand(cmd *exec.Cmd) {}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// shellcheckWikiBase is where every shellcheck code has a page
+const shellcheckWikiBase = "https://www.shellcheck.net/wiki/"
+
+var (
+	// shellcheckCodePattern finds the [SCnnnn] tag at the end of gcc-format output
+	shellcheckCodePattern = regexp.MustCompile(`\[(SC\d{4})\]`)
+	// shellcheckCodeArg is a full code as accepted by --explain
+	shellcheckCodeArg = regexp.MustCompile(`^SC\d{4}$`)
+)
+
+// shellcheckExplanations covers the codes that come up most in dotfiles
+var shellcheckExplanations = map[string]string{
+	"SC1090": "Can't follow a non-constant source. Add '# shellcheck source=path' above the line or ignore it for dynamic paths.",
+	"SC1091": "Sourced file wasn't found or wasn't passed to shellcheck. Run with -x or add a 'source=' directive.",
+	"SC2001": "Use ${var//search/replace} instead of piping echo through sed.",
+	"SC2002": "Useless cat. Pass the file to the command directly or redirect with < file.",
+	"SC2004": "$ is unnecessary on variables inside $((...)) arithmetic.",
+	"SC2012": "Parsing ls output breaks on unusual filenames. Use find or a glob.",
+	"SC2034": "Variable is assigned but never used. Remove it, export it, or use it.",
+	"SC2046": "Quote $(...) to prevent word splitting, or use read/mapfile to split deliberately.",
+	"SC2059": "Don't put variables in the printf format string. Use printf '%s' \"$var\".",
+	"SC2068": "Quote array expansions (\"$@\", \"${arr[@]}\") so elements aren't re-split.",
+	"SC2086": "Double-quote variables to prevent globbing and word splitting.",
+	"SC2115": "Use \"${var:?}\" so 'rm -rf \"$var/\"' can't expand to 'rm -rf /' when var is empty.",
+	"SC2128": "Expanding an array without an index only gives its first element. Use \"${arr[@]}\".",
+	"SC2153": "Possible misspelling: this variable is used but a similar name is assigned.",
+	"SC2154": "Variable is referenced but not assigned. Check the spelling or declare where it comes from.",
+	"SC2155": "Declare and assign separately ('local x; x=$(cmd)') so the command's exit status isn't masked.",
+	"SC2162": "read without -r mangles backslashes. Use 'read -r'.",
+	"SC2164": "Use 'cd ... || exit' (or return) in case cd fails.",
+	"SC2181": "Check the exit code directly with 'if cmd; then' instead of testing $? afterwards.",
+	"SC2206": "Quote to prevent word splitting, or use read -a / mapfile to split into an array.",
+	"SC2207": "Prefer mapfile or read -a over arr=($(cmd)) to split command output into an array.",
+	"SC2230": "'which' is non-standard. Use 'command -v' instead.",
+	"SC2317": "Command appears unreachable. Often a false positive for functions called indirectly (traps, dispatch tables).",
+}
+
+// shellcheckWikiURL returns the wiki page for a shellcheck code
+func shellcheckWikiURL(code string) string {
+	return shellcheckWikiBase + code
+}
+
+// normalizeShellcheckCode accepts "SC2155", "sc2155", or "2155"
+func normalizeShellcheckCode(arg string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(arg))
+	if !strings.HasPrefix(code, "SC") {
+		code = "SC" + code
+	}
+	if !shellcheckCodeArg.MatchString(code) {
+		return "", fmt.Errorf("invalid shellcheck code %q (expected e.g. SC2155)", arg)
+	}
+	return code, nil
+}
+
+// runLintExplain prints the built-in explanation and wiki link for a code
+func runLintExplain(code string) error {
+	code, err := normalizeShellcheckCode(code)
+	if err != nil {
+		return err
+	}
+
+	BoldCyan.Println(code)
+	if text, ok := shellcheckExplanations[code]; ok {
+		fmt.Printf("  %s\n", text)
+	} else {
+		Dim.Println("  No built-in explanation for this code.")
+	}
+	fmt.Printf("  %s\n", shellcheckWikiURL(code))
+	return nil
+}
+
+// withShellcheckURL appends the wiki link to a shellcheck message, if it has a code
+func withShellcheckURL(msg string) string {
+	m := shellcheckCodePattern.FindStringSubmatch(msg)
+	if m == nil {
+		return msg
+	}
+	return msg + " " + Dim.Sprint(shellcheckWikiURL(m[1]))
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// goModuleWideFiles are files whose changes can affect every package, so
+// lint --diff falls back to checking ./...
+var goModuleWideFiles = map[string]bool{
+	"go.mod":  true,
+	"go.sum":  true,
+	"go.work": true,
+}
+
+// lintChangedFiles lists files under dir that differ from ref in the
+// working tree, staged or not, plus untracked files. Paths are relative to
+// dir and slash-separated.
+func lintChangedFiles(dir, ref string) ([]string, error) {
+	changed, err := runLintCommand("git diff", dir, "git", "diff", "--name-only", "--relative", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w", ref, err)
+	}
+	untracked, err := runLintCommand("git ls-files", dir, "git", "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(changed)+"\n"+string(untracked), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// goChangedPackages maps changed files to the import paths of the packages
+// in dir's module that contain them. ok is false when a change can reach
+// beyond those packages (go.mod, vendor/, a deleted package) or the module
+// path is unknown; callers then check ./... instead. Non-Go files count
+// toward the package in their directory, since they may be embedded.
+func goChangedPackages(dir string, files []string) (pkgs []string, ok bool) {
+	module := goModulePath(dir)
+	if module == "" {
+		return nil, false
+	}
+
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if goModuleWideFiles[path.Base(file)] || file == "vendor" || strings.HasPrefix(file, "vendor/") {
+			logger.Debug("module-wide change, checking all packages", "file", file)
+			return nil, false
+		}
+
+		// testdata belongs to the package above it
+		pkgDir := path.Dir(file)
+		parts := strings.Split(pkgDir, "/")
+		if i := slices.Index(parts, "testdata"); i >= 0 {
+			pkgDir = path.Join(parts[:i]...)
+			if pkgDir == "" {
+				pkgDir = "."
+			}
+		}
+		if seen[pkgDir] {
+			continue
+		}
+
+		if !goDirHasSources(filepath.Join(dir, filepath.FromSlash(pkgDir))) {
+			// A removed package breaks its importers; anything else
+			// (scripts, docs, config) doesn't touch Go code
+			if strings.HasSuffix(file, ".go") {
+				logger.Debug("package removed, checking all packages", "dir", pkgDir)
+				return nil, false
+			}
+			continue
+		}
+		if goNestedModule(dir, pkgDir) {
+			continue
+		}
+		seen[pkgDir] = true
+	}
+
+	for pkgDir := range seen {
+		if pkgDir == "." {
+			pkgs = append(pkgs, module)
+		} else {
+			pkgs = append(pkgs, module+"/"+pkgDir)
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs, true
+}
+
+// goModulePath reads the module path from dir/go.mod
+func goModulePath(dir string) string {
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// goModuleRoot reports whether dir holds a go.mod or go.work, which go vet
+// ./... needs to run there
+func goModuleRoot(dir string) bool {
+	return lintFileExists(filepath.Join(dir, "go.mod")) || lintFileExists(filepath.Join(dir, "go.work"))
+}
+
+// goDirHasSources reports whether dir directly contains a .go file
+func goDirHasSources(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	return len(matches) > 0
+}
+
+// goNestedModule reports whether pkgDir (relative to root) belongs to a
+// module of its own below root
+func goNestedModule(root, pkgDir string) bool {
+	for d := pkgDir; d != "." && d != "/"; d = path.Dir(d) {
+		if lintFileExists(filepath.Join(root, filepath.FromSlash(d), "go.mod")) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintGoDiffScope picks the packages for lint --diff: pkgs is empty for
+// ./..., scope describes the choice for the report, and none is true when
+// no Go package changed.
+func lintGoDiffScope(dir, ref string) (pkgs []string, scope string, none bool) {
+	files, err := lintChangedFiles(dir, ref)
+	if err != nil {
+		logger.Debug("no git info, checking all packages", "error", err)
+		return nil, "(all packages: no git info)", false
+	}
+	pkgs, ok := goChangedPackages(dir, files)
+	switch {
+	case !ok:
+		return nil, "(all packages)", false
+	case len(pkgs) == 0:
+		return nil, "", true
+	default:
+		return pkgs, fmt.Sprintf("(%d changed package(s))", len(pkgs)), false
+	}
+}
+
+// goPackageArgs is pkgs, or ./... when there are none
+func goPackageArgs(pkgs []string) []string {
+	if len(pkgs) == 0 {
+		return []string{"./..."}
+	}
+	return pkgs
+}
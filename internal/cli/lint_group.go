@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// lintGroupings are the --group-by values; file is the default
+var lintGroupings = []string{"file", "severity", "code"}
+
+// lintNoCode heads the --group-by code group for issues that aren't
+// shellcheck findings
+const lintNoCode = "other"
+
+// lintIssue is one error or warning, for regrouping results
+type lintIssue struct {
+	file     string
+	severity string // "error" or "warning"
+	msg      string
+}
+
+// lintIssueGroup is a heading and the issues under it
+type lintIssueGroup struct {
+	name   string
+	issues []lintIssue
+}
+
+// printLintIssues writes the "Issues Found" block for results (in path
+// order) grouped by groupBy, each group headed by its issue count
+func printLintIssues(w io.Writer, results []lintResult, groupBy string) {
+	bold := color.New(color.Bold).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Fprintln(w, bold("Issues Found:"))
+	fmt.Fprintln(w)
+	for _, group := range groupLintIssues(results, groupBy) {
+		fmt.Fprintf(w, "%s %s\n", cyan(group.name+":"), dim(fmt.Sprintf("(%d)", len(group.issues))))
+		for _, issue := range group.issues {
+			label := yellow("warning:")
+			if issue.severity == "error" {
+				label = red("error:")
+			}
+			// Outside file groups, name the file unless the message does
+			msg := withShellcheckURL(issue.msg)
+			if groupBy != "file" && !strings.HasPrefix(issue.msg, issue.file+":") {
+				msg = issue.file + ": " + msg
+			}
+			fmt.Fprintf(w, "  %s %s\n", label, msg)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// groupLintIssues splits results into groups: one per file in path order,
+// errors then warnings, or one per shellcheck code with the largest group
+// first and issues without a code last. Within a group issues keep their
+// file order.
+func groupLintIssues(results []lintResult, groupBy string) []lintIssueGroup {
+	var groups []lintIssueGroup
+	index := make(map[string]int)
+	add := func(name string, issue lintIssue) {
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, lintIssueGroup{name: name})
+		}
+		groups[i].issues = append(groups[i].issues, issue)
+	}
+
+	for _, severity := range []string{"error", "warning"} {
+		for _, r := range results {
+			msgs := r.errors
+			if severity == "warning" {
+				msgs = r.warnings
+			}
+			for _, msg := range msgs {
+				issue := lintIssue{file: r.file, severity: severity, msg: msg}
+				switch groupBy {
+				case "severity":
+					add(map[string]string{"error": "Errors", "warning": "Warnings"}[severity], issue)
+				case "code":
+					code := lintNoCode
+					if m := shellcheckCodePattern.FindStringSubmatch(msg); m != nil {
+						code = m[1]
+					}
+					add(code, issue)
+				default:
+					add(r.file, issue)
+				}
+			}
+		}
+	}
+
+	// Errors were added first, so each group lists its errors, then warnings
+	switch groupBy {
+	case "file":
+		slices.SortStableFunc(groups, func(a, b lintIssueGroup) int { return cmp.Compare(a.name, b.name) })
+	case "code":
+		slices.SortStableFunc(groups, func(a, b lintIssueGroup) int {
+			if (a.name == lintNoCode) != (b.name == lintNoCode) {
+				if a.name == lintNoCode {
+					return 1
+				}
+				return -1
+			}
+			if c := cmp.Compare(len(b.issues), len(a.issues)); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.name, b.name)
+		})
+	}
+	return groups
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// lintDefaultMaxFileSize is the --max-file-size default
+	lintDefaultMaxFileSize = "5MB"
+
+	// lintBinarySniffSize is how much of a file is searched for a NUL byte
+	lintBinarySniffSize = 8 << 10
+)
+
+// lintFileGuard keeps files the checks shouldn't read out of them: files
+// over --max-file-size, and binary files (a NUL byte in the first 8 KB),
+// which are usually build artifacts committed by accident. Each skipped
+// file is reported once, as a warning.
+type lintFileGuard struct {
+	maxSize int64             // 0: no limit
+	skipped map[string]string // file -> reason
+	order   []string          // skipped files, in the order found
+}
+
+func newLintFileGuard(maxSize int64) *lintFileGuard {
+	return &lintFileGuard{maxSize: maxSize, skipped: make(map[string]string)}
+}
+
+// files returns files, in order, without the ones to skip
+func (g *lintFileGuard) files(files []string) []string {
+	var kept []string
+	for _, file := range files {
+		if g.skipReason(file) == "" {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+// path returns file, or "" if it is skipped
+func (g *lintFileGuard) path(file string) string {
+	if g.skipReason(file) != "" {
+		return ""
+	}
+	return file
+}
+
+// skipReason says why file is skipped, or "" to check it. Files that can't
+// be read are left to the checks, which report the error.
+func (g *lintFileGuard) skipReason(file string) string {
+	if reason, ok := g.skipped[file]; ok {
+		return reason
+	}
+	info, err := os.Stat(file)
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+
+	var reason string
+	if g.maxSize > 0 && info.Size() > g.maxSize {
+		reason = fmt.Sprintf("%s, over --max-file-size %s", formatSize(info.Size()), formatSize(g.maxSize))
+	} else if lintIsBinary(file) {
+		reason = "binary: NUL byte in the first 8 KB"
+	}
+	if reason != "" {
+		logger.Debug("skipping file", "file", file, "reason", reason)
+		g.skipped[file] = reason
+		g.order = append(g.order, file)
+	}
+	return reason
+}
+
+// results reports each skipped file as a warning
+func (g *lintFileGuard) results() []lintResult {
+	var results []lintResult
+	for _, file := range g.order {
+		results = append(results, lintResult{file: file, warnings: []string{"skipped: " + g.skipped[file]}})
+	}
+	return results
+}
+
+// lintIsBinary reports whether file has a NUL byte in its first 8 KB, as
+// git and grep decide
+func lintIsBinary(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, lintBinarySniffSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
+
+// parseLintFileSize parses a size such as 5MB, 512K, or 1048576 (bytes).
+// Units are powers of 1024; 0 turns the limit off.
+func parseLintFileSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffixes []string
+		size     int64
+	}{
+		{[]string{"GIB", "GB", "G"}, 1 << 30},
+		{[]string{"MIB", "MB", "M"}, 1 << 20},
+		{[]string{"KIB", "KB", "K"}, 1 << 10},
+		{[]string{"B"}, 1},
+	} {
+		if suffix, ok := lintSizeSuffix(str, unit.suffixes); ok {
+			str = strings.TrimSpace(strings.TrimSuffix(str, suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(str, 64)
+	// ParseFloat also accepts inf and nan, and a huge value would overflow
+	// the int64 conversion
+	size := n * float64(multiplier)
+	if err != nil || n < 0 || math.IsNaN(n) || size >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 5MB, 512KB, or a byte count)", s)
+	}
+	return int64(size), nil
+}
+
+// lintSizeSuffix returns the first of suffixes that s ends with
+func lintSizeSuffix(s string, suffixes []string) (string, bool) {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return suffix, true
+		}
+	}
+	return "", false
+}
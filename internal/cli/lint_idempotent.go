@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// lintIdempotentFile adds or drops re-run safety patterns, in the format
+// read by loadLintPatternRules
+const lintIdempotentFile = ".blackdot-lint-idempotent"
+
+// lintAllowNonIdempotentMarker on a line suppresses the re-run safety check for
+// that line
+const lintAllowNonIdempotentMarker = "# blackdot: allow-non-idempotent"
+
+// lintGuardWindow is how many lines before a match are searched for a guard
+const lintGuardWindow = 3
+
+// lintGuardPattern matches lines that make what follows conditional: a grep
+// for what is about to be appended, an if or test, or [ -d ... ] ||
+var lintGuardPattern = regexp.MustCompile(`\b(?:grep|if|elif|test|case)\b|\[\[?\s`)
+
+// lintBuiltinIdempotentRules are the default re-run safety checks for
+// bootstrap scripts. They run on shellCommandLines output, so comments and
+// quoted text never match.
+var lintBuiltinIdempotentRules = []lintPatternRule{
+	// Appending adds the line again on every run
+	{name: "append-unguarded", pattern: regexp.MustCompile(`\b(?:echo|printf|cat)\b[^|;&]*>>\s*\S`)},
+	{name: "append-unguarded", pattern: regexp.MustCompile(`\btee\s+(?:-\S+\s+)*(?:-a|--append)\b`)},
+	// mkdir without -p fails when the directory is already there
+	{name: "mkdir-no-p", pattern: regexp.MustCompile(`\bmkdir(?:\s+-[a-oq-zA-Z]+)*\s+[^-\s]`)},
+}
+
+// lintIdempotentHints suggests a re-run safe alternative per built-in rule
+var lintIdempotentHints = map[string]string{
+	"append-unguarded": "check first with grep -q ... ||, or write the whole file",
+	"mkdir-no-p":       "use mkdir -p",
+}
+
+// checkIdempotent warns about commands in a bootstrap script that change
+// something again, or fail, when the script is re-run. Matches with a guard
+// (grep, if, test) on the same line or the lintGuardWindow lines before are
+// assumed to be safe; lines carrying lintAllowNonIdempotentMarker are skipped.
+func checkIdempotent(file string, rules []lintPatternRule) lintResult {
+	result := lintResult{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping re-run safety check", "file", file, "error", err)
+		return result
+	}
+	raw := strings.Split(string(data), "\n")
+	code := shellCommandLines(string(data))
+
+	for i, line := range code {
+		if strings.Contains(raw[i], lintAllowNonIdempotentMarker) {
+			continue
+		}
+		for _, rule := range rules {
+			if !rule.pattern.MatchString(line) || guardedBefore(code, i) {
+				continue
+			}
+			msg := fmt.Sprintf("line %d: not safe to re-run (%s): %s", i+1, rule.name, lintSnippet(raw[i]))
+			if hint, ok := lintIdempotentHints[rule.name]; ok {
+				msg += "; " + hint
+			}
+			result.warnings = append(result.warnings, msg)
+			break
+		}
+	}
+	return result
+}
+
+// guardedBefore reports whether line n or one of the lintGuardWindow lines
+// before it is a guard
+func guardedBefore(lines []string, n int) bool {
+	for i := max(0, n-lintGuardWindow); i <= n; i++ {
+		if lintGuardPattern.MatchString(lines[i]) {
+			return true
+		}
+	}
+	return false
+}
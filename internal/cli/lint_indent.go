@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// lintIndentStyles are the values --indent accepts. "spaces" means the
+// style guide's 2 spaces.
+var lintIndentStyles = []string{"tabs", "spaces", "2", "4"}
+
+// lintIndentDefaultWidth is the spaces one tab stands for when nothing
+// else says so
+const lintIndentDefaultWidth = 2
+
+// checkIndent reports lines of a shell file whose leading whitespace
+// doesn't match style. Here-document bodies and lines inside multi-line
+// strings are data, so they are never reported or changed. With apply,
+// the file's indentation is rewritten instead, and fixed is the number of
+// lines changed.
+func checkIndent(file, style string, apply bool) (result lintResult, fixed int) {
+	result = lintResult{file: file}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		result.warnings = append(result.warnings, err.Error())
+		return result, 0
+	}
+	content := string(data)
+	lines := strings.Split(content, "\n")
+	_, verbatim := scanShellLines(content)
+
+	useTabs := style == "tabs"
+	width := lintIndentDefaultWidth
+	if n, err := strconv.Atoi(style); err == nil {
+		width = n
+	} else if useTabs {
+		width = spaceIndentWidth(lines, verbatim)
+	}
+
+	for i, line := range lines {
+		lead := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if verbatim[i] || lead == "" || lead == line {
+			continue
+		}
+		hasTab, hasSpace := strings.Contains(lead, "\t"), strings.Contains(lead, " ")
+		var msg string
+		switch {
+		case hasTab && hasSpace:
+			msg = "mixed tabs and spaces in indentation"
+		case useTabs && hasSpace:
+			msg = "indented with spaces, expected tabs"
+		case !useTabs && hasTab:
+			msg = "indented with tabs, expected spaces"
+		default:
+			continue
+		}
+		// Less than one level of spaces can't become a tab; leave it reported
+		if apply {
+			if newLead := reindent(lead, useTabs, width); newLead != lead {
+				lines[i] = newLead + line[len(lead):]
+				fixed++
+				continue
+			}
+		}
+		result.warnings = append(result.warnings, fmt.Sprintf("line %d: %s", i+1, msg))
+	}
+
+	if fixed > 0 {
+		info, err := os.Stat(file)
+		if err == nil {
+			// WriteFile on an existing file keeps its mode
+			err = os.WriteFile(file, []byte(strings.Join(lines, "\n")), info.Mode().Perm())
+		}
+		if err != nil {
+			result.warnings = append(result.warnings, fmt.Sprintf("could not fix indentation: %v", err))
+			return result, 0
+		}
+	}
+	return result, fixed
+}
+
+// reindent rewrites leading whitespace, counting a tab as width columns.
+// Columns left over when converting to tabs stay spaces.
+func reindent(lead string, useTabs bool, width int) string {
+	cols := 0
+	for _, c := range lead {
+		if c == '\t' {
+			cols += width
+		} else {
+			cols++
+		}
+	}
+	if !useTabs {
+		return strings.Repeat(" ", cols)
+	}
+	return strings.Repeat("\t", cols/width) + strings.Repeat(" ", cols%width)
+}
+
+// spaceIndentWidth guesses how many spaces make one level in a file, the
+// way editors do: the smallest indent among lines indented only with spaces
+func spaceIndentWidth(lines []string, verbatim []bool) int {
+	width := 0
+	for i, line := range lines {
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if verbatim[i] || n == 0 || n == len(line) || line[n] == '\t' {
+			continue
+		}
+		if width == 0 || n < width {
+			width = n
+		}
+	}
+	if width < 2 {
+		return lintIndentDefaultWidth
+	}
+	return width
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// lintJSONIndent is the indentation --fix-json writes
+const lintJSONIndent = "  "
+
+// formatLintJSON re-serializes valid JSON with lintJSONIndent and a final
+// newline. Without sortKeys, keys keep their order and every literal its
+// exact text; with it, objects are rewritten with sorted keys. The result
+// is checked to decode to the same value as data.
+func formatLintJSON(data []byte, sortKeys bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if sortKeys {
+		value, err := decodeLintJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", lintJSONIndent)
+		// Maps encode with sorted keys; json.Number keeps numbers verbatim
+		if err := enc.Encode(value); err != nil {
+			return nil, err
+		}
+	} else {
+		// Indent keeps whitespace it doesn't own, so drop it all first
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			return nil, err
+		}
+		if err := json.Indent(&buf, compact.Bytes(), "", lintJSONIndent); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+	}
+
+	before, err := decodeLintJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeLintJSON(buf.Bytes())
+	if err != nil || !reflect.DeepEqual(before, after) {
+		return nil, fmt.Errorf("reformatting would change the content")
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeLintJSON decodes data keeping numbers as written
+func decodeLintJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// fixJSONFormat reformats a JSON file that passed validation in place,
+// keeping its permissions, and reports whether it changed. With preview
+// it writes nothing and returns the diff instead.
+func fixJSONFormat(file string, sortKeys, preview bool) (changed bool, diff string, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, "", err
+	}
+	formatted, err := formatLintJSON(data, sortKeys)
+	if err != nil {
+		return false, "", err
+	}
+	if bytes.Equal(data, formatted) {
+		return false, "", nil
+	}
+
+	if preview {
+		diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitDiffLines(string(data)),
+			B:        splitDiffLines(string(formatted)),
+			FromFile: file,
+			ToFile:   file + " (formatted)",
+			Context:  3,
+		})
+		return true, diff, err
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, "", err
+	}
+	if err := os.WriteFile(file, formatted, info.Mode().Perm()); err != nil {
+		return false, "", err
+	}
+	return true, "", nil
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/blackwell-systems/blackdot/internal/config"
+)
+
+// jsonSchema is the part of a JSON schema that says which keys an object
+// may have. Only the keys are checked; types and values are not.
+type jsonSchema struct {
+	Properties           map[string]*jsonSchema `json:"properties"`
+	PatternProperties    map[string]*jsonSchema `json:"patternProperties"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	never                bool                   // the false schema: nothing is allowed
+}
+
+// UnmarshalJSON accepts the boolean schemas true and false. A tuple-style
+// "items" array is treated as allowing anything.
+func (s *jsonSchema) UnmarshalJSON(data []byte) error {
+	switch trimmed := strings.TrimSpace(string(data)); {
+	case trimmed == "true", strings.HasPrefix(trimmed, "["):
+		*s = jsonSchema{}
+		return nil
+	case trimmed == "false":
+		*s = jsonSchema{never: true}
+		return nil
+	}
+	type plain jsonSchema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// lintJSONSchemas maps the JSON files the lint JSON phase checks to the
+// schema for their keys: config.json to the one built into blackdot, and
+// vault-items.json to vault/vault-items.schema.json in the repository
+func lintJSONSchemas(blackdotDir, configDir string) map[string]func() ([]byte, error) {
+	return map[string]func() ([]byte, error){
+		filepath.Join(configDir, "config.json"): func() ([]byte, error) {
+			return config.Schema, nil
+		},
+		filepath.Join(configDir, "vault-items.json"): func() ([]byte, error) {
+			return os.ReadFile(filepath.Join(blackdotDir, "vault", "vault-items.schema.json"))
+		},
+	}
+}
+
+// checkJSONSchemaKeys warns about keys in file that its schema doesn't
+// know: each one that is a likely typo of a known key, with a suggestion,
+// and any key at all where the schema sets additionalProperties false
+func checkJSONSchemaKeys(file string, schemaData []byte) lintResult {
+	result := lintResult{file: file}
+	var schema jsonSchema
+	if err := json.Unmarshal(schemaData, &schema); err != nil {
+		logger.Debug("skipping key check", "file", file, "error", err)
+		return result
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return result
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return result
+	}
+	result.warnings = jsonSchemaKeyWarnings(doc, &schema, "")
+	return result
+}
+
+// jsonSchemaKeyWarnings walks value alongside schema; path is the dotted
+// path of value, empty at the top
+func jsonSchemaKeyWarnings(value any, schema *jsonSchema, path string) []string {
+	if schema == nil {
+		return nil
+	}
+	var warnings []string
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		known := make([]string, 0, len(schema.Properties))
+		for key := range schema.Properties {
+			known = append(known, key)
+		}
+		slices.Sort(known)
+
+		for _, key := range keys {
+			if sub := schema.keySchema(key); sub != nil {
+				warnings = append(warnings, jsonSchemaKeyWarnings(v[key], sub, jsonKeyPath(path, key))...)
+				continue
+			}
+			if match := closestKey(key, known); match != "" {
+				warnings = append(warnings, fmt.Sprintf("unknown key '%s', did you mean '%s'?", jsonKeyPath(path, key), jsonKeyPath(path, match)))
+			} else if schema.AdditionalProperties != nil && schema.AdditionalProperties.never {
+				warnings = append(warnings, fmt.Sprintf("unknown key '%s'", jsonKeyPath(path, key)))
+			}
+		}
+	case []any:
+		for i, item := range v {
+			warnings = append(warnings, jsonSchemaKeyWarnings(item, schema.Items, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return warnings
+}
+
+// keySchema returns the schema for key, or nil if key isn't declared and
+// additional keys aren't described (unknown, or not allowed)
+func (s *jsonSchema) keySchema(key string) *jsonSchema {
+	if sub, ok := s.Properties[key]; ok {
+		return sub
+	}
+	for pattern, sub := range s.PatternProperties {
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) && !sub.never {
+			return sub
+		}
+	}
+	if s.AdditionalProperties != nil && !s.AdditionalProperties.never {
+		return s.AdditionalProperties
+	}
+	return nil
+}
+
+func jsonKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// closestKey returns the known key nearest to key by edit distance, if it
+// is close enough to be a typo: within a third of key's length, at least 1
+func closestKey(key string, known []string) string {
+	best, bestDist := "", max(1, len([]rune(key))/3)+1
+	for _, k := range known {
+		if d := levenshtein(strings.ToLower(key), strings.ToLower(k)); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// levenshtein is the number of single-rune insertions, deletions, and
+// substitutions that turn a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
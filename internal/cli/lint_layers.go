@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// configLayerFile is one file layer of blackdot config, as resolved by
+// blackdot config get
+type configLayerFile struct {
+	name string // user, machine, or project
+	path string // empty when the layer has no file (no project config)
+}
+
+// configLayerMetaKeys are keys a layer file may set without overriding
+// anything: schema annotations, and the machine section config init machine
+// writes to identify the machine
+var configLayerMetaKeys = map[string][]string{
+	"":        {"$schema", "$comment"},
+	"machine": {"machine"},
+}
+
+// checkConfigLayers checks the file layers, lowest priority first. A layer
+// that exists must be a JSON object; user, the base, is already validated
+// by the JSON phase. Every key a higher layer sets should override a key a
+// lower layer sets, or one the config schema declares: anything else is
+// most likely a typo that silently does nothing.
+//
+// The layers are the fixed set config get resolves, in that order, not a
+// list declared in config.json: every layer file is optional and its place
+// in layers is its priority, so there is no declaration to check for
+// missing files or duplicate priorities.
+func checkConfigLayers(layers []configLayerFile, schemaData []byte) []lintResult {
+	var schema jsonSchema
+	if err := json.Unmarshal(schemaData, &schema); err != nil {
+		logger.Debug("config layers: no schema", "error", err)
+	}
+
+	var results []lintResult
+	var lower []string // leaf keys set by the layers checked so far
+	var lowerNames []string
+	for _, layer := range layers {
+		if layer.path == "" || !lintFileExists(layer.path) {
+			continue
+		}
+		result := lintResult{file: layer.path}
+		doc, err := readConfigLayer(layer.path)
+		if err != nil {
+			if layer.name != "user" {
+				result.errors = append(result.errors, fmt.Sprintf("layer %s: %v", layer.name, err))
+				results = append(results, result)
+			}
+			continue
+		}
+
+		keys := configLayerKeys(doc, "")
+		if len(lowerNames) > 0 {
+			for _, key := range keys {
+				if isConfigLayerMetaKey(layer.name, key) || slices.Contains(lower, key) || schemaDeclares(&schema, key) {
+					continue
+				}
+				msg := fmt.Sprintf("layer %s: '%s' overrides no key in %s or the defaults", layer.name, key, strings.Join(lowerNames, ", "))
+				if match := closestKey(key, lower); match != "" {
+					msg += fmt.Sprintf(", did you mean '%s'?", match)
+				}
+				result.warnings = append(result.warnings, msg)
+			}
+		}
+		if len(result.warnings) > 0 {
+			results = append(results, result)
+		}
+		lower = append(lower, keys...)
+		lowerNames = append(lowerNames, layer.name)
+	}
+	return results
+}
+
+// readConfigLayer parses a layer file, which must hold a JSON object
+func readConfigLayer(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %v", err)
+	}
+	return doc, nil
+}
+
+// configLayerKeys lists the dotted paths of the leaf values in doc, sorted,
+// as config get and config set address them
+func configLayerKeys(doc map[string]any, prefix string) []string {
+	var keys []string
+	for key, value := range doc {
+		path := jsonKeyPath(prefix, key)
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			keys = append(keys, configLayerKeys(nested, path)...)
+			continue
+		}
+		keys = append(keys, path)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func isConfigLayerMetaKey(layer, key string) bool {
+	top, _, _ := strings.Cut(key, ".")
+	return slices.Contains(configLayerMetaKeys[""], top) || slices.Contains(configLayerMetaKeys[layer], top)
+}
+
+// schemaDeclares reports whether every segment of a dotted key is declared
+// by schema, through properties, patterns, or additionalProperties
+func schemaDeclares(schema *jsonSchema, key string) bool {
+	for _, part := range strings.Split(key, ".") {
+		if schema = schema.keySchema(part); schema == nil {
+			return false
+		}
+	}
+	return true
+}
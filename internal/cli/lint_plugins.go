@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// lintPluginPrefix starts the name of every external checker, the way git
+// finds git-<name> subcommands
+const lintPluginPrefix = "blackdot-lint-"
+
+// lintPluginLinePattern matches the gcc format plugins report in:
+// file:line[:col]: severity: message
+var lintPluginLinePattern = regexp.MustCompile(`^(.+?):(\d+):(?:\d+:)?\s*(error|warning|note|info|style):\s*(.*)$`)
+
+// lintPlugin is an external checker found on disk
+type lintPlugin struct {
+	name string // without the prefix, e.g. "terraform"
+	path string
+}
+
+// findLintPlugins lists the blackdot-lint-* executables in dirs, in order.
+// A name found in an earlier directory hides the same name in later ones,
+// as with PATH lookups.
+func findLintPlugins(dirs []string) []lintPlugin {
+	var plugins []lintPlugin
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), lintPluginPrefix) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			// LookPath on a path checks the execute bit (or PATHEXT on Windows)
+			if _, err := exec.LookPath(path); err != nil {
+				continue
+			}
+			name := strings.TrimPrefix(entry.Name(), lintPluginPrefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, lintPlugin{name: name, path: path})
+		}
+	}
+	slices.SortStableFunc(plugins, func(a, b lintPlugin) int { return strings.Compare(a.name, b.name) })
+	return plugins
+}
+
+// lintPluginDirs is where --plugins looks: --plugin-dir (relative to
+// blackdotDir) first, then PATH
+func lintPluginDirs(blackdotDir, pluginDir string) []string {
+	var dirs []string
+	if pluginDir != "" {
+		if !filepath.IsAbs(pluginDir) {
+			pluginDir = filepath.Join(blackdotDir, pluginDir)
+		}
+		dirs = append(dirs, pluginDir)
+	}
+	return append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+}
+
+// runLintPlugin runs plugin on files from blackdotDir and turns its gcc
+// format stdout into results, one per file in the order files are first
+// reported. Exit status 0 or 1 is success; anything else, or a timeout, is
+// an error against the plugin itself.
+func runLintPlugin(plugin lintPlugin, blackdotDir string, files []string) []lintResult {
+	output, err := runLintCommandOutput(plugin.path, blackdotDir, (*exec.Cmd).Output, plugin.path, files...)
+	failed := lintResult{file: plugin.path}
+	if lintTimedOut(&failed, err) {
+		return []lintResult{failed}
+	}
+	if exitErr, ok := err.(*exec.ExitError); err != nil && (!ok || exitErr.ExitCode() != 1) {
+		msg := err.Error()
+		if ok {
+			if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+				first, _, _ := strings.Cut(stderr, "\n")
+				msg += ": " + first
+			}
+		}
+		failed.errors = append(failed.errors, fmt.Sprintf("plugin %s failed: %s", plugin.name, msg))
+		return []lintResult{failed}
+	}
+	return parseLintPluginOutput(plugin.name, blackdotDir, string(output))
+}
+
+// parseLintPluginOutput groups gcc format lines by file. Errors are errors;
+// every other severity is a warning. Lines in any other format are ignored.
+func parseLintPluginOutput(name, blackdotDir, output string) []lintResult {
+	var results []lintResult
+	index := make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		m := lintPluginLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			if strings.TrimSpace(line) != "" {
+				logger.Debug("ignoring lint plugin output", "plugin", name, "line", line)
+			}
+			continue
+		}
+		file := m[1]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(blackdotDir, file)
+		}
+		i, ok := index[file]
+		if !ok {
+			i = len(results)
+			index[file] = i
+			results = append(results, lintResult{file: file})
+		}
+
+		// "line N:" like blackdot's own checks, so baselines ignore moves
+		msg := fmt.Sprintf("line %s: %s [%s]", m[2], m[4], name)
+		if m[3] == "error" {
+			results[i].errors = append(results[i].errors, msg)
+		} else {
+			results[i].warnings = append(results[i].warnings, msg)
+		}
+	}
+	return results
+}
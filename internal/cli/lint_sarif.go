@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIF documents are handled as generic JSON so properties this command
+// doesn't know about (invocations, taxonomies, ...) pass through unchanged.
+type sarifObject = map[string]any
+
+func newLintMergeSARIFCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "merge-sarif <file>...",
+		Short: "Merge SARIF reports into one document",
+		Long: `Merge SARIF 2.1.0 reports, e.g. from matrixed CI jobs, into a single
+document for one code scanning upload.
+
+Runs from the same tool (and automationDetails.id, if set) are combined
+into one run; rules are merged by id and results that are identical by
+(file, rule, line) are kept once. Runs from different tools stay separate.
+
+Examples:
+  blackdot lint merge-sarif shell.sarif go.sarif -o lint.sarif
+  blackdot lint merge-sarif reports/*.sarif > lint.sarif`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLintMergeSARIF(args, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the merged report to this file (default: stdout)")
+
+	return cmd
+}
+
+func runLintMergeSARIF(files []string, output string) error {
+	docs := make([]sarifObject, 0, len(files))
+	for _, file := range files {
+		doc, err := readSARIF(file)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	merged, dropped := mergeSARIF(docs)
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return err
+	}
+	runs := merged["runs"].([]any)
+	Pass("Merged %d reports into %s (%d runs, %d duplicate results dropped)", len(files), output, len(runs), dropped)
+	return nil
+}
+
+// readSARIF parses one report and checks it looks like SARIF 2.1.0
+func readSARIF(path string) (sarifObject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc sarifObject
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON: %w", path, err)
+	}
+	if v, _ := doc["version"].(string); v != sarifVersion {
+		return nil, fmt.Errorf("%s: unsupported SARIF version %q (want %s)", path, v, sarifVersion)
+	}
+	if _, ok := doc["runs"].([]any); !ok {
+		return nil, fmt.Errorf("%s: missing runs array", path)
+	}
+	return doc, nil
+}
+
+// mergeSARIF combines the runs of docs, grouping them by tool, and returns
+// the merged document and how many duplicate results were dropped
+func mergeSARIF(docs []sarifObject) (sarifObject, int) {
+	var runs []any
+	byTool := make(map[string]*sarifRunMerger)
+	dropped := 0
+
+	for _, doc := range docs {
+		for _, r := range doc["runs"].([]any) {
+			run, ok := r.(sarifObject)
+			if !ok {
+				continue
+			}
+			key := sarifRunKey(run)
+			m, ok := byTool[key]
+			if !ok {
+				m = newSARIFRunMerger(run)
+				byTool[key] = m
+				runs = append(runs, m.run)
+			}
+			dropped += m.add(run)
+		}
+	}
+	if runs == nil {
+		runs = []any{}
+	}
+
+	return sarifObject{
+		"$schema": sarifSchema,
+		"version": sarifVersion,
+		"runs":    runs,
+	}, dropped
+}
+
+// sarifRunKey identifies runs that GitHub treats as the same analysis
+func sarifRunKey(run sarifObject) string {
+	driver := sarifPath(run, "tool", "driver")
+	name, _ := driver["name"].(string)
+	category, _ := sarifPath(run, "automationDetails")["id"].(string)
+	return name + "\x00" + category
+}
+
+// sarifRunMerger accumulates rules and results for one tool
+type sarifRunMerger struct {
+	run       sarifObject
+	ruleIndex map[string]int // rule id -> index in the merged rules array
+	seen      map[string]bool
+}
+
+// newSARIFRunMerger starts a merged run from first's metadata, with empty
+// rules and results that add fills in
+func newSARIFRunMerger(first sarifObject) *sarifRunMerger {
+	run := make(sarifObject, len(first))
+	for k, v := range first {
+		run[k] = v
+	}
+	driver := make(sarifObject)
+	for k, v := range sarifPath(first, "tool", "driver") {
+		driver[k] = v
+	}
+	driver["rules"] = []any{}
+	tool := make(sarifObject)
+	for k, v := range sarifPath(first, "tool") {
+		tool[k] = v
+	}
+	tool["driver"] = driver
+	run["tool"] = tool
+	run["results"] = []any{}
+
+	return &sarifRunMerger{run: run, ruleIndex: make(map[string]int), seen: make(map[string]bool)}
+}
+
+// add merges run's rules and results, remapping ruleIndex to the merged
+// rules array. Returns the number of duplicate results skipped.
+func (m *sarifRunMerger) add(run sarifObject) int {
+	driver := sarifPath(m.run, "tool", "driver")
+	rules := driver["rules"].([]any)
+	srcRules, _ := sarifPath(run, "tool", "driver")["rules"].([]any)
+	srcIDs := make([]string, len(srcRules))
+	for i, r := range srcRules {
+		rule, _ := r.(sarifObject)
+		id, _ := rule["id"].(string)
+		srcIDs[i] = id
+		if _, ok := m.ruleIndex[id]; !ok && id != "" {
+			m.ruleIndex[id] = len(rules)
+			rules = append(rules, rule)
+		}
+	}
+	driver["rules"] = rules
+
+	results := m.run["results"].([]any)
+	dropped := 0
+	srcResults, _ := run["results"].([]any)
+	for _, r := range srcResults {
+		result, ok := r.(sarifObject)
+		if !ok {
+			continue
+		}
+		key := sarifResultKey(result, srcIDs)
+		if m.seen[key] {
+			dropped++
+			continue
+		}
+		m.seen[key] = true
+
+		if idx, ok := result["ruleIndex"].(float64); ok {
+			if i := int(idx); i >= 0 && i < len(srcIDs) {
+				if newIdx, ok := m.ruleIndex[srcIDs[i]]; ok {
+					result["ruleIndex"] = newIdx
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	m.run["results"] = results
+	return dropped
+}
+
+// sarifResultKey is the (file, rule, line) identity of a result. The rule
+// comes from ruleId, or from the run's rules when only ruleIndex is set.
+func sarifResultKey(result sarifObject, ruleIDs []string) string {
+	code, _ := result["ruleId"].(string)
+	if code == "" {
+		if idx, ok := result["ruleIndex"].(float64); ok && int(idx) >= 0 && int(idx) < len(ruleIDs) {
+			code = ruleIDs[int(idx)]
+		}
+	}
+	var file string
+	var line float64
+	if locs, _ := result["locations"].([]any); len(locs) > 0 {
+		loc, _ := locs[0].(sarifObject)
+		file, _ = sarifPath(loc, "physicalLocation", "artifactLocation")["uri"].(string)
+		line, _ = sarifPath(loc, "physicalLocation", "region")["startLine"].(float64)
+	}
+	return fmt.Sprintf("%s\x00%s\x00%d", file, code, int(line))
+}
+
+// sarifPath walks nested objects, returning nil if any step is missing
+func sarifPath(obj sarifObject, keys ...string) sarifObject {
+	for _, k := range keys {
+		next, ok := obj[k].(sarifObject)
+		if !ok {
+			return nil
+		}
+		obj = next
+	}
+	return obj
+}
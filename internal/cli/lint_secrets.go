@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// lintSecretsFile adds project-specific secret patterns, in the format read
+// by loadLintPatternRules
+const lintSecretsFile = ".blackdot-lint-secrets"
+
+// lintAllowSecretMarker on a line suppresses the secrets check for that line
+const lintAllowSecretMarker = "# blackdot: allow-secret"
+
+// lintSnippetMax bounds the line excerpt shown for each match
+const lintSnippetMax = 80
+
+// lintPatternRule is one pattern a line-matching check looks for. For the
+// secrets check, a group named "secret" limits redaction to that part.
+type lintPatternRule struct {
+	name    string
+	pattern *regexp.Regexp
+
+	// unlessConfirmed skips matches that follow a confirmation prompt
+	// within lintConfirmWindow lines
+	unlessConfirmed bool
+}
+
+// lintSecretNamePattern matches variable names that usually hold credentials
+const lintSecretNamePattern = `[A-Za-z0-9_]*(?i:TOKEN|SECRET|PASSWORD|PASSWD|API_?KEY|ACCESS_KEY)[A-Za-z0-9_]*`
+
+// lintBuiltinSecretRules are always checked. Assignments must have a literal
+// value of at least 8 characters, so empty values, placeholders, and
+// expansions like "$(pass show ...)" don't match.
+var lintBuiltinSecretRules = []lintPatternRule{
+	{name: "aws-access-key", pattern: regexp.MustCompile(`\b(?P<secret>(?:AKIA|ASIA)[0-9A-Z]{16})\b`)},
+	{name: "bearer-token", pattern: regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]{20,}=*)`)},
+	{name: "private-key", pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----`)},
+	{name: "token-export", pattern: regexp.MustCompile(`\bexport\s+` + lintSecretNamePattern + `=['"]?(?P<secret>[^\s'"$` + "`" + `]{8,})`)},
+	{name: "token-export", pattern: regexp.MustCompile(`\$env:` + lintSecretNamePattern + `\s*=\s*['"](?P<secret>[^'"$` + "`" + `]{8,})['"]`)},
+}
+
+// loadLintPatternRules returns builtin plus the rules in file at the root of
+// dir. Each line is a rule name, whitespace, and a Go regular expression;
+// "!name" drops the built-in rules with that name. Lines starting with '#'
+// are comments. A missing file leaves the built-in rules unchanged.
+func loadLintPatternRules(dir, file string, builtin []lintPatternRule) ([]lintPatternRule, error) {
+	f, err := os.Open(filepath.Join(dir, file))
+	if os.IsNotExist(err) {
+		return builtin, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	disabled := make(map[string]bool)
+	var custom []lintPatternRule
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		// Only whole-line comments: '#' is common inside regexes
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, ok := strings.CutPrefix(line, "!"); ok {
+			disabled[name] = true
+			continue
+		}
+		name, expr, ok := strings.Cut(line, " ")
+		if !ok {
+			name, expr, ok = strings.Cut(line, "\t")
+		}
+		expr = strings.TrimSpace(expr)
+		if !ok || expr == "" {
+			return nil, fmt.Errorf("%s:%d: expected \"<name> <regex>\" or \"!<name>\"", file, lineNum)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, lineNum, err)
+		}
+		custom = append(custom, lintPatternRule{name: name, pattern: re})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var rules []lintPatternRule
+	for _, r := range builtin {
+		if !disabled[r.name] {
+			rules = append(rules, r)
+		}
+	}
+	return append(rules, custom...), nil
+}
+
+// checkSecrets reports lines that look like they contain a credential, with
+// the matched value redacted. Lines carrying lintAllowSecretMarker are skipped.
+func checkSecrets(file string, rules []lintPatternRule) lintResult {
+	result := lintResult{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping secrets check", "file", file, "error", err)
+		return result
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.Contains(line, lintAllowSecretMarker) {
+			continue
+		}
+		line = strings.TrimRight(line, "\r")
+		for _, rule := range rules {
+			loc := rule.pattern.FindStringSubmatchIndex(line)
+			if loc == nil {
+				continue
+			}
+			result.errors = append(result.errors,
+				fmt.Sprintf("line %d: possible secret (%s): %s", i+1, rule.name, redactSecretMatch(line, rule.pattern, loc)))
+			break
+		}
+	}
+	return result
+}
+
+// redactSecretMatch masks the secret in line (the "secret" group, else the
+// whole match), keeping its first 4 characters
+func redactSecretMatch(line string, re *regexp.Regexp, loc []int) string {
+	start, end := loc[0], loc[1]
+	if i := re.SubexpIndex("secret"); i > 0 && loc[2*i] >= 0 {
+		start, end = loc[2*i], loc[2*i+1]
+	}
+	secret := line[start:end]
+	masked := secret
+	if len(secret) > 4 {
+		masked = secret[:4] + strings.Repeat("*", 8)
+	}
+	return lintSnippet(line[:start] + masked + line[end:])
+}
+
+// lintSnippet trims a source line for display, cutting it at lintSnippetMax
+func lintSnippet(line string) string {
+	snippet := strings.TrimSpace(line)
+	if len(snippet) > lintSnippetMax {
+		snippet = snippet[:lintSnippetMax-3] + "..."
+	}
+	return snippet
+}
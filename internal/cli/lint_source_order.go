@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// lintAllowSourceOrderMarker on a line suppresses the source order check for
+// that line
+const lintAllowSourceOrderMarker = "# blackdot: allow-source-order"
+
+var (
+	// zshrcSourcePattern matches a source or . statement and its argument
+	zshrcSourcePattern = regexp.MustCompile(`(?:^|[;&|{]|\bthen|\bdo)\s*(?:source|\.)\s+(\S+)`)
+	// zshrcModulePattern matches a single zsh.d module in a sourced path
+	zshrcModulePattern = regexp.MustCompile(`zsh\.d/([\w.-]+\.zsh)\b`)
+	// zshrcAutoloadPattern matches autoload and the names after its options
+	zshrcAutoloadPattern = regexp.MustCompile(`^\s*autoload\s+((?:-\S+\s+)*)(.*)$`)
+	// zshCommandSeparator splits a line into simple commands
+	zshCommandSeparator = regexp.MustCompile(`;|&&|\|\||\||\$\(|\bthen\b|\bdo\b|\belse\b`)
+	// zshParamExpansion matches ${...}, whose braces don't open a block
+	zshParamExpansion = regexp.MustCompile(`\$\{[^{}]*\}`)
+	// zshAssignmentPrefix matches VAR=value before a command word
+	zshAssignmentPrefix = regexp.MustCompile(`^[A-Za-z_]\w*=\S*\s*`)
+	// zshCommandWord is a name that could be a function call
+	zshCommandWord = regexp.MustCompile(`^[A-Za-z_][\w:.+-]*$`)
+	// zshGuardPattern matches a check that a command exists before use
+	zshGuardPattern = regexp.MustCompile(`\$\+(?:functions|aliases|commands)\[([\w:.+-]+)\]|\b(?:command\s+-v|whence|type|which)\s+(?:-\w+\s+)*([\w:.+-]+)`)
+)
+
+// zshCommandPrefixes are words that run the command after them
+var zshCommandPrefixes = map[string]bool{
+	"if": true, "elif": true, "while": true, "until": true, "!": true,
+	"noglob": true, "nocorrect": true, "time": true,
+}
+
+// zshBuiltinNames are builtins and keywords; a same-named function defined
+// later would only wrap them, so calling them early is fine
+var zshBuiltinNames = map[string]bool{
+	"alias": true, "autoload": true, "bindkey": true, "builtin": true, "cd": true,
+	"command": true, "compdef": true, "declare": true, "echo": true, "emulate": true,
+	"eval": true, "exec": true, "exit": true, "export": true, "false": true, "fc": true,
+	"fi": true, "done": true, "esac": true, "for": true, "case": true, "function": true,
+	"history": true, "kill": true, "local": true, "popd": true, "print": true,
+	"printf": true, "pushd": true, "read": true, "return": true, "set": true,
+	"setopt": true, "shift": true, "source": true, "test": true, "trap": true,
+	"true": true, "type": true, "typeset": true, "unalias": true, "unfunction": true,
+	"unset": true, "unsetopt": true, "wait": true, "whence": true, "which": true,
+	"zle": true, "zmodload": true, "zstyle": true,
+}
+
+// zshrcLoadOrder returns the zsh.d modules zshrc sources, in order. A loop
+// or source over zsh.d/*.zsh adds every module in zshFiles (already sorted)
+// not yet listed; other sourced files are outside the repo and ignored.
+func zshrcLoadOrder(zshrc string, zshFiles []string) ([]string, error) {
+	data, err := os.ReadFile(zshrc)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]string, len(zshFiles))
+	for _, f := range zshFiles {
+		byName[filepath.Base(f)] = f
+	}
+
+	var order []string
+	add := func(file string) {
+		if file != "" && !slices.Contains(order, file) {
+			order = append(order, file)
+		}
+	}
+	for _, line := range shellCodeLines(string(data)) {
+		line = strings.ReplaceAll(line, `"`, "")
+		isLoop := strings.HasPrefix(strings.TrimSpace(line), "for ")
+		m := zshrcSourcePattern.FindStringSubmatch(line)
+		switch {
+		case (isLoop || m != nil) && strings.Contains(line, "zsh.d/*.zsh"):
+			for _, f := range zshFiles {
+				add(f)
+			}
+		case m != nil:
+			if mod := zshrcModulePattern.FindStringSubmatch(m[1]); mod != nil {
+				add(byName[mod[1]])
+			}
+		}
+	}
+	return order, nil
+}
+
+// zshDefinitionSite is where a function or alias is first defined
+type zshDefinitionSite struct {
+	file  string
+	line  int
+	index int // position of file in the load order
+}
+
+// zshTopLevelCall is a command run while a file is being sourced
+type zshTopLevelCall struct {
+	name string
+	line int
+}
+
+// checkSourceOrder warns when a module runs a function or alias at source
+// time that only a later module defines. To stay quiet on valid code, only
+// the first word of each simple command outside braces (function bodies,
+// brace groups) and quotes counts as a call, and names that are builtins,
+// found on PATH, defined earlier, or checked for earlier in the file (e.g.
+// command -v name) are skipped.
+func checkSourceOrder(order []string) []lintResult {
+	defs := make(map[string]zshDefinitionSite)
+	calls := make([][]zshTopLevelCall, len(order))
+	for i, file := range order {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			logger.Debug("skipping source order check", "file", file, "error", err)
+			continue
+		}
+		var fileDefs map[string]int
+		fileDefs, calls[i] = scanZshSourcing(string(data))
+		for name, line := range fileDefs {
+			if _, ok := defs[name]; !ok {
+				defs[name] = zshDefinitionSite{file: file, line: line, index: i}
+			}
+		}
+	}
+
+	var results []lintResult
+	for i, file := range order {
+		result := lintResult{file: file}
+		reported := make(map[string]bool)
+		for _, call := range calls[i] {
+			site, ok := defs[call.name]
+			if !ok || site.index <= i || reported[call.name] || commandExists(call.name) {
+				continue
+			}
+			reported[call.name] = true
+			result.warnings = append(result.warnings, fmt.Sprintf("line %d: '%s' runs before it is defined (%s:%d is sourced later by zshrc)",
+				call.line, call.name, filepath.Base(site.file), site.line))
+		}
+		if len(result.warnings) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// scanZshSourcing returns the functions, aliases, and autoloads content
+// defines (name to first line), and the commands it runs at top level
+func scanZshSourcing(content string) (map[string]int, []zshTopLevelCall) {
+	raw := strings.Split(content, "\n")
+	defs := make(map[string]int)
+	guarded := make(map[string]bool)
+	var calls []zshTopLevelCall
+
+	depth := 0
+	lines := shellCodeLines(content)
+	for i, unquoted := range blankDoubleQuoted(lines) {
+		for _, m := range zshGuardPattern.FindAllStringSubmatch(lines[i], -1) {
+			guarded[m[1]+m[2]] = true
+		}
+		code := zshParamExpansion.ReplaceAllString(unquoted, "$$x")
+		define := func(name string) {
+			if _, ok := defs[name]; !ok {
+				defs[name] = i + 1
+			}
+		}
+		if m := zshAliasPattern.FindStringSubmatch(code); m != nil {
+			define(m[1])
+		} else if m := zshFunctionPattern.FindStringSubmatch(code); m != nil {
+			define(m[1])
+		} else if m := zshFuncDefPattern.FindStringSubmatch(code); m != nil {
+			define(m[1])
+		} else if m := zshrcAutoloadPattern.FindStringSubmatch(code); m != nil {
+			for _, name := range strings.Fields(m[2]) {
+				define(name)
+			}
+		}
+
+		if depth == 0 && !strings.Contains(raw[i], lintAllowSourceOrderMarker) {
+			for _, name := range zshCommandNames(code) {
+				if _, earlier := defs[name]; !earlier && !guarded[name] {
+					calls = append(calls, zshTopLevelCall{name: name, line: i + 1})
+				}
+			}
+		}
+		depth = max(0, depth+strings.Count(code, "{")-strings.Count(code, "}"))
+	}
+	return defs, calls
+}
+
+// zshCommandNames returns the command word of each simple command on a line
+// that starts no block, skipping definitions, assignments, and builtins
+func zshCommandNames(code string) []string {
+	if strings.Contains(code, "{") || zshFunctionPattern.MatchString(code) || zshFuncDefPattern.MatchString(code) {
+		return nil
+	}
+	var names []string
+	start, substitution := 0, false
+	for _, sep := range append(zshCommandSeparator.FindAllStringIndex(code, -1), []int{len(code), len(code)}) {
+		fields := strings.Fields(zshAssignmentPrefix.ReplaceAllString(strings.TrimSpace(code[start:sep[0]]), ""))
+		for len(fields) > 0 && zshCommandPrefixes[fields[0]] {
+			fields = fields[1:]
+		}
+		if len(fields) > 0 {
+			// "name)" is a case pattern unless it closes a $( substitution
+			word, closed := strings.CutSuffix(fields[0], ")")
+			if (substitution || !closed) && zshCommandWord.MatchString(word) && !zshBuiltinNames[word] {
+				names = append(names, word)
+			}
+		}
+		start, substitution = sep[1], code[sep[0]:sep[1]] == "$("
+	}
+	return names
+}
+
+// blankDoubleQuoted replaces the contents of double-quoted strings with
+// spaces, following strings across lines, so quoted text is never read as
+// a command
+func blankDoubleQuoted(lines []string) []string {
+	out := make([]string, len(lines))
+	in := false
+	for n, line := range lines {
+		b := []byte(line)
+		for i, c := range b {
+			switch {
+			case c == '"':
+				in = !in
+			case in:
+				b[i] = ' '
+			}
+		}
+		out[n] = string(b)
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// lintAllowMissingSourceMarker on a line suppresses the sourced file check
+// for that line
+const lintAllowMissingSourceMarker = "# blackdot: allow-missing-source"
+
+// lintDirnameSelf matches $(dirname "$0") and $(dirname "${BASH_SOURCE[0]}"),
+// quoted or not
+const lintDirnameSelf = `"?\$\(dirname\s+"?\$\{?(?:0|BASH_SOURCE(?:\[0\])?)\}?"?\)"?`
+
+var (
+	// lintSourceStatementPattern matches a source or . command up to the
+	// whitespace before its argument
+	lintSourceStatementPattern = regexp.MustCompile(`(?:^|[;&|{(]|\bthen\b|\bdo\b|\belse\b)\s*(?:source|\.)[ \t]`)
+	// lintSourceRepoPrefix matches BLACKDOT_DIR, with or without a default
+	lintSourceRepoPrefix = regexp.MustCompile(`\$\{BLACKDOT_DIR(?::?-(?:[^{}]|\{[^{}]*\})*)?\}|\$BLACKDOT_DIR\b`)
+	// lintSourceScriptDirPrefix matches the ways a script names its own
+	// directory: ${0:A:h} in zsh, dirname of $0 or BASH_SOURCE in bash
+	lintSourceScriptDirPrefix = regexp.MustCompile(`\$\{0:[aA]:h\}|\$\{BASH_SOURCE(?:\[0\])?%/\*\}|` +
+		`\$\(cd\s+` + lintDirnameSelf + `\s*&&\s*pwd\)|` + lintDirnameSelf)
+	// lintSourceHomePrefix matches HOME or a leading ~
+	lintSourceHomePrefix = regexp.MustCompile(`^~/|\$\{HOME\}|\$HOME\b`)
+	// lintSourceFileTest matches a test that a file exists before sourcing it
+	lintSourceFileTest = regexp.MustCompile(`\[\[?\s+!?\s*-[efrsL]\s|\btest\s+!?\s*-[efrsL]\s`)
+)
+
+// checkSourcedFiles reports source and . commands in a shell or zsh file
+// whose target doesn't exist, as errors. Targets are resolved from
+// BLACKDOT_DIR (the repository), the script's own directory (${0:A:h},
+// $(dirname "$0"), ...), and HOME; a target that still holds a variable,
+// command substitution, or glob, or is relative, can't be checked and is
+// returned in skipped instead. A source that may fail on purpose (a file
+// test in the lintGuardWindow lines before it, 2>/dev/null, or ||) is
+// optional and never reported, nor are lines carrying
+// lintAllowMissingSourceMarker.
+func checkSourcedFiles(file, blackdotDir, home string) (result lintResult, skipped []string) {
+	result = lintResult{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping sourced file check", "file", file, "error", err)
+		return result, nil
+	}
+	raw := strings.Split(string(data), "\n")
+	code, verbatim := scanShellLines(string(data))
+
+	for i, line := range code {
+		if verbatim[i] || strings.Contains(raw[i], lintAllowMissingSourceMarker) {
+			continue
+		}
+		for _, loc := range lintSourceStatementPattern.FindAllStringIndex(line, -1) {
+			target, rest := shellWordAt(raw[i], loc[1])
+			if target == "" {
+				continue
+			}
+			path, ok := resolveSourceTarget(target, filepath.Dir(file), blackdotDir, home)
+			if !ok {
+				skipped = append(skipped, fmt.Sprintf("line %d: %s", i+1, target))
+				continue
+			}
+			if lintFileExists(path) || optionalSource(code, i, rest) {
+				continue
+			}
+			result.errors = append(result.errors, fmt.Sprintf("line %d: sourced file not found: %s (%s)", i+1, target, path))
+		}
+	}
+	return result, skipped
+}
+
+// resolveSourceTarget turns a source argument into a path, replacing the
+// prefixes checkSourcedFiles knows. ok is false if anything dynamic is left.
+func resolveSourceTarget(target, scriptDir, blackdotDir, home string) (string, bool) {
+	path := lintSourceRepoPrefix.ReplaceAllLiteralString(target, blackdotDir)
+	path = lintSourceScriptDirPrefix.ReplaceAllLiteralString(path, scriptDir)
+	if home != "" {
+		// filepath.Clean drops the doubled slash ~/ leaves
+		path = lintSourceHomePrefix.ReplaceAllLiteralString(path, home+"/")
+	}
+	if strings.ContainsAny(path, "$`*?[") || !filepath.IsAbs(path) {
+		return "", false
+	}
+	return filepath.Clean(path), true
+}
+
+// optionalSource reports whether the source on line n is allowed to fail:
+// its errors are discarded or handled (2>/dev/null, ||), or a file test
+// appears on the line or in the lintGuardWindow lines before it
+func optionalSource(code []string, n int, rest string) bool {
+	if strings.Contains(rest, "2>/dev/null") || strings.Contains(rest, "&>/dev/null") || strings.Contains(rest, "||") {
+		return true
+	}
+	for i := max(0, n-lintGuardWindow); i <= n; i++ {
+		if lintSourceFileTest.MatchString(code[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellWordAt returns the shell word starting at or after offset in line,
+// with quotes removed, and the text after it
+func shellWordAt(line string, offset int) (word, rest string) {
+	i := offset
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	var b strings.Builder
+	var quote byte
+	depth := 0 // inside $( ) or ${ }, where spaces don't end the word
+	for ; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+				continue
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			continue
+		case c == '\\' && i+1 < len(line):
+			i++
+			c = line[i]
+		case c == '$' && i+1 < len(line) && (line[i+1] == '(' || line[i+1] == '{'):
+			depth++
+			b.WriteString(line[i : i+2])
+			i++
+			continue
+		case depth > 0 && (c == ')' || c == '}'):
+			depth--
+		case depth == 0 && strings.IndexByte(" \t;&|)<>", c) >= 0:
+			return b.String(), line[i:]
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), ""
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// lintStaged holds the staged copies lint --staged checks in place of the
+// working tree files. A nil *lintStaged means lint the working tree.
+type lintStaged struct {
+	root      string            // BLACKDOT_DIR
+	dir       string            // temp dir holding the copies, laid out like root
+	copies    map[string]string // original path -> staged copy
+	originals map[string]string // staged copy -> original path
+}
+
+// newLintStaged writes the index version of each file staged under
+// blackdotDir (added, copied, or modified) to a temp dir with `git show`,
+// keeping its relative path and executable bit. Symlinks and submodules
+// have no content to lint and are left out. Call cleanup when done.
+func newLintStaged(blackdotDir string) (*lintStaged, error) {
+	output, err := runLintCommand("git diff", blackdotDir, "git", "diff", "--cached", "--raw", "-z", "--no-renames", "--diff-filter=ACM", "--relative")
+	if err != nil {
+		return nil, fmt.Errorf("listing staged files: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	dir, err := os.MkdirTemp("", "blackdot-lint-staged-")
+	if err != nil {
+		return nil, err
+	}
+	s := &lintStaged{root: blackdotDir, dir: dir, copies: make(map[string]string), originals: make(map[string]string)}
+
+	// Each entry is ":oldmode newmode oldsha newsha status" NUL path NUL
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		meta, rel := strings.Fields(fields[i]), fields[i+1]
+		if len(meta) < 2 {
+			continue
+		}
+		var perm os.FileMode
+		switch meta[1] {
+		case "100644":
+			perm = 0644
+		case "100755":
+			perm = 0755
+		default:
+			logger.Debug("skipping staged entry", "path", rel, "mode", meta[1])
+			continue
+		}
+
+		content, err := gitStagedContent(blackdotDir, rel)
+		if err != nil {
+			s.cleanup()
+			return nil, err
+		}
+		staged := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(staged), 0755); err != nil {
+			s.cleanup()
+			return nil, err
+		}
+		if err := os.WriteFile(staged, content, perm); err != nil {
+			s.cleanup()
+			return nil, err
+		}
+		// WriteFile's perm is masked by the umask
+		if err := os.Chmod(staged, perm); err != nil {
+			s.cleanup()
+			return nil, err
+		}
+		original := filepath.Join(blackdotDir, filepath.FromSlash(rel))
+		s.copies[original] = staged
+		s.originals[staged] = original
+	}
+	logger.Debug("wrote staged copies", "dir", dir, "files", len(s.copies))
+	return s, nil
+}
+
+// gitStagedContent returns the staged blob for rel, a path relative to dir.
+// stdout only: the content must not pick up git's warnings.
+func gitStagedContent(dir, rel string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lintCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "show", ":./"+rel)
+	cmd.Dir = dir
+	content, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show :%s: %w", rel, err)
+	}
+	return content, nil
+}
+
+// cleanup removes the staged copies
+func (s *lintStaged) cleanup() {
+	if s != nil {
+		os.RemoveAll(s.dir)
+	}
+}
+
+// count is the number of staged files being linted
+func (s *lintStaged) count() int {
+	return len(s.copies)
+}
+
+// files returns the staged copies of files, in order, dropping files that
+// aren't staged; without --staged it returns files unchanged
+func (s *lintStaged) files(files []string) []string {
+	if s == nil {
+		return files
+	}
+	var staged []string
+	for _, file := range files {
+		if c, ok := s.copies[file]; ok {
+			staged = append(staged, c)
+		}
+	}
+	return staged
+}
+
+// path returns the staged copy of file, or "" if it isn't staged; without
+// --staged it returns file unchanged
+func (s *lintStaged) path(file string) string {
+	if s == nil {
+		return file
+	}
+	return s.copies[file]
+}
+
+// original returns the file a staged copy was made from; without --staged
+// it returns file unchanged
+func (s *lintStaged) original(file string) string {
+	if s == nil {
+		return file
+	}
+	if original, ok := s.originals[file]; ok {
+		return original
+	}
+	return file
+}
+
+// restore reports results against the original paths: files are renamed
+// back and copy paths in messages (tool output often names the file) are
+// replaced
+func (s *lintStaged) restore(results lintResults) lintResults {
+	if s == nil {
+		return results
+	}
+	restored := lintResults{}
+	for _, r := range results {
+		result := lintResult{file: r.file}
+		if original, ok := s.originals[r.file]; ok {
+			result.file = original
+		}
+		for _, e := range r.errors {
+			result.errors = append(result.errors, s.restoreMessage(e))
+		}
+		for _, w := range r.warnings {
+			result.warnings = append(result.warnings, s.restoreMessage(w))
+		}
+		restored.add(result)
+	}
+	return restored
+}
+
+// restoreMessage replaces paths inside the staged temp dir with the
+// original paths
+func (s *lintStaged) restoreMessage(msg string) string {
+	return strings.ReplaceAll(msg, s.dir, s.root)
+}
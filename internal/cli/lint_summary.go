@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// lintSummary is the outcome of the last lint run, kept for doctor --bundle
+type lintSummary struct {
+	Timestamp string `json:"timestamp"`
+	Dir       string `json:"dir"`
+	Checked   int    `json:"checked"`
+	Errors    int    `json:"errors"`
+	Warnings  int    `json:"warnings"`
+	Passed    bool   `json:"passed"`
+	Staged    bool   `json:"staged,omitempty"`
+}
+
+// lintSummaryReport is what --summary-json writes: the run summary and
+// every issue reported
+type lintSummaryReport struct {
+	lintSummary
+	Issues []lintSummaryIssue `json:"issues"`
+}
+
+// lintSummaryIssue is one reported error or warning
+type lintSummaryIssue struct {
+	File     string `json:"file"` // relative to BLACKDOT_DIR when inside it
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`       // "error" or "warning"
+	Code     string `json:"code,omitempty"` // shellcheck code, e.g. SC2086
+	Message  string `json:"message"`
+}
+
+// lintIssueLinePattern finds the line number in "line N:" messages from
+// blackdot's checks and file:N: ones from shellcheck and go vet
+var lintIssueLinePattern = regexp.MustCompile(`(?:\bline |^[^:]*:)(\d+):`)
+
+// lintSummaryPath returns ~/.cache/blackdot/lint-last.json
+func lintSummaryPath() string {
+	return filepath.Join(CacheDir(), "lint-last.json")
+}
+
+// newLintSummary summarizes a finished run
+func newLintSummary(dir string, stats lintStats, passed, staged bool) lintSummary {
+	return lintSummary{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Dir:       dir,
+		Checked:   stats.checked,
+		Errors:    stats.errors,
+		Warnings:  stats.warnings,
+		Passed:    passed,
+		Staged:    staged,
+	}
+}
+
+// saveLintSummary records a finished run. It is best effort: a read-only
+// cache never fails the lint.
+func saveLintSummary(dir string, stats lintStats, passed, staged bool) {
+	summary := newLintSummary(dir, stats, passed, staged)
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(CacheDir(), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(lintSummaryPath(), append(data, '\n'), 0644)
+	}
+	if err != nil {
+		logger.Debug("could not save lint summary", "path", lintSummaryPath(), "error", err)
+	}
+}
+
+// loadLintSummary reads the last run's summary, or nil if lint hasn't run
+func loadLintSummary() (*lintSummary, error) {
+	data, err := os.ReadFile(lintSummaryPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var summary lintSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// writeLintSummaryReport writes summary and the issues in results to path
+// for --summary-json
+func writeLintSummaryReport(path string, summary lintSummary, results []lintResult) error {
+	report := lintSummaryReport{lintSummary: summary, Issues: []lintSummaryIssue{}}
+	for _, r := range results {
+		file := baselineRelPath(summary.Dir, r.file)
+		for _, severity := range []string{"error", "warning"} {
+			msgs := r.errors
+			if severity == "warning" {
+				msgs = r.warnings
+			}
+			for _, msg := range msgs {
+				issue := lintSummaryIssue{File: file, Severity: severity, Message: msg}
+				if m := lintIssueLinePattern.FindStringSubmatch(msg); m != nil {
+					issue.Line, _ = strconv.Atoi(m[1])
+				}
+				if m := shellcheckCodePattern.FindStringSubmatch(msg); m != nil {
+					issue.Code = m[1]
+				}
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling lint summary: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing --summary-json: %w", err)
+	}
+	return nil
+}
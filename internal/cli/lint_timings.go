@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lintTimingsTopFiles caps the per-file breakdown unless --verbose is set
+const lintTimingsTopFiles = 20
+
+// lintTiming is the wall-clock time spent on one phase or file
+type lintTiming struct {
+	name     string
+	duration time.Duration
+}
+
+// lintTimings records wall-clock time per lint phase and per file for
+// --timings. A nil *lintTimings is valid and records nothing, so the phase
+// loops can call it unconditionally.
+type lintTimings struct {
+	mu         sync.Mutex
+	start      time.Time
+	phases     []lintTiming
+	phase      string
+	phaseStart time.Time
+	files      map[string]time.Duration
+}
+
+func newLintTimings() *lintTimings {
+	return &lintTimings{start: time.Now(), files: make(map[string]time.Duration)}
+}
+
+// startPhase ends the running phase, if any, and starts timing name
+func (t *lintTimings) startPhase(name string) {
+	if t == nil {
+		return
+	}
+	t.stopPhase()
+	t.phase = name
+	t.phaseStart = time.Now()
+}
+
+// stopPhase ends the running phase
+func (t *lintTimings) stopPhase() {
+	if t == nil || t.phase == "" {
+		return
+	}
+	t.phases = append(t.phases, lintTiming{t.phase, time.Since(t.phaseStart)})
+	t.phase = ""
+}
+
+// file starts timing one file and returns the function that stops it.
+// Time for a file checked in several phases is summed. Safe for concurrent use.
+func (t *lintTimings) file(path string) func() {
+	if t == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+		t.mu.Lock()
+		t.files[path] += d
+		t.mu.Unlock()
+	}
+}
+
+// sortedFiles returns the per-file times, slowest first
+func (t *lintTimings) sortedFiles() []lintTiming {
+	files := make([]lintTiming, 0, len(t.files))
+	for path, d := range t.files {
+		files = append(files, lintTiming{path, d})
+	}
+	sortLintTimings(files)
+	return files
+}
+
+// sortLintTimings orders slowest first, then by name for stable output
+func sortLintTimings(timings []lintTiming) {
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].duration != timings[j].duration {
+			return timings[i].duration > timings[j].duration
+		}
+		return timings[i].name < timings[j].name
+	})
+}
+
+// report prints the phase and file breakdown to w. Files are shown relative
+// to dir, capped at lintTimingsTopFiles unless all is set.
+func (t *lintTimings) report(w io.Writer, dir string, all bool) {
+	if t == nil {
+		return
+	}
+	t.stopPhase()
+	total := time.Since(t.start)
+
+	phases := append([]lintTiming(nil), t.phases...)
+	sortLintTimings(phases)
+
+	fmt.Fprintln(w)
+	Bold.Fprintln(w, "Lint timings:")
+	for _, p := range phases {
+		fmt.Fprintf(w, "  %-18s %10s  %s\n", p.name, formatLintDuration(p.duration), Dim.Sprintf("%4.1f%%", percentOf(p.duration, total)))
+	}
+	fmt.Fprintf(w, "  %-18s %10s\n", "total", formatLintDuration(total))
+
+	files := t.sortedFiles()
+	if len(files) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	Bold.Fprintln(w, "Slowest files:")
+	shown := files
+	if !all && len(shown) > lintTimingsTopFiles {
+		shown = shown[:lintTimingsTopFiles]
+	}
+	for _, f := range shown {
+		name := f.name
+		if rel, err := filepath.Rel(dir, f.name); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+		fmt.Fprintf(w, "  %10s  %s\n", formatLintDuration(f.duration), name)
+	}
+	if hidden := len(files) - len(shown); hidden > 0 {
+		Dim.Fprintf(w, "  ... %d more (use --verbose to show all)\n", hidden)
+	}
+}
+
+// formatLintDuration rounds to a readable precision
+func formatLintDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+func percentOf(d, total time.Duration) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(d) * 100 / float64(total)
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// lintAllowTodoMarker in a comment keeps it out of the count, for markers
+// that are meant to stay
+const lintAllowTodoMarker = "blackdot: allow-todo"
+
+// lintTodoPattern matches the technical debt markers --todos counts
+var lintTodoPattern = regexp.MustCompile(`\b(?:TODO|FIXME|XXX)\b`)
+
+// lintTodo is a comment carrying a debt marker
+type lintTodo struct {
+	file string
+	line int
+	text string // the comment from the marker on
+}
+
+// findShellTodos returns the debt markers in a shell or zsh file's
+// comments; here-document bodies and strings are data, not comments
+func findShellTodos(file string) []lintTodo {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping TODO check", "file", file, "error", err)
+		return nil
+	}
+	raw := strings.Split(string(data), "\n")
+	code, verbatim := scanShellLines(string(data))
+
+	var todos []lintTodo
+	for i, line := range raw {
+		// scanShellLines blanks text in place and cuts comments off, so
+		// whatever is past the code is the comment
+		if verbatim[i] || len(code[i]) >= len(line) {
+			continue
+		}
+		if todo, ok := lintTodoIn(line[len(code[i]):]); ok {
+			todos = append(todos, lintTodo{file: file, line: i + 1, text: todo})
+		}
+	}
+	return todos
+}
+
+// findGoTodos returns the debt markers in a Go file's comments, a line at
+// a time for block comments
+func findGoTodos(file string) []lintTodo {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping TODO check", "file", file, "error", err)
+		return nil
+	}
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	// Syntax errors are go vet's business; scanning goes on past them
+	s.Init(fset.AddFile(file, -1, len(data)), data, nil, scanner.ScanComments)
+
+	var todos []lintTodo
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.COMMENT {
+			continue
+		}
+		start := fset.Position(pos).Line
+		for j, line := range strings.Split(lit, "\n") {
+			if todo, ok := lintTodoIn(line); ok {
+				todos = append(todos, lintTodo{file: file, line: start + j, text: todo})
+			}
+		}
+	}
+	return todos
+}
+
+// lintTodoIn returns comment from its first debt marker on, unless it has
+// none or carries lintAllowTodoMarker
+func lintTodoIn(comment string) (string, bool) {
+	loc := lintTodoPattern.FindStringIndex(comment)
+	if loc == nil || strings.Contains(comment, lintAllowTodoMarker) {
+		return "", false
+	}
+	text := strings.TrimSpace(strings.TrimSuffix(comment[loc[0]:], "*/"))
+	return strings.TrimRight(text, "\r"), true
+}
+
+// goSourceFiles lists the *.go files under root, skipping lintDocsSkipDirs
+// and testdata
+func goSourceFiles(root string) []string {
+	var files []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			logger.Debug("skipping unreadable path", "path", path, "error", err)
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && (lintDocsSkipDirs[d.Name()] || d.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".go" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
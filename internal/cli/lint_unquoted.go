@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// lintAllowUnquotedMarker on a line suppresses the unquoted expansion check
+// for that line
+const lintAllowUnquotedMarker = "# blackdot: allow-unquoted"
+
+// lintShellcheckSC2086Pattern matches a shellcheck directive that disables
+// SC2086, shellcheck's own unquoted expansion warning, which this check
+// honors too
+var lintShellcheckSC2086Pattern = regexp.MustCompile(`#\s*shellcheck\s+disable=[\w,]*\bSC2086\b`)
+
+// lintUnquotedPrefixWords can come before a command name without being it
+var lintUnquotedPrefixWords = []string{"if", "then", "else", "elif", "while", "until", "do", "!", "{", "}", "time", "fi", "done", "esac", "command", "builtin", "exec", "sudo", "nohup"}
+
+// lintUnquotedSkipCommands take expansions where splitting can't hurt or is
+// the point: conditional expressions and arithmetic don't split, case
+// subjects aren't split, for lists split on purpose, eval's arguments are
+// the dangerous check's business, and the rest take numbers
+var lintUnquotedSkipCommands = []string{"[[", "case", "for", "select", "eval", "exit", "return", "shift", "let"}
+
+// lintDeclarationCommands treat NAME=value arguments as assignments
+var lintDeclarationCommands = []string{"local", "export", "declare", "typeset", "readonly"}
+
+// lintAssignmentPattern matches a word that assigns a variable
+var lintAssignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(?:\[[^]]*\])?\+?=`)
+
+// shellWord is a word of a simple command and the expansions in it that
+// are outside double quotes
+type shellWord struct {
+	text     string
+	unquoted []string
+}
+
+// checkUnquoted warns about $var expansions outside double quotes in the
+// arguments of bash and sh scripts, where the value is split on whitespace
+// and globbed, and in [ ] tests, which break when it is empty. It is a
+// tokenizer, not a parser: [[ ]], arithmetic, assignments, case subjects,
+// and for lists are left alone. Lines carrying lintAllowUnquotedMarker, or
+// after a shellcheck disable=SC2086 directive, are skipped.
+func checkUnquoted(file string) lintResult {
+	result := lintResult{file: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug("skipping unquoted expansion check", "file", file, "error", err)
+		return result
+	}
+	raw := strings.Split(string(data), "\n")
+	code, verbatim := scanShellLines(string(data))
+
+	inConditional := false
+	for i, line := range code {
+		// [[ ]] spans the && and || that split it into commands
+		commands := shellCommandWords(line)
+		var checked [][]shellWord
+		for _, words := range commands {
+			if start := shellCommandStart(words); inConditional || (start < len(words) && words[start].text == "[[") {
+				inConditional = !slices.ContainsFunc(words, func(w shellWord) bool { return w.text == "]]" })
+				continue
+			}
+			checked = append(checked, words)
+		}
+		if verbatim[i] || strings.Contains(raw[i], lintAllowUnquotedMarker) || unquotedDisabled(raw, i) {
+			continue
+		}
+		for _, words := range checked {
+			if msg := unquotedInCommand(words); msg != "" {
+				result.warnings = append(result.warnings, fmt.Sprintf("line %d: %s", i+1, msg))
+			}
+		}
+	}
+	return result
+}
+
+// unquotedDisabled reports whether shellcheck's SC2086 is disabled for line
+// n, by a directive at the end of it or on a comment line just before
+func unquotedDisabled(raw []string, n int) bool {
+	if lintShellcheckSC2086Pattern.MatchString(raw[n]) {
+		return true
+	}
+	prev := ""
+	if n > 0 {
+		prev = strings.TrimSpace(raw[n-1])
+	}
+	return strings.HasPrefix(prev, "#") && lintShellcheckSC2086Pattern.MatchString(prev)
+}
+
+// unquotedInCommand describes the unquoted expansions in a simple command's
+// arguments, or returns "" if there are none worth reporting
+func unquotedInCommand(words []shellWord) string {
+	i := shellCommandStart(words)
+	if i >= len(words) || slices.Contains(lintUnquotedSkipCommands, words[i].text) {
+		return ""
+	}
+	name := words[i].text
+	declaration := slices.Contains(lintDeclarationCommands, name)
+
+	var vars []string
+	for _, w := range words[i+1:] {
+		if declaration && lintAssignmentPattern.MatchString(w.text) {
+			continue
+		}
+		for _, v := range w.unquoted {
+			if !slices.Contains(vars, v) {
+				vars = append(vars, v)
+			}
+		}
+	}
+	if len(vars) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, len(vars))
+	for j, v := range vars {
+		quoted[j] = `"` + v + `"`
+	}
+	if name == "[" || name == "test" {
+		return fmt.Sprintf("unquoted %s in a [ ] test breaks it when empty or containing spaces; use %s",
+			strings.Join(vars, ", "), strings.Join(quoted, ", "))
+	}
+	return fmt.Sprintf("unquoted %s in %s arguments is split on whitespace and globbed; use %s",
+		strings.Join(vars, ", "), name, strings.Join(quoted, ", "))
+}
+
+// shellCommandStart returns the index of the command name in words, after
+// keywords like if and leading assignments
+func shellCommandStart(words []shellWord) int {
+	i := 0
+	for i < len(words) && (slices.Contains(lintUnquotedPrefixWords, words[i].text) || lintAssignmentPattern.MatchString(words[i].text)) {
+		i++
+	}
+	return i
+}
+
+// shellCommandWords splits a line from shellCodeLines into simple commands
+// at ;, &, |, and parentheses, and each command into words. Command
+// substitutions, backticks, and arithmetic are kept inside their word, and
+// expansions in them aren't reported.
+func shellCommandWords(line string) [][]shellWord {
+	var commands [][]shellWord
+	var words []shellWord
+	var word strings.Builder
+	var unquoted []string
+	inDouble, backtick := false, false
+	depth := 0
+
+	endWord := func() {
+		if word.Len() > 0 {
+			words = append(words, shellWord{text: word.String(), unquoted: unquoted})
+		}
+		word.Reset()
+		unquoted = nil
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+		}
+		words = nil
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		top := depth == 0 && !backtick
+		switch {
+		case c == '"':
+			inDouble = !inDouble
+		case c == '`':
+			backtick = !backtick
+		case c == '$' && i+1 < len(line) && line[i+1] == '(':
+			depth++
+			word.WriteString("$(")
+			i++
+			continue
+		case c == '(' && depth > 0:
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == '(' && top && !inDouble && i+1 < len(line) && line[i+1] == '(':
+			// (( arithmetic )) doesn't split
+			endCommand()
+			depth += 2
+			word.WriteString("((")
+			i++
+			continue
+		case c == '$' && top && !inDouble:
+			if name, width := shellExpansion(line[i:]); width > 0 {
+				if name != "" {
+					unquoted = append(unquoted, name)
+				}
+				word.WriteString(line[i : i+width])
+				i += width - 1
+				continue
+			}
+		case top && !inDouble && (c == ' ' || c == '\t'):
+			endWord()
+			continue
+		case top && !inDouble && strings.IndexByte(";&|()", c) >= 0:
+			endCommand()
+			continue
+		}
+		word.WriteByte(c)
+	}
+	endCommand()
+	return commands
+}
+
+// shellExpansion parses the parameter expansion at the start of s and
+// returns how it should be written quoted, or "" if it can't split (like
+// $? or ${#list[@]}), and its length. width is 0 if s isn't an expansion.
+func shellExpansion(s string) (name string, width int) {
+	if len(s) < 2 {
+		return "", 0
+	}
+	switch c := s[1]; {
+	case c == '{':
+		end, depth := -1, 0
+		for j := 1; j < len(s) && end < 0; j++ {
+			switch s[j] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			return "", 0
+		}
+		if strings.HasPrefix(s, "${#") {
+			return "", end + 1
+		}
+		return s[:end+1], end + 1
+	case c == '@' || c == '*' || (c >= '1' && c <= '9'):
+		return s[:2], 2
+	case c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+		n := 2
+		for n < len(s) && (s[n] == '_' || (s[n] >= 'A' && s[n] <= 'Z') || (s[n] >= 'a' && s[n] <= 'z') || (s[n] >= '0' && s[n] <= '9')) {
+			n++
+		}
+		return s[:n], n
+	case strings.IndexByte("?#$!-0", c) >= 0:
+		return "", 2
+	}
+	return "", 0
+}
@@ -0,0 +1,85 @@
+// Package cli diagnostic logging
+// User-facing output goes through output.go; this is for --log-level diagnostics
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// Global logging flags
+	logLevel string
+	logFile  string
+
+	// logger receives diagnostic output. It discards everything until
+	// setupLogging runs so package-level code can log unconditionally.
+	logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// logFileHandle is closed by closeLogging
+	logFileHandle *os.File
+)
+
+// parseLogLevel maps a --log-level value to a slog level
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "error":
+		return slog.LevelError, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	}
+	return 0, fmt.Errorf("invalid log level: %s (valid: error, warn, info, debug)", level)
+}
+
+// setupLogging configures the package logger from the global flags.
+// Logs go to stderr unless --log-file is set.
+func setupLogging(level, file string) error {
+	lvl, err := parseLogLevel(level)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stderr
+	if file != "" {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("creating log directory: %w", err)
+		}
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("opening log file: %w", err)
+		}
+		logFileHandle = f
+		w = f
+	}
+
+	logger = slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: lvl}))
+	return nil
+}
+
+// closeLogging flushes and closes the log file, if any
+func closeLogging() {
+	if logFileHandle != nil {
+		logFileHandle.Close()
+		logFileHandle = nil
+	}
+}
+
+// globLogged wraps filepath.Glob, logging bad patterns and match counts
+// instead of silently dropping them
+func globLogged(pattern string) []string {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.Debug("glob failed", "pattern", pattern, "error", err)
+		return nil
+	}
+	logger.Debug("glob", "pattern", pattern, "matches", len(matches))
+	return matches
+}
@@ -4,10 +4,14 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -26,6 +30,19 @@ type MetricEntry struct {
 	OS          string `json:"os"`
 }
 
+// metricsReport is the --json output of the metrics command
+type metricsReport struct {
+	Since           string   `json:"since,omitempty"`
+	Runs            int      `json:"runs"`
+	First           string   `json:"first,omitempty"`
+	Last            string   `json:"last,omitempty"`
+	AverageScore    float64  `json:"average_score"`
+	AverageErrors   float64  `json:"average_errors"`
+	AverageWarnings float64  `json:"average_warnings"`
+	PerfectRuns     int      `json:"perfect_runs"`
+	EnabledFeatures []string `json:"enabled_features"`
+}
+
 func newMetricsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "metrics",
@@ -36,16 +53,29 @@ Modes:
   (default)     Summary with statistics and recent checks
   --graph, -g   ASCII bar chart of health scores (last 30)
   --all, -a     Show all metric entries
+  --json        Summary with enabled features as JSON
+  --format prometheus
+                Latest check, run totals, and features as Prometheus
+                text-format gauges, for a node-exporter textfile collector
+
+--since limits every mode to recent checks. It accepts Go durations
+(12h, 90m) plus days and weeks (7d, 2w).
 
 Examples:
-  blackdot metrics           # Summary view
-  blackdot metrics --graph   # Health score trend
-  blackdot metrics --all     # All entries`,
+  blackdot metrics             # Summary view
+  blackdot metrics --graph     # Health score trend
+  blackdot metrics --all       # All entries
+  blackdot metrics --since 7d  # Only the last week
+  blackdot metrics --since 30d --json
+  blackdot metrics --format prometheus > /var/lib/node_exporter/blackdot.prom`,
 		RunE: runMetrics,
 	}
 
 	cmd.Flags().BoolP("all", "a", false, "Show all metric entries")
 	cmd.Flags().BoolP("graph", "g", false, "Show health score graph (last 30)")
+	cmd.Flags().String("since", "", "Only include checks newer than this (e.g. 7d, 12h)")
+	cmd.Flags().Bool("json", false, "Output summary as JSON")
+	cmd.Flags().String("format", "text", "Output format: text, json, or prometheus")
 
 	return cmd
 }
@@ -56,20 +86,62 @@ func runMetrics(cmd *cobra.Command, args []string) error {
 
 	showAll, _ := cmd.Flags().GetBool("all")
 	showGraph, _ := cmd.Flags().GetBool("graph")
+	since, _ := cmd.Flags().GetString("since")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	format, _ := cmd.Flags().GetString("format")
+
+	switch format {
+	case "text":
+	case "json":
+		jsonOutput = true
+	case "prometheus":
+		if jsonOutput {
+			return fmt.Errorf("--json and --format prometheus can't be combined")
+		}
+	default:
+		return fmt.Errorf("unknown --format %q (valid: text, json, prometheus)", format)
+	}
 
-	// Check if metrics file exists
-	if _, err := os.Stat(metricsFile); os.IsNotExist(err) {
-		fmt.Println("No metrics found. Run 'blackdot doctor' to start collecting metrics.")
-		return nil
+	var window time.Duration
+	if since != "" {
+		var err error
+		if window, err = parseSinceDuration(since); err != nil {
+			return err
+		}
 	}
 
-	// Load all metrics
-	entries, err := loadMetrics(metricsFile)
-	if err != nil {
-		return fmt.Errorf("loading metrics: %w", err)
+	// Load all metrics; a missing file is just an empty history
+	var entries []MetricEntry
+	if _, err := os.Stat(metricsFile); err == nil {
+		if entries, err = loadMetrics(metricsFile); err != nil {
+			return fmt.Errorf("loading metrics: %w", err)
+		}
+	}
+	if window > 0 {
+		entries = filterMetricsSince(entries, time.Now().Add(-window))
+	}
+
+	if format == "prometheus" {
+		reg := initRegistry()
+		return writeMetricsPrometheus(os.Stdout, entries, reg.List(""), enabledFeatureNames())
+	}
+
+	if jsonOutput {
+		report := buildMetricsReport(entries, enabledFeatureNames())
+		report.Since = since
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
 	}
 
 	if len(entries) == 0 {
+		if since != "" {
+			fmt.Printf("No health checks in the last %s.\n", since)
+			return nil
+		}
 		fmt.Println("No metrics found. Run 'blackdot doctor' to start collecting metrics.")
 		return nil
 	}
@@ -133,7 +205,11 @@ func runMetrics(cmd *cobra.Command, args []string) error {
 
 		// Total checks
 		total := len(entries)
-		fmt.Printf("%s %d\n", cyan("Total health checks:"), total)
+		if since != "" {
+			fmt.Printf("%s %d (last %s)\n", cyan("Total health checks:"), total, since)
+		} else {
+			fmt.Printf("%s %d\n", cyan("Total health checks:"), total)
+		}
 		fmt.Println()
 
 		// Last 10 checks
@@ -246,6 +322,14 @@ func runMetrics(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  %s: %d checks\n", os, count)
 		}
 
+		// Features currently enabled
+		fmt.Println()
+		enabled := enabledFeatureNames()
+		fmt.Printf("%s %d\n", bold("Enabled features:"), len(enabled))
+		if len(enabled) > 0 {
+			fmt.Printf("  %s\n", strings.Join(enabled, ", "))
+		}
+
 		fmt.Println()
 		fmt.Printf("%s\n", blue("Tip: Use --graph to see health score trend"))
 		fmt.Printf("%s\n", blue("     Use --all to see all entries"))
@@ -302,3 +386,128 @@ func parseTimestamp(ts string) (date, timeStr string) {
 	}
 	return
 }
+
+// parseSinceDuration accepts Go durations plus a d (day) or w (week) suffix
+func parseSinceDuration(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(s, "d"), "w"))
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid --since %q (examples: 7d, 2w, 12h)", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --since %q (examples: 7d, 2w, 12h)", s)
+	}
+	return d, nil
+}
+
+// filterMetricsSince keeps entries at or after cutoff. Entries with an
+// unparseable timestamp are dropped since they can't be placed in the window.
+func filterMetricsSince(entries []MetricEntry, cutoff time.Time) []MetricEntry {
+	var kept []MetricEntry
+	for _, e := range entries {
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil || ts.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// buildMetricsReport summarizes entries for --json
+func buildMetricsReport(entries []MetricEntry, enabled []string) metricsReport {
+	report := metricsReport{Runs: len(entries), EnabledFeatures: enabled}
+	if report.EnabledFeatures == nil {
+		report.EnabledFeatures = []string{}
+	}
+	if len(entries) == 0 {
+		return report
+	}
+
+	var score, errs, warns int
+	for _, e := range entries {
+		score += e.HealthScore
+		errs += e.Errors
+		warns += e.Warnings
+		if e.HealthScore == 100 {
+			report.PerfectRuns++
+		}
+	}
+	n := float64(len(entries))
+	report.AverageScore = float64(score) / n
+	report.AverageErrors = float64(errs) / n
+	report.AverageWarnings = float64(warns) / n
+	report.First = entries[0].Timestamp
+	report.Last = entries[len(entries)-1].Timestamp
+	return report
+}
+
+// enabledFeatureNames lists features enabled in the persisted registry state
+func enabledFeatureNames() []string {
+	reg := initRegistry()
+	var names []string
+	for _, name := range reg.List("") {
+		if reg.Enabled(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// writeMetricsPrometheus writes the health check history and feature state
+// in the Prometheus text exposition format. Per-check gauges describe the
+// most recent check and are left out when there is none.
+func writeMetricsPrometheus(w io.Writer, entries []MetricEntry, features, enabled []string) error {
+	var b strings.Builder
+	gauge := func(name, help string, samples ...string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, sample := range samples {
+			fmt.Fprintf(&b, "%s%s\n", name, sample)
+		}
+	}
+	value := func(v float64) string {
+		return " " + strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	report := buildMetricsReport(entries, enabled)
+	gauge("blackdot_health_runs", "Health checks recorded by blackdot doctor.", value(float64(report.Runs)))
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		gauge("blackdot_health_score", "Health score of the most recent check (0-100).", value(float64(last.HealthScore)))
+		gauge("blackdot_health_errors", "Errors found by the most recent check.", value(float64(last.Errors)))
+		gauge("blackdot_health_warnings", "Warnings found by the most recent check.", value(float64(last.Warnings)))
+		gauge("blackdot_health_fixed", "Issues auto-fixed by the most recent check.", value(float64(last.Fixed)))
+		if ts, err := time.Parse(time.RFC3339, last.Timestamp); err == nil {
+			gauge("blackdot_health_last_run_timestamp_seconds", "Unix time of the most recent check.", value(float64(ts.Unix())))
+		}
+		gauge("blackdot_health_score_average", "Average health score over the recorded checks.", value(report.AverageScore))
+		gauge("blackdot_health_perfect_runs", "Recorded checks that scored 100.", value(float64(report.PerfectRuns)))
+	}
+
+	gauge("blackdot_features_enabled", "Features currently enabled.", value(float64(len(enabled))))
+	var samples []string
+	for _, name := range features {
+		on := 0.0
+		if slices.Contains(enabled, name) {
+			on = 1
+		}
+		samples = append(samples, fmt.Sprintf("{feature=%q}%s", name, value(on)))
+	}
+	if len(samples) > 0 {
+		gauge("blackdot_feature_enabled", "Whether each feature is enabled (1) or not (0).", samples...)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
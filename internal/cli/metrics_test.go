@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseSinceDuration verifies day/week suffixes and Go durations
+func TestParseSinceDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"0d", 0, true},
+		{"-3h", 0, true},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSinceDuration(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSinceDuration(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSinceDuration(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMetricsReportSince verifies windowing and summary statistics
+func TestMetricsReportSince(t *testing.T) {
+	now := time.Now().UTC()
+	stamp := func(d time.Duration) string {
+		return now.Add(-d).Format("2006-01-02T15:04:05+00:00")
+	}
+	entries := []MetricEntry{
+		{Timestamp: stamp(30 * 24 * time.Hour), HealthScore: 50, Errors: 5, Warnings: 4},
+		{Timestamp: stamp(3 * 24 * time.Hour), HealthScore: 100, Errors: 0, Warnings: 0},
+		{Timestamp: stamp(time.Hour), HealthScore: 90, Errors: 0, Warnings: 2},
+		{Timestamp: "not-a-time", HealthScore: 10},
+	}
+
+	recent := filterMetricsSince(entries, now.Add(-7*24*time.Hour))
+	if len(recent) != 2 {
+		t.Fatalf("expected 2 entries in the last week, got %d", len(recent))
+	}
+
+	report := buildMetricsReport(recent, []string{"vault"})
+	if report.Runs != 2 || report.PerfectRuns != 1 {
+		t.Errorf("runs/perfect = %d/%d, want 2/1", report.Runs, report.PerfectRuns)
+	}
+	if report.AverageScore != 95 || report.AverageWarnings != 1 {
+		t.Errorf("averages = %v/%v, want 95/1", report.AverageScore, report.AverageWarnings)
+	}
+	if report.First != recent[0].Timestamp || report.Last != recent[1].Timestamp {
+		t.Errorf("first/last = %s/%s", report.First, report.Last)
+	}
+
+	empty := buildMetricsReport(nil, nil)
+	if empty.Runs != 0 || empty.EnabledFeatures == nil {
+		t.Errorf("empty report should have zero runs and a non-nil feature list: %+v", empty)
+	}
+}
+
+// TestWriteMetricsPrometheus verifies the gauges describe the latest check
+// and each feature
+func TestWriteMetricsPrometheus(t *testing.T) {
+	entries := []MetricEntry{
+		{Timestamp: "2026-01-01T10:00:00+00:00", HealthScore: 80, Errors: 2, Warnings: 3},
+		{Timestamp: "2026-01-02T10:00:00+00:00", HealthScore: 100, Warnings: 1, Fixed: 4},
+	}
+	var out strings.Builder
+	if err := writeMetricsPrometheus(&out, entries, []string{"vault", "workspace_symlink"}, []string{"vault"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE blackdot_health_score gauge\nblackdot_health_score 100\n",
+		"blackdot_health_runs 2\n",
+		"blackdot_health_errors 0\n",
+		"blackdot_health_warnings 1\n",
+		"blackdot_health_fixed 4\n",
+		"blackdot_health_last_run_timestamp_seconds 1767348000\n",
+		"blackdot_health_score_average 90\n",
+		"blackdot_features_enabled 1\n",
+		`blackdot_feature_enabled{feature="vault"} 1` + "\n",
+		`blackdot_feature_enabled{feature="workspace_symlink"} 0` + "\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	// Without history only the run count and features are reported
+	out.Reset()
+	if err := writeMetricsPrometheus(&out, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "blackdot_health_score") || !strings.Contains(out.String(), "blackdot_health_runs 0\n") {
+		t.Errorf("empty history output:\n%s", out.String())
+	}
+}
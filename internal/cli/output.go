@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"golang.org/x/term"
 )
 
 // ============================================================
@@ -215,6 +216,31 @@ func PrintHint(format string, a ...interface{}) {
 // Color Control
 // ============================================================
 
+// colorModes are the values accepted by the global --color flag
+var colorModes = []string{"auto", "always", "never"}
+
+// colorMode is set by the global --color flag
+var colorMode = "auto"
+
+// setupColor applies a --color mode. "auto" disables color when NO_COLOR is
+// set, TERM is dumb, or stdout isn't a terminal. Colors are decided when
+// text is printed, so this covers the shared styles and inline color.New
+// alike.
+func setupColor(mode string) error {
+	switch mode {
+	case "auto":
+		color.NoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" ||
+			!term.IsTerminal(int(os.Stdout.Fd()))
+	case "always":
+		ForceColor()
+	case "never":
+		NoColor()
+	default:
+		return fmt.Errorf("invalid --color value: %q (valid: %s)", mode, strings.Join(colorModes, ", "))
+	}
+	return nil
+}
+
 // NoColor disables color output (for piping, CI, etc.)
 func NoColor() {
 	color.NoColor = true
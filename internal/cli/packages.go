@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/fatih/color"
@@ -39,7 +40,17 @@ Examples:
   blackdot packages                        # Status overview
   blackdot packages --check                # See what needs installing
   blackdot packages --install              # Install from saved tier
-  blackdot packages --install --tier minimal  # Install minimal tier`,
+  blackdot packages --install --tier minimal  # Install minimal tier
+  blackdot packages --check --platform darwin # Cross-check the macOS set
+  blackdot packages install --tier minimal    # brew bundle a tier, with a summary
+  blackdot packages install --dry-run         # List what the tier is missing
+  blackdot packages diff --file -             # Compare a packages.json from stdin
+  blackdot packages generate --tier enhanced  # Capture installed packages into a tier
+
+Platforms:
+  Packages are filtered for the current OS. cask and mas entries, and
+  anything inside an "if OS.mac?" block, only apply to macOS; entries
+  inside "if OS.linux?" only apply to Linux.`,
 		RunE: runPackages,
 	}
 
@@ -47,6 +58,11 @@ Examples:
 	cmd.Flags().BoolP("install", "i", false, "Install missing packages")
 	cmd.Flags().BoolP("outdated", "o", false, "Show outdated packages")
 	cmd.Flags().StringP("tier", "t", "", "Use specific tier (minimal/enhanced/full)")
+	cmd.Flags().String("platform", "", "Filter packages for a platform (darwin/linux, default: current OS)")
+
+	cmd.AddCommand(newPackagesInstallCmd())
+	cmd.AddCommand(newPackagesDiffCmd())
+	cmd.AddCommand(newPackagesGenerateCmd())
 
 	return cmd
 }
@@ -56,6 +72,12 @@ func runPackages(cmd *cobra.Command, args []string) error {
 	installMode, _ := cmd.Flags().GetBool("install")
 	outdatedMode, _ := cmd.Flags().GetBool("outdated")
 	tierOverride, _ := cmd.Flags().GetString("tier")
+	platformOverride, _ := cmd.Flags().GetString("platform")
+
+	platform, err := normalizeBrewPlatform(platformOverride)
+	if err != nil {
+		return err
+	}
 
 	// Colors
 	bold := color.New(color.Bold).SprintFunc()
@@ -69,47 +91,24 @@ func runPackages(cmd *cobra.Command, args []string) error {
 	if _, err := exec.LookPath("brew"); err != nil {
 		fmt.Printf("%s Homebrew not installed\n", red("[FAIL]"))
 		fmt.Println("Install with: /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)\"")
-		return fmt.Errorf("homebrew not installed")
+		return classErrorf(ErrToolMissing, "homebrew not installed")
 	}
 
-	// Determine blackdot directory
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		home, _ := os.UserHomeDir()
-		blackdotDir = filepath.Join(home, ".blackdot")
-	}
-
-	// Determine tier
-	tier := getPackageTier(tierOverride, blackdotDir)
-
-	// Map tier to Brewfile
-	var brewfilePath string
-	switch tier {
-	case "minimal":
-		brewfilePath = filepath.Join(blackdotDir, "brew", "Brewfile.minimal")
-	case "enhanced":
-		brewfilePath = filepath.Join(blackdotDir, "brew", "Brewfile.enhanced")
-	default:
-		brewfilePath = filepath.Join(blackdotDir, "brew", "Brewfile")
-		tier = "full"
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
 	}
 
-	// Check Brewfile exists, fall back to main if needed
-	if _, err := os.Stat(brewfilePath); os.IsNotExist(err) {
-		mainBrewfile := filepath.Join(blackdotDir, "brew", "Brewfile")
-		if _, err := os.Stat(mainBrewfile); err == nil {
-			fmt.Printf("%s Brewfile for '%s' tier not found, using full Brewfile\n", yellow("[WARN]"), tier)
-			brewfilePath = mainBrewfile
-			tier = "full"
-		} else {
-			return fmt.Errorf("no Brewfile found at %s", brewfilePath)
-		}
+	tier, brewfilePath, err := resolvePackageBrewfile(tierOverride, blackdotDir)
+	if err != nil {
+		return err
 	}
 
 	fmt.Println()
 	fmt.Println(bold("Blackdot Package Manager"))
 	fmt.Println("========================")
 	fmt.Printf("%s\n", dim(fmt.Sprintf("Tier: %s (%s)", tier, filepath.Base(brewfilePath))))
+	fmt.Printf("%s\n", dim(fmt.Sprintf("Platform: %s", platform)))
 	fmt.Println()
 
 	// Outdated mode
@@ -134,10 +133,11 @@ func runPackages(cmd *cobra.Command, args []string) error {
 	// Parse Brewfile
 	fmt.Printf("%s Analyzing Brewfile (%s tier)...\n", cyan("[INFO]"), tier)
 
-	brewfileFormulas, brewfileCasks, err := parseBrewfile(brewfilePath)
+	brewfilePackages, err := parseBrewfilePackages(brewfilePath)
 	if err != nil {
 		return fmt.Errorf("parsing Brewfile: %w", err)
 	}
+	brewfileFormulas, brewfileCasks, skipped := filterBrewfilePackages(brewfilePackages, platform)
 
 	// Get installed packages
 	installedFormulas := getInstalledFormulas()
@@ -153,6 +153,9 @@ func runPackages(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s\n", bold(fmt.Sprintf("Brewfile Summary (%s):", tier)))
 		fmt.Printf("  Formulas defined: %d\n", len(brewfileFormulas))
 		fmt.Printf("  Casks defined: %d\n", len(brewfileCasks))
+		if skipped > 0 {
+			fmt.Printf("  %s\n", dim(fmt.Sprintf("Skipped (not for %s): %d", platform, skipped)))
+		}
 		fmt.Println()
 		fmt.Printf("%s\n", bold("Installed:"))
 		fmt.Printf("  Formulas: %d\n", len(installedFormulas))
@@ -231,6 +234,27 @@ func runPackages(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// resolvePackageBrewfile maps the selected tier to its Brewfile, falling
+// back to the full Brewfile when the tier's file doesn't exist
+func resolvePackageBrewfile(tierOverride, blackdotDir string) (tier, brewfilePath string, err error) {
+	tier = getPackageTier(tierOverride, blackdotDir)
+
+	if tier != "minimal" && tier != "enhanced" {
+		tier = "full"
+	}
+	brewfilePath = packageTierBrewfile(blackdotDir, tier)
+
+	if _, err := os.Stat(brewfilePath); os.IsNotExist(err) {
+		mainBrewfile := filepath.Join(blackdotDir, "brew", "Brewfile")
+		if _, err := os.Stat(mainBrewfile); err != nil {
+			return "", "", fmt.Errorf("no Brewfile found at %s", brewfilePath)
+		}
+		Warn("Brewfile for '%s' tier not found, using full Brewfile", tier)
+		return "full", mainBrewfile, nil
+	}
+	return tier, brewfilePath, nil
+}
+
 // getPackageTier determines which tier to use
 // Priority: --tier flag > config.json > BREWFILE_TIER env > default (full)
 func getPackageTier(tierOverride, blackdotDir string) string {
@@ -262,17 +286,64 @@ func getPackageTier(tierOverride, blackdotDir string) string {
 	return "full"
 }
 
-// parseBrewfile extracts formula and cask names from a Brewfile
-func parseBrewfile(path string) (formulas, casks []string, err error) {
+// brewfilePackage is a single Brewfile directive tagged with the platform it
+// applies to. Platform is "darwin", "linux", or "" for every platform.
+type brewfilePackage struct {
+	Kind     string // brew, cask, mas
+	Name     string
+	Platform string
+}
+
+var (
+	brewfileDirectivePattern = regexp.MustCompile(`^(brew|cask|mas)\s+["']([^"']+)["']`)
+	brewfileOSBlockPattern   = regexp.MustCompile(`^(if|unless)\s+OS\.(mac|linux)\?`)
+	brewfileOSSuffixPattern  = regexp.MustCompile(`\s(if|unless)\s+OS\.(mac|linux)\?\s*(#.*)?$`)
+)
+
+// normalizeBrewPlatform validates a --platform value, defaulting to the current OS
+func normalizeBrewPlatform(platform string) (string, error) {
+	switch strings.ToLower(platform) {
+	case "":
+		return runtime.GOOS, nil
+	case "darwin", "macos", "mac":
+		return "darwin", nil
+	case "linux":
+		return "linux", nil
+	}
+	return "", fmt.Errorf("unknown platform: %s (valid: darwin, linux)", platform)
+}
+
+// appliesTo reports whether the package should be installed on platform
+func (p brewfilePackage) appliesTo(platform string) bool {
+	return p.Platform == "" || p.Platform == platform
+}
+
+// parseBrewfilePackages parses brew, cask, and mas directives, tracking
+// "if OS.mac?" / "if OS.linux?" blocks and trailing modifiers (and their
+// unless/else forms).
+// cask and mas are macOS-only regardless of the enclosing block.
+func parseBrewfilePackages(path string) ([]brewfilePackage, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer file.Close()
 
-	brewRe := regexp.MustCompile(`^brew\s+["']([^"']+)["']`)
-	caskRe := regexp.MustCompile(`^cask\s+["']([^"']+)["']`)
+	// Platform of each enclosing block; "" for conditions we don't track
+	var blocks []string
+	other := map[string]string{"darwin": "linux", "linux": "darwin"}
+	osCondition := func(keyword, osName string) string {
+		platform := "darwin"
+		if osName == "linux" {
+			platform = "linux"
+		}
+		if keyword == "unless" {
+			platform = other[platform]
+		}
+		return platform
+	}
 
+	var pkgs []brewfilePackage
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -282,14 +353,82 @@ func parseBrewfile(path string) (formulas, casks []string, err error) {
 			continue
 		}
 
-		if match := brewRe.FindStringSubmatch(line); match != nil {
-			formulas = append(formulas, match[1])
-		} else if match := caskRe.FindStringSubmatch(line); match != nil {
-			casks = append(casks, match[1])
+		if m := brewfileOSBlockPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, osCondition(m[1], m[2]))
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "if ") || strings.HasPrefix(line, "unless ") || strings.HasSuffix(line, " do"):
+			blocks = append(blocks, "")
+			continue
+		case line == "else":
+			if n := len(blocks); n > 0 {
+				blocks[n-1] = other[blocks[n-1]]
+			}
+			continue
+		case line == "end":
+			if n := len(blocks); n > 0 {
+				blocks = blocks[:n-1]
+			}
+			continue
+		}
+
+		m := brewfileDirectivePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		pkg := brewfilePackage{Kind: m[1], Name: m[2]}
+		for _, platform := range blocks {
+			if platform != "" {
+				pkg.Platform = platform
+			}
+		}
+		if m := brewfileOSSuffixPattern.FindStringSubmatch(line); m != nil {
+			pkg.Platform = osCondition(m[1], m[2])
+		}
+		if pkg.Kind == "cask" || pkg.Kind == "mas" {
+			pkg.Platform = "darwin"
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	return pkgs, scanner.Err()
+}
+
+// filterBrewfilePackages splits packages that apply to platform into
+// formulas and casks, returning how many were skipped. mas entries are
+// counted as skipped on Linux but otherwise not compared.
+func filterBrewfilePackages(pkgs []brewfilePackage, platform string) (formulas, casks []string, skipped int) {
+	for _, p := range pkgs {
+		if !p.appliesTo(platform) {
+			skipped++
+			continue
+		}
+		switch p.Kind {
+		case "brew":
+			formulas = append(formulas, p.Name)
+		case "cask":
+			casks = append(casks, p.Name)
 		}
 	}
+	return formulas, casks, skipped
+}
 
-	return formulas, casks, scanner.Err()
+// parseBrewfile extracts formula and cask names from a Brewfile for every platform
+func parseBrewfile(path string) (formulas, casks []string, err error) {
+	pkgs, err := parseBrewfilePackages(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range pkgs {
+		switch p.Kind {
+		case "brew":
+			formulas = append(formulas, p.Name)
+		case "cask":
+			casks = append(casks, p.Name)
+		}
+	}
+	return formulas, casks, nil
 }
 
 // getInstalledFormulas returns list of installed Homebrew formulas
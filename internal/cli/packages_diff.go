@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// wingetManifest is the part of a winget export (powershell/packages.json)
+// that blackdot reads
+type wingetManifest struct {
+	Schema  string         `json:"$schema"`
+	Sources []wingetSource `json:"sources"`
+}
+
+type wingetSource struct {
+	Packages      []wingetPackage `json:"packages"`
+	SourceDetails struct {
+		Name string `json:"name"`
+	} `json:"sourceDetails"`
+}
+
+type wingetPackage struct {
+	ID      string `json:"id"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// packageIDs returns every package id in the manifest, in order
+func (m wingetManifest) packageIDs() []string {
+	var ids []string
+	for _, src := range m.Sources {
+		for _, pkg := range src.Packages {
+			ids = append(ids, pkg.ID)
+		}
+	}
+	return ids
+}
+
+func newPackagesDiffCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare a packages.json manifest with the repository's",
+		Long: `Compare a winget packages.json manifest with powershell/packages.json in
+the blackdot repository, listing the package ids it adds (+) and drops (-).
+
+The manifest is validated first: it must be JSON with a "sources" list of
+"packages" that each have an "id", and no id may appear twice. Use
+--file - to read it from stdin, so a generated manifest can be checked
+without writing it to disk.
+
+Examples:
+  blackdot packages diff --file ~/export.json
+  winget export -o - | blackdot packages diff --file -
+  ./gen-manifest | blackdot packages diff --file -`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			blackdotDir, err := resolveBlackdotDir()
+			if err != nil {
+				return err
+			}
+			return runPackagesDiff(cmd.InOrStdin(), cmd.OutOrStdout(), file, filepath.Join(blackdotDir, "powershell", "packages.json"))
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Manifest to compare (- for stdin)")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func runPackagesDiff(stdin io.Reader, out io.Writer, file, repoManifest string) error {
+	theirs, err := readWingetManifest(file, stdin)
+	if err != nil {
+		return err
+	}
+	ours, err := readWingetManifest(repoManifest, nil)
+	if err != nil {
+		return err
+	}
+
+	added, removed := diffPackageIDs(ours.packageIDs(), theirs.packageIDs())
+	name := file
+	if file == "-" {
+		name = "stdin"
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Fprintf(out, "%s %s matches %s (%d packages)\n", Green.Sprint("✓"), name, repoManifest, len(ours.packageIDs()))
+		return nil
+	}
+
+	fmt.Fprintf(out, "%s\n", Dim.Sprint(fmt.Sprintf("--- %s\n+++ %s", repoManifest, name)))
+	for _, id := range removed {
+		fmt.Fprintf(out, "%s\n", Red.Sprint("- "+id))
+	}
+	for _, id := range added {
+		fmt.Fprintf(out, "%s\n", Green.Sprint("+ "+id))
+	}
+	fmt.Fprintf(out, "\n%d added, %d removed\n", len(added), len(removed))
+	return nil
+}
+
+// readWingetManifest parses and validates a packages.json manifest. A path
+// of "-" reads from stdin.
+func readWingetManifest(path string, stdin io.Reader) (wingetManifest, error) {
+	var data []byte
+	var err error
+	name := path
+	if path == "-" {
+		name = "stdin"
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return wingetManifest{}, fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	var m wingetManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return wingetManifest{}, fmt.Errorf("%s is not valid JSON: %w", name, err)
+	}
+	if len(m.Sources) == 0 {
+		return wingetManifest{}, fmt.Errorf("%s has no \"sources\"", name)
+	}
+	seen := make(map[string]bool)
+	for i, id := range m.packageIDs() {
+		if id == "" {
+			return wingetManifest{}, fmt.Errorf("%s: package %d has no \"id\"", name, i+1)
+		}
+		if seen[id] {
+			return wingetManifest{}, fmt.Errorf("%s: package %s is listed twice", name, id)
+		}
+		seen[id] = true
+	}
+	return m, nil
+}
+
+// diffPackageIDs returns the ids only in theirs (added) and only in ours
+// (removed), sorted
+func diffPackageIDs(ours, theirs []string) (added, removed []string) {
+	for _, id := range theirs {
+		if !slices.Contains(ours, id) {
+			added = append(added, id)
+		}
+	}
+	for _, id := range ours {
+		if !slices.Contains(theirs, id) {
+			removed = append(removed, id)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// brewDumpLinePattern matches an entry of brew bundle dump output, e.g.
+// `brew "postgresql@16", restart_service: true`
+var brewDumpLinePattern = regexp.MustCompile(`^(\w+)\s+["']([^"']+)["']`)
+
+// brewDumpSections orders and titles the entry kinds in a generated
+// Brewfile; kinds brew adds later go under "Other" at the end
+var brewDumpSections = []struct {
+	kind  string
+	title string
+}{
+	{"tap", "Taps"},
+	{"brew", "Formulae"},
+	{"cask", "Casks (macOS only)"},
+	{"mas", "Mac App Store (macOS only)"},
+	{"vscode", "VS Code extensions"},
+}
+
+func newPackagesGenerateCmd() *cobra.Command {
+	var tierOverride string
+	var exclude []string
+	var printOnly, force bool
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Write a Brewfile tier from what is installed",
+		Long: `Capture the installed Homebrew packages into a Brewfile tier.
+
+Runs 'brew bundle dump' and writes its entries to the tier's Brewfile,
+grouped into taps, formulae, casks, Mac App Store apps, and VS Code
+extensions. tap lines already in the tier file are kept, so custom tap
+URLs survive; taps brew reports that the file lacks are added after them.
+
+The tier comes from --tier, then packages.tier in config.json, then
+BREWFILE_TIER, and defaults to full:
+  minimal   brew/Brewfile.minimal
+  enhanced  brew/Brewfile.enhanced
+  full      brew/Brewfile
+
+An existing tier file is only replaced after you confirm, or with
+--force. --exclude drops formulae and casks by name, e.g. ones installed
+for a single project.
+
+Examples:
+  blackdot packages generate --tier enhanced
+  blackdot packages generate --tier minimal --print
+  blackdot packages generate --exclude postgresql@16,docker --force`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPackagesGenerate(tierOverride, exclude, printOnly, force)
+		},
+	}
+
+	cmd.Flags().StringVarP(&tierOverride, "tier", "t", "", "Tier to write ("+strings.Join(packageTiers, "/")+")")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "Formulae and casks to leave out")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the Brewfile to stdout instead of writing it")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite the tier file without asking")
+
+	return cmd
+}
+
+func runPackagesGenerate(tierOverride string, exclude []string, printOnly, force bool) error {
+	if tierOverride != "" && !slices.Contains(packageTiers, tierOverride) {
+		return classErrorf(ErrUsage, "unknown tier: %s (valid: %s)", tierOverride, strings.Join(packageTiers, ", "))
+	}
+	if _, err := exec.LookPath("brew"); err != nil {
+		Fail("Homebrew not installed")
+		return classErrorf(ErrToolMissing, "brew not found in PATH")
+	}
+
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
+	}
+	tier := getPackageTier(tierOverride, blackdotDir)
+	if !slices.Contains(packageTiers, tier) {
+		tier = "full"
+	}
+	brewfilePath := packageTierBrewfile(blackdotDir, tier)
+
+	var stderr bytes.Buffer
+	dumpCmd := exec.Command("brew", "bundle", "dump", "--file=-")
+	dumpCmd.Stderr = &stderr
+	dump, err := dumpCmd.Output()
+	if err != nil {
+		return fmt.Errorf("brew bundle dump failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	existing, err := os.ReadFile(brewfilePath)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content, counts := generateBrewfile(string(dump), string(existing), exclude, tier, time.Now())
+	if printOnly {
+		fmt.Print(content)
+		return nil
+	}
+
+	if exists && !force {
+		Warn("%s already exists and will be replaced; comments and OS blocks in it are not kept", brewfilePath)
+		if !Confirm("Overwrite?") {
+			Info("Cancelled")
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(brewfilePath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(brewfilePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", brewfilePath, err)
+	}
+
+	Pass("Wrote %s (%s tier)", brewfilePath, tier)
+	for _, section := range brewDumpSections {
+		if n := counts[section.kind]; n > 0 {
+			fmt.Printf("  %-28s %d\n", section.title+":", n)
+		}
+	}
+	if counts["excluded"] > 0 {
+		Dim.Printf("  Excluded: %d\n", counts["excluded"])
+	}
+	return nil
+}
+
+// packageTierBrewfile returns the Brewfile a tier is kept in
+func packageTierBrewfile(blackdotDir, tier string) string {
+	switch tier {
+	case "minimal":
+		return filepath.Join(blackdotDir, "brew", "Brewfile.minimal")
+	case "enhanced":
+		return filepath.Join(blackdotDir, "brew", "Brewfile.enhanced")
+	}
+	return filepath.Join(blackdotDir, "brew", "Brewfile")
+}
+
+// generateBrewfile turns brew bundle dump output into a sectioned Brewfile.
+// tap lines from existing come first, verbatim; brew and cask entries
+// named in exclude are dropped. counts has the entries written per kind,
+// and "excluded".
+func generateBrewfile(dump, existing string, exclude []string, tier string, now time.Time) (string, map[string]int) {
+	entries := make(map[string][]string)
+	var otherKinds []string
+	counts := make(map[string]int)
+
+	var taps []string
+	for _, line := range strings.Split(existing, "\n") {
+		line = strings.TrimSpace(line)
+		if m := brewDumpLinePattern.FindStringSubmatch(line); m != nil && m[1] == "tap" && !slices.Contains(taps, m[2]) {
+			taps = append(taps, m[2])
+			entries["tap"] = append(entries["tap"], line)
+		}
+	}
+
+	for _, line := range strings.Split(dump, "\n") {
+		line = strings.TrimSpace(line)
+		m := brewDumpLinePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		kind, name := m[1], m[2]
+		switch {
+		case kind == "tap" && slices.Contains(taps, name):
+			continue
+		case kind == "tap":
+			taps = append(taps, name)
+		case (kind == "brew" || kind == "cask") && slices.Contains(exclude, name):
+			counts["excluded"]++
+			continue
+		}
+		if _, ok := entries[kind]; !ok && !isBrewDumpSection(kind) {
+			otherKinds = append(otherKinds, kind)
+		}
+		entries[kind] = append(entries[kind], line)
+	}
+
+	var b strings.Builder
+	rule := "# " + strings.Repeat("=", 60) + "\n"
+	b.WriteString(rule)
+	fmt.Fprintf(&b, "# %s - %s tier\n", filepath.Base(packageTierBrewfile("", tier)), tier)
+	b.WriteString(rule)
+	fmt.Fprintf(&b, "# Generated by blackdot packages generate on %s\n", now.Format("2006-01-02"))
+	b.WriteString("# from 'brew bundle dump'. Edit freely; regenerating replaces\n")
+	b.WriteString("# everything except tap lines.\n")
+	b.WriteString(rule)
+
+	write := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n# %s\n", title)
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	}
+	for _, section := range brewDumpSections {
+		write(section.title, entries[section.kind])
+		counts[section.kind] = len(entries[section.kind])
+	}
+	var other []string
+	for _, kind := range otherKinds {
+		other = append(other, entries[kind]...)
+	}
+	write("Other", other)
+	return b.String(), counts
+}
+
+func isBrewDumpSection(kind string) bool {
+	for _, section := range brewDumpSections {
+		if section.kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// packageTiers are the Brewfile tiers, smallest first
+var packageTiers = []string{"minimal", "enhanced", "full"}
+
+// brewBundleMissingPattern matches 'brew bundle check --verbose' lines such as
+// "→ Formula jq needs to be installed or updated."
+var brewBundleMissingPattern = regexp.MustCompile(`^(?:→\s*)?(Formula|Cask|Tap|App|Whalebrew|VSCode extension) (\S+) needs to be`)
+
+// brewInstallSummary compares what a tier needed before and after install
+type brewInstallSummary struct {
+	Installed      []string // missing before, present after
+	AlreadyPresent []string
+	StillMissing   []string
+}
+
+func newPackagesInstallCmd() *cobra.Command {
+	var tierOverride, platformOverride string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install a Brewfile tier with brew bundle",
+		Long: `Install every package in a Brewfile tier with 'brew bundle'.
+
+brew's output is streamed as it runs, followed by a summary of which
+packages were installed and which were already present. The tier comes
+from --tier, then packages.tier in config.json, then BREWFILE_TIER, and
+defaults to full.
+
+With --dry-run, runs 'brew bundle check' instead and lists what is
+missing without installing anything.
+
+Examples:
+  blackdot packages install                  # Install the saved tier
+  blackdot packages install --tier minimal   # Install the minimal tier
+  blackdot packages install --dry-run        # Show what would be installed`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPackagesInstall(tierOverride, platformOverride, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&tierOverride, "tier", "t", "", "Tier to install ("+strings.Join(packageTiers, "/")+")")
+	cmd.Flags().StringVar(&platformOverride, "platform", "", "Platform for the summary (darwin/linux, default: current OS)")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Run 'brew bundle check' and list missing packages without installing")
+
+	return cmd
+}
+
+func runPackagesInstall(tierOverride, platformOverride string, dryRun bool) error {
+	if tierOverride != "" && !slices.Contains(packageTiers, tierOverride) {
+		return fmt.Errorf("unknown tier: %s (valid: %s)", tierOverride, strings.Join(packageTiers, ", "))
+	}
+	platform, err := normalizeBrewPlatform(platformOverride)
+	if err != nil {
+		return err
+	}
+
+	if _, err := exec.LookPath("brew"); err != nil {
+		Fail("Homebrew not installed")
+		PrintHint("Install with: /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)\"")
+		return classErrorf(ErrToolMissing, "brew not found in PATH")
+	}
+
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
+	}
+	tier, brewfilePath, err := resolvePackageBrewfile(tierOverride, blackdotDir)
+	if err != nil {
+		return err
+	}
+
+	pkgs, err := parseBrewfilePackages(brewfilePath)
+	if err != nil {
+		return fmt.Errorf("parsing Brewfile: %w", err)
+	}
+	formulas, casks, _ := filterBrewfilePackages(pkgs, platform)
+	wanted := append(prefixPackages("formula", formulas), prefixPackages("cask", casks)...)
+
+	PrintHeader(fmt.Sprintf("Packages: %s tier", tier))
+	Dim.Printf("Brewfile: %s\n\n", brewfilePath)
+
+	if dryRun {
+		var out bytes.Buffer
+		check := exec.Command("brew", "bundle", "check", "--verbose", "--file="+brewfilePath)
+		check.Stdout = &out
+		check.Stderr = &out
+		if check.Run() == nil {
+			Pass("All packages in the %s tier are installed", tier)
+			return nil
+		}
+		missing := parseBrewBundleCheck(out.String())
+		if len(missing) == 0 {
+			// Older brew without per-package lines: fall back to our own comparison
+			missing = findMissing(wanted, installedBrewPackages())
+		}
+		DryRun("%d package(s) would be installed:", len(missing))
+		for _, name := range missing {
+			fmt.Printf("  - %s\n", name)
+		}
+		return nil
+	}
+
+	before := installedBrewPackages()
+
+	install := exec.Command("brew", "bundle", "install", "--file="+brewfilePath)
+	install.Stdin = os.Stdin
+	install.Stdout = os.Stdout
+	install.Stderr = os.Stderr
+	installErr := install.Run()
+
+	summary := summarizeBrewInstall(wanted, before, installedBrewPackages())
+	fmt.Println()
+	Pass("Installed: %d", len(summary.Installed))
+	for _, name := range summary.Installed {
+		fmt.Printf("  + %s\n", name)
+	}
+	Info("Already present: %d", len(summary.AlreadyPresent))
+	if len(summary.StillMissing) > 0 {
+		Warn("Still missing: %d", len(summary.StillMissing))
+		for _, name := range summary.StillMissing {
+			fmt.Printf("  - %s\n", name)
+		}
+	}
+
+	if installErr != nil {
+		return fmt.Errorf("brew bundle install failed (%s tier): %w", tier, installErr)
+	}
+	return nil
+}
+
+// installedBrewPackages lists installed formulas and casks as "formula x" /
+// "cask y", matching prefixPackages
+func installedBrewPackages() []string {
+	return append(prefixPackages("formula", getInstalledFormulas()), prefixPackages("cask", getInstalledCasks())...)
+}
+
+// prefixPackages labels names with their kind so formulas and casks that
+// share a name stay distinct
+func prefixPackages(kind string, names []string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = kind + " " + name
+	}
+	return out
+}
+
+// parseBrewBundleCheck returns the packages 'brew bundle check --verbose'
+// reports as missing, e.g. "formula jq"
+func parseBrewBundleCheck(output string) []string {
+	var missing []string
+	for _, line := range strings.Split(output, "\n") {
+		if m := brewBundleMissingPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			missing = append(missing, strings.ToLower(m[1])+" "+m[2])
+		}
+	}
+	return missing
+}
+
+// summarizeBrewInstall sorts wanted packages by what happened to them
+func summarizeBrewInstall(wanted, before, after []string) brewInstallSummary {
+	var s brewInstallSummary
+	missingBefore := findMissing(wanted, before)
+	missingAfter := findMissing(wanted, after)
+	for _, name := range wanted {
+		switch {
+		case !slices.Contains(missingBefore, name):
+			s.AlreadyPresent = append(s.AlreadyPresent, name)
+		case slices.Contains(missingAfter, name):
+			s.StillMissing = append(s.StillMissing, name)
+		default:
+			s.Installed = append(s.Installed, name)
+		}
+	}
+	return s
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseBrewfilePackages verifies directives are tagged by platform
+func TestParseBrewfilePackages(t *testing.T) {
+	content := `tap "homebrew/bundle"
+brew "git"
+brew 'jq'  # comment
+cask "rectangle"
+mas "Xcode", id: 497799835
+brew "pinentry-mac" if OS.mac?
+brew "xclip" unless OS.mac?
+
+if OS.mac?
+  brew "coreutils"
+else
+  brew "util-linux"
+end
+
+if OS.linux?
+  brew "gcc"
+end
+
+# brew "disabled"
+`
+	path := filepath.Join(t.TempDir(), "Brewfile")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write Brewfile: %v", err)
+	}
+
+	pkgs, err := parseBrewfilePackages(path)
+	if err != nil {
+		t.Fatalf("parseBrewfilePackages failed: %v", err)
+	}
+
+	expected := []brewfilePackage{
+		{"brew", "git", ""},
+		{"brew", "jq", ""},
+		{"cask", "rectangle", "darwin"},
+		{"mas", "Xcode", "darwin"},
+		{"brew", "pinentry-mac", "darwin"},
+		{"brew", "xclip", "linux"},
+		{"brew", "coreutils", "darwin"},
+		{"brew", "util-linux", "linux"},
+		{"brew", "gcc", "linux"},
+	}
+	if len(pkgs) != len(expected) {
+		t.Fatalf("expected %d packages, got %d: %+v", len(expected), len(pkgs), pkgs)
+	}
+	for i := range expected {
+		if pkgs[i] != expected[i] {
+			t.Errorf("package %d: expected %+v, got %+v", i, expected[i], pkgs[i])
+		}
+	}
+
+	formulas, casks, skipped := filterBrewfilePackages(pkgs, "linux")
+	if len(formulas) != 5 || len(casks) != 0 || skipped != 4 {
+		t.Errorf("linux: got formulas=%v casks=%v skipped=%d", formulas, casks, skipped)
+	}
+
+	formulas, casks, skipped = filterBrewfilePackages(pkgs, "darwin")
+	if len(formulas) != 4 || len(casks) != 1 || skipped != 3 {
+		t.Errorf("darwin: got formulas=%v casks=%v skipped=%d", formulas, casks, skipped)
+	}
+}
+
+// TestNormalizeBrewPlatform verifies --platform values
+func TestNormalizeBrewPlatform(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"", runtime.GOOS, false},
+		{"darwin", "darwin", false},
+		{"macOS", "darwin", false},
+		{"linux", "linux", false},
+		{"windows", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeBrewPlatform(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeBrewPlatform(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("normalizeBrewPlatform(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// TestParseBrewBundleCheck verifies missing packages are read from brew's output
+func TestParseBrewBundleCheck(t *testing.T) {
+	output := `brew bundle can't satisfy your Brewfile's dependencies.
+→ Formula jq needs to be installed or updated.
+→ Cask rectangle needs to be installed or updated.
+→ Tap homebrew/cask-fonts needs to be tapped.
+Satisfy missing dependencies with ` + "`brew bundle install`" + `.
+`
+	want := []string{"formula jq", "cask rectangle", "tap homebrew/cask-fonts"}
+	if got := parseBrewBundleCheck(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBrewBundleCheck() = %v, want %v", got, want)
+	}
+}
+
+// TestSummarizeBrewInstall verifies installed vs already-present accounting
+func TestSummarizeBrewInstall(t *testing.T) {
+	wanted := []string{"formula git", "formula jq", "formula fd", "cask rectangle"}
+	before := []string{"formula git", "formula zsh"}
+	after := []string{"formula git", "formula zsh", "formula jq", "cask rectangle"}
+
+	got := summarizeBrewInstall(wanted, before, after)
+	want := brewInstallSummary{
+		Installed:      []string{"formula jq", "cask rectangle"},
+		AlreadyPresent: []string{"formula git"},
+		StillMissing:   []string{"formula fd"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizeBrewInstall() = %+v, want %+v", got, want)
+	}
+}
+
+// TestPackagesInstallErrors verifies unknown tiers and a missing brew fail clearly
+func TestPackagesInstallErrors(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	if err := runPackagesInstall("developer", "", false); err == nil || !strings.Contains(err.Error(), "unknown tier") {
+		t.Errorf("unknown tier error = %v", err)
+	}
+	if err := runPackagesInstall("minimal", "", true); err == nil || !strings.Contains(err.Error(), "brew not found") {
+		t.Errorf("missing brew error = %v", err)
+	}
+}
+
+// TestPackagesDiff verifies manifests from a file or stdin are validated
+// and compared by package id
+func TestPackagesDiff(t *testing.T) {
+	manifest := func(ids ...string) string {
+		var pkgs []string
+		for _, id := range ids {
+			pkgs = append(pkgs, `{"id": "`+id+`"}`)
+		}
+		return `{"sources": [{"packages": [` + strings.Join(pkgs, ", ") + `], "sourceDetails": {"name": "winget"}}]}`
+	}
+	repo := filepath.Join(t.TempDir(), "packages.json")
+	if err := os.WriteFile(repo, []byte(manifest("Git.Git", "junegunn.fzf", "stedolan.jq")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := runPackagesDiff(strings.NewReader(manifest("Git.Git", "stedolan.jq", "sharkdp.bat")), &out, "-", repo); err != nil {
+		t.Fatalf("runPackagesDiff() error = %v", err)
+	}
+	for _, want := range []string{"+++ stdin", "- junegunn.fzf", "+ sharkdp.bat", "1 added, 1 removed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runPackagesDiff(nil, &out, repo, repo); err != nil || !strings.Contains(out.String(), "matches") {
+		t.Errorf("same manifest: err = %v, output = %q", err, out.String())
+	}
+
+	for input, want := range map[string]string{
+		`{"sources": [`:                     "not valid JSON",
+		`{"packages": []}`:                  `no "sources"`,
+		`{"sources": [{"packages": [{}]}]}`: `package 1 has no "id"`,
+		manifest("Git.Git", "Git.Git"):      "listed twice",
+	} {
+		err := runPackagesDiff(strings.NewReader(input), &out, "-", repo)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("input %s: error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+// TestGenerateBrewfile verifies taps are kept, excludes dropped and entries grouped
+func TestGenerateBrewfile(t *testing.T) {
+	dump := `tap "homebrew/bundle"
+tap "hashicorp/tap"
+brew "git"
+brew "jq"
+brew "postgresql@16", restart_service: true
+cask "docker"
+mas "Xcode", id: 497799835
+vscode "golang.go"
+go "golang.org/x/tools/gopls"
+`
+	existing := `# Old header
+tap "acme/tools", "https://git.example.com/acme/homebrew-tools"
+tap "homebrew/bundle"
+brew "old-thing"
+`
+	now := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	got, counts := generateBrewfile(dump, existing, []string{"postgresql@16", "docker"}, "enhanced", now)
+
+	want := `# ============================================================
+# Brewfile.enhanced - enhanced tier
+# ============================================================
+# Generated by blackdot packages generate on 2026-10-15
+# from 'brew bundle dump'. Edit freely; regenerating replaces
+# everything except tap lines.
+# ============================================================
+
+# Taps
+tap "acme/tools", "https://git.example.com/acme/homebrew-tools"
+tap "homebrew/bundle"
+tap "hashicorp/tap"
+
+# Formulae
+brew "git"
+brew "jq"
+
+# Mac App Store (macOS only)
+mas "Xcode", id: 497799835
+
+# VS Code extensions
+vscode "golang.go"
+
+# Other
+go "golang.org/x/tools/gopls"
+`
+	if got != want {
+		t.Errorf("generateBrewfile() =\n%s\nwant:\n%s", got, want)
+	}
+	wantCounts := map[string]int{"tap": 3, "brew": 2, "cask": 0, "mas": 1, "vscode": 1, "excluded": 2}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("counts = %v, want %v", counts, wantCounts)
+	}
+
+	if path := packageTierBrewfile("/repo", "full"); path != filepath.Join("/repo", "brew", "Brewfile") {
+		t.Errorf("packageTierBrewfile(full) = %s", path)
+	}
+}
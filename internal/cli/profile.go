@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// profileName is set by the global --profile flag
+var profileName string
+
+// profilesPath returns ~/.config/blackdot/profiles.json
+func profilesPath() string {
+	return filepath.Join(ConfigDir(), "profiles.json")
+}
+
+// loadProfiles reads the name -> directory map. A missing file is no profiles.
+func loadProfiles() (map[string]string, error) {
+	profiles := map[string]string{}
+	data, err := os.ReadFile(profilesPath())
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", profilesPath(), err)
+	}
+	return profiles, nil
+}
+
+// saveProfiles writes the profile map, creating the config directory if needed
+func saveProfiles(profiles map[string]string) error {
+	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(profilesPath(), append(data, '\n'), 0644)
+}
+
+// sortedProfileNames returns the profile names in display order
+func sortedProfileNames(profiles map[string]string) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveProfile returns the directory registered for name
+func resolveProfile(name string) (string, error) {
+	profiles, err := loadProfiles()
+	if err != nil {
+		return "", err
+	}
+	dir, ok := profiles[name]
+	if !ok {
+		names := sortedProfileNames(profiles)
+		if len(names) == 0 {
+			return "", fmt.Errorf("unknown profile: %s (no profiles defined; add one with 'blackdot profile add')", name)
+		}
+		return "", fmt.Errorf("unknown profile: %s (valid: %s)", name, strings.Join(names, ", "))
+	}
+	return dir, nil
+}
+
+// applyProfile points blackdotDir at the --profile directory. BLACKDOT_DIR is
+// exported as well so commands that read the env var, and the scripts they
+// run, see the same tree.
+func applyProfile() error {
+	if profileName == "" {
+		return nil
+	}
+	dir, err := resolveProfile(profileName)
+	if err != nil {
+		return err
+	}
+	blackdotDir = dir
+	return os.Setenv("BLACKDOT_DIR", dir)
+}
+
+// expandDirArg makes a directory argument absolute, expanding a leading ~
+func expandDirArg(dir string) (string, error) {
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+	}
+	return filepath.Abs(dir)
+}
+
+func newProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "profile",
+		Aliases: []string{"profiles"},
+		Short:   "Manage named blackdot directories",
+		Long: `Manage named blackdot directories (profiles).
+
+Profiles map a name to a blackdot directory in ~/.config/blackdot/profiles.json.
+Select one for any command with the global --profile flag; it takes precedence
+over BLACKDOT_DIR and ~/.blackdot.
+
+Examples:
+  blackdot profile add work ~/src/work-dotfiles
+  blackdot profile list
+  blackdot --profile work lint
+  blackdot profile remove work`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfileList()
+		},
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:     "list",
+			Aliases: []string{"ls"},
+			Short:   "List profiles",
+			Args:    cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runProfileList()
+			},
+		},
+		&cobra.Command{
+			Use:   "add <name> <dir>",
+			Short: "Add or update a profile",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runProfileAdd(args[0], args[1])
+			},
+		},
+		&cobra.Command{
+			Use:     "remove <name>",
+			Aliases: []string{"rm"},
+			Short:   "Remove a profile",
+			Args:    cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runProfileRemove(args[0])
+			},
+		},
+	)
+
+	return cmd
+}
+
+// runProfileList prints each profile, marking the one in use
+func runProfileList() error {
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	PrintHeader("Profiles")
+	if len(profiles) == 0 {
+		Dim.Println("  No profiles defined")
+		PrintHint("Add one with: blackdot profile add <name> <dir>")
+		return nil
+	}
+
+	for _, name := range sortedProfileNames(profiles) {
+		dir := profiles[name]
+		marker := "  "
+		if filepath.Clean(dir) == filepath.Clean(BlackdotDir()) {
+			marker = Green.Sprint("* ")
+		}
+		fmt.Printf("%s%-12s %s", marker, name, dir)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			Yellow.Print(" (missing)")
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	Dim.Printf("Active directory: %s\n", BlackdotDir())
+	return nil
+}
+
+// runProfileAdd registers name -> dir, replacing any existing mapping
+func runProfileAdd(name, dir string) error {
+	if name == "" || strings.ContainsAny(name, "/\\ \t") {
+		return fmt.Errorf("invalid profile name: %q", name)
+	}
+
+	abs, err := expandDirArg(dir)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		Fail("Not a directory: %s", abs)
+		return fmt.Errorf("profile directory does not exist: %s", abs)
+	}
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	old, existed := profiles[name]
+	profiles[name] = abs
+	if err := saveProfiles(profiles); err != nil {
+		return err
+	}
+
+	if existed && old != abs {
+		Pass("Updated profile %s: %s (was %s)", name, abs, old)
+	} else {
+		Pass("Added profile %s: %s", name, abs)
+	}
+	return nil
+}
+
+// runProfileRemove deletes a profile mapping; the directory is left alone
+func runProfileRemove(name string) error {
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	if _, ok := profiles[name]; !ok {
+		return fmt.Errorf("unknown profile: %s", name)
+	}
+	delete(profiles, name)
+	if err := saveProfiles(profiles); err != nil {
+		return err
+	}
+	Pass("Removed profile %s", name)
+	return nil
+}
@@ -233,7 +233,7 @@ func rollbackRestore(specificBackup string, skipConfirm bool, dryRun bool) error
 			fmt.Println()
 			fmt.Println("Available backups:")
 			rollbackList()
-			return fmt.Errorf("backup not found: %s", specificBackup)
+			return classErrorf(ErrFileNotFound, "backup not found: %s", specificBackup)
 		}
 		backupID = specificBackup
 	} else {
@@ -276,7 +276,7 @@ func rollbackRestore(specificBackup string, skipConfirm bool, dryRun bool) error
 
 	// Confirm we have a valid path
 	if _, err := os.Stat(backupPath); err != nil {
-		return fmt.Errorf("backup not found: %s", backupPath)
+		return classErrorf(ErrFileNotFound, "backup not found: %s", backupPath)
 	}
 
 	// In dry-run mode, skip the warning and confirmation
@@ -6,9 +6,9 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -20,8 +20,9 @@ var (
 	dateStr    = "unknown"
 
 	// Global flags
-	verbose bool
-	force   bool
+	verbose    bool
+	force      bool
+	printPaths bool
 
 	// blackdotDir is resolved at init
 	blackdotDir string
@@ -40,6 +41,24 @@ customize the available functionality.
 Run 'blackdot help' for detailed command information.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupColor(colorMode); err != nil {
+			return err
+		}
+		if err := setupLogging(logLevel, logFile); err != nil {
+			return err
+		}
+		if httpRetries < 0 {
+			return fmt.Errorf("--retries must not be negative")
+		}
+		if err := applyProfile(); err != nil {
+			return err
+		}
+		if printPaths {
+			return printResolvedPaths(os.Stderr)
+		}
+		return nil
+	},
 	// Show help when called without subcommand
 	Run: func(cmd *cobra.Command, args []string) {
 		customHelpFunc(cmd, args)
@@ -55,13 +74,17 @@ func SetVersionInfo(version, commit, date string) {
 
 // Execute runs the root command
 func Execute() error {
+	classifyUsageOnce.Do(func() { classifyUsageErrors(rootCmd) })
 	err := rootCmd.Execute()
+	// Errors are already printed to stderr below; only record them in a log file
+	if err != nil && logFile != "" {
+		logger.Error("command failed", "error", err)
+	}
+	closeLogging()
 	if err != nil {
 		// Check if it's an unknown command error vs execution error
 		errStr := err.Error()
-		if strings.Contains(errStr, "unknown command") ||
-			strings.Contains(errStr, "unknown flag") ||
-			strings.Contains(errStr, "unknown shorthand flag") {
+		if isCobraUsageError(err) {
 			// Unknown command/flag - show help hint
 			Red.Fprintf(os.Stderr, "Error: ")
 			fmt.Fprintln(os.Stderr, errStr)
@@ -84,11 +107,19 @@ func init() {
 	// Global flags available to all commands
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVar(&force, "force", false, "bypass feature checks")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "error", "diagnostic log level (error, warn, info, debug)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "write diagnostic logs to a file instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "use the blackdot directory of a named profile")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "colorize output (auto, always, never)")
+	rootCmd.PersistentFlags().IntVar(&httpRetries, "retries", httpRetries, "retry failed network requests this many times")
+	rootCmd.PersistentFlags().BoolVar(&printPaths, "print-paths", false, "print the resolved blackdot, config, and cache directories")
 
 	// Add subcommands
 	rootCmd.AddCommand(
 		newVersionCmd(),
+		newSelfUpdateCmd(),
 		newCompletionCmd(),
+		newCompleteVersionCmd(),
 		newFeaturesCmd(),
 		newConfigCmd(),
 		newDoctorCmd(),
@@ -107,6 +138,7 @@ func init() {
 		newMetricsCmd(),
 		newPackagesCmd(),
 		newSetupCmd(),
+		newInitCmd(),
 		newSyncCmd(),
 		newUninstallCmd(),
 		// Cross-platform developer tools
@@ -119,11 +151,14 @@ func init() {
 		newShellInitCmd(),
 		// Devcontainer support
 		newDevcontainerCmd(),
+		// Named blackdot directories
+		newProfileCmd(),
 		// Note: migrate command dropped - one-time v2→v3 migration handled by bash
 	)
 }
 
-// initConfig resolves the blackdot directory
+// initConfig resolves the blackdot directory. A --profile selection is
+// applied afterwards in PersistentPreRunE, where it can fail the command.
 func initConfig() {
 	// Check BLACKDOT_DIR env var first
 	blackdotDir = os.Getenv("BLACKDOT_DIR")
@@ -146,6 +181,19 @@ func BlackdotDir() string {
 	return blackdotDir
 }
 
+// resolveBlackdotDir returns the blackdot directory chosen by initConfig or
+// --profile, falling back to ~/.blackdot when neither set one
+func resolveBlackdotDir() (string, error) {
+	if blackdotDir != "" {
+		return blackdotDir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".blackdot"), nil
+}
+
 // ConfigDir returns the config directory (~/.config/blackdot)
 func ConfigDir() string {
 	configHome := os.Getenv("XDG_CONFIG_HOME")
@@ -155,3 +203,26 @@ func ConfigDir() string {
 	}
 	return filepath.Join(configHome, "blackdot")
 }
+
+// CacheDir returns the cache directory (~/.cache/blackdot)
+func CacheDir() string {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		home, _ := os.UserHomeDir()
+		cacheHome = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(cacheHome, "blackdot")
+}
+
+// printResolvedPaths writes the directories a command will use, for
+// --print-paths
+func printResolvedPaths(w io.Writer) error {
+	dir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "blackdot dir: %s\n", dir)
+	fmt.Fprintf(w, "config dir:   %s\n", ConfigDir())
+	fmt.Fprintf(w, "cache dir:    %s\n", CacheDir())
+	return nil
+}
@@ -0,0 +1,380 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// selfUpdateTimeout bounds the release lookup; downloads get longer
+	selfUpdateTimeout = 15 * time.Second
+
+	// selfUpdateDownloadTimeout bounds the binary download
+	selfUpdateDownloadTimeout = 5 * time.Minute
+
+	// selfUpdateChecksumAsset lists sha256 sums for every release asset
+	selfUpdateChecksumAsset = "SHA256SUMS.txt"
+)
+
+// selfUpdateAPIURL is the GitHub API endpoint for the latest release
+var selfUpdateAPIURL = "https://api.github.com/repos/blackwell-systems/blackdot/releases/latest"
+
+// githubRelease is the subset of the GitHub releases API we use
+type githubRelease struct {
+	TagName string               `json:"tag_name"`
+	HTMLURL string               `json:"html_url"`
+	Assets  []githubReleaseAsset `json:"assets"`
+}
+
+type githubReleaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+func newSelfUpdateCmd() *cobra.Command {
+	var checkOnly, skipVerify bool
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update blackdot to the latest release",
+		Long: `Check GitHub releases for a newer blackdot and install it in place.
+
+The binary for this platform is downloaded, verified against the release's
+SHA256SUMS.txt and its minisign signature (the .minisig asset next to it,
+checked with the public key built into blackdot), and atomically swapped
+in for the running executable. Nothing is installed if either check fails.
+
+--skip-verify installs without checking the signature, for mirrors that
+don't carry it or builds without the key; the checksum is still required.
+Only use it when you trust where the binary comes from.
+
+Binaries installed by Homebrew should be upgraded with 'brew upgrade'
+instead; pass --force to replace them anyway. Development builds can't be
+compared against releases and also require --force.
+
+Examples:
+  blackdot self-update               # Update if a newer release exists
+  blackdot self-update --check-only  # Only report whether one exists
+  blackdot self-update --skip-verify # Install without a signature check`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSelfUpdate(checkOnly, skipVerify)
+		},
+	}
+
+	cmd.Flags().BoolVar(&checkOnly, "check-only", false, "Report whether an update is available without installing")
+	cmd.Flags().BoolVar(&skipVerify, "skip-verify", false, "Install without verifying the release signature (not recommended)")
+
+	return cmd
+}
+
+func runSelfUpdate(checkOnly, skipVerify bool) error {
+	release, err := fetchLatestRelease(selfUpdateAPIURL)
+	if err != nil {
+		return fmt.Errorf("checking for updates: %w", err)
+	}
+	latest := release.TagName
+	logger.Debug("latest release", "tag", latest, "current", versionStr)
+
+	isDev := !looksLikeVersion(versionStr)
+	if !isDev && compareVersions(versionStr, latest) >= 0 {
+		Pass("blackdot %s is up to date", versionStr)
+		return nil
+	}
+
+	if isDev {
+		Info("Running a development build (%s); latest release is %s", versionStr, latest)
+	} else {
+		Info("Update available: %s → %s", versionStr, latest)
+	}
+	if release.HTMLURL != "" {
+		Dim.Printf("  %s\n", release.HTMLURL)
+	}
+	if checkOnly {
+		return nil
+	}
+	if isDev && !force {
+		PrintHint("Use --force to replace a development build")
+		return nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating running binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	if strings.Contains(exePath, "/Cellar/") && !force {
+		Warn("blackdot was installed by Homebrew: %s", exePath)
+		PrintHint("Run 'brew upgrade blackdot' (or pass --force to replace it anyway)")
+		return nil
+	}
+
+	assetName := selfUpdateAssetName(runtime.GOOS, runtime.GOARCH)
+	binaryURL, sumsURL, sigURL := "", "", ""
+	for _, a := range release.Assets {
+		switch a.Name {
+		case assetName:
+			binaryURL = a.BrowserDownloadURL
+		case selfUpdateChecksumAsset:
+			sumsURL = a.BrowserDownloadURL
+		case assetName + selfUpdateSignatureSuffix:
+			sigURL = a.BrowserDownloadURL
+		}
+	}
+	if binaryURL == "" {
+		return fmt.Errorf("release %s has no binary for %s/%s (%s)", latest, runtime.GOOS, runtime.GOARCH, assetName)
+	}
+	if sumsURL == "" {
+		return fmt.Errorf("release %s has no %s; refusing to install an unverified binary", latest, selfUpdateChecksumAsset)
+	}
+	if !skipVerify {
+		if selfUpdatePublicKey == "" {
+			return classErrorf(ErrConfigInvalid, "this build of blackdot has no release signing key; refusing to install an unverified binary (use --skip-verify to override)")
+		}
+		if sigURL == "" {
+			return fmt.Errorf("release %s has no %s%s; refusing to install an unverified binary (use --skip-verify to override)", latest, assetName, selfUpdateSignatureSuffix)
+		}
+	}
+
+	client := &http.Client{Timeout: selfUpdateDownloadTimeout}
+	sums, err := httpGetBytes(client, sumsURL, 1<<20)
+	if err != nil {
+		return fmt.Errorf("downloading checksums: %w", err)
+	}
+	expected, err := findChecksum(sums, assetName)
+	if err != nil {
+		return err
+	}
+	var sig []byte
+	if skipVerify {
+		warnUnverifiedUpdate()
+	} else if sig, err = httpGetBytes(client, sigURL, 64<<10); err != nil {
+		return fmt.Errorf("downloading signature: %w", err)
+	}
+
+	Info("Downloading %s...", assetName)
+	tmpPath, err := downloadVerified(client, binaryURL, filepath.Dir(exePath), expected)
+	if err != nil {
+		return err
+	}
+	Pass("Checksum verified")
+	if !skipVerify {
+		trusted, err := verifyMinisign(selfUpdatePublicKey, tmpPath, sig)
+		if err != nil {
+			os.Remove(tmpPath)
+			return fmt.Errorf("%s: %w; refusing to install", assetName, err)
+		}
+		Pass("Signature verified")
+		Dim.Printf("  %s\n", trusted)
+	}
+
+	if err := replaceExecutable(tmpPath, exePath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	Pass("Updated blackdot to %s (%s)", latest, exePath)
+	return nil
+}
+
+// warnUnverifiedUpdate makes sure --skip-verify is never silent
+func warnUnverifiedUpdate() {
+	banner := color.New(color.FgRed, color.Bold)
+	Separator()
+	banner.Fprintln(os.Stderr, "WARNING: --skip-verify is set; the release signature is NOT checked.")
+	banner.Fprintln(os.Stderr, "The binary is only compared against SHA256SUMS.txt from the same source,")
+	banner.Fprintln(os.Stderr, "so a tampered release or mirror would be installed without complaint.")
+	Separator()
+}
+
+// selfUpdateAssetName matches the binary names produced by the release workflow
+func selfUpdateAssetName(goos, goarch string) string {
+	name := fmt.Sprintf("blackdot-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// fetchLatestRelease queries the GitHub releases API
+func fetchLatestRelease(url string) (*githubRelease, error) {
+	client := &http.Client{Timeout: selfUpdateTimeout}
+	data, err := httpGetBytes(client, url, 4<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("invalid release response: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("release response has no tag")
+	}
+	return &release, nil
+}
+
+// httpGetBytes fetches a URL, failing on non-200 responses or bodies over limit
+func httpGetBytes(client *http.Client, url string, limit int64) ([]byte, error) {
+	resp, err := httpDoWithRetry(context.Background(), client, http.MethodGet, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
+
+// findChecksum returns the sha256 for name from sha256sum-format output
+func findChecksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s in %s", name, selfUpdateChecksumAsset)
+}
+
+// downloadVerified downloads url into a temp file in dir (so the final rename
+// stays on one filesystem) and checks its sha256. The temp path is returned
+// only if the checksum matches.
+func downloadVerified(client *http.Client, url, dir, expected string) (string, error) {
+	resp, err := httpDoWithRetry(context.Background(), client, http.MethodGet, url)
+	if err != nil {
+		return "", fmt.Errorf("downloading update: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading update: HTTP %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".blackdot-update-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temp file (is %s writable?): %w", dir, err)
+	}
+
+	hash := sha256.New()
+	_, copyErr := io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	closeErr := tmp.Close()
+	if copyErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		if copyErr != nil {
+			return "", fmt.Errorf("downloading update: %w", copyErr)
+		}
+		return "", fmt.Errorf("writing update: %w", closeErr)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return tmp.Name(), nil
+}
+
+// replaceExecutable atomically moves newPath over exePath. Windows can't
+// overwrite a running executable, so the old one is renamed aside first.
+func replaceExecutable(newPath, exePath string) error {
+	if err := os.Chmod(newPath, 0755); err != nil {
+		return fmt.Errorf("setting permissions: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := exePath + ".old"
+		os.Remove(oldPath)
+		if err := os.Rename(exePath, oldPath); err != nil {
+			return fmt.Errorf("moving old binary aside: %w", err)
+		}
+		if err := os.Rename(newPath, exePath); err != nil {
+			os.Rename(oldPath, exePath)
+			return fmt.Errorf("installing update: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.Rename(newPath, exePath); err != nil {
+		return fmt.Errorf("installing update: %w", err)
+	}
+	return nil
+}
+
+// looksLikeVersion reports whether v is a release version like v1.2.3
+func looksLikeVersion(v string) bool {
+	v = strings.TrimPrefix(v, "v")
+	if v == "" || v[0] < '0' || v[0] > '9' {
+		return false
+	}
+	return true
+}
+
+// compareVersions compares semver-style versions ("v4.0.0", "4.0.0-rc6"),
+// returning -1, 0, or 1. A release sorts after its prereleases, and
+// prerelease numbers compare numerically (rc10 > rc9).
+func compareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			return cmp.Compare(x, y)
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	// Compare prerelease tags by text prefix, then trailing number
+	aText, aNum := splitTrailingNumber(aPre)
+	bText, bNum := splitTrailingNumber(bPre)
+	if aText != bText {
+		return strings.Compare(aText, bText)
+	}
+	return cmp.Compare(aNum, bNum)
+}
+
+func splitTrailingNumber(s string) (string, int) {
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+	n, _ := strconv.Atoi(s[i:])
+	return s[:i], n
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// selfUpdateSignatureSuffix is appended to an asset name for its minisign
+// signature, e.g. blackdot-linux-amd64.minisig
+const selfUpdateSignatureSuffix = ".minisig"
+
+// selfUpdatePublicKey is the minisign public key release binaries are
+// signed with (the base64 line of minisign.pub). Release builds embed it
+// with -ldflags "-X github.com/blackwell-systems/blackdot/internal/cli.selfUpdatePublicKey=...";
+// a build without it can't verify updates and needs --skip-verify.
+var selfUpdatePublicKey = ""
+
+// minisignSignature is a parsed .minisig file
+type minisignSignature struct {
+	algorithm       string // "Ed" signs the file, "ED" its BLAKE2b-512 hash
+	keyID           [8]byte
+	signature       []byte
+	trustedComment  string
+	globalSignature []byte
+}
+
+// parseMinisignPublicKey decodes a minisign public key, either the base64
+// line alone or a whole minisign.pub with its comment line
+func parseMinisignPublicKey(s string) (keyID [8]byte, key ed25519.PublicKey, err error) {
+	line := ""
+	for _, l := range strings.Split(strings.TrimSpace(s), "\n") {
+		if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "untrusted comment:") {
+			line = l
+			break
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(data) != 2+8+ed25519.PublicKeySize || string(data[:2]) != "Ed" {
+		return keyID, nil, fmt.Errorf("invalid minisign public key")
+	}
+	copy(keyID[:], data[2:10])
+	return keyID, ed25519.PublicKey(data[10:]), nil
+}
+
+// parseMinisignSignature decodes the four lines of a .minisig file
+func parseMinisignSignature(data []byte) (*minisignSignature, error) {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], "untrusted comment:") {
+		return nil, fmt.Errorf("invalid minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid minisign signature")
+	}
+	trusted, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return nil, fmt.Errorf("invalid minisign signature: no trusted comment")
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid minisign signature: bad trusted comment signature")
+	}
+
+	parsed := &minisignSignature{
+		algorithm:       string(sig[:2]),
+		signature:       sig[10:],
+		trustedComment:  trusted,
+		globalSignature: global,
+	}
+	copy(parsed.keyID[:], sig[2:10])
+	if parsed.algorithm != "Ed" && parsed.algorithm != "ED" {
+		return nil, fmt.Errorf("unsupported minisign signature algorithm %q", parsed.algorithm)
+	}
+	return parsed, nil
+}
+
+// verifyMinisign checks sigData, a .minisig file, against the file at path
+// and publicKey, and returns the signature's trusted comment. Both the file
+// signature and the one over the trusted comment must be valid.
+func verifyMinisign(publicKey, path string, sigData []byte) (string, error) {
+	keyID, key, err := parseMinisignPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sig, err := parseMinisignSignature(sigData)
+	if err != nil {
+		return "", err
+	}
+	if sig.keyID != keyID {
+		return "", fmt.Errorf("signature is from key %s, expected %s", minisignKeyIDString(sig.keyID), minisignKeyIDString(keyID))
+	}
+
+	var message []byte
+	if sig.algorithm == "ED" {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		hash, _ := blake2b.New512(nil)
+		_, err = io.Copy(hash, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		message = hash.Sum(nil)
+	} else if message, err = os.ReadFile(path); err != nil {
+		return "", err
+	}
+
+	if !ed25519.Verify(key, message, sig.signature) {
+		return "", fmt.Errorf("signature verification failed")
+	}
+	global := append(bytes.Clone(sig.signature), sig.trustedComment...)
+	if !ed25519.Verify(key, global, sig.globalSignature) {
+		return "", fmt.Errorf("trusted comment signature verification failed")
+	}
+	return sig.trustedComment, nil
+}
+
+// minisignKeyIDString formats a key ID the way minisign prints it
+func minisignKeyIDString(id [8]byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
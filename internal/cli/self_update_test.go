@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// TestCompareVersions verifies semver and prerelease ordering
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.2.3", "1.2.3", 0},
+		{"v1.2.3", "v1.2.4", -1},
+		{"v1.10.0", "v1.9.9", 1},
+		{"v2.0", "v2.0.0", 0},
+		{"v4.0.0-rc6", "v4.0.0", -1},
+		{"v4.0.0", "v4.0.0-rc6", 1},
+		{"v4.0.0-rc10", "v4.0.0-rc9", 1},
+		{"v4.0.0-beta1", "v4.0.0-rc1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.expected {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+// TestLooksLikeVersion verifies development builds are recognized
+func TestLooksLikeVersion(t *testing.T) {
+	for v, expected := range map[string]bool{"v1.0.0": true, "4.0.0-rc6": true, "dev": false, "": false} {
+		if got := looksLikeVersion(v); got != expected {
+			t.Errorf("looksLikeVersion(%q) = %v, want %v", v, got, expected)
+		}
+	}
+}
+
+// TestFindChecksum verifies lookups in the release SHA256SUMS.txt format
+func TestFindChecksum(t *testing.T) {
+	sums := []byte(`# Source Archives
+aaaa  blackdot-4.0.0.tar.gz
+
+# Go Binaries
+BBBB  blackdot-linux-amd64
+cccc *blackdot-windows-amd64.exe
+`)
+
+	if got, err := findChecksum(sums, "blackdot-linux-amd64"); err != nil || got != "bbbb" {
+		t.Errorf("expected bbbb, got %q (%v)", got, err)
+	}
+	if got, err := findChecksum(sums, "blackdot-windows-amd64.exe"); err != nil || got != "cccc" {
+		t.Errorf("expected cccc, got %q (%v)", got, err)
+	}
+	if _, err := findChecksum(sums, "blackdot-darwin-arm64"); err == nil {
+		t.Error("expected error for missing asset")
+	}
+}
+
+// TestDownloadVerifiedAndReplace verifies checksum enforcement and the binary swap
+func TestDownloadVerifiedAndReplace(t *testing.T) {
+	payload := []byte("#!/bin/sh\necho new\n")
+	sum := sha256.Sum256(payload)
+	expected := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "blackdot")
+	if err := os.WriteFile(exePath, []byte("old"), 0755); err != nil {
+		t.Fatalf("failed to write binary: %v", err)
+	}
+
+	if _, err := downloadVerified(server.Client(), server.URL, dir, "deadbeef"); err == nil {
+		t.Fatal("expected checksum mismatch")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temp file left behind after mismatch: %v", entries)
+	}
+
+	tmpPath, err := downloadVerified(server.Client(), server.URL, dir, expected)
+	if err != nil {
+		t.Fatalf("downloadVerified failed: %v", err)
+	}
+	if err := replaceExecutable(tmpPath, exePath); err != nil {
+		t.Fatalf("replaceExecutable failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(exePath)
+	if string(data) != string(payload) {
+		t.Errorf("binary not replaced, got %q", data)
+	}
+	if info, _ := os.Stat(exePath); info.Mode().Perm()&0100 == 0 {
+		t.Error("replaced binary is not executable")
+	}
+}
+
+// TestRunSelfUpdateCheckOnly verifies --check-only reports without installing
+func TestRunSelfUpdateCheckOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name": "v99.0.0", "assets": []}`))
+	}))
+	defer server.Close()
+
+	origURL, origVersion := selfUpdateAPIURL, versionStr
+	selfUpdateAPIURL, versionStr = server.URL, "v1.0.0"
+	defer func() { selfUpdateAPIURL, versionStr = origURL, origVersion }()
+
+	// No assets: installing would fail, so success means nothing was attempted
+	if err := runSelfUpdate(true, false); err != nil {
+		t.Errorf("check-only should succeed, got %v", err)
+	}
+	if err := runSelfUpdate(false, false); err == nil {
+		t.Error("expected error for release without a binary")
+	}
+}
+
+// TestHTTPDoWithRetry verifies which failures are retried and when retrying stops
+func TestHTTPDoWithRetry(t *testing.T) {
+	origDelay, origRetries := httpRetryBaseDelay, httpRetries
+	httpRetryBaseDelay, httpRetries = time.Millisecond, 2
+	defer func() { httpRetryBaseDelay, httpRetries = origDelay, origRetries }()
+
+	tests := []struct {
+		name     string
+		statuses []int // response per attempt; the last one repeats
+		want     int
+		attempts int
+	}{
+		{"success", []int{200}, 200, 1},
+		{"5xx then success", []int{503, 502, 200}, 200, 3},
+		{"5xx exhausts retries", []int{500}, 500, 3},
+		{"4xx not retried", []int{404}, 404, 1},
+		{"5xx then 4xx", []int{503, 403}, 403, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1)) - 1
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses)-1)])
+			}))
+			defer server.Close()
+
+			resp, err := httpDoWithRetry(context.Background(), server.Client(), http.MethodGet, server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got := int(attempts.Load()); resp.StatusCode != tt.want || got != tt.attempts {
+				t.Errorf("got HTTP %d after %d attempts, want %d after %d", resp.StatusCode, got, tt.want, tt.attempts)
+			}
+		})
+	}
+
+	// Timeouts are retried; the first handler is still sleeping when the
+	// retry arrives, so the count is shared between goroutines
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	resp, err := httpDoWithRetry(context.Background(), client, http.MethodGet, server.URL)
+	if err != nil || resp.StatusCode != 200 || attempts.Load() != 2 {
+		t.Errorf("timeout: got %v, %v after %d attempts", resp, err, attempts.Load())
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	// No retry when the context deadline can't fit the backoff
+	httpRetryBaseDelay = time.Hour
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	resp, err = httpDoWithRetry(ctx, failing.Client(), http.MethodGet, failing.URL)
+	if err != nil || resp.StatusCode != http.StatusBadGateway || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("deadline: got %v, %v after %s", resp, err, time.Since(start))
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+}
+
+// TestHTTPRetryDelay verifies exponential growth, jitter bounds, and the cap
+func TestHTTPRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 40; attempt++ {
+		full := httpRetryMaxDelay
+		if attempt < 16 {
+			full = min(httpRetryBaseDelay<<attempt, httpRetryMaxDelay)
+		}
+		if d := httpRetryDelay(attempt); d < full/2 || d > full {
+			t.Errorf("attempt %d: delay %s outside [%s, %s]", attempt, d, full/2, full)
+		}
+	}
+}
+
+// minisignTestKey returns a minisign public key line and its private key
+func minisignTestKey(t *testing.T, keyID string) (string, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(append([]byte("Ed"+keyID), pub...)), priv
+}
+
+// minisignTestSign signs data the way minisign -S does
+func minisignTestSign(priv ed25519.PrivateKey, keyID, algorithm string, data []byte, trusted string) []byte {
+	message := data
+	if algorithm == "ED" {
+		sum := blake2b.Sum512(data)
+		message = sum[:]
+	}
+	sig := ed25519.Sign(priv, message)
+	global := ed25519.Sign(priv, append(bytes.Clone(sig), trusted...))
+	return []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append([]byte(algorithm+keyID), sig...)) + "\n" +
+		"trusted comment: " + trusted + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+// TestVerifyMinisign verifies signatures are checked against the key, the
+// file, and the trusted comment
+func TestVerifyMinisign(t *testing.T) {
+	pub, priv := minisignTestKey(t, "12345678")
+	otherPub, _ := minisignTestKey(t, "12345678")
+	path := filepath.Join(t.TempDir(), "blackdot-linux-amd64")
+	payload := []byte("new binary")
+	if err := os.WriteFile(path, payload, 0755); err != nil {
+		t.Fatalf("failed to write binary: %v", err)
+	}
+
+	for _, algorithm := range []string{"ED", "Ed"} {
+		sig := minisignTestSign(priv, "12345678", algorithm, payload, "timestamp:1760000000\tfile:blackdot-linux-amd64")
+		trusted, err := verifyMinisign("untrusted comment: minisign public key\n"+pub+"\n", path, sig)
+		if err != nil {
+			t.Errorf("%s: expected valid signature, got %v", algorithm, err)
+		}
+		if !strings.HasPrefix(trusted, "timestamp:1760000000") {
+			t.Errorf("%s: unexpected trusted comment %q", algorithm, trusted)
+		}
+	}
+
+	sig := minisignTestSign(priv, "12345678", "ED", payload, "file:blackdot-linux-amd64")
+	tests := []struct {
+		name string
+		key  string
+		sig  []byte
+		want string
+	}{
+		{"wrong key", otherPub, sig, "signature verification failed"},
+		{"wrong key ID", pub, minisignTestSign(priv, "87654321", "ED", payload, ""), "signature is from key"},
+		{"tampered file", pub, minisignTestSign(priv, "12345678", "ED", []byte("old binary"), ""), "signature verification failed"},
+		{"tampered comment", pub, bytes.Replace(sig, []byte("file:"), []byte("name:"), 1), "trusted comment signature"},
+		{"garbage", pub, []byte("not a signature"), "invalid minisign signature"},
+		{"bad key", "bm90IGEga2V5", sig, "invalid minisign public key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifyMinisign(tt.key, path, tt.sig)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestRunSelfUpdateRequiresSignature verifies nothing is downloaded without
+// a key and a signature unless --skip-verify is given
+func TestRunSelfUpdateRequiresSignature(t *testing.T) {
+	asset := selfUpdateAssetName(runtime.GOOS, runtime.GOARCH)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "v99.0.0", "assets": [
+			{"name": %q, "browser_download_url": "http://127.0.0.1:1/binary"},
+			{"name": "SHA256SUMS.txt", "browser_download_url": "http://127.0.0.1:1/sums"}]}`, asset)
+	}))
+	defer server.Close()
+
+	pub, _ := minisignTestKey(t, "12345678")
+	origURL, origVersion, origKey := selfUpdateAPIURL, versionStr, selfUpdatePublicKey
+	selfUpdateAPIURL, versionStr = server.URL, "v1.0.0"
+	defer func() { selfUpdateAPIURL, versionStr, selfUpdatePublicKey = origURL, origVersion, origKey }()
+
+	selfUpdatePublicKey = ""
+	if err := runSelfUpdate(false, false); ExitCode(err) != ExitConfigInvalid || !strings.Contains(err.Error(), "no release signing key") {
+		t.Errorf("expected a config error for a build without a key, got %v", err)
+	}
+
+	selfUpdatePublicKey = pub
+	if err := runSelfUpdate(false, false); err == nil || !strings.Contains(err.Error(), asset+".minisig") {
+		t.Errorf("expected an error for a release without a signature, got %v", err)
+	}
+
+	// --skip-verify gets as far as the (unreachable) checksum download
+	if err := runSelfUpdate(false, true); err == nil || !strings.Contains(err.Error(), "downloading checksums") {
+		t.Errorf("expected --skip-verify to go on to the download, got %v", err)
+	}
+}
@@ -84,7 +84,8 @@ The setup wizard will:
   3. Save your preferences for future sessions
 
 Your progress is saved automatically. If interrupted, just
-run 'blackdot setup' again to continue where you left off.`,
+run 'blackdot setup' again to continue where you left off, or
+'blackdot setup status' to see where that is.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runSetup(reset, status)
 		},
@@ -93,6 +94,8 @@ run 'blackdot setup' again to continue where you left off.`,
 	cmd.Flags().BoolVarP(&reset, "reset", "r", false, "Reset state and re-run setup from beginning")
 	cmd.Flags().BoolVarP(&status, "status", "s", false, "Show current setup status only")
 
+	cmd.AddCommand(newSetupStatusCmd())
+
 	return cmd
 }
 
@@ -110,6 +113,9 @@ type SetupConfig struct {
 type SetupState struct {
 	Completed []string `json:"completed,omitempty"`
 	Timestamp string   `json:"timestamp,omitempty"`
+	// Current is the phase the wizard is running; it is still set after an
+	// interrupted run, so setup status can report that phase in progress
+	Current string `json:"current,omitempty"`
 }
 
 type VaultConfig struct {
@@ -135,7 +141,7 @@ func runSetup(reset, statusOnly bool) error {
 	// Load config
 	cfg, err := loadSetupConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return classErrorf(ErrConfigInvalid, "failed to load config: %w", err)
 	}
 
 	// Infer state from existing system
@@ -252,7 +258,14 @@ func runSetup(reset, statusOnly bool) error {
 	for _, phase := range setupPhases {
 		if !isPhaseCompleted(cfg, phase) {
 			if fn, ok := phaseFuncs[phase]; ok {
-				if err := fn(cfg); err != nil {
+				// Record the phase first, so an interrupted run shows it in progress
+				cfg.Setup.Current = phase
+				if err := saveSetupConfig(cfg); err != nil {
+					fmt.Printf("%s Failed to save config: %v\n", yellow("!"), err)
+				}
+				err := fn(cfg)
+				cfg.Setup.Current = ""
+				if err != nil {
 					fmt.Printf("%s Phase %s failed: %v\n", yellow("!"), phase, err)
 					// Continue even if phase fails
 				}
@@ -442,39 +455,7 @@ func inferState(cfg *SetupConfig) {
 
 // showSetupStatus displays current setup status
 func showSetupStatus(cfg *SetupConfig) {
-	green := color.New(color.FgGreen).SprintFunc()
-	yellow := color.New(color.FgYellow).SprintFunc()
-	dim := color.New(color.Faint).SprintFunc()
-	bold := color.New(color.Bold).SprintFunc()
-
-	fmt.Println()
-	fmt.Println(bold("Current Status:"))
-	fmt.Println("───────────────")
-
-	phaseDescs := getPhaseDescriptions()
-	for _, phase := range setupPhases {
-		desc := phaseDescs[phase]
-		if isPhaseCompleted(cfg, phase) {
-			extra := ""
-			switch phase {
-			case "workspace":
-				if cfg.Paths.WorkspaceTarget != "" {
-					extra = fmt.Sprintf(" %s", dim(fmt.Sprintf("(→ %s)", cfg.Paths.WorkspaceTarget)))
-				}
-			case "vault":
-				if cfg.Vault.Backend == "none" {
-					fmt.Printf("  %s %s %s\n", yellow("[⊘]"), titleCase(phase), dim("(Skipped - run 'blackdot vault init')"))
-					continue
-				} else if cfg.Vault.Backend != "" {
-					extra = fmt.Sprintf(" %s", dim(fmt.Sprintf("(%s)", cfg.Vault.Backend)))
-				}
-			}
-			fmt.Printf("  %s %s%s\n", green("[✓]"), titleCase(phase), extra)
-		} else {
-			fmt.Printf("  %s %s %s\n", yellow("[ ]"), titleCase(phase), dim(fmt.Sprintf("(%s)", desc)))
-		}
-	}
-	fmt.Println()
+	printSetupSteps(setupSteps(cfg))
 }
 
 // showProgress displays a progress bar for the current step
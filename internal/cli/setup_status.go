@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Setup step statuses reported by setup status
+const (
+	setupStepComplete   = "complete"
+	setupStepInProgress = "in-progress"
+	setupStepPending    = "pending"
+	setupStepSkipped    = "skipped"
+)
+
+// setupStep is one wizard step as setup status reports it
+type setupStep struct {
+	Step        int    `json:"step"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+	// Inferred marks a step the wizard never ran that looks done anyway
+	Inferred bool `json:"inferred,omitempty"`
+}
+
+// setupStatusReport is setup status --json output
+type setupStatusReport struct {
+	Config    string      `json:"config"`
+	Steps     []setupStep `json:"steps"`
+	Completed int         `json:"completed"`
+	Total     int         `json:"total"`
+	Done      bool        `json:"done"`
+}
+
+// newSetupStatusCmd reports wizard progress without changing anything
+func newSetupStatusCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show setup wizard progress",
+		Long: `Show which of the 7 setup steps are complete, in progress, or pending.
+
+Progress is read from the setup section of ~/.config/blackdot/config.json,
+where the wizard saves it after every step. A step the wizard was running
+when it was interrupted is reported as in-progress; rerun 'blackdot setup'
+to finish it. Steps that look done from the system (a workspace symlink,
+a configured vault backend, ...) count as complete, as they do when the
+wizard resumes.
+
+This only reads state, so it is safe to run anywhere, including from
+scripts and on machines where the wizard hasn't run.
+
+Examples:
+  blackdot setup status
+  blackdot setup status --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadSetupConfig()
+			if err != nil {
+				return classErrorf(ErrConfigInvalid, "failed to load config: %w", err)
+			}
+			steps := setupSteps(cfg)
+			if jsonOutput {
+				return printSetupStatusJSON(steps)
+			}
+			printSetupSteps(steps)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// setupSteps works out each phase's status from the saved progress, with
+// inferred state applied to a copy so nothing is written back
+func setupSteps(cfg *SetupConfig) []setupStep {
+	saved := *cfg
+	saved.Setup.Completed = append([]string(nil), cfg.Setup.Completed...)
+	inferred := saved
+	inferred.Setup.Completed = append([]string(nil), saved.Setup.Completed...)
+	inferState(&inferred)
+
+	phaseDescs := getPhaseDescriptions()
+	var steps []setupStep
+	for i, phase := range setupPhases {
+		step := setupStep{
+			Step:        i + 1,
+			Name:        phase,
+			Description: phaseDescs[phase],
+			Status:      setupStepPending,
+		}
+		switch {
+		case isPhaseCompleted(&inferred, phase):
+			step.Status = setupStepComplete
+			step.Inferred = !isPhaseCompleted(&saved, phase)
+			switch {
+			case phase == "workspace" && cfg.Paths.WorkspaceTarget != "":
+				step.Detail = cfg.Paths.WorkspaceTarget
+			case phase == "vault" && cfg.Vault.Backend == "none":
+				step.Status = setupStepSkipped
+			case phase == "vault":
+				step.Detail = cfg.Vault.Backend
+			}
+		case cfg.Setup.Current == phase:
+			step.Status = setupStepInProgress
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// printSetupSteps prints steps as a checklist
+func printSetupSteps(steps []setupStep) {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	fmt.Println()
+	fmt.Println(bold("Current Status:"))
+	fmt.Println("───────────────")
+
+	for _, step := range steps {
+		name := titleCase(step.Name)
+		switch step.Status {
+		case setupStepComplete:
+			extra := ""
+			if step.Detail != "" {
+				extra = " " + dim(fmt.Sprintf("(%s)", step.Detail))
+			}
+			fmt.Printf("  %s %s%s\n", green("[✓]"), name, extra)
+		case setupStepSkipped:
+			fmt.Printf("  %s %s %s\n", yellow("[⊘]"), name, dim("(Skipped - run 'blackdot vault init')"))
+		case setupStepInProgress:
+			fmt.Printf("  %s %s %s\n", cyan("[~]"), name, dim("(Interrupted - run 'blackdot setup' to resume)"))
+		default:
+			fmt.Printf("  %s %s %s\n", yellow("[ ]"), name, dim(fmt.Sprintf("(%s)", step.Description)))
+		}
+	}
+	fmt.Println()
+}
+
+func printSetupStatusJSON(steps []setupStep) error {
+	report := setupStatusReport{
+		Config: filepath.Join(ConfigDir(), "config.json"),
+		Steps:  steps,
+		Total:  len(steps),
+	}
+	for _, step := range steps {
+		if step.Status == setupStepComplete || step.Status == setupStepSkipped {
+			report.Completed++
+		}
+	}
+	report.Done = report.Completed == report.Total
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -12,6 +12,10 @@ import (
 
 // Custom help template matching ZSH style
 func customHelpFunc(cmd *cobra.Command, args []string) {
+	// Help is printed without running PersistentPreRunE; an invalid value is
+	// reported when a command actually runs
+	_ = setupColor(colorMode)
+
 	// Root command gets special treatment
 	if cmd.Name() == "blackdot" && cmd.Parent() == nil {
 		printRootHelp()
@@ -36,6 +40,7 @@ func printRootHelp() {
 	// Setup & Health (always visible)
 	BoldCyan.Println("Setup & Health:")
 	printCmd("setup", "Interactive setup wizard (recommended)")
+	printCmd("init <dir>", "Scaffold a new blackdot repository")
 	printCmdAlias("status", "s", "Quick visual dashboard")
 	printCmdAlias("doctor", "health", "Run comprehensive health check")
 	printCmd("lint", "Validate shell config syntax")
@@ -85,11 +90,13 @@ func printRootHelp() {
 	printCmd("config set", "Set config value in specific layer")
 	printCmd("config show", "Show where a config value comes from")
 	printCmd("config list", "Show configuration layer status")
+	printCmd("profile", "Manage named blackdot directories")
 	fmt.Println()
 
 	// Templates
 	BoldCyan.Println("Templates:")
 	printCmdAlias("template", "tmpl", "Machine-specific config templates")
+	printCmd("template apply", "Render Go templates to their destinations")
 	fmt.Println()
 
 	// Developer Tools
@@ -126,6 +133,7 @@ func printRootHelp() {
 	BoldCyan.Println("Other Commands:")
 	printCmd("uninstall", "Remove blackdot configuration")
 	printCmd("version", "Show version information")
+	printCmd("self-update", "Update blackdot to the latest release")
 	printCmd("help", "Show this help")
 	fmt.Println()
 
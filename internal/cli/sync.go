@@ -70,7 +70,8 @@ Examples:
   blackdot sync --all             # Sync everything
   blackdot sync Git-Config        # Sync just Git config
   blackdot sync --force-local     # Push all local to vault
-  blackdot sync --force-vault     # Pull all vault to local`,
+  blackdot sync --force-vault     # Pull all vault to local
+  blackdot sync status            # Read-only comparison, no changes`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runSync(args, dryRun, forceLocal, forceVault, verbose, all)
 		},
@@ -82,6 +83,8 @@ Examples:
 	cmd.Flags().BoolVar(&verbose, "verbose", false, "Show detailed comparison info")
 	cmd.Flags().BoolVarP(&all, "all", "a", false, "Sync all syncable items")
 
+	cmd.AddCommand(newSyncStatusCmd())
+
 	return cmd
 }
 
@@ -96,7 +99,7 @@ func runSync(args []string, dryRun, forceLocal, forceVault, verbose, all bool) e
 	// Validate conflicting flags
 	if forceLocal && forceVault {
 		fmt.Printf("%s Cannot use --force-local and --force-vault together\n", red("[ERROR]"))
-		return fmt.Errorf("conflicting flags")
+		return classErrorf(ErrUsage, "conflicting flags")
 	}
 
 	// Determine items to sync
@@ -114,10 +117,9 @@ func runSync(args []string, dryRun, forceLocal, forceVault, verbose, all bool) e
 		}
 	}
 
-	home, _ := os.UserHomeDir()
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		blackdotDir = filepath.Join(home, ".blackdot")
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
 	}
 
 	// Check offline mode
@@ -177,10 +179,7 @@ func runSync(args []string, dryRun, forceLocal, forceVault, verbose, all bool) e
 	failed := 0
 
 	// Get drift state for baseline checksums
-	driftStateFile := filepath.Join(os.Getenv("XDG_CACHE_HOME"), "blackdot", "vault-state.json")
-	if driftStateFile == "/blackdot/vault-state.json" {
-		driftStateFile = filepath.Join(home, ".cache", "blackdot", "vault-state.json")
-	}
+	driftStateFile := getVaultDriftStatePath()
 
 	// Process each item
 	for _, itemName := range itemsToSync {
@@ -272,7 +271,7 @@ func runSync(args []string, dryRun, forceLocal, forceVault, verbose, all bool) e
 		return fmt.Errorf("%d sync operations failed", failed)
 	}
 	if conflicts > 0 && !forceLocal && !forceVault {
-		return fmt.Errorf("%d conflicts detected", conflicts)
+		return classErrorf(ErrConflict, "%d conflicts detected", conflicts)
 	}
 
 	return nil
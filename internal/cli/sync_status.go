@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Sync statuses reported by 'sync status'. Everything except in-sync and
+// absent counts as out of sync.
+const (
+	syncStatusInSync       = "in-sync"
+	syncStatusLocalNewer   = "local-newer"
+	syncStatusVaultNewer   = "vault-newer"
+	syncStatusConflict     = "conflict"      // both sides changed since the last sync
+	syncStatusMissingLocal = "missing-local" // only the vault has it
+	syncStatusMissingVault = "missing-vault" // only the local file exists
+	syncStatusAbsent       = "absent"        // neither side has it
+)
+
+// syncItemStatus is the state of one syncable item
+type syncItemStatus struct {
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	Status        string `json:"status"`
+	LocalModified string `json:"local_modified,omitempty"`
+	VaultModified string `json:"vault_modified,omitempty"`
+}
+
+// syncStatusReport is the --json output of 'sync status'
+type syncStatusReport struct {
+	OutOfSync int              `json:"out_of_sync"`
+	Items     []syncItemStatus `json:"items"`
+}
+
+// vaultIndexEntry is the part of a Bitwarden item 'sync status' compares
+type vaultIndexEntry struct {
+	Name         string    `json:"name"`
+	Notes        string    `json:"notes"`
+	RevisionDate time.Time `json:"revisionDate"`
+}
+
+func newSyncStatusCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "status [items...]",
+		Short: "Show which items differ between local and vault (read-only)",
+		Long: `Compare syncable items against the vault without changing anything.
+
+Each item is reported as:
+  in-sync        local and vault content match
+  local-newer    local changed since the last sync (sync would push)
+  vault-newer    vault changed since the last sync (sync would pull)
+  conflict       both changed since the last sync
+  missing-local  only the vault has it
+  missing-vault  only the local file exists
+
+Without a record of the last sync, modification times decide which side
+is newer. The vault is read with a single 'bw list items' call.
+
+Examples:
+  blackdot sync status
+  blackdot sync status Git-Config
+  blackdot sync status --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSyncStatus(args, jsonOutput)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func runSyncStatus(args []string, jsonOutput bool) error {
+	names := getSyncableItemNames()
+	sort.Strings(names)
+	items := args
+	if len(items) == 0 {
+		items = names
+	}
+	for _, item := range items {
+		if _, ok := syncableItems[item]; !ok {
+			return fmt.Errorf("unknown item: %s (valid: %s)", item, strings.Join(names, ", "))
+		}
+	}
+
+	if os.Getenv("BLACKDOT_OFFLINE") == "1" {
+		return fmt.Errorf("BLACKDOT_OFFLINE=1 - vault status needs vault access (try 'blackdot drift --quick')")
+	}
+
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
+	}
+
+	session, err := getVaultSession(blackdotDir)
+	if err != nil {
+		Fail("Vault not unlocked")
+		PrintHint("Run: export BW_SESSION=\"$(bw unlock --raw)\"")
+		return fmt.Errorf("vault not unlocked")
+	}
+
+	out, err := exec.Command("bw", "list", "items", "--session", session).Output()
+	if err != nil {
+		return fmt.Errorf("listing vault items: %w", err)
+	}
+	index, err := parseVaultIndex(out)
+	if err != nil {
+		return err
+	}
+
+	stateFile := getVaultDriftStatePath()
+	report := syncStatusReport{}
+	for _, name := range items {
+		st := syncItemStatus{Name: name, Path: syncableItems[name]}
+
+		var localSum string
+		var localMod time.Time
+		if data, err := os.ReadFile(st.Path); err == nil {
+			localSum = calcChecksum(string(data))
+			if info, err := os.Stat(st.Path); err == nil {
+				localMod = info.ModTime()
+				st.LocalModified = localMod.UTC().Format(time.RFC3339)
+			}
+		}
+
+		var vaultSum string
+		var vaultMod time.Time
+		if entry, ok := index[name]; ok && entry.Notes != "" {
+			vaultSum = calcChecksum(entry.Notes)
+			vaultMod = entry.RevisionDate
+			if !vaultMod.IsZero() {
+				st.VaultModified = vaultMod.UTC().Format(time.RFC3339)
+			}
+		}
+
+		st.Status = classifySyncItem(localSum, vaultSum, getCachedChecksum(name, stateFile), localMod, vaultMod)
+		if st.Status != syncStatusInSync && st.Status != syncStatusAbsent {
+			report.OutOfSync++
+		}
+		report.Items = append(report.Items, st)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	PrintHeader("Sync Status")
+	Bold.Printf("  %-22s %-15s %-22s %s\n", "ITEM", "STATUS", "LOCAL MODIFIED", "VAULT MODIFIED")
+	for _, st := range report.Items {
+		status := fmt.Sprintf("%-15s", st.Status)
+		switch st.Status {
+		case syncStatusInSync:
+			status = Green.Sprint(status)
+		case syncStatusAbsent:
+			status = Dim.Sprint(status)
+		case syncStatusLocalNewer, syncStatusVaultNewer:
+			status = Yellow.Sprint(status)
+		default:
+			status = Red.Sprint(status)
+		}
+		fmt.Printf("  %-22s %s %-22s %s\n", st.Name, status, orDash(st.LocalModified), orDash(st.VaultModified))
+	}
+	fmt.Println()
+	if report.OutOfSync == 0 {
+		Pass("All items in sync")
+	} else {
+		Info("%d item(s) out of sync", report.OutOfSync)
+		PrintHint("Preview changes with: blackdot sync --dry-run")
+	}
+	return nil
+}
+
+// classifySyncItem compares checksums ("" = missing) against the checksum
+// recorded at the last sync. Without one, modification times decide.
+func classifySyncItem(local, vault, cached string, localMod, vaultMod time.Time) string {
+	switch {
+	case local == "" && vault == "":
+		return syncStatusAbsent
+	case local == vault:
+		return syncStatusInSync
+	case local == "":
+		return syncStatusMissingLocal
+	case vault == "":
+		return syncStatusMissingVault
+	}
+
+	if cached != "" {
+		switch {
+		case local == cached:
+			return syncStatusVaultNewer
+		case vault == cached:
+			return syncStatusLocalNewer
+		default:
+			return syncStatusConflict
+		}
+	}
+
+	switch {
+	case localMod.IsZero() || vaultMod.IsZero() || localMod.Equal(vaultMod):
+		return syncStatusConflict
+	case localMod.After(vaultMod):
+		return syncStatusLocalNewer
+	default:
+		return syncStatusVaultNewer
+	}
+}
+
+// parseVaultIndex indexes 'bw list items' output by item name, keeping the
+// first item when names repeat
+func parseVaultIndex(data []byte) (map[string]vaultIndexEntry, error) {
+	var entries []vaultIndexEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing vault item list: %w", err)
+	}
+	index := make(map[string]vaultIndexEntry, len(entries))
+	for _, e := range entries {
+		if _, ok := index[e.Name]; !ok {
+			index[e.Name] = e
+		}
+	}
+	return index, nil
+}
+
+// orDash shows "-" for an empty table cell
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+// TestClassifySyncItem verifies each status, with and without a baseline
+func TestClassifySyncItem(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		name                 string
+		local, vault, cached string
+		localMod, vaultMod   time.Time
+		want                 string
+	}{
+		{"absent", "", "", "", time.Time{}, time.Time{}, syncStatusAbsent},
+		{"in sync", "a", "a", "", newer, older, syncStatusInSync},
+		{"missing local", "", "a", "a", time.Time{}, older, syncStatusMissingLocal},
+		{"missing vault", "a", "", "", newer, time.Time{}, syncStatusMissingVault},
+		{"local changed", "b", "a", "a", older, newer, syncStatusLocalNewer},
+		{"vault changed", "a", "b", "a", newer, older, syncStatusVaultNewer},
+		{"both changed", "b", "c", "a", newer, older, syncStatusConflict},
+		{"no baseline, local newer", "b", "a", "", newer, older, syncStatusLocalNewer},
+		{"no baseline, vault newer", "b", "a", "", older, newer, syncStatusVaultNewer},
+		{"no baseline, no times", "b", "a", "", newer, time.Time{}, syncStatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifySyncItem(tt.local, tt.vault, tt.cached, tt.localMod, tt.vaultMod)
+			if got != tt.want {
+				t.Errorf("classifySyncItem() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseVaultIndex verifies bw list output is indexed by name
+func TestParseVaultIndex(t *testing.T) {
+	data := []byte(`[
+		{"id": "1", "name": "Git-Config", "notes": "[user]\n", "revisionDate": "2026-03-04T05:06:07.000Z"},
+		{"id": "2", "name": "Git-Config", "notes": "duplicate"},
+		{"id": "3", "name": "Login", "notes": null, "login": {"username": "me"}}
+	]`)
+	index, err := parseVaultIndex(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	git := index["Git-Config"]
+	if git.Notes != "[user]\n" || !git.RevisionDate.Equal(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Errorf("Git-Config = %+v", git)
+	}
+	if _, ok := index["Login"]; !ok || len(index) != 2 {
+		t.Errorf("index = %v", index)
+	}
+
+	if _, err := parseVaultIndex([]byte("Vault is locked.")); err == nil {
+		t.Error("expected an error for non-JSON output")
+	}
+}
@@ -26,13 +26,9 @@ type templateConfig struct {
 }
 
 func getTemplateConfig() (*templateConfig, error) {
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("cannot determine home directory: %w", err)
-		}
-		blackdotDir = filepath.Join(home, ".blackdot")
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return nil, err
 	}
 
 	return &templateConfig{
@@ -179,6 +175,7 @@ Commands:
 
 	cmd.AddCommand(
 		renderCmd,
+		newTemplateApplyCmd(),
 		varsCmd,
 		listCmd,
 		checkCmd,
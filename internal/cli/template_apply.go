@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// templateDestPattern matches the destination directive a text/template file
+// must declare, e.g. {{/* dest: ~/.gitconfig */}}. It is an ordinary template
+// comment, so it renders to nothing.
+var templateDestPattern = regexp.MustCompile(`\{\{-?\s*/\*\s*dest:\s*(.+?)\s*\*/\s*-?\}\}`)
+
+// machineTemplate is one *.tmpl file and where its output goes
+type machineTemplate struct {
+	name string // path relative to the templates directory
+	path string
+	dest string
+}
+
+// newTemplateApplyCmd renders text/template files to the destinations they
+// declare, alongside the Handlebars templates 'template render' handles
+func newTemplateApplyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply [file...]",
+		Short: "Render Go templates and write them to their destinations",
+		Long: `Render machine-specific files written with Go's text/template syntax
+and write each one to the destination it declares.
+
+Every *.tmpl under $BLACKDOT_DIR/templates (except configs/, which holds the
+Handlebars templates used by 'template render') is rendered and written to
+the destination it declares on its own line:
+
+  {{/* dest: ~/.config/app/config.toml */}}
+
+Relative destinations are relative to your home directory.
+
+Template context:
+  .Hostname  .OS  .Arch  .User  .Home
+  .Env       environment variables, e.g. {{ .Env.EDITOR }}
+  .Config    values from ~/.config/blackdot/config.json, e.g. {{ .Config.vault.backend }}
+
+Referencing a variable that doesn't exist is an error, and nothing is
+written unless every template renders. For optional values use index,
+which yields an empty value instead: {{ index .Env "WORK_EMAIL" }}
+
+Examples:
+  blackdot template apply                  # Render and write all templates
+  blackdot template apply gitconfig.tmpl   # Render one template
+  blackdot template apply --dry-run        # Print output without writing`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			return runTemplateApply(BlackdotDir(), args, dryRun)
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "Print rendered output instead of writing it")
+
+	return cmd
+}
+
+// runTemplateApply renders the selected templates (all if names is empty)
+// and writes each to its destination
+func runTemplateApply(blackdotDir string, names []string, dryRun bool) error {
+	tmplDir := filepath.Join(blackdotDir, "templates")
+	templates, err := findMachineTemplates(tmplDir, names)
+	if err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		Info("No templates found in %s", tmplDir)
+		return nil
+	}
+
+	data, err := machineTemplateContext()
+	if err != nil {
+		return err
+	}
+
+	// Render everything first so one bad template doesn't leave a partial set
+	outputs := make([][]byte, len(templates))
+	for i, t := range templates {
+		out, err := renderMachineTemplate(t.path, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+		outputs[i] = out
+	}
+
+	for i, t := range templates {
+		if dryRun {
+			BoldCyan.Printf("=== %s -> %s ===\n", t.name, t.dest)
+			os.Stdout.Write(outputs[i])
+			if len(outputs[i]) > 0 && !bytes.HasSuffix(outputs[i], []byte("\n")) {
+				fmt.Println()
+			}
+			continue
+		}
+
+		if existing, err := os.ReadFile(t.dest); err == nil && bytes.Equal(existing, outputs[i]) {
+			Dim.Printf("  - %s -> %s (unchanged)\n", t.name, t.dest)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(t.dest), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(t.dest, outputs[i], 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", t.dest, err)
+		}
+		Pass("%s -> %s", t.name, t.dest)
+	}
+	return nil
+}
+
+// findMachineTemplates returns the templates to render, in name order. With
+// no names, every *.tmpl under dir is used; configs/ is left to the
+// Handlebars engine.
+func findMachineTemplates(dir string, names []string) ([]machineTemplate, error) {
+	var paths []string
+	if len(names) > 0 {
+		for _, name := range names {
+			p := name
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(dir, name)
+			}
+			if _, err := os.Stat(p); err != nil {
+				return nil, classErrorf(ErrFileNotFound, "template not found: %s", name)
+			}
+			paths = append(paths, p)
+		}
+	} else {
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if p == dir && os.IsNotExist(err) {
+					return filepath.SkipAll
+				}
+				return err
+			}
+			if d.IsDir() && p == filepath.Join(dir, "configs") {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && strings.HasSuffix(p, ".tmpl") {
+				paths = append(paths, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(paths)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+
+	templates := make([]machineTemplate, 0, len(paths))
+	for _, p := range paths {
+		name, err := filepath.Rel(dir, p)
+		if err != nil || strings.HasPrefix(name, "..") {
+			name = p
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		m := templateDestPattern.FindSubmatch(content)
+		if m == nil {
+			return nil, fmt.Errorf("%s: no destination declared (add a {{/* dest: <path> */}} line)", name)
+		}
+		templates = append(templates, machineTemplate{
+			name: name,
+			path: p,
+			dest: resolveTemplateDest(string(m[1]), home),
+		})
+	}
+	return templates, nil
+}
+
+// resolveTemplateDest expands ~ and makes relative destinations relative to home
+func resolveTemplateDest(dest, home string) string {
+	if dest == "~" || strings.HasPrefix(dest, "~/") {
+		return filepath.Join(home, strings.TrimPrefix(dest, "~"))
+	}
+	if !filepath.IsAbs(dest) {
+		return filepath.Join(home, dest)
+	}
+	return filepath.Clean(dest)
+}
+
+// machineTemplateContext collects the facts templates can reference. It is a
+// map rather than a struct so missingkey=error reports unknown names the
+// same way at every level.
+func machineTemplateContext() (map[string]any, error) {
+	hostname, _ := os.Hostname()
+	home, _ := os.UserHomeDir()
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	config := map[string]any{}
+	configPath := filepath.Join(ConfigDir(), "config.json")
+	if data, err := os.ReadFile(configPath); err == nil {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", configPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return map[string]any{
+		"Hostname": hostname,
+		"OS":       runtime.GOOS,
+		"Arch":     runtime.GOARCH,
+		"User":     os.Getenv("USER"),
+		"Home":     home,
+		"Env":      env,
+		"Config":   config,
+	}, nil
+}
+
+// renderMachineTemplate executes one template, failing on any reference to a
+// variable that isn't in data
+func renderMachineTemplate(path string, data map[string]any) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := texttemplate.New(filepath.Base(path)).Option("missingkey=error").Parse(stripTemplateDestLine(string(content)))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// stripTemplateDestLine moves the newline after the destination directive
+// inside the comment, so the directive's line renders to nothing while error
+// line numbers stay accurate
+func stripTemplateDestLine(content string) string {
+	loc := templateDestPattern.FindStringIndex(content)
+	if loc == nil || !strings.HasPrefix(content[loc[1]:], "\n") {
+		return content
+	}
+	directive := content[loc[0]:loc[1]]
+	k := strings.LastIndex(directive, "*/")
+	return content[:loc[0]] + directive[:k] + "\n" + directive[k:] + content[loc[1]+1:]
+}
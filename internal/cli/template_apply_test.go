@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestTemplateApply verifies destinations, context values, and that
+// undefined variables fail without writing anything
+func TestTemplateApply(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("BLACKDOT_TEST_VALUE", "from-env")
+
+	configDir := filepath.Join(home, ".config", "blackdot")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"vault":{"backend":"pass"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTmpl := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(dir, "templates", name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeTmpl("app.tmpl", "{{/* dest: ~/.config/app/app.conf */}}\n"+
+		"arch={{ .Arch }}\nbackend={{ .Config.vault.backend }}\nenv={{ .Env.BLACKDOT_TEST_VALUE }}\nopt={{ index .Env \"BLACKDOT_UNSET\" }}\n")
+	// Handlebars templates are not ours to render
+	writeTmpl("configs/gitconfig.tmpl", "{{ git_name }}\n")
+
+	if err := runTemplateApply(dir, nil, false); err != nil {
+		t.Fatalf("runTemplateApply() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(home, ".config", "app", "app.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "arch=" + runtime.GOARCH + "\nbackend=pass\nenv=from-env\nopt=\n"
+	if string(got) != want {
+		t.Errorf("rendered = %q, want %q", got, want)
+	}
+
+	// One undefined variable blocks every write
+	writeTmpl("app.tmpl", "{{/* dest: app.conf */}}\nnew\n")
+	writeTmpl("broken.tmpl", "{{/* dest: broken.conf */}}\n{{ .Config.missing }}\n")
+	err = runTemplateApply(dir, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "broken.tmpl") || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("undefined variable error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "app.conf")); !os.IsNotExist(err) {
+		t.Error("app.conf was written despite a failing template")
+	}
+
+	writeTmpl("broken.tmpl", "no directive\n")
+	if err := runTemplateApply(dir, []string{"broken.tmpl"}, false); err == nil || !strings.Contains(err.Error(), "no destination") {
+		t.Errorf("missing directive error = %v", err)
+	}
+}
+
+func TestStripTemplateDestLine(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"{{/* dest: ~/a */}}\nbody\n", "{{/* dest: ~/a \n*/}}body\n"},
+		{"{{- /* dest: ~/a */ -}}\nbody", "{{- /* dest: ~/a \n*/ -}}body"},
+		{"x {{/* dest: ~/a */}} y\n", "x {{/* dest: ~/a */}} y\n"},
+		{"no directive\n", "no directive\n"},
+	}
+	for _, tt := range tests {
+		if got := stripTemplateDestLine(tt.in); got != tt.want {
+			t.Errorf("stripTemplateDestLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestTemplateApplyRegistered verifies apply is a template subcommand, not
+// a second top-level command
+func TestTemplateApplyRegistered(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"template", "apply"})
+	if err != nil || cmd.Name() != "apply" {
+		t.Fatalf("template apply not found: %v", err)
+	}
+	if cmd.Flags().Lookup("dry-run") == nil {
+		t.Error("template apply should have --dry-run")
+	}
+	if found, _, _ := rootCmd.Find([]string{"templates"}); found != rootCmd {
+		t.Errorf("expected no top-level templates command, found %q", found.Name())
+	}
+}
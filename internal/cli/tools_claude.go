@@ -368,10 +368,9 @@ func runClaudeInit(force bool) error {
 	yellow := color.New(color.FgYellow).SprintFunc()
 
 	// Find blackdot directory
-	blackdotDir := os.Getenv("BLACKDOT_DIR")
-	if blackdotDir == "" {
-		home, _ := os.UserHomeDir()
-		blackdotDir = filepath.Join(home, ".blackdot")
+	blackdotDir, err := resolveBlackdotDir()
+	if err != nil {
+		return err
 	}
 
 	srcDir := filepath.Join(blackdotDir, "claude")
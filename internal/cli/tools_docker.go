@@ -1193,6 +1193,10 @@ func newComposeDownCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&volumes, "volumes", "v", false, "Remove volumes too")
+	// -v means --volumes here, as in docker compose down. Declaring --verbose
+	// locally, without a shorthand, keeps the global one (and its -v) from
+	// being merged in, which would panic.
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "verbose output")
 
 	return cmd
 }
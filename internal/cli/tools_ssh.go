@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"os"
@@ -44,7 +45,15 @@ Commands:
   unload    - Remove key from SSH agent
   clear     - Remove all keys from agent
   tunnels   - List active SSH connections
-  add-host  - Add new host to SSH config`,
+  add-host  - Add new host to SSH config
+  config-generate - Regenerate managed hosts from config.json or vault
+  enable-multiplexing  - Reuse connections with ControlMaster
+  disable-multiplexing - Remove the ControlMaster block
+  export    - Export config and public keys to a tar.gz
+  import    - Restore config and keys from an export
+  audit     - Flag weak keys and keys without a passphrase
+  test      - Check non-interactive authentication to a host
+  convert   - Convert a key between OpenSSH and PEM formats`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runSSHStatusLocal()
 		},
@@ -66,6 +75,14 @@ Commands:
 		newSSHClearCmd(),
 		newSSHTunnelsCmd(),
 		newSSHAddHostCmd(),
+		newSSHConfigGenerateCmd(),
+		newSSHEnableMuxCmd(),
+		newSSHDisableMuxCmd(),
+		newSSHExportCmd(),
+		newSSHImportCmd(),
+		newSSHAuditCmd(),
+		newSSHTestCmd(),
+		newSSHConvertCmd(),
 	)
 
 	return cmd
@@ -74,6 +91,7 @@ Commands:
 // newSSHKeysCmd lists SSH keys with fingerprints
 func newSSHKeysCmd() *cobra.Command {
 	var keyDir string
+	var jsonOutput bool
 
 	cmd := &cobra.Command{
 		Use:   "keys",
@@ -81,7 +99,12 @@ func newSSHKeysCmd() *cobra.Command {
 		Long: `List all SSH keys in the specified directory with their fingerprints.
 
 Shows key name, bit size, type, and SHA256 fingerprint.
-Default directory is ~/.ssh`,
+Default directory is ~/.ssh
+
+With --json, prints an array of objects with path, type, bits,
+fingerprint, comment, and hasPassphrase. hasPassphrase is read from the
+private key's header without decrypting it, and is null when there is no
+private key next to the .pub file.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if keyDir == "" {
 				home, err := os.UserHomeDir()
@@ -91,11 +114,15 @@ Default directory is ~/.ssh`,
 				keyDir = filepath.Join(home, ".ssh")
 			}
 
+			if jsonOutput {
+				return runSSHKeysJSON(keyDir)
+			}
 			return runSSHKeys(keyDir)
 		},
 	}
 
 	cmd.Flags().StringVarP(&keyDir, "dir", "d", "", "SSH key directory (default: ~/.ssh)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
 
 	return cmd
 }
@@ -157,6 +184,85 @@ func runSSHKeys(keyDir string) error {
 	return nil
 }
 
+// sshKeyInfo is one entry of 'tools ssh keys --json'
+type sshKeyInfo struct {
+	Path          string `json:"path"`
+	Type          string `json:"type"`
+	Bits          int    `json:"bits"`
+	Fingerprint   string `json:"fingerprint"`
+	Comment       string `json:"comment"`
+	HasPassphrase *bool  `json:"hasPassphrase"` // nil without a private key
+}
+
+// listSSHKeys describes every .pub file in keyDir, sorted by path. Files
+// that don't parse are skipped.
+func listSSHKeys(keyDir string) ([]sshKeyInfo, error) {
+	matches, err := filepath.Glob(filepath.Join(keyDir, "*.pub"))
+	if err != nil {
+		return nil, fmt.Errorf("error searching for keys: %w", err)
+	}
+	sort.Strings(matches)
+
+	keys := []sshKeyInfo{}
+	for _, pubPath := range matches {
+		pubData, err := os.ReadFile(pubPath)
+		if err != nil {
+			logger.Debug("skipping public key", "file", pubPath, "error", err)
+			continue
+		}
+		pubKey, comment, _, _, err := ssh.ParseAuthorizedKey(pubData)
+		if err != nil {
+			logger.Debug("skipping public key", "file", pubPath, "error", err)
+			continue
+		}
+
+		info := sshKeyInfo{
+			Path:        pubPath,
+			Type:        pubKey.Type(),
+			Bits:        sshPublicKeyBits(pubKey),
+			Fingerprint: ssh.FingerprintSHA256(pubKey),
+			Comment:     comment,
+		}
+		if encrypted, ok := sshPrivateKeyEncrypted(strings.TrimSuffix(pubPath, ".pub")); ok {
+			info.HasPassphrase = &encrypted
+		}
+		keys = append(keys, info)
+	}
+	return keys, nil
+}
+
+// sshPrivateKeyEncrypted reports whether the private key at path has a
+// passphrase, judged from its header alone. ok is false when path is not a
+// readable private key.
+func sshPrivateKeyEncrypted(path string) (encrypted, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > 64*1024 {
+		return false, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, false
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return false, false
+	}
+	return pemBlockEncrypted(block), true
+}
+
+func runSSHKeysJSON(keyDir string) error {
+	keys, err := listSSHKeys(keyDir)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
 // getKeyBits returns the bit size for a public key
 func getKeyBits(pubKey ssh.PublicKey) int {
 	switch pubKey.Type() {
@@ -219,7 +325,7 @@ func runSSHGen(name, comment string, noPassphrase bool) error {
 
 	// Check if key already exists
 	if _, err := os.Stat(keyPath); err == nil {
-		return fmt.Errorf("key already exists: %s\nDelete it first if you want to regenerate", keyPath)
+		return classErrorf(ErrConflict, "key already exists: %s\nDelete it first if you want to regenerate", keyPath)
 	}
 
 	// Ensure .ssh directory exists
@@ -612,7 +718,7 @@ func runSSHFingerprint(keyName string) error {
 	}
 
 	if pubPath == "" {
-		return fmt.Errorf("key not found: %s", keyName)
+		return classErrorf(ErrFileNotFound, "key not found: %s", keyName)
 	}
 
 	// Ensure we have the .pub file
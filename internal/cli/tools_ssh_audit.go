@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// Audit finding severities
+const (
+	sshSeverityHigh   = "high"
+	sshSeverityMedium = "medium"
+)
+
+// sshIssueNoPassphrase is the finding for an unencrypted private key
+const sshIssueNoPassphrase = "private key has no passphrase"
+
+// sshMinRSABits is the smallest RSA key the audit accepts (NIST 128-bit level)
+const sshMinRSABits = 3072
+
+// sshKeyFinding is one problem with a key
+type sshKeyFinding struct {
+	Severity string `json:"severity"`
+	Issue    string `json:"issue"`
+}
+
+// sshKeyAudit is the result for one key. Only public metadata is recorded;
+// private key bytes never leave auditSSHKey.
+type sshKeyAudit struct {
+	Name        string          `json:"name"`
+	Path        string          `json:"path"`
+	Type        string          `json:"type"`
+	Bits        int             `json:"bits,omitempty"`
+	Fingerprint string          `json:"fingerprint,omitempty"`
+	Private     bool            `json:"private"`   // a private key file was found
+	Encrypted   bool            `json:"encrypted"` // private key is passphrase protected
+	Findings    []sshKeyFinding `json:"findings"`
+}
+
+// sshAuditReport is the --json output of 'tools ssh audit'
+type sshAuditReport struct {
+	Dir  string        `json:"dir"`
+	Weak int           `json:"weak"`
+	Keys []sshKeyAudit `json:"keys"`
+}
+
+// newSSHAuditCmd checks keys for weak algorithms and missing passphrases
+func newSSHAuditCmd() *cobra.Command {
+	var keyDir string
+	var jsonOutput, failOnWeak bool
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Audit SSH keys for weak algorithms and missing passphrases",
+		Long: `Inspect every key in ~/.ssh and report problems with a severity:
+
+  high    DSA keys (deprecated, disabled by default since OpenSSH 7.0)
+  high    RSA keys under 2048 bits
+  medium  RSA keys under 3072 bits
+  medium  private keys without a passphrase
+
+Public keys without a matching private key are checked for algorithm
+and size only. Private key material is never printed.
+
+Examples:
+  blackdot tools ssh audit
+  blackdot tools ssh audit --json
+  blackdot tools ssh audit --fail-on-weak   # Exit 1 if any key has findings`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keyDir == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("cannot determine home directory: %w", err)
+				}
+				keyDir = filepath.Join(home, ".ssh")
+			}
+			return runSSHAudit(keyDir, jsonOutput, failOnWeak)
+		},
+	}
+
+	cmd.Flags().StringVarP(&keyDir, "dir", "d", "", "SSH key directory (default: ~/.ssh)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output results as JSON")
+	cmd.Flags().BoolVar(&failOnWeak, "fail-on-weak", false, "Exit non-zero if any key has findings")
+
+	return cmd
+}
+
+// auditSSHDir audits every private key in dir, plus public keys that have
+// no private key alongside them
+func auditSSHDir(dir string) ([]sshKeyAudit, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []sshKeyAudit{}
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), ".pub") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if a, ok := auditSSHKey(path); ok {
+			results = append(results, a)
+			seen[path] = true
+		}
+	}
+
+	pubs, _ := filepath.Glob(filepath.Join(dir, "*.pub"))
+	for _, pubPath := range pubs {
+		if seen[strings.TrimSuffix(pubPath, ".pub")] {
+			continue
+		}
+		pub, err := readSSHPublicKey(pubPath)
+		if err != nil {
+			continue
+		}
+		a := sshKeyAudit{Name: filepath.Base(pubPath), Path: pubPath}
+		describeSSHPublicKey(&a, pub)
+		results = append(results, a)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results, nil
+}
+
+// auditSSHKey inspects a file that may be a private key. ok is false for
+// anything else (config, known_hosts, ...).
+func auditSSHKey(path string) (sshKeyAudit, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > 64*1024 {
+		return sshKeyAudit{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sshKeyAudit{}, false
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return sshKeyAudit{}, false
+	}
+
+	a := sshKeyAudit{Name: filepath.Base(path), Path: path, Private: true}
+	a.Encrypted = pemBlockEncrypted(block)
+
+	// Prefer the public half embedded in the key; fall back to the .pub file
+	var pub ssh.PublicKey
+	if a.Encrypted {
+		var missing *ssh.PassphraseMissingError
+		if _, err := ssh.ParsePrivateKey(data); errors.As(err, &missing) {
+			pub = missing.PublicKey
+		}
+	} else if signer, err := ssh.ParsePrivateKey(data); err == nil {
+		pub = signer.PublicKey()
+	}
+	if pub == nil {
+		pub, _ = readSSHPublicKey(path + ".pub")
+	}
+
+	if pub != nil {
+		describeSSHPublicKey(&a, pub)
+	} else {
+		a.Type = pemKeyType(block)
+		if a.Type == ssh.KeyAlgoDSA {
+			a.Findings = append(a.Findings, sshKeyFinding{sshSeverityHigh, "DSA keys are deprecated and rejected by modern OpenSSH"})
+		}
+	}
+
+	if !a.Encrypted {
+		a.Findings = append(a.Findings, sshKeyFinding{sshSeverityMedium, sshIssueNoPassphrase})
+	}
+	if a.Findings == nil {
+		a.Findings = []sshKeyFinding{}
+	}
+	return a, true
+}
+
+// pemBlockEncrypted reads the encryption marker from the key's header:
+// the cipher name of an OpenSSH key, the PKCS#8 block type, or the legacy
+// Proc-Type PEM header.
+func pemBlockEncrypted(block *pem.Block) bool {
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		const magic = "openssh-key-v1\x00"
+		b := block.Bytes
+		if !bytes.HasPrefix(b, []byte(magic)) || len(b) < len(magic)+4 {
+			return false
+		}
+		b = b[len(magic):]
+		n := binary.BigEndian.Uint32(b)
+		if uint32(len(b)-4) < n {
+			return false
+		}
+		return string(b[4:4+n]) != "none"
+	case "ENCRYPTED PRIVATE KEY":
+		return true
+	default:
+		return strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED")
+	}
+}
+
+// pemKeyType guesses the algorithm of a legacy PEM key we couldn't parse
+func pemKeyType(block *pem.Block) string {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return ssh.KeyAlgoRSA
+	case "DSA PRIVATE KEY":
+		return ssh.KeyAlgoDSA
+	case "EC PRIVATE KEY":
+		return "ecdsa"
+	default:
+		return "unknown"
+	}
+}
+
+// readSSHPublicKey parses an authorized_keys style .pub file
+func readSSHPublicKey(path string) (ssh.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	return pub, err
+}
+
+// describeSSHPublicKey fills in type, size, and fingerprint, and records
+// algorithm findings
+func describeSSHPublicKey(a *sshKeyAudit, pub ssh.PublicKey) {
+	a.Type = pub.Type()
+	a.Fingerprint = ssh.FingerprintSHA256(pub)
+	a.Bits = sshPublicKeyBits(pub)
+	if a.Findings == nil {
+		a.Findings = []sshKeyFinding{}
+	}
+
+	switch {
+	case a.Type == ssh.KeyAlgoDSA:
+		a.Findings = append(a.Findings, sshKeyFinding{sshSeverityHigh, "DSA keys are deprecated and rejected by modern OpenSSH"})
+	case a.Type == ssh.KeyAlgoRSA && a.Bits < 2048:
+		a.Findings = append(a.Findings, sshKeyFinding{sshSeverityHigh, fmt.Sprintf("RSA key is %d bits (minimum %d)", a.Bits, sshMinRSABits)})
+	case a.Type == ssh.KeyAlgoRSA && a.Bits < sshMinRSABits:
+		a.Findings = append(a.Findings, sshKeyFinding{sshSeverityMedium, fmt.Sprintf("RSA key is %d bits (minimum %d)", a.Bits, sshMinRSABits)})
+	}
+}
+
+// sshPublicKeyBits returns the exact modulus size for RSA keys and the
+// fixed size for other algorithms
+func sshPublicKeyBits(pub ssh.PublicKey) int {
+	if pub.Type() == ssh.KeyAlgoRSA {
+		if cpk, ok := pub.(ssh.CryptoPublicKey); ok {
+			if rsaKey, ok := cpk.CryptoPublicKey().(*rsa.PublicKey); ok {
+				return rsaKey.N.BitLen()
+			}
+		}
+	}
+	if pub.Type() == ssh.KeyAlgoDSA {
+		return 1024
+	}
+	return getKeyBits(pub)
+}
+
+// runSSHAudit prints the audit and, with failOnWeak, returns an error when
+// any key has findings
+func runSSHAudit(keyDir string, jsonOutput, failOnWeak bool) error {
+	results, err := auditSSHDir(keyDir)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", keyDir, err)
+	}
+
+	weak := 0
+	for _, a := range results {
+		if len(a.Findings) > 0 {
+			weak++
+		}
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(sshAuditReport{Dir: keyDir, Weak: weak, Keys: results}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		PrintHeader("SSH Key Audit")
+		if len(results) == 0 {
+			Dim.Printf("  No SSH keys found in %s\n", keyDir)
+		}
+		for _, a := range results {
+			desc := a.Type
+			if a.Bits > 0 {
+				desc = fmt.Sprintf("%s %d", a.Type, a.Bits)
+			}
+			if len(a.Findings) == 0 {
+				Pass("%s (%s)", a.Name, desc)
+				continue
+			}
+			for _, f := range a.Findings {
+				if f.Severity == sshSeverityHigh {
+					Fail("%s (%s): [%s] %s", a.Name, desc, f.Severity, f.Issue)
+				} else {
+					Warn("%s (%s): [%s] %s", a.Name, desc, f.Severity, f.Issue)
+				}
+			}
+		}
+		fmt.Println()
+		if weak == 0 {
+			Pass("All %d keys passed", len(results))
+		} else {
+			PrintHint("Generate a replacement with: blackdot tools ssh gen <name>")
+			PrintHint("Add a passphrase with: ssh-keygen -p -f <key>")
+		}
+	}
+
+	if failOnWeak && weak > 0 {
+		return fmt.Errorf("%d of %d SSH keys have findings", weak, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackwell-systems/blackdot/internal/config"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+)
+
+const (
+	sshManagedBegin = "# BEGIN blackdot managed"
+	sshManagedEnd   = "# END blackdot managed"
+
+	// sshHostsVaultItem holds {"hosts": [...]} for --from vault
+	sshHostsVaultItem = "SSH-Hosts"
+)
+
+// sshOptionKeyword matches an ssh_config keyword such as ForwardAgent
+var sshOptionKeyword = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// newSSHConfigGenerateCmd renders declared hosts into ~/.ssh/config
+func newSSHConfigGenerateCmd() *cobra.Command {
+	var keyDir, from, item string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "config-generate",
+		Short: "Regenerate SSH config hosts from config.json or vault",
+		Long: `Regenerate the blackdot-managed hosts in ~/.ssh/config from declared
+host definitions.
+
+Hosts come from "ssh.hosts" in ~/.config/blackdot/config.json (--from
+config, the default) or from a vault item holding {"hosts": [...]}
+(--from vault, item SSH-Hosts unless --item is given):
+
+  {"ssh": {"hosts": [
+    {"name": "web", "hostname": "10.0.0.5", "user": "deploy", "port": 2222,
+     "identity_file": "~/.ssh/id_ed25519_work", "proxy_jump": "bastion",
+     "options": {"ForwardAgent": "yes"}}
+  ]}}
+
+Generated hosts go between "# BEGIN blackdot managed" and
+"# END blackdot managed" lines. Everything outside the markers is left as
+it is, so hand-written hosts and settings survive regeneration. The first
+run appends the block to the end of the file.
+
+Examples:
+  blackdot tools ssh config-generate --dry-run   # Show the diff only
+  blackdot tools ssh config-generate
+  blackdot tools ssh config-generate --from vault`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from != "config" && from != "vault" {
+				return fmt.Errorf("--from must be config or vault, got %q", from)
+			}
+			if keyDir == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("cannot determine home directory: %w", err)
+				}
+				keyDir = filepath.Join(home, ".ssh")
+			}
+			return runSSHConfigGenerate(keyDir, from, item, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&keyDir, "dir", "d", "", "SSH directory (default: ~/.ssh)")
+	cmd.Flags().StringVar(&from, "from", "config", "Where hosts are declared: config or vault")
+	cmd.Flags().StringVar(&item, "item", sshHostsVaultItem, "Vault item holding the hosts (with --from vault)")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Print the diff without writing")
+
+	return cmd
+}
+
+func runSSHConfigGenerate(keyDir, from, item string, dryRun bool) error {
+	hosts, source, err := loadSSHHosts(from, item)
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		// Refuse rather than empty the block because a source came back blank
+		return fmt.Errorf("no SSH hosts declared in %s", source)
+	}
+	if err := validateSSHHosts(hosts); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	configPath := filepath.Join(keyDir, "config")
+	var existing string
+	mode := os.FileMode(0600)
+	if data, err := os.ReadFile(configPath); err == nil {
+		existing = string(data)
+		if info, err := os.Stat(configPath); err == nil {
+			mode = info.Mode().Perm()
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read SSH config: %w", err)
+	}
+
+	updated, err := replaceSSHManagedBlock(existing, renderSSHManagedBlock(hosts, source))
+	if err != nil {
+		return fmt.Errorf("%s: %w", configPath, err)
+	}
+	if updated == existing {
+		Pass("%s is up to date (%d hosts)", configPath, len(hosts))
+		return nil
+	}
+
+	if dryRun {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitDiffLines(existing),
+			B:        splitDiffLines(updated),
+			FromFile: configPath,
+			ToFile:   configPath + " (generated)",
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		printUnifiedDiff(diff)
+		return nil
+	}
+
+	if err := os.MkdirAll(keyDir, 0700); err != nil {
+		return fmt.Errorf("failed to create .ssh directory: %w", err)
+	}
+	if err := os.WriteFile(configPath, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write SSH config: %w", err)
+	}
+	Pass("Wrote %d hosts from %s to %s", len(hosts), source, configPath)
+	return nil
+}
+
+// loadSSHHosts reads host definitions from config.json or a vault item,
+// returning a description of where they came from
+func loadSSHHosts(from, item string) ([]config.SSHHost, string, error) {
+	if from == "config" {
+		mgr := config.DefaultManager()
+		cfg, err := mgr.Load()
+		if err != nil {
+			return nil, "", fmt.Errorf("reading %s: %w", mgr.UserConfigPath(), err)
+		}
+		return cfg.SSH.Hosts, mgr.UserConfigPath(), nil
+	}
+
+	source := fmt.Sprintf("vault item %s", item)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	backend, err := newVaultBackend()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create vault backend: %w", err)
+	}
+	defer backend.Close()
+
+	if err := backend.Init(ctx); err != nil {
+		return nil, "", fmt.Errorf("vault backend not available: %w", err)
+	}
+	session, err := backend.Authenticate(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("authentication required: %w", err)
+	}
+	notes, err := backend.GetNotes(ctx, item, session)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", source, err)
+	}
+
+	var sshCfg config.SSHConfig
+	if err := json.Unmarshal([]byte(notes), &sshCfg); err != nil {
+		return nil, "", fmt.Errorf("%s is not a {\"hosts\": [...]} JSON document: %w", source, err)
+	}
+	return sshCfg.Hosts, source, nil
+}
+
+// validateSSHHosts rejects hosts that would render a broken or ambiguous
+// config: missing or duplicate names, bad ports, line breaks in values, and
+// options that repeat a field or aren't ssh_config keywords
+func validateSSHHosts(hosts []config.SSHHost) error {
+	fields := map[string]bool{"hostname": true, "user": true, "port": true, "identityfile": true, "proxyjump": true}
+	seen := make(map[string]bool)
+
+	for i, h := range hosts {
+		if strings.TrimSpace(h.Name) == "" {
+			return fmt.Errorf("host %d has no name", i+1)
+		}
+		if seen[h.Name] {
+			return fmt.Errorf("host %q is declared twice", h.Name)
+		}
+		seen[h.Name] = true
+
+		if h.Port < 0 || h.Port > 65535 {
+			return fmt.Errorf("host %q: port %d out of range", h.Name, h.Port)
+		}
+		values := []string{h.Name, h.HostName, h.User, h.IdentityFile, h.ProxyJump}
+		for key, value := range h.Options {
+			if !sshOptionKeyword.MatchString(key) {
+				return fmt.Errorf("host %q: %q is not an ssh_config keyword", h.Name, key)
+			}
+			switch lower := strings.ToLower(key); {
+			case lower == "host" || lower == "match":
+				return fmt.Errorf("host %q: %s would start a new block; declare another host instead", h.Name, key)
+			case fields[lower]:
+				return fmt.Errorf("host %q: set %s with its own field, not options", h.Name, key)
+			}
+			values = append(values, value)
+		}
+		for _, value := range values {
+			if strings.ContainsAny(value, "\r\n") {
+				return fmt.Errorf("host %q: values can't contain line breaks", h.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// renderSSHManagedBlock renders hosts between the managed markers, in
+// declaration order with options sorted, so regenerating is stable
+func renderSSHManagedBlock(hosts []config.SSHHost, source string) string {
+	var b strings.Builder
+	b.WriteString(sshManagedBegin + "\n")
+	fmt.Fprintf(&b, "# Generated by 'blackdot tools ssh config-generate' from %s.\n", source)
+	b.WriteString("# Edits inside this block are overwritten; put your own hosts outside it.\n")
+
+	for _, h := range hosts {
+		fmt.Fprintf(&b, "\nHost %s\n", h.Name)
+		if h.HostName != "" {
+			fmt.Fprintf(&b, "    HostName %s\n", h.HostName)
+		}
+		if h.User != "" {
+			fmt.Fprintf(&b, "    User %s\n", h.User)
+		}
+		if h.Port != 0 {
+			fmt.Fprintf(&b, "    Port %d\n", h.Port)
+		}
+		if h.IdentityFile != "" {
+			identity := h.IdentityFile
+			if strings.ContainsAny(identity, " \t") {
+				identity = `"` + identity + `"`
+			}
+			fmt.Fprintf(&b, "    IdentityFile %s\n", identity)
+		}
+		if h.ProxyJump != "" {
+			fmt.Fprintf(&b, "    ProxyJump %s\n", h.ProxyJump)
+		}
+
+		keys := make([]string, 0, len(h.Options))
+		for key := range h.Options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "    %s %s\n", key, h.Options[key])
+		}
+	}
+
+	b.WriteString(sshManagedEnd + "\n")
+	return b.String()
+}
+
+// replaceSSHManagedBlock swaps the managed block in content for block,
+// appending it when content has none. A begin marker without an end is an
+// error rather than a guess at where the block stops.
+func replaceSSHManagedBlock(content, block string) (string, error) {
+	return replaceSSHBlock(content, sshManagedBegin, sshManagedEnd, block)
+}
+
+// replaceSSHBlock is replaceSSHManagedBlock for the block between any pair
+// of marker lines
+func replaceSSHBlock(content, beginMarker, endMarker, block string) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	begin, end, err := findSSHBlock(lines, beginMarker, endMarker)
+	if err != nil {
+		return "", err
+	}
+
+	if begin < 0 {
+		if content == "" {
+			return block, nil
+		}
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + "\n" + block, nil
+	}
+
+	before := strings.Join(lines[:begin], "")
+	after := strings.Join(lines[end+1:], "")
+	return before + block + after, nil
+}
+
+// findSSHBlock returns the indexes of the marker lines in lines, or -1 and
+// -1 if there is no block; duplicate or unpaired markers are errors
+func findSSHBlock(lines []string, beginMarker, endMarker string) (begin, end int, err error) {
+	begin, end = -1, -1
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case beginMarker:
+			if begin >= 0 {
+				return -1, -1, fmt.Errorf("more than one %q line", beginMarker)
+			}
+			begin = i
+		case endMarker:
+			if begin < 0 {
+				return -1, -1, fmt.Errorf("%q without a %q line before it", endMarker, beginMarker)
+			}
+			if end < 0 {
+				end = i
+			}
+		}
+	}
+	if begin >= 0 && end < 0 {
+		return -1, -1, fmt.Errorf("%q has no matching %q line", beginMarker, endMarker)
+	}
+	return begin, end, nil
+}
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Outcomes of 'tools ssh test'
+const (
+	sshTestSuccess     = "success"
+	sshTestAuthFailed  = "auth-failed"
+	sshTestTimeout     = "timeout"
+	sshTestUnreachable = "unreachable"
+	sshTestHostKey     = "host-key"
+	sshTestError       = "error"
+)
+
+var (
+	sshIdentityFilePattern  = regexp.MustCompile(`^debug1: identity file (\S+) type (-?\d+)`)
+	sshOfferingPattern      = regexp.MustCompile(`^debug1: Offering public key: (\S+) (\S+) (\S+)`)
+	sshAcceptsPattern       = regexp.MustCompile(`^debug1: Server accepts key: (\S+) (\S+) (\S+)`)
+	sshAuthenticatedPattern = regexp.MustCompile(`^Authenticated to (\S+)`)
+)
+
+// sshOfferedKey is a key ssh presented to the server
+type sshOfferedKey struct {
+	Path        string
+	Type        string
+	Fingerprint string
+}
+
+func (k sshOfferedKey) String() string {
+	return fmt.Sprintf("%s (%s %s)", shortenHome(k.Path), k.Type, k.Fingerprint)
+}
+
+// sshTestResult is what 'tools ssh test' learned from one connection attempt
+type sshTestResult struct {
+	Outcome       string
+	Message       string
+	IdentityFiles []string // IdentityFile candidates that exist on disk
+	Offered       []sshOfferedKey
+	Accepted      *sshOfferedKey
+}
+
+// newSSHTestCmd checks that a host accepts a key without prompting
+func newSSHTestCmd() *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "test <host>",
+		Short: "Test non-interactive SSH authentication to a host",
+		Long: `Attempt a non-interactive connection (ssh -o BatchMode=yes -T) and report
+whether it authenticated, was refused, or timed out, along with the
+IdentityFile candidates and the keys that were offered to the server.
+
+Useful to confirm a key is set up before relying on it in scripts.
+Exits non-zero unless authentication succeeded.
+
+Examples:
+  blackdot tools ssh test github.com
+  blackdot tools ssh test git@github.com
+  blackdot tools ssh test myserver --timeout 5s`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if timeout <= 0 {
+				return fmt.Errorf("--timeout must be positive")
+			}
+			return runSSHTest(args[0], timeout)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&timeout, "timeout", "t", 10*time.Second, "Give up after this long")
+
+	return cmd
+}
+
+func runSSHTest(host string, timeout time.Duration) error {
+	if !commandExists("ssh") {
+		return classErrorf(ErrToolMissing, "ssh not found in PATH")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	connectTimeout := int(timeout.Round(time.Second) / time.Second)
+	if connectTimeout < 1 {
+		connectTimeout = 1
+	}
+	cmd := exec.CommandContext(ctx, "ssh", "-v", "-T",
+		"-o", "BatchMode=yes",
+		"-o", fmt.Sprintf("ConnectTimeout=%d", connectTimeout),
+		host)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = 2 * time.Second
+
+	runErr := cmd.Run()
+	result := parseSSHVerbose(stderr.String())
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && result.Outcome != sshTestSuccess {
+		result.Outcome = sshTestTimeout
+		result.Message = fmt.Sprintf("no response within %s", timeout)
+	}
+	if result.Outcome == "" {
+		result.Outcome = sshTestError
+		result.Message = "ssh exited without authenticating"
+		if runErr != nil {
+			result.Message = fmt.Sprintf("ssh failed: %v", runErr)
+		}
+	}
+	logger.Debug("ssh test finished", "host", host, "outcome", result.Outcome, "error", runErr)
+
+	PrintHeader("SSH Connection Test: " + host)
+
+	Bold.Println("Identity files:")
+	if len(result.IdentityFiles) == 0 {
+		Dim.Println("  none found (ssh may still use keys from the agent)")
+	}
+	for _, f := range result.IdentityFiles {
+		fmt.Printf("  %s\n", shortenHome(f))
+	}
+	fmt.Println()
+
+	Bold.Println("Keys offered:")
+	if len(result.Offered) == 0 {
+		Dim.Println("  none")
+	}
+	for _, k := range result.Offered {
+		marker := "  "
+		if result.Accepted != nil && k.Fingerprint == result.Accepted.Fingerprint {
+			marker = Green.Sprint("✓ ")
+		}
+		fmt.Printf("  %s%s\n", marker, k)
+	}
+	fmt.Println()
+
+	switch result.Outcome {
+	case sshTestSuccess:
+		if result.Accepted != nil {
+			Pass("Authenticated to %s with %s", host, shortenHome(result.Accepted.Path))
+		} else {
+			Pass("Authenticated to %s", host)
+		}
+		return nil
+	case sshTestAuthFailed:
+		Fail("Authentication failed: %s", result.Message)
+		PrintHint("Copy a key to the host with: blackdot tools ssh copy %s", host)
+		PrintHint("Load a key into the agent with: blackdot tools ssh load <key>")
+	case sshTestHostKey:
+		Fail("Host key verification failed")
+		PrintHint("Connect once interactively to accept the host key, or check ~/.ssh/known_hosts")
+	default:
+		Fail("%s: %s", result.Outcome, result.Message)
+	}
+	return fmt.Errorf("ssh test to %s failed (%s)", host, result.Outcome)
+}
+
+// parseSSHVerbose extracts identity files, offered keys, and the outcome
+// from 'ssh -v' stderr. Outcome is empty if the output doesn't say.
+func parseSSHVerbose(output string) sshTestResult {
+	var result sshTestResult
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case sshIdentityFilePattern.MatchString(line):
+			m := sshIdentityFilePattern.FindStringSubmatch(line)
+			// type -1 means the file doesn't exist
+			if m[2] != "-1" && !seen[m[1]] {
+				seen[m[1]] = true
+				result.IdentityFiles = append(result.IdentityFiles, m[1])
+			}
+		case sshOfferingPattern.MatchString(line):
+			m := sshOfferingPattern.FindStringSubmatch(line)
+			result.Offered = append(result.Offered, sshOfferedKey{m[1], m[2], m[3]})
+		case sshAcceptsPattern.MatchString(line):
+			m := sshAcceptsPattern.FindStringSubmatch(line)
+			result.Accepted = &sshOfferedKey{m[1], m[2], m[3]}
+		case sshAuthenticatedPattern.MatchString(line):
+			result.Outcome = sshTestSuccess
+			result.Message = ""
+		case result.Outcome == sshTestSuccess:
+			// Anything after authenticating is the remote side's business
+		case strings.Contains(line, "Permission denied"):
+			result.Outcome, result.Message = sshTestAuthFailed, sshErrorMessage(line)
+		case strings.Contains(line, "Host key verification failed"),
+			strings.Contains(line, "REMOTE HOST IDENTIFICATION HAS CHANGED"):
+			result.Outcome, result.Message = sshTestHostKey, sshErrorMessage(line)
+		case strings.Contains(line, "timed out"):
+			result.Outcome, result.Message = sshTestTimeout, sshErrorMessage(line)
+		case strings.Contains(line, "Could not resolve hostname"),
+			strings.Contains(line, "Connection refused"),
+			strings.Contains(line, "Connection reset"),
+			strings.Contains(line, "Connection closed"),
+			strings.Contains(line, "No route to host"),
+			strings.Contains(line, "Network is unreachable"):
+			if result.Outcome == "" {
+				result.Outcome, result.Message = sshTestUnreachable, sshErrorMessage(line)
+			}
+		}
+	}
+	return result
+}
+
+// sshErrorMessage strips ssh's "ssh: " / "debug1: " prefix from a line
+func sshErrorMessage(line string) string {
+	for _, prefix := range []string{"ssh: ", "debug1: "} {
+		line = strings.TrimPrefix(line, prefix)
+	}
+	return strings.TrimSpace(line)
+}
+
+// shortenHome replaces the home directory prefix with ~
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
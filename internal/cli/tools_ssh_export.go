@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshBundleManifest is stored as manifest.json inside an export archive
+type sshBundleManifest struct {
+	Created  time.Time       `json:"created"`
+	Hostname string          `json:"hostname"`
+	Files    []sshBundleFile `json:"files"`
+}
+
+// sshBundleFile describes one file in an export archive
+type sshBundleFile struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // config, public, private
+	Mode        uint32 `json:"mode"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+const sshBundleManifestName = "manifest.json"
+
+// sshBundleMaxEntry caps each file read from a bundle; SSH configs and
+// keys are a few KiB
+const sshBundleMaxEntry = 1 << 20
+
+// newSSHExportCmd archives SSH config and public keys
+func newSSHExportCmd() *cobra.Command {
+	var keyDir string
+	var includePrivate bool
+
+	cmd := &cobra.Command{
+		Use:   "export <file>",
+		Short: "Export SSH config and public keys to a tar.gz",
+		Long: `Archive ~/.ssh/config and all public keys into a portable tar.gz bundle.
+
+A manifest.json inside the archive records each file's mode and key
+fingerprint. Private keys are never included unless --include-private
+is passed explicitly.
+
+Examples:
+  blackdot tools ssh export ssh-backup.tar.gz
+  blackdot tools ssh export ssh-full.tar.gz --include-private`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keyDir == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("cannot determine home directory: %w", err)
+				}
+				keyDir = filepath.Join(home, ".ssh")
+			}
+			return runSSHExport(keyDir, args[0], includePrivate)
+		},
+	}
+
+	cmd.Flags().StringVarP(&keyDir, "dir", "d", "", "SSH directory (default: ~/.ssh)")
+	cmd.Flags().BoolVar(&includePrivate, "include-private", false, "Also archive private keys (handle the bundle with care)")
+
+	return cmd
+}
+
+// newSSHImportCmd restores a bundle created by export
+func newSSHImportCmd() *cobra.Command {
+	var keyDir string
+	var forceImport bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Restore SSH config and keys from an export bundle",
+		Long: `Restore files from a bundle created by 'blackdot tools ssh export'.
+
+Refuses to overwrite existing files unless --force is passed. Nothing is
+written if any file would be clobbered, or if a public key doesn't match
+the fingerprint recorded in the manifest.
+
+Examples:
+  blackdot tools ssh import ssh-backup.tar.gz
+  blackdot tools ssh import ssh-backup.tar.gz --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keyDir == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("cannot determine home directory: %w", err)
+				}
+				keyDir = filepath.Join(home, ".ssh")
+			}
+			return runSSHImport(args[0], keyDir, forceImport)
+		},
+	}
+
+	cmd.Flags().StringVarP(&keyDir, "dir", "d", "", "SSH directory (default: ~/.ssh)")
+	cmd.Flags().BoolVarP(&forceImport, "force", "f", false, "Overwrite existing files")
+
+	return cmd
+}
+
+// collectSSHBundleFiles lists the files to export from keyDir
+func collectSSHBundleFiles(keyDir string, includePrivate bool) ([]sshBundleFile, error) {
+	var files []sshBundleFile
+
+	addFile := func(name, kind, fingerprint string) error {
+		info, err := os.Stat(filepath.Join(keyDir, name))
+		if err != nil {
+			return err
+		}
+		files = append(files, sshBundleFile{
+			Name:        name,
+			Kind:        kind,
+			Mode:        uint32(info.Mode().Perm()),
+			Fingerprint: fingerprint,
+		})
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(keyDir, "config")); err == nil {
+		if err := addFile("config", "config", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	pubKeys, err := filepath.Glob(filepath.Join(keyDir, "*.pub"))
+	if err != nil {
+		return nil, fmt.Errorf("error searching for keys: %w", err)
+	}
+	sort.Strings(pubKeys)
+
+	for _, pubPath := range pubKeys {
+		name := filepath.Base(pubPath)
+		fp := ""
+		if data, err := os.ReadFile(pubPath); err == nil {
+			if pubKey, _, _, _, err := ssh.ParseAuthorizedKey(data); err == nil {
+				fp = ssh.FingerprintSHA256(pubKey)
+			}
+		}
+		if err := addFile(name, "public", fp); err != nil {
+			return nil, err
+		}
+
+		if includePrivate {
+			privName := strings.TrimSuffix(name, ".pub")
+			if _, err := os.Stat(filepath.Join(keyDir, privName)); err == nil {
+				if err := addFile(privName, "private", fp); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func runSSHExport(keyDir, archivePath string, includePrivate bool) error {
+	files, err := collectSSHBundleFiles(keyDir, includePrivate)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("nothing to export in %s", keyDir)
+	}
+
+	hostname, _ := os.Hostname()
+	manifest := sshBundleManifest{Created: time.Now(), Hostname: hostname, Files: files}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling manifest: %w", err)
+	}
+
+	// The bundle may hold private keys, so keep it owner-only
+	out, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	writeEntry := func(name string, mode int64, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: mode, Size: int64(len(data)), ModTime: manifest.Created}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := writeEntry(sshBundleManifestName, 0644, manifestData); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(keyDir, f.Name))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		if err := writeEntry(f.Name, int64(f.Mode), data); err != nil {
+			return fmt.Errorf("archiving %s: %w", f.Name, err)
+		}
+		detail := f.Kind
+		if f.Fingerprint != "" {
+			detail += " " + f.Fingerprint
+		}
+		fmt.Printf("  %s %-24s %s\n", Green.Sprint("+"), f.Name, Dim.Sprint(detail))
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("finalizing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("finalizing archive: %w", err)
+	}
+
+	fmt.Println()
+	Pass("Exported %d file(s) to %s", len(files), archivePath)
+	if includePrivate {
+		Warn("Bundle contains private keys - store it securely")
+	}
+	return nil
+}
+
+// readSSHBundle loads the manifest and file contents from an export archive
+func readSSHBundle(archivePath string) (sshBundleManifest, map[string][]byte, error) {
+	var manifest sshBundleManifest
+	contents := make(map[string][]byte)
+
+	in, err := os.Open(archivePath)
+	if err != nil {
+		return manifest, nil, fmt.Errorf("opening archive: %w", err)
+	}
+	defer in.Close()
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return manifest, nil, fmt.Errorf("reading archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return manifest, nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if hdr.Size > sshBundleMaxEntry {
+			return manifest, nil, fmt.Errorf("%s in bundle is %d bytes, over the %d byte limit", hdr.Name, hdr.Size, sshBundleMaxEntry)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, sshBundleMaxEntry))
+		if err != nil {
+			return manifest, nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		if int64(len(data)) != hdr.Size {
+			return manifest, nil, fmt.Errorf("reading %s: got %d of %d bytes", hdr.Name, len(data), hdr.Size)
+		}
+		contents[hdr.Name] = data
+	}
+
+	data, ok := contents[sshBundleManifestName]
+	if !ok {
+		return manifest, nil, fmt.Errorf("%s is not a blackdot SSH bundle (no manifest)", archivePath)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+
+	return manifest, contents, nil
+}
+
+// checkSSHBundleFingerprint verifies a public key still has the fingerprint
+// export recorded for it, so a corrupt bundle doesn't restore a broken key
+func checkSSHBundleFingerprint(f sshBundleFile, data []byte) error {
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return fmt.Errorf("%s in bundle is not a valid public key: %w", f.Name, err)
+	}
+	if got := ssh.FingerprintSHA256(pubKey); got != f.Fingerprint {
+		return fmt.Errorf("%s in bundle has fingerprint %s, manifest says %s", f.Name, got, f.Fingerprint)
+	}
+	return nil
+}
+
+func runSSHImport(archivePath, keyDir string, forceImport bool) error {
+	manifest, contents, err := readSSHBundle(archivePath)
+	if err != nil {
+		return err
+	}
+
+	// Validate everything before writing anything
+	var conflicts []string
+	for _, f := range manifest.Files {
+		if f.Name != filepath.Base(f.Name) || f.Name == "." || f.Name == ".." {
+			return fmt.Errorf("refusing unsafe path in bundle: %s", f.Name)
+		}
+		data, ok := contents[f.Name]
+		if !ok {
+			return fmt.Errorf("bundle is missing %s listed in manifest", f.Name)
+		}
+		if f.Kind == "public" && f.Fingerprint != "" {
+			if err := checkSSHBundleFingerprint(f, data); err != nil {
+				return err
+			}
+		}
+		if _, err := os.Stat(filepath.Join(keyDir, f.Name)); err == nil {
+			conflicts = append(conflicts, f.Name)
+		}
+	}
+	if len(conflicts) > 0 && !forceImport {
+		return classErrorf(ErrConflict, "would overwrite existing files: %s (use --force)", strings.Join(conflicts, ", "))
+	}
+
+	if err := os.MkdirAll(keyDir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", keyDir, err)
+	}
+
+	for _, f := range manifest.Files {
+		mode := os.FileMode(f.Mode).Perm()
+		if f.Kind == "private" || mode == 0 {
+			mode = 0600
+		}
+		path := filepath.Join(keyDir, f.Name)
+		if err := os.WriteFile(path, contents[f.Name], mode); err != nil {
+			return fmt.Errorf("writing %s: %w", f.Name, err)
+		}
+		// WriteFile keeps the mode of an existing file; enforce it
+		if err := os.Chmod(path, mode); err != nil {
+			return fmt.Errorf("setting permissions on %s: %w", f.Name, err)
+		}
+		fmt.Printf("  %s %-24s %s\n", Green.Sprint("+"), f.Name, Dim.Sprint(f.Kind))
+	}
+
+	fmt.Println()
+	Pass("Imported %d file(s) into %s (exported from %s on %s)",
+		len(manifest.Files), keyDir, manifest.Hostname, manifest.Created.Format("2006-01-02"))
+	return nil
+}
@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// sshMuxMarker starts the begin and end lines of a multiplexing block,
+	// which name the Host pattern so each one can be removed on its own
+	sshMuxMarker = "blackdot multiplexing"
+
+	// sshMuxSocket is the ControlPath file name: user, host, and port keep
+	// connections to different accounts apart
+	sshMuxSocket = "cm-%r@%h:%p"
+)
+
+// sshControlPersistPattern matches a ControlPersist value: yes, no, or a
+// time like 600, 10m, or 1h30m
+var sshControlPersistPattern = regexp.MustCompile(`^(?:yes|no|(?:\d+[sSmMhHdDwW]?)+)$`)
+
+// newSSHEnableMuxCmd adds a ControlMaster block to ~/.ssh/config
+func newSSHEnableMuxCmd() *cobra.Command {
+	var keyDir, host, persist string
+
+	cmd := &cobra.Command{
+		Use:   "enable-multiplexing",
+		Short: "Reuse SSH connections with ControlMaster",
+		Long: `Add a managed block to ~/.ssh/config that turns on connection
+multiplexing, so later ssh, scp, and git commands to the same host reuse
+the first connection instead of authenticating again:
+
+  Host *
+      ControlMaster auto
+      ControlPath ~/.ssh/cm-%r@%h:%p
+      ControlPersist 10m
+
+--host limits it to a Host pattern (e.g. "github.com" or "*.corp"); each
+pattern gets its own block, and running the command again replaces it.
+The block is appended to the end of the file, and ssh uses the first value
+it finds, so settings for the same hosts earlier in the file win.
+
+The socket directory is created with mode 700 (or tightened to it), since
+anyone who can reach a control socket can use the connection behind it.
+
+Examples:
+  blackdot tools ssh enable-multiplexing
+  blackdot tools ssh enable-multiplexing --host github.com --persist 1h
+  blackdot tools ssh disable-multiplexing`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keyDir == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("cannot determine home directory: %w", err)
+				}
+				keyDir = filepath.Join(home, ".ssh")
+			}
+			return runSSHEnableMux(keyDir, host, persist)
+		},
+	}
+
+	cmd.Flags().StringVarP(&keyDir, "dir", "d", "", "SSH directory (default: ~/.ssh)")
+	cmd.Flags().StringVar(&host, "host", "*", "Host pattern to multiplex")
+	cmd.Flags().StringVar(&persist, "persist", "10m", "ControlPersist: how long an idle master stays open (yes, no, or a time)")
+
+	return cmd
+}
+
+// newSSHDisableMuxCmd removes the block enable-multiplexing added
+func newSSHDisableMuxCmd() *cobra.Command {
+	var keyDir, host string
+
+	cmd := &cobra.Command{
+		Use:   "disable-multiplexing",
+		Short: "Remove the ControlMaster block from SSH config",
+		Long: `Remove the managed multiplexing block that enable-multiplexing added to
+~/.ssh/config. With --host, only the block for that Host pattern is
+removed; without it, every one is. Nothing else in the file is touched.
+
+Masters that are already running stay open until ControlPersist runs
+out; 'ssh -O exit <host>' closes one now.
+
+Examples:
+  blackdot tools ssh disable-multiplexing
+  blackdot tools ssh disable-multiplexing --host github.com`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keyDir == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("cannot determine home directory: %w", err)
+				}
+				keyDir = filepath.Join(home, ".ssh")
+			}
+			return runSSHDisableMux(keyDir, host)
+		},
+	}
+
+	cmd.Flags().StringVarP(&keyDir, "dir", "d", "", "SSH directory (default: ~/.ssh)")
+	cmd.Flags().StringVar(&host, "host", "", "Only remove the block for this Host pattern")
+
+	return cmd
+}
+
+func runSSHEnableMux(keyDir, host, persist string) error {
+	if strings.TrimSpace(host) == "" || strings.ContainsAny(host, "\r\n#") {
+		return classErrorf(ErrUsage, "--host must be a Host pattern, got %q", host)
+	}
+	if !sshControlPersistPattern.MatchString(persist) {
+		return classErrorf(ErrUsage, "--persist must be yes, no, or a time like 10m, got %q", persist)
+	}
+	if runtime.GOOS == "windows" {
+		Warn("Windows OpenSSH doesn't support ControlMaster; the block only helps other ssh clients reading this config")
+	}
+
+	// The sockets live next to the config, so the directory is the socket directory
+	if err := os.MkdirAll(keyDir, 0700); err != nil {
+		return fmt.Errorf("failed to create .ssh directory: %w", err)
+	}
+	if info, err := os.Stat(keyDir); err == nil && info.Mode().Perm()&0077 != 0 && runtime.GOOS != "windows" {
+		if err := os.Chmod(keyDir, 0700); err != nil {
+			return fmt.Errorf("failed to restrict %s: %w", keyDir, err)
+		}
+		Info("Restricted %s to mode 700 (was %o)", keyDir, info.Mode().Perm())
+	}
+
+	configPath, existing, mode, err := readSSHConfigFile(keyDir)
+	if err != nil {
+		return err
+	}
+	begin, end := sshMuxMarkers(host)
+	updated, err := replaceSSHBlock(existing, begin, end, renderSSHMuxBlock(host, sshMuxControlPath(keyDir), persist))
+	if err != nil {
+		return fmt.Errorf("%s: %w", configPath, err)
+	}
+	if updated == existing {
+		Pass("Multiplexing already enabled for Host %s in %s", host, configPath)
+		return nil
+	}
+	if err := os.WriteFile(configPath, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write SSH config: %w", err)
+	}
+	Pass("Enabled multiplexing for Host %s in %s", host, configPath)
+	switch persist {
+	case "no":
+		Dim.Printf("  The shared connection closes with the first session\n")
+	case "yes":
+		Dim.Printf("  The shared connection stays open until 'ssh -O exit <host>'\n")
+	default:
+		Dim.Printf("  The shared connection stays open %s after the last session closes\n", persist)
+	}
+	return nil
+}
+
+func runSSHDisableMux(keyDir, host string) error {
+	configPath, existing, mode, err := readSSHConfigFile(keyDir)
+	if err != nil {
+		return err
+	}
+
+	hosts := sshMuxHosts(existing)
+	if host != "" {
+		hosts = nil
+		if begin, _ := sshMuxMarkers(host); strings.Contains(existing, begin) {
+			hosts = []string{host}
+		}
+	}
+	if len(hosts) == 0 {
+		if host != "" {
+			Info("No multiplexing block for Host %s in %s", host, configPath)
+		} else {
+			Info("No multiplexing block in %s", configPath)
+		}
+		return nil
+	}
+
+	updated := existing
+	for _, h := range hosts {
+		begin, end := sshMuxMarkers(h)
+		if updated, err = removeSSHBlock(updated, begin, end); err != nil {
+			return fmt.Errorf("%s: %w", configPath, err)
+		}
+	}
+	if err := os.WriteFile(configPath, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write SSH config: %w", err)
+	}
+	for _, h := range hosts {
+		Pass("Disabled multiplexing for Host %s in %s", h, configPath)
+	}
+	return nil
+}
+
+// readSSHConfigFile returns the path, content, and mode of the config in
+// keyDir; a missing file is empty, with mode 600
+func readSSHConfigFile(keyDir string) (path, content string, mode os.FileMode, err error) {
+	path = filepath.Join(keyDir, "config")
+	mode = 0600
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return path, "", mode, nil
+	}
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to read SSH config: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return path, string(data), mode, nil
+}
+
+// sshMuxMarkers returns the begin and end lines of host's block
+func sshMuxMarkers(host string) (begin, end string) {
+	return fmt.Sprintf("# BEGIN %s (Host %s)", sshMuxMarker, host), fmt.Sprintf("# END %s (Host %s)", sshMuxMarker, host)
+}
+
+// sshMuxHosts lists the Host patterns with a multiplexing block in content
+func sshMuxHosts(content string) []string {
+	var hosts []string
+	prefix := fmt.Sprintf("# BEGIN %s (Host ", sshMuxMarker)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, prefix); ok && strings.HasSuffix(rest, ")") {
+			hosts = append(hosts, strings.TrimSuffix(rest, ")"))
+		}
+	}
+	return hosts
+}
+
+// sshMuxControlPath keeps ~/.ssh in the portable ~ form and quotes other
+// directories if they need it
+func sshMuxControlPath(keyDir string) string {
+	if home, err := os.UserHomeDir(); err == nil && filepath.Clean(keyDir) == filepath.Join(home, ".ssh") {
+		return "~/.ssh/" + sshMuxSocket
+	}
+	path := filepath.Join(keyDir, sshMuxSocket)
+	if strings.ContainsAny(path, " \t") {
+		path = `"` + path + `"`
+	}
+	return path
+}
+
+// renderSSHMuxBlock renders the multiplexing settings for host between its
+// markers
+func renderSSHMuxBlock(host, controlPath, persist string) string {
+	begin, end := sshMuxMarkers(host)
+	var b strings.Builder
+	b.WriteString(begin + "\n")
+	b.WriteString("# Added by 'blackdot tools ssh enable-multiplexing'; remove with disable-multiplexing.\n")
+	fmt.Fprintf(&b, "Host %s\n", host)
+	b.WriteString("    ControlMaster auto\n")
+	fmt.Fprintf(&b, "    ControlPath %s\n", controlPath)
+	fmt.Fprintf(&b, "    ControlPersist %s\n", persist)
+	b.WriteString(end + "\n")
+	return b.String()
+}
+
+// removeSSHBlock deletes the block between the marker lines, with the blank
+// line replaceSSHBlock put before it when appending
+func removeSSHBlock(content, beginMarker, endMarker string) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	begin, end, err := findSSHBlock(lines, beginMarker, endMarker)
+	if err != nil || begin < 0 {
+		return content, err
+	}
+	if begin > 0 && strings.TrimSpace(lines[begin-1]) == "" {
+		begin--
+	}
+	return strings.Join(lines[:begin], "") + strings.Join(lines[end+1:], ""), nil
+}
@@ -0,0 +1,463 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/blackwell-systems/blackdot/internal/config"
+	"golang.org/x/crypto/ssh"
+)
+
+// writeTestSSHKey writes a private key block and its .pub file
+func writeTestSSHKey(t *testing.T, dir, name string, block *pem.Block, pub ssh.PublicKey) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".pub"), ssh.MarshalAuthorizedKey(pub), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestAuditSSHDir verifies weak-key and passphrase findings
+func TestAuditSSHDir(t *testing.T) {
+	dir := t.TempDir()
+
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	sshEdPub, _ := ssh.NewPublicKey(edPub)
+
+	// Passphrase-protected ed25519: clean
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(edPriv, "good", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestSSHKey(t, dir, "id_ed25519", block, sshEdPub)
+
+	// Unencrypted ed25519: no passphrase
+	block, err = ssh.MarshalPrivateKey(edPriv, "bare")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestSSHKey(t, dir, "id_bare", block, sshEdPub)
+
+	// Legacy PEM RSA 2048: too small and unencrypted
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshRSAPub, _ := ssh.NewPublicKey(&rsaKey.PublicKey)
+	writeTestSSHKey(t, dir, "id_rsa", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, sshRSAPub)
+
+	// Not keys
+	os.WriteFile(filepath.Join(dir, "config"), []byte("Host *\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "known_hosts"), []byte(""), 0644)
+
+	results, err := auditSSHDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]sshKeyAudit)
+	for _, a := range results {
+		byName[a.Name] = a
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 keys, got %d: %+v", len(results), results)
+	}
+
+	if a := byName["id_ed25519"]; !a.Encrypted || len(a.Findings) != 0 {
+		t.Errorf("id_ed25519: expected encrypted with no findings, got %+v", a)
+	}
+	if a := byName["id_bare"]; a.Encrypted || len(a.Findings) != 1 || !strings.Contains(a.Findings[0].Issue, "passphrase") {
+		t.Errorf("id_bare: expected no-passphrase finding, got %+v", a)
+	}
+	a := byName["id_rsa"]
+	if a.Bits != 2048 || len(a.Findings) != 2 || a.Findings[0].Severity != sshSeverityMedium {
+		t.Errorf("id_rsa: expected 2048-bit medium size and passphrase findings, got %+v", a)
+	}
+}
+
+// TestListSSHKeys verifies the --json listing and header-only passphrase detection
+func TestListSSHKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	sshEdPub, _ := ssh.NewPublicKey(edPub)
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(edPriv, "locked", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestSSHKey(t, dir, "id_locked", block, sshEdPub)
+
+	block, err = ssh.MarshalPrivateKey(edPriv, "bare")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestSSHKey(t, dir, "id_bare", block, sshEdPub)
+
+	// Public key only, with a comment
+	pubLine := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshEdPub))) + " me@laptop\n"
+	os.WriteFile(filepath.Join(dir, "id_pubonly.pub"), []byte(pubLine), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.pub"), []byte("not a key\n"), 0644)
+
+	keys, err := listSSHKeys(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys, got %d: %+v", len(keys), keys)
+	}
+	byName := make(map[string]sshKeyInfo)
+	for _, k := range keys {
+		byName[filepath.Base(k.Path)] = k
+		if k.Type != ssh.KeyAlgoED25519 || k.Bits != 256 || !strings.HasPrefix(k.Fingerprint, "SHA256:") {
+			t.Errorf("%s: unexpected key description %+v", k.Path, k)
+		}
+	}
+
+	if k := byName["id_locked.pub"]; k.HasPassphrase == nil || !*k.HasPassphrase {
+		t.Errorf("id_locked: expected hasPassphrase true, got %+v", k)
+	}
+	if k := byName["id_bare.pub"]; k.HasPassphrase == nil || *k.HasPassphrase {
+		t.Errorf("id_bare: expected hasPassphrase false, got %+v", k)
+	}
+	if k := byName["id_pubonly.pub"]; k.HasPassphrase != nil || k.Comment != "me@laptop" {
+		t.Errorf("id_pubonly: expected comment and null hasPassphrase, got %+v", k)
+	}
+}
+
+// TestParseSSHVerbose verifies outcome, identity file, and offered key parsing
+func TestParseSSHVerbose(t *testing.T) {
+	const preamble = `OpenSSH_9.2p1, OpenSSL 3.0.17 1 Jul 2025
+debug1: Connecting to github.com [140.82.112.3] port 22.
+debug1: Connection established.
+debug1: identity file /home/u/.ssh/id_rsa type -1
+debug1: identity file /home/u/.ssh/id_ed25519 type 3
+debug1: identity file /home/u/.ssh/id_ed25519-cert type -1
+debug1: identity file /home/u/.ssh/id_ed25519 type 3
+debug1: Will attempt key: /home/u/.ssh/id_ed25519 ED25519 SHA256:abc explicit
+debug1: Offering public key: /home/u/.ssh/id_ed25519 ED25519 SHA256:abc explicit
+`
+
+	tests := []struct {
+		name      string
+		output    string
+		outcome   string
+		accepted  string
+		wantInMsg string
+	}{
+		{
+			name: "success",
+			output: preamble + `debug1: Server accepts key: /home/u/.ssh/id_ed25519 ED25519 SHA256:abc explicit
+Authenticated to github.com ([140.82.112.3]:22) using "publickey".
+Hi u! You've successfully authenticated, but GitHub does not provide shell access.
+debug1: Exit status 1
+`,
+			outcome:  sshTestSuccess,
+			accepted: "/home/u/.ssh/id_ed25519",
+		},
+		{
+			name:      "auth failed",
+			output:    preamble + "debug1: No more authentication methods to try.\ngit@github.com: Permission denied (publickey).\n",
+			outcome:   sshTestAuthFailed,
+			wantInMsg: "Permission denied (publickey)",
+		},
+		{
+			name:      "timeout",
+			output:    "debug1: Connecting to 10.0.0.1 [10.0.0.1] port 22.\nssh: connect to host 10.0.0.1 port 22: Connection timed out\n",
+			outcome:   sshTestTimeout,
+			wantInMsg: "Connection timed out",
+		},
+		{
+			name:      "unresolvable",
+			output:    "ssh: Could not resolve hostname nope: Name or service not known\n",
+			outcome:   sshTestUnreachable,
+			wantInMsg: "Could not resolve hostname nope",
+		},
+		{
+			name:    "host key",
+			output:  "No ED25519 host key is known for example.com and you have requested strict checking.\nHost key verification failed.\n",
+			outcome: sshTestHostKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := parseSSHVerbose(tt.output)
+			if r.Outcome != tt.outcome {
+				t.Errorf("Outcome = %q, want %q", r.Outcome, tt.outcome)
+			}
+			if !strings.Contains(r.Message, tt.wantInMsg) {
+				t.Errorf("Message = %q, want it to contain %q", r.Message, tt.wantInMsg)
+			}
+			if tt.accepted == "" && r.Accepted != nil {
+				t.Errorf("Accepted = %v, want none", r.Accepted)
+			} else if tt.accepted != "" && (r.Accepted == nil || r.Accepted.Path != tt.accepted) {
+				t.Errorf("Accepted = %v, want %s", r.Accepted, tt.accepted)
+			}
+		})
+	}
+
+	r := parseSSHVerbose(preamble)
+	if len(r.IdentityFiles) != 1 || r.IdentityFiles[0] != "/home/u/.ssh/id_ed25519" {
+		t.Errorf("IdentityFiles = %v, want only the existing id_ed25519", r.IdentityFiles)
+	}
+	if len(r.Offered) != 1 || r.Offered[0].Type != "ED25519" || r.Offered[0].Fingerprint != "SHA256:abc" {
+		t.Errorf("Offered = %+v", r.Offered)
+	}
+}
+
+// TestSSHConfigGenerate verifies hosts from config.json replace only the
+// managed block and regenerating is a no-op
+func TestSSHConfigGenerate(t *testing.T) {
+	cfgHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfgHome)
+	os.MkdirAll(filepath.Join(cfgHome, "blackdot"), 0755)
+	os.WriteFile(filepath.Join(cfgHome, "blackdot", "config.json"), []byte(`{"version": 3, "ssh": {"hosts": [
+		{"name": "web", "hostname": "10.0.0.5", "user": "deploy", "port": 2222, "identity_file": "~/My Keys/id_web"},
+		{"name": "db", "hostname": "db.internal", "proxy_jump": "web", "options": {"ServerAliveInterval": "30", "ForwardAgent": "no"}}
+	]}}`), 0644)
+
+	keyDir := t.TempDir()
+	configPath := filepath.Join(keyDir, "config")
+	os.WriteFile(configPath, []byte("Host personal\n    User me\n\n"+sshManagedBegin+"\nHost stale\n"+sshManagedEnd+"\n\nHost *\n    AddKeysToAgent yes\n"), 0600)
+
+	// --dry-run leaves the file alone
+	if err := runSSHConfigGenerate(keyDir, "config", "", true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(configPath); strings.Contains(string(data), "Host web") {
+		t.Fatal("dry run wrote the config")
+	}
+
+	if err := runSSHConfigGenerate(keyDir, "config", "", false); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(configPath)
+	got := string(data)
+	for _, want := range []string{
+		"Host personal\n    User me\n\n" + sshManagedBegin + "\n",
+		"Host web\n    HostName 10.0.0.5\n    User deploy\n    Port 2222\n    IdentityFile \"~/My Keys/id_web\"\n",
+		"Host db\n    HostName db.internal\n    ProxyJump web\n    ForwardAgent no\n    ServerAliveInterval 30\n" + sshManagedEnd + "\n",
+		sshManagedEnd + "\n\nHost *\n    AddKeysToAgent yes\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "stale") {
+		t.Errorf("old managed hosts should be replaced:\n%s", got)
+	}
+
+	if err := runSSHConfigGenerate(keyDir, "config", "", false); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(configPath); string(again) != got {
+		t.Errorf("regenerating changed the config:\n%s", again)
+	}
+}
+
+// TestReplaceSSHManagedBlock verifies the block is appended when missing and
+// broken markers are errors
+func TestReplaceSSHManagedBlock(t *testing.T) {
+	block := sshManagedBegin + "\nHost a\n" + sshManagedEnd + "\n"
+
+	got, err := replaceSSHManagedBlock("Host mine\n    User me", block)
+	if err != nil || got != "Host mine\n    User me\n\n"+block {
+		t.Errorf("expected block appended, got %q (%v)", got, err)
+	}
+	if _, err := replaceSSHManagedBlock("Host x\n"+sshManagedBegin+"\nHost y\n", block); err == nil {
+		t.Error("expected error for a begin marker without an end")
+	}
+	if _, err := replaceSSHManagedBlock(sshManagedEnd+"\n"+block, block); err == nil {
+		t.Error("expected error for an end marker before the begin")
+	}
+}
+
+// TestSSHMultiplexing verifies enable adds one block per Host pattern,
+// tightens the socket directory, and disable removes only those blocks
+func TestSSHMultiplexing(t *testing.T) {
+	keyDir := filepath.Join(t.TempDir(), "ssh dir")
+	if err := os.MkdirAll(keyDir, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	configPath := filepath.Join(keyDir, "config")
+	original := "Host personal\n    User me\n"
+	os.WriteFile(configPath, []byte(original), 0600)
+
+	if err := runSSHEnableMux(keyDir, "*", "10m"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSSHEnableMux(keyDir, "github.com", "1h"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(keyDir); runtime.GOOS != "windows" && info.Mode().Perm() != 0700 {
+		t.Errorf("socket directory mode = %o, want 700", info.Mode().Perm())
+	}
+
+	data, _ := os.ReadFile(configPath)
+	got := string(data)
+	begin, end := sshMuxMarkers("*")
+	controlPath := `"` + filepath.Join(keyDir, "cm-%r@%h:%p") + `"`
+	for _, want := range []string{
+		original + "\n" + begin + "\n",
+		"Host *\n    ControlMaster auto\n    ControlPath " + controlPath + "\n    ControlPersist 10m\n" + end + "\n",
+		"Host github.com\n    ControlMaster auto\n    ControlPath " + controlPath + "\n    ControlPersist 1h\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+
+	// Enabling again replaces the block instead of adding another
+	if err := runSSHEnableMux(keyDir, "*", "30m"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(configPath)
+	if n := strings.Count(string(data), begin); n != 1 || !strings.Contains(string(data), "ControlPersist 30m") {
+		t.Errorf("expected one updated block for Host *, got %d in:\n%s", n, data)
+	}
+
+	if err := runSSHDisableMux(keyDir, "github.com"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(configPath)
+	if strings.Contains(string(data), "github.com") || !strings.Contains(string(data), begin) {
+		t.Errorf("expected only the github.com block removed:\n%s", data)
+	}
+	if err := runSSHDisableMux(keyDir, ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ = os.ReadFile(configPath); string(data) != original {
+		t.Errorf("expected the original config back, got %q", data)
+	}
+
+	for _, args := range [][2]string{{"", "10m"}, {"a\nHost b", "10m"}, {"*", "forever"}} {
+		if err := runSSHEnableMux(keyDir, args[0], args[1]); ExitCode(err) != ExitUsage {
+			t.Errorf("enable %q --persist %q: expected a usage error, got %v", args[0], args[1], err)
+		}
+	}
+}
+
+// TestValidateSSHHosts verifies hosts that would break the config are rejected
+func TestValidateSSHHosts(t *testing.T) {
+	tests := []struct {
+		host config.SSHHost
+		want string
+	}{
+		{config.SSHHost{Name: " "}, "no name"},
+		{config.SSHHost{Name: "a", Port: 70000}, "out of range"},
+		{config.SSHHost{Name: "a", HostName: "x\nHost evil"}, "line breaks"},
+		{config.SSHHost{Name: "a", Options: map[string]string{"Forward Agent": "yes"}}, "not an ssh_config keyword"},
+		{config.SSHHost{Name: "a", Options: map[string]string{"HostName": "x"}}, "its own field"},
+		{config.SSHHost{Name: "a", Options: map[string]string{"Match": "all"}}, "new block"},
+	}
+	for _, tt := range tests {
+		err := validateSSHHosts([]config.SSHHost{tt.host})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: expected error containing %q, got %v", tt.host, tt.want, err)
+		}
+	}
+	if err := validateSSHHosts([]config.SSHHost{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Error("expected error for duplicate hosts")
+	}
+}
+
+// TestConvertSSHKey round-trips keys between OpenSSH and PEM formats
+func TestConvertSSHKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	// PKCS#1 -> OpenSSH -> PKCS#1 gives back the same file
+	openssh, err := convertSSHKey(pkcs1, "openssh", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !openssh.private || openssh.from != "PEM PKCS#1" || !strings.Contains(string(openssh.data), "OPENSSH PRIVATE KEY") {
+		t.Fatalf("unexpected OpenSSH conversion: %+v", openssh)
+	}
+	back, err := convertSSHKey(openssh.data, "pem", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(back.data) != string(pkcs1) {
+		t.Error("PKCS#1 round trip changed the key")
+	}
+	if pkcs8, _ := convertSSHKey(pkcs1, "pem", nil, true); pkcs8.to != "PEM PKCS#8" {
+		t.Errorf("--pkcs8 wrote %s", pkcs8.to)
+	}
+
+	// A protected key needs the passphrase, and keeps it as OpenSSH
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(edPriv, "", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	protected := pem.EncodeToMemory(block)
+	if _, err := convertSSHKey(protected, "pem", nil, false); err != errSSHKeyPassphrase {
+		t.Errorf("missing passphrase: got %v", err)
+	}
+	if _, err := convertSSHKey(protected, "pem", []byte("wrong"), false); err == nil {
+		t.Error("wrong passphrase accepted")
+	}
+	edPEM, err := convertSSHKey(protected, "pem", []byte("secret"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edPEM.to != "PEM PKCS#8" {
+		t.Errorf("ed25519 written as %s", edPEM.to)
+	}
+	reprotected, err := convertSSHKey(edPEM.data, "openssh", []byte("secret"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ssh.ParseRawPrivateKey(reprotected.data); err == nil {
+		t.Error("OpenSSH output lost the passphrase")
+	}
+
+	// Public keys: authorized_keys -> PKIX -> authorized_keys
+	sshPub, _ := ssh.NewPublicKey(edPriv.Public())
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
+	pkix, err := convertSSHKey([]byte(line+" me@host\n"), "pem", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkix.private || pkix.to != "PEM PKIX" {
+		t.Fatalf("unexpected public conversion: %+v", pkix)
+	}
+	pubBack, err := convertSSHKey(pkix.data, "openssh", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(pubBack.data)) != line {
+		t.Errorf("public round trip: got %q, want %q", pubBack.data, line)
+	}
+
+	// Private keys are written 0600, and existing files need --force
+	dir := t.TempDir()
+	in := filepath.Join(dir, "id_rsa.pem")
+	out := filepath.Join(dir, "id_rsa")
+	if err := os.WriteFile(in, pkcs1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSSHConvert(in, "openssh", out, false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(out); err != nil || (runtime.GOOS != "windows" && info.Mode().Perm() != 0600) {
+		t.Errorf("private key mode: %v %v", info.Mode(), err)
+	}
+	if err := runSSHConvert(in, "openssh", out, false, false, false); err == nil {
+		t.Error("overwrote an existing file without --force")
+	}
+}
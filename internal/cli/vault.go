@@ -2426,12 +2426,7 @@ func checkItemDrift(localPath, vaultContent string) int {
 
 // getVaultDriftStatePath returns the path to the vault drift state file
 func getVaultDriftStatePath() string {
-	cacheDir := os.Getenv("XDG_CACHE_HOME")
-	if cacheDir == "" {
-		home, _ := os.UserHomeDir()
-		cacheDir = filepath.Join(home, ".cache")
-	}
-	return filepath.Join(cacheDir, "blackdot", "vault-state.json")
+	return filepath.Join(CacheDir(), "vault-state.json")
 }
 
 // saveVaultDriftState saves the current vault drift state after restore
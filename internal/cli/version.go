@@ -1,22 +1,119 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"runtime"
+	"runtime/debug"
 
 	"github.com/spf13/cobra"
 )
 
+// versionInfo describes the running binary, for version --verbose and --json
+type versionInfo struct {
+	Version      string              `json:"version"`
+	Commit       string              `json:"commit"`
+	Date         string              `json:"date"`
+	Modified     bool                `json:"modified,omitempty"` // built from a tree with uncommitted changes
+	GoVersion    string              `json:"go_version"`
+	Platform     string              `json:"platform"`
+	Module       string              `json:"module,omitempty"`
+	Dependencies []versionDependency `json:"dependencies,omitempty"`
+}
+
+// versionDependency is a module compiled into the binary
+type versionDependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+}
+
 func newVersionCmd() *cobra.Command {
-	return &cobra.Command{
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
+		Long: `Print the blackdot version. With --verbose, also print the git commit,
+build date, Go version, and platform; with --json, print all of that plus
+the modules compiled into the binary.
+
+Release builds get the version, commit, and date from -ldflags. Other
+builds (go install, go build in a checkout) fall back to what the Go
+toolchain recorded: the module version and the VCS revision and time.
+
+Examples:
+  blackdot version
+  blackdot version --verbose
+  blackdot version --json | jq -r .commit`,
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("⚫ blackdot %s (Go CLI)\n", versionStr)
+			info := buildVersionInfo(debug.ReadBuildInfo())
+			if jsonOutput {
+				data, _ := json.MarshalIndent(info, "", "  ")
+				fmt.Println(string(data))
+				return
+			}
+
+			fmt.Printf("⚫ blackdot %s (Go CLI)\n", info.Version)
 			if verbose {
-				fmt.Printf("  runtime: go\n")
-				fmt.Printf("  commit:  %s\n", commitStr)
-				fmt.Printf("  built:   %s\n", dateStr)
+				commit := info.Commit
+				if info.Modified {
+					commit += " (modified)"
+				}
+				fmt.Printf("  runtime:  go\n")
+				fmt.Printf("  commit:   %s\n", commit)
+				fmt.Printf("  built:    %s\n", info.Date)
+				fmt.Printf("  go:       %s\n", info.GoVersion)
+				fmt.Printf("  platform: %s\n", info.Platform)
 			}
 		},
 	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output build metadata and dependencies as JSON")
+
+	return cmd
+}
+
+// buildVersionInfo combines the -ldflags values with the build info the Go
+// toolchain embeds, which fills in whatever -ldflags left at its default
+func buildVersionInfo(bi *debug.BuildInfo, ok bool) versionInfo {
+	info := versionInfo{
+		Version:   versionStr,
+		Commit:    commitStr,
+		Date:      dateStr,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if !ok || bi == nil {
+		return info
+	}
+
+	if bi.GoVersion != "" {
+		info.GoVersion = bi.GoVersion
+	}
+	info.Module = bi.Main.Path
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "none" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Date == "unknown" {
+				info.Date = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	for _, dep := range bi.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		info.Dependencies = append(info.Dependencies, versionDependency{Path: dep.Path, Version: dep.Version, Sum: dep.Sum})
+	}
+	return info
 }
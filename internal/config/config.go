@@ -7,6 +7,7 @@
 package config
 
 import (
+	_ "embed"
 	"encoding/json"
 	"errors"
 	"os"
@@ -32,13 +33,29 @@ const (
 	UserConfigFile    = "config.json"
 )
 
+// Schema is the JSON schema for the user config file. It lists the keys
+// blackdot reads; other keys are allowed, since config set accepts any key.
+//
+//go:embed config.schema.json
+var Schema []byte
+
 // Config represents the blackdot configuration
 type Config struct {
-	Version  int                    `json:"version"`
-	Features map[string]bool        `json:"features,omitempty"`
-	Vault    VaultConfig            `json:"vault,omitempty"`
-	Setup    SetupState             `json:"setup,omitempty"`
-	Extra    map[string]interface{} `json:"-"` // Catch-all for unknown fields
+	Version        int                      `json:"version"`
+	Features       map[string]bool          `json:"features,omitempty"`
+	FeatureSources map[string]FeatureSource `json:"feature_sources,omitempty"` // Why each enabled feature is on
+	Preset         string                   `json:"preset,omitempty"`          // Last applied feature preset
+	Vault          VaultConfig              `json:"vault,omitempty"`
+	Setup          SetupState               `json:"setup,omitempty"`
+	SSH            SSHConfig                `json:"ssh,omitempty"`
+	Extra          map[string]interface{}   `json:"-"` // Catch-all for unknown fields
+}
+
+// FeatureSource records how a feature was enabled: "direct", "dependency"
+// or "preset", with the feature or preset responsible in By
+type FeatureSource struct {
+	Source string `json:"source"`
+	By     string `json:"by,omitempty"`
 }
 
 // VaultConfig holds vault-related configuration
@@ -49,6 +66,23 @@ type VaultConfig struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// SSHConfig holds the hosts 'tools ssh config-generate' renders
+type SSHConfig struct {
+	Hosts []SSHHost `json:"hosts,omitempty"`
+}
+
+// SSHHost is one Host block in ~/.ssh/config. Options holds any other
+// ssh_config keywords (ForwardAgent, LocalForward, ...) by name.
+type SSHHost struct {
+	Name         string            `json:"name"` // Host pattern(s)
+	HostName     string            `json:"hostname,omitempty"`
+	User         string            `json:"user,omitempty"`
+	Port         int               `json:"port,omitempty"`
+	IdentityFile string            `json:"identity_file,omitempty"`
+	ProxyJump    string            `json:"proxy_jump,omitempty"`
+	Options      map[string]string `json:"options,omitempty"`
+}
+
 // SetupState tracks setup wizard progress
 type SetupState struct {
 	Completed []string `json:"completed,omitempty"`
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -479,3 +482,77 @@ func TestLayerResultFields(t *testing.T) {
 		t.Error("File not set correctly")
 	}
 }
+
+// schemaNode is the part of a JSON schema TestSchemaMatchesConfig compares
+type schemaNode struct {
+	Properties           map[string]*schemaNode `json:"properties"`
+	Items                *schemaNode            `json:"items"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+}
+
+// schemaPaths adds the dotted path of every key node declares to paths;
+// array items add "[]" and map values ".*" to the path
+func schemaPaths(node *schemaNode, prefix string, paths map[string]bool) {
+	if node == nil {
+		return
+	}
+	for key, sub := range node.Properties {
+		path := strings.TrimPrefix(prefix+"."+key, ".")
+		paths[path] = true
+		schemaPaths(sub, path, paths)
+	}
+	schemaPaths(node.Items, prefix+"[]", paths)
+	var values schemaNode
+	if json.Unmarshal(node.AdditionalProperties, &values) == nil {
+		schemaPaths(&values, prefix+".*", paths)
+	}
+}
+
+// structPaths is schemaPaths for the json tags of t
+func structPaths(t reflect.Type, prefix string, paths map[string]bool) {
+	switch t.Kind() {
+	case reflect.Slice:
+		structPaths(t.Elem(), prefix+"[]", paths)
+	case reflect.Map:
+		structPaths(t.Elem(), prefix+".*", paths)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			path := strings.TrimPrefix(prefix+"."+name, ".")
+			paths[path] = true
+			structPaths(t.Field(i).Type, path, paths)
+		}
+	}
+}
+
+// TestSchemaMatchesConfig verifies config.schema.json declares exactly the
+// Config struct's keys, plus the ones only ever read by path with Get
+func TestSchemaMatchesConfig(t *testing.T) {
+	readByPath := []string{"vault.last_push", "vault.last_pull", "packages", "packages.tier", "paths", "paths.workspace_target"}
+
+	var schema schemaNode
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("parsing schema: %v", err)
+	}
+	inSchema := map[string]bool{}
+	schemaPaths(&schema, "", inSchema)
+	inStruct := map[string]bool{}
+	structPaths(reflect.TypeOf(Config{}), "", inStruct)
+	for _, path := range readByPath {
+		inStruct[path] = true
+	}
+
+	for path := range inStruct {
+		if !inSchema[path] {
+			t.Errorf("%s is read by blackdot but missing from config.schema.json", path)
+		}
+	}
+	for path := range inSchema {
+		if !inStruct[path] {
+			t.Errorf("config.schema.json declares %s, which blackdot never reads", path)
+		}
+	}
+}
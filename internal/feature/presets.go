@@ -1,14 +1,23 @@
 package feature
 
-// Preset represents a named set of features
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Preset represents a named set of features. A preset may extend another
+// preset, inheriting its features before adding its own.
 type Preset struct {
 	Name        string
 	Description string
+	Extends     string
 	Features    []string
 }
 
-// Built-in presets - mirrors FEATURE_PRESETS in lib/_features.sh exactly
-var presets = map[string]*Preset{
+// Built-in preset definitions - resolved sets mirror FEATURE_PRESETS in
+// lib/_features.sh exactly
+var presetDefs = map[string]*Preset{
 	"minimal": {
 		Name:        "minimal",
 		Description: "Shell only (fastest startup)",
@@ -20,8 +29,8 @@ var presets = map[string]*Preset{
 	"developer": {
 		Name:        "developer",
 		Description: "Vault, AWS helpers, git hooks, modern CLI",
+		Extends:     "minimal",
 		Features: []string{
-			"shell",
 			"vault",
 			"aws_helpers",
 			"cdk_tools",
@@ -34,7 +43,6 @@ var presets = map[string]*Preset{
 			"sdkman_integration",
 			"git_hooks",
 			"modern_cli",
-			"config_layers",
 		},
 	},
 	"claude": {
@@ -53,71 +61,240 @@ var presets = map[string]*Preset{
 	"full": {
 		Name:        "full",
 		Description: "All features enabled",
+		Extends:     "developer",
 		Features: []string{
-			"shell",
 			"workspace_symlink",
 			"claude_integration",
-			"vault",
 			"templates",
-			"aws_helpers",
-			"cdk_tools",
-			"rust_tools",
-			"go_tools",
-			"python_tools",
-			"ssh_tools",
-			"docker_tools",
-			"git_hooks",
 			"drift_check",
 			"backup_auto",
 			"health_metrics",
-			"config_layers",
-			"modern_cli",
-			"nvm_integration",
-			"sdkman_integration",
 		},
 	},
 }
 
-// GetPreset returns a preset by name
+// presets holds the resolved built-in presets
+var presets = mustResolvePresets(presetDefs)
+
+// customPresets holds the resolved user presets set by SetCustomPresets
+var customPresets = map[string]*Preset{}
+
+// resolvePresets flattens extends chains so every returned preset carries
+// its full feature set, parent features first and without duplicates
+func resolvePresets(defs map[string]*Preset) (map[string]*Preset, error) {
+	resolved := make(map[string]*Preset, len(defs))
+
+	var resolve func(name string, chain []string) (*Preset, error)
+	resolve = func(name string, chain []string) (*Preset, error) {
+		if p, ok := resolved[name]; ok {
+			return p, nil
+		}
+		for _, seen := range chain {
+			if seen == name {
+				return nil, &PresetCycleError{Chain: append(chain, name)}
+			}
+		}
+
+		def, ok := defs[name]
+		if !ok {
+			return nil, &PresetNotFoundError{Name: name}
+		}
+
+		var features []string
+		if def.Extends != "" {
+			parent, err := resolve(def.Extends, append(chain, name))
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, parent.Features...)
+		}
+		features = append(features, def.Features...)
+
+		p := &Preset{
+			Name:        def.Name,
+			Description: def.Description,
+			Extends:     def.Extends,
+			Features:    dedupe(features),
+		}
+		resolved[name] = p
+		return p, nil
+	}
+
+	for name := range defs {
+		if _, err := resolve(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// mustResolvePresets resolves the built-in presets, which must be valid
+func mustResolvePresets(defs map[string]*Preset) map[string]*Preset {
+	resolved, err := resolvePresets(defs)
+	if err != nil {
+		panic("invalid built-in presets: " + err.Error())
+	}
+	return resolved
+}
+
+// dedupe removes repeated entries, keeping the first occurrence
+func dedupe(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// SetCustomPresets replaces the user-defined presets. A custom preset may
+// extend a built-in or another custom preset but may not reuse a built-in
+// name. On error the previous custom presets are kept.
+func SetCustomPresets(defs []*Preset) error {
+	all := make(map[string]*Preset, len(presetDefs)+len(defs))
+	for name, p := range presetDefs {
+		all[name] = p
+	}
+	for _, p := range defs {
+		if IsBuiltinPreset(p.Name) {
+			return &PresetBuiltinError{Name: p.Name}
+		}
+		all[p.Name] = p
+	}
+
+	resolved, err := resolvePresets(all)
+	if err != nil {
+		return err
+	}
+	custom := make(map[string]*Preset, len(defs))
+	for _, p := range defs {
+		custom[p.Name] = resolved[p.Name]
+	}
+	customPresets = custom
+	return nil
+}
+
+// IsBuiltinPreset reports whether name is one of the built-in presets
+func IsBuiltinPreset(name string) bool {
+	_, ok := presetDefs[name]
+	return ok
+}
+
+// GetPreset returns a preset by name with its extends chain fully resolved
 func GetPreset(name string) (*Preset, bool) {
-	p, ok := presets[name]
+	if p, ok := presets[name]; ok {
+		return p, true
+	}
+	p, ok := customPresets[name]
 	return p, ok
 }
 
-// AllPresets returns all available presets in display order
+// AllPresets returns all available presets in display order: built-ins
+// first, then custom presets by name
 func AllPresets() []*Preset {
-	return []*Preset{
-		presets["minimal"],
-		presets["developer"],
-		presets["claude"],
-		presets["full"],
+	var all []*Preset
+	for _, name := range PresetNames() {
+		p, _ := GetPreset(name)
+		all = append(all, p)
 	}
+	return all
 }
 
 // PresetNames returns just the preset names
 func PresetNames() []string {
-	return []string{"minimal", "developer", "claude", "full"}
+	names := []string{"minimal", "developer", "claude", "full"}
+	var custom []string
+	for name := range customPresets {
+		custom = append(custom, name)
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
 }
 
-// ApplyPreset applies a preset to the registry
+// ApplyPreset replaces the enabled state with the preset's features on top
+// of the defaults. The new state is built and validated on a scratch copy and
+// only swapped in on success, so a failed apply leaves r unchanged.
 func (r *Registry) ApplyPreset(name string) error {
+	next, err := r.presetRegistry(name)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enabled = next.enabled
+	r.provenance = next.provenance
+	r.preset = next.preset
+	return nil
+}
+
+// PresetDiff is how applying a preset would change the enabled features
+type PresetDiff struct {
+	Preset  string
+	Enable  []string // disabled now, enabled by the preset
+	Disable []string // enabled now, disabled by the preset
+}
+
+// Empty reports whether the preset matches the current state
+func (d *PresetDiff) Empty() bool {
+	return len(d.Enable) == 0 && len(d.Disable) == 0
+}
+
+// PresetDiff reports what ApplyPreset(name) would change, leaving r as it is
+func (r *Registry) PresetDiff(name string) (*PresetDiff, error) {
+	next, err := r.presetRegistry(name)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	diff := &PresetDiff{Preset: name}
+	for fname := range r.features {
+		before, after := r.isEnabled(fname), next.isEnabled(fname)
+		switch {
+		case after && !before:
+			diff.Enable = append(diff.Enable, fname)
+		case before && !after:
+			diff.Disable = append(diff.Disable, fname)
+		}
+	}
+	sort.Strings(diff.Enable)
+	sort.Strings(diff.Disable)
+	return diff, nil
+}
+
+// presetRegistry builds and validates the state preset name produces, on a
+// registry sharing r's definitions
+func (r *Registry) presetRegistry(name string) (*Registry, error) {
 	preset, ok := GetPreset(name)
 	if !ok {
-		return &PresetNotFoundError{Name: name}
+		return nil, &PresetNotFoundError{Name: name}
 	}
 
-	// Reset to defaults first
-	r.enabled = make(map[string]bool)
-	r.initDefaults()
+	// Same definitions, fresh state
+	next := &Registry{
+		features:   r.features,
+		enabled:    make(map[string]bool),
+		provenance: make(map[string]Provenance),
+		preset:     name,
+		conflicts:  r.conflicts,
+		envMap:     r.envMap,
+	}
+	next.initDefaults()
 
-	// Enable preset features
 	for _, fname := range preset.Features {
-		if err := r.Enable(fname); err != nil {
-			return err
+		if err := next.enable(fname, Provenance{Source: SourcePreset, By: name}); err != nil {
+			return nil, fmt.Errorf("applying preset %s: %w", name, err)
 		}
 	}
-
-	return nil
+	if err := next.Validate(); err != nil {
+		return nil, fmt.Errorf("applying preset %s: %w", name, err)
+	}
+	return next, nil
 }
 
 // PresetNotFoundError indicates an unknown preset
@@ -128,3 +305,21 @@ type PresetNotFoundError struct {
 func (e *PresetNotFoundError) Error() string {
 	return "unknown preset: " + e.Name
 }
+
+// PresetBuiltinError indicates a custom preset named like a built-in one
+type PresetBuiltinError struct {
+	Name string
+}
+
+func (e *PresetBuiltinError) Error() string {
+	return "preset name is reserved for a built-in preset: " + e.Name
+}
+
+// PresetCycleError indicates presets that extend each other in a loop
+type PresetCycleError struct {
+	Chain []string
+}
+
+func (e *PresetCycleError) Error() string {
+	return "preset extends cycle: " + strings.Join(e.Chain, " -> ")
+}
@@ -1,6 +1,9 @@
 package feature
 
 import (
+	"errors"
+	"reflect"
+	"slices"
 	"testing"
 )
 
@@ -94,6 +97,43 @@ func TestApplyPresetMinimal(t *testing.T) {
 	}
 }
 
+// TestPresetDiff verifies the diff matches what ApplyPreset changes and
+// leaves the registry alone
+func TestPresetDiff(t *testing.T) {
+	r := NewRegistry()
+	if err := r.ApplyPreset("developer"); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := r.PresetDiff("minimal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(diff.Disable, "vault") || len(diff.Enable) != 0 {
+		t.Errorf("developer -> minimal: expected vault disabled and nothing enabled, got %+v", diff)
+	}
+	if !r.Enabled("vault") {
+		t.Error("PresetDiff changed the registry")
+	}
+
+	if err := r.ApplyPreset("minimal"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range diff.Disable {
+		if r.Enabled(name) {
+			t.Errorf("%s still enabled after applying minimal", name)
+		}
+	}
+	if again, _ := r.PresetDiff("minimal"); !again.Empty() {
+		t.Errorf("expected no changes re-applying minimal, got %+v", again)
+	}
+
+	var notFound *PresetNotFoundError
+	if _, err := r.PresetDiff("nope"); !errors.As(err, &notFound) {
+		t.Errorf("expected PresetNotFoundError, got %v", err)
+	}
+}
+
 // TestApplyPresetDeveloper verifies developer preset
 func TestApplyPresetDeveloper(t *testing.T) {
 	r := NewRegistry()
@@ -191,6 +231,33 @@ func TestPresetResetsState(t *testing.T) {
 	}
 }
 
+// TestApplyPresetAtomic verifies a failed apply leaves the previous state intact
+func TestApplyPresetAtomic(t *testing.T) {
+	presets["broken"] = &Preset{Name: "broken", Features: []string{"vault", "no_such_feature"}}
+	defer delete(presets, "broken")
+
+	r := NewRegistry()
+	if err := r.ApplyPreset("developer"); err != nil {
+		t.Fatalf("ApplyPreset(developer) error = %v", err)
+	}
+	before := r.SaveState()
+
+	err := r.ApplyPreset("broken")
+	var unknown *UnknownFeatureError
+	if !errors.As(err, &unknown) || unknown.Name != "no_such_feature" {
+		t.Fatalf("ApplyPreset(broken) error = %v, want UnknownFeatureError", err)
+	}
+
+	if after := r.SaveState(); !reflect.DeepEqual(after, before) {
+		t.Errorf("state changed by failed apply:\n got  %v\n want %v", after, before)
+	}
+	for _, f := range []string{"vault", "aws_helpers", "cdk_tools"} {
+		if !r.Enabled(f) {
+			t.Errorf("%s should still be enabled from the developer preset", f)
+		}
+	}
+}
+
 // TestMinimalPresetIncludesShell verifies all presets include shell
 func TestAllPresetsIncludeShell(t *testing.T) {
 	for _, preset := range AllPresets() {
@@ -226,3 +293,149 @@ func TestFullPresetHasMoreThanDeveloper(t *testing.T) {
 		t.Error("full preset should have more features than developer")
 	}
 }
+
+// TestBuiltinPresetsUseExtends verifies built-in presets inherit their parents
+func TestBuiltinPresetsUseExtends(t *testing.T) {
+	tests := []struct {
+		name    string
+		extends string
+	}{
+		{"developer", "minimal"},
+		{"full", "developer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			child, _ := GetPreset(tt.name)
+			parent, _ := GetPreset(tt.extends)
+			if child.Extends != tt.extends {
+				t.Errorf("expected %s to extend %s, got %q", tt.name, tt.extends, child.Extends)
+			}
+
+			have := make(map[string]bool)
+			for _, f := range child.Features {
+				if have[f] {
+					t.Errorf("duplicate feature %s in %s", f, tt.name)
+				}
+				have[f] = true
+			}
+			for _, f := range parent.Features {
+				if !have[f] {
+					t.Errorf("%s should inherit %s from %s", tt.name, f, tt.extends)
+				}
+			}
+		})
+	}
+}
+
+// TestResolvePresets verifies extends resolution and error cases
+func TestResolvePresets(t *testing.T) {
+	defs := map[string]*Preset{
+		"base":  {Name: "base", Features: []string{"shell", "vault"}},
+		"mid":   {Name: "mid", Extends: "base", Features: []string{"git_hooks", "shell"}},
+		"child": {Name: "child", Extends: "mid", Features: []string{"templates"}},
+	}
+
+	resolved, err := resolvePresets(defs)
+	if err != nil {
+		t.Fatalf("resolvePresets failed: %v", err)
+	}
+
+	expected := []string{"shell", "vault", "git_hooks", "templates"}
+	got := resolved["child"].Features
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, got)
+			break
+		}
+	}
+
+	// Definitions must not be modified
+	if len(defs["child"].Features) != 1 {
+		t.Error("resolvePresets modified its input")
+	}
+}
+
+// TestResolvePresetsCycle verifies cycles in extends chains are rejected
+func TestResolvePresetsCycle(t *testing.T) {
+	defs := map[string]*Preset{
+		"a": {Name: "a", Extends: "b", Features: []string{"shell"}},
+		"b": {Name: "b", Extends: "c"},
+		"c": {Name: "c", Extends: "a"},
+	}
+
+	_, err := resolvePresets(defs)
+	if _, ok := err.(*PresetCycleError); !ok {
+		t.Fatalf("expected PresetCycleError, got %T (%v)", err, err)
+	}
+
+	self := map[string]*Preset{"a": {Name: "a", Extends: "a"}}
+	if _, err := resolvePresets(self); err == nil {
+		t.Error("expected error for preset extending itself")
+	}
+}
+
+// TestResolvePresetsMissingParent verifies unknown parents are rejected
+func TestResolvePresetsMissingParent(t *testing.T) {
+	defs := map[string]*Preset{
+		"a": {Name: "a", Extends: "missing"},
+	}
+
+	_, err := resolvePresets(defs)
+	if _, ok := err.(*PresetNotFoundError); !ok {
+		t.Errorf("expected PresetNotFoundError, got %T (%v)", err, err)
+	}
+}
+
+// TestSetCustomPresets verifies custom presets resolve alongside built-ins
+func TestSetCustomPresets(t *testing.T) {
+	defer SetCustomPresets(nil)
+
+	err := SetCustomPresets([]*Preset{
+		{Name: "work", Extends: "minimal", Features: []string{"vault"}},
+		{Name: "laptop", Extends: "work", Features: []string{"git_hooks"}},
+	})
+	if err != nil {
+		t.Fatalf("SetCustomPresets failed: %v", err)
+	}
+
+	p, ok := GetPreset("laptop")
+	if !ok {
+		t.Fatal("custom preset 'laptop' not found")
+	}
+	expected := []string{"shell", "config_layers", "vault", "git_hooks"}
+	if !reflect.DeepEqual(p.Features, expected) {
+		t.Errorf("expected %v, got %v", expected, p.Features)
+	}
+
+	names := PresetNames()
+	if got := names[len(names)-2:]; !reflect.DeepEqual(got, []string{"laptop", "work"}) {
+		t.Errorf("expected custom presets last in name order, got %v", names)
+	}
+	if len(AllPresets()) != len(names) {
+		t.Errorf("AllPresets and PresetNames disagree: %d vs %d", len(AllPresets()), len(names))
+	}
+
+	r := NewRegistry()
+	if err := r.ApplyPreset("work"); err != nil {
+		t.Fatalf("ApplyPreset(work) failed: %v", err)
+	}
+	if !r.Enabled("vault") {
+		t.Error("expected vault enabled by custom preset")
+	}
+
+	// Built-in names are reserved; a failed set keeps the previous presets
+	err = SetCustomPresets([]*Preset{{Name: "full", Features: []string{"shell"}}})
+	if _, ok := err.(*PresetBuiltinError); !ok {
+		t.Errorf("expected PresetBuiltinError, got %T (%v)", err, err)
+	}
+	if _, ok := GetPreset("work"); !ok {
+		t.Error("failed SetCustomPresets dropped existing custom presets")
+	}
+	if IsBuiltinPreset("work") || !IsBuiltinPreset("full") {
+		t.Error("IsBuiltinPreset misclassified presets")
+	}
+}
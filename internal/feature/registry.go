@@ -12,6 +12,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // Category represents a feature category
@@ -32,6 +33,25 @@ const (
 	DefaultEnv   DefaultValue = "env"   // Check env var, disabled if not set
 )
 
+// Source is how a feature came to be enabled
+type Source string
+
+const (
+	SourceDefault    Source = "default"    // Core feature or enabled by default
+	SourceDirect     Source = "direct"     // Enabled by name
+	SourceDependency Source = "dependency" // Pulled in by another feature
+	SourcePreset     Source = "preset"     // Listed by an applied preset
+	SourceEnv        Source = "env"        // Turned on by an environment variable
+	SourceConfig     Source = "config"     // In the config file with no recorded reason
+)
+
+// Provenance records why a feature is enabled. By names the feature that
+// required it (SourceDependency) or the preset that listed it (SourcePreset).
+type Provenance struct {
+	Source Source `json:"source"`
+	By     string `json:"by,omitempty"`
+}
+
 // Feature represents a single feature in the registry
 type Feature struct {
 	Name         string
@@ -41,22 +61,29 @@ type Feature struct {
 	Default      DefaultValue
 }
 
-// Registry manages all features
+// Registry manages all features. It is safe for concurrent use: feature
+// definitions are fixed once NewRegistry returns, and mu guards the state
+// that changes.
 type Registry struct {
 	features  map[string]*Feature
-	enabled   map[string]bool
 	conflicts map[string][]string // feature -> conflicting features
 	envMap    map[string]string   // SKIP_* env var -> feature name
+
+	mu         sync.RWMutex
+	enabled    map[string]bool
+	provenance map[string]Provenance // enabled feature -> why it was enabled
+	preset     string                // last applied preset
 }
 
 // NewRegistry creates a registry with all built-in features
 // This mirrors FEATURE_REGISTRY in lib/_features.sh exactly
 func NewRegistry() *Registry {
 	r := &Registry{
-		features:  make(map[string]*Feature),
-		enabled:   make(map[string]bool),
-		conflicts: make(map[string][]string),
-		envMap:    make(map[string]string),
+		features:   make(map[string]*Feature),
+		enabled:    make(map[string]bool),
+		provenance: make(map[string]Provenance),
+		conflicts:  make(map[string][]string),
+		envMap:     make(map[string]string),
 	}
 
 	// ============================================================
@@ -170,6 +197,13 @@ func (r *Registry) ByCategory(cat Category) []*Feature {
 // Enabled checks if a feature is enabled
 // Resolution order: runtime state -> env vars -> config file -> registry default
 func (r *Registry) Enabled(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.isEnabled(name)
+}
+
+// isEnabled is Enabled for callers holding r.mu
+func (r *Registry) isEnabled(name string) bool {
 	f, ok := r.features[name]
 	if !ok {
 		return false
@@ -232,9 +266,17 @@ func (r *Registry) checkEnvOverride(name string) string {
 
 // Enable enables a feature and its dependencies
 func (r *Registry) Enable(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enable(name, Provenance{Source: SourceDirect})
+}
+
+// enable enables a feature, recording prov as the reason, and any disabled
+// dependencies, recorded as required by name. The caller holds r.mu.
+func (r *Registry) enable(name string, prov Provenance) error {
 	f, ok := r.features[name]
 	if !ok {
-		return fmt.Errorf("unknown feature: %s", name)
+		return r.unknownFeature(name)
 	}
 
 	// Check for circular dependencies
@@ -249,14 +291,15 @@ func (r *Registry) Enable(name string) error {
 
 	// Enable dependencies first
 	for _, dep := range f.Dependencies {
-		if !r.Enabled(dep) {
-			if err := r.Enable(dep); err != nil {
+		if !r.isEnabled(dep) {
+			if err := r.enable(dep, Provenance{Source: SourceDependency, By: name}); err != nil {
 				return fmt.Errorf("failed to enable dependency %s: %w", dep, err)
 			}
 		}
 	}
 
 	r.enabled[name] = true
+	r.provenance[name] = prov
 	return nil
 }
 
@@ -264,14 +307,17 @@ func (r *Registry) Enable(name string) error {
 func (r *Registry) Disable(name string) error {
 	f, ok := r.features[name]
 	if !ok {
-		return fmt.Errorf("unknown feature: %s", name)
+		return r.unknownFeature(name)
 	}
 
 	if f.Category == CategoryCore {
 		return fmt.Errorf("cannot disable core feature: %s", name)
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.enabled[name] = false
+	delete(r.provenance, name)
 	return nil
 }
 
@@ -299,7 +345,8 @@ func (r *Registry) detectCircularDep(name string, visited []string) error {
 	return nil
 }
 
-// checkConflicts checks if enabling a feature would create a conflict
+// checkConflicts checks if enabling a feature would create a conflict. The
+// caller holds r.mu.
 func (r *Registry) checkConflicts(name string) error {
 	conflicts, ok := r.conflicts[name]
 	if !ok {
@@ -307,7 +354,7 @@ func (r *Registry) checkConflicts(name string) error {
 	}
 
 	for _, conflict := range conflicts {
-		if r.Enabled(conflict) {
+		if r.isEnabled(conflict) {
 			return fmt.Errorf("cannot enable '%s': conflicts with enabled feature '%s'", name, conflict)
 		}
 	}
@@ -337,6 +384,11 @@ func (r *Registry) Dependents(name string) []string {
 	return result
 }
 
+// Conflicts returns the features that cannot be enabled alongside the given feature
+func (r *Registry) Conflicts(name string) []string {
+	return r.conflicts[name]
+}
+
 // MissingDeps returns missing dependencies for a feature
 func (r *Registry) MissingDeps(name string) []string {
 	f, ok := r.features[name]
@@ -344,9 +396,11 @@ func (r *Registry) MissingDeps(name string) []string {
 		return nil
 	}
 
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var missing []string
 	for _, dep := range f.Dependencies {
-		if !r.Enabled(dep) {
+		if !r.isEnabled(dep) {
 			missing = append(missing, dep)
 		}
 	}
@@ -362,11 +416,13 @@ func (r *Registry) Validate() error {
 	}
 
 	// Check for conflict violations
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	for name := range r.features {
-		if r.Enabled(name) {
+		if r.isEnabled(name) {
 			if conflicts, ok := r.conflicts[name]; ok {
 				for _, conflict := range conflicts {
-					if r.Enabled(conflict) {
+					if r.isEnabled(conflict) {
 						return fmt.Errorf("conflict: '%s' and '%s' are both enabled but mutually exclusive", name, conflict)
 					}
 				}
@@ -379,6 +435,8 @@ func (r *Registry) Validate() error {
 
 // LoadState loads enabled state from a map (e.g., from config file)
 func (r *Registry) LoadState(state map[string]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	for name, enabled := range state {
 		if _, ok := r.features[name]; ok {
 			r.enabled[name] = enabled
@@ -389,6 +447,8 @@ func (r *Registry) LoadState(state map[string]bool) {
 // SaveState returns the current enabled state as a map
 // Only returns non-core features that differ from defaults
 func (r *Registry) SaveState() map[string]bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	result := make(map[string]bool)
 	for name, f := range r.features {
 		if f.Category == CategoryCore {
@@ -404,6 +464,80 @@ func (r *Registry) SaveState() map[string]bool {
 	return result
 }
 
+// Provenance returns why an enabled feature is enabled; the zero value if
+// it is disabled. Features enabled before reasons were recorded report
+// SourceConfig, or SourceEnv/SourceDefault when that explains them.
+func (r *Registry) Provenance(name string) Provenance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.features[name]
+	if !ok || !r.isEnabled(name) {
+		return Provenance{}
+	}
+	if f.Category == CategoryCore {
+		return Provenance{Source: SourceDefault}
+	}
+	if prov, ok := r.provenance[name]; ok {
+		return prov
+	}
+	if _, hasState := r.enabled[name]; !hasState {
+		return Provenance{Source: SourceEnv}
+	}
+	if f.Default == DefaultTrue {
+		return Provenance{Source: SourceDefault}
+	}
+	return Provenance{Source: SourceConfig}
+}
+
+// LastPreset returns the name of the last applied preset, if any
+func (r *Registry) LastPreset() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.preset
+}
+
+// LoadProvenance restores reasons saved by SaveProvenance. Call it after
+// LoadState; reasons for features that are no longer enabled are dropped.
+func (r *Registry) LoadProvenance(preset string, provenance map[string]Provenance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.preset = preset
+	for name, prov := range provenance {
+		if r.enabled[name] {
+			r.provenance[name] = prov
+		}
+	}
+}
+
+// SaveProvenance returns the last applied preset and the recorded reason
+// for each enabled feature
+func (r *Registry) SaveProvenance() (string, map[string]Provenance) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make(map[string]Provenance)
+	for name, prov := range r.provenance {
+		if r.enabled[name] {
+			result[name] = prov
+		}
+	}
+	return r.preset, result
+}
+
+// UnknownFeatureError indicates a feature name that is not in the registry
+type UnknownFeatureError struct {
+	Name  string
+	Valid []string
+}
+
+func (e *UnknownFeatureError) Error() string {
+	return fmt.Sprintf("unknown feature: %s (valid: %s)", e.Name, strings.Join(e.Valid, ", "))
+}
+
+// unknownFeature builds an UnknownFeatureError listing every registered name
+func (r *Registry) unknownFeature(name string) error {
+	return &UnknownFeatureError{Name: name, Valid: r.List("")}
+}
+
 // List returns feature names, optionally filtered by category
 func (r *Registry) List(category string) []string {
 	var result []string
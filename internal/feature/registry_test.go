@@ -1,7 +1,10 @@
 package feature
 
 import (
+	"errors"
 	"os"
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -130,6 +133,24 @@ func TestDisableUnknownFeature(t *testing.T) {
 	}
 }
 
+// TestUnknownFeatureError verifies the typed error lists valid names
+func TestUnknownFeatureError(t *testing.T) {
+	r := NewRegistry()
+
+	for _, fn := range []func(string) error{r.Enable, r.Disable} {
+		var unknown *UnknownFeatureError
+		if err := fn("nonexistent_feature"); !errors.As(err, &unknown) {
+			t.Fatalf("expected *UnknownFeatureError, got %T: %v", err, err)
+		}
+		if unknown.Name != "nonexistent_feature" {
+			t.Errorf("Name = %q, want nonexistent_feature", unknown.Name)
+		}
+		if !slices.Equal(unknown.Valid, r.List("")) {
+			t.Errorf("Valid = %v, want all registered features", unknown.Valid)
+		}
+	}
+}
+
 // TestDependencyEnabling verifies dependencies are auto-enabled
 func TestDependencyEnabling(t *testing.T) {
 	r := NewRegistry()
@@ -336,6 +357,64 @@ func TestSaveState(t *testing.T) {
 	}
 }
 
+// TestProvenance verifies why features are recorded as enabled
+func TestProvenance(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Enable("dotclaude"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want Provenance
+	}{
+		{"dotclaude", Provenance{Source: SourceDirect}},
+		{"claude_integration", Provenance{Source: SourceDependency, By: "dotclaude"}},
+		{"workspace_symlink", Provenance{Source: SourceDependency, By: "claude_integration"}},
+		{"shell", Provenance{Source: SourceDefault}},
+		{"git_hooks", Provenance{Source: SourceDefault}},
+		{"vault", Provenance{}},
+	}
+	for _, tt := range tests {
+		if got := r.Provenance(tt.name); got != tt.want {
+			t.Errorf("Provenance(%s) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// Enabling a dependency by name makes it direct; disabling forgets why
+	r.Enable("claude_integration")
+	if got := r.Provenance("claude_integration"); got.Source != SourceDirect {
+		t.Errorf("expected direct after Enable, got %+v", got)
+	}
+	r.Disable("dotclaude")
+	if got := r.Provenance("dotclaude"); got != (Provenance{}) {
+		t.Errorf("expected no provenance when disabled, got %+v", got)
+	}
+
+	// A preset records itself, and round-trips through Save/LoadProvenance
+	if err := r.ApplyPreset("full"); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Provenance("drift_check"); got != (Provenance{Source: SourcePreset, By: "full"}) {
+		t.Errorf("expected drift_check from preset full, got %+v", got)
+	}
+	preset, saved := r.SaveProvenance()
+
+	loaded := NewRegistry()
+	loaded.LoadState(r.SaveState())
+	loaded.LoadProvenance(preset, saved)
+	if loaded.LastPreset() != "full" || loaded.Provenance("vault") != r.Provenance("vault") {
+		t.Errorf("provenance not restored: preset %q, vault %+v", loaded.LastPreset(), loaded.Provenance("vault"))
+	}
+
+	// Enabled in the config file before reasons were recorded
+	legacy := NewRegistry()
+	legacy.LoadState(map[string]bool{"templates": true})
+	if got := legacy.Provenance("templates"); got.Source != SourceConfig {
+		t.Errorf("expected config source, got %+v", got)
+	}
+}
+
 // TestValidate verifies Validate function
 func TestValidate(t *testing.T) {
 	r := NewRegistry()
@@ -487,3 +566,49 @@ func TestDotclaudeDependsOnClaude(t *testing.T) {
 		t.Error("dotclaude should depend on claude_integration")
 	}
 }
+
+// TestRegistryConcurrentAccess hammers one registry from many goroutines;
+// run with -race (as CI does) to catch unguarded state
+func TestRegistryConcurrentAccess(t *testing.T) {
+	r := NewRegistry()
+	names := r.List("")
+	presetNames := []string{"minimal", "developer", "claude", "full"}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				name := names[(g+i)%len(names)]
+				switch i % 8 {
+				case 0:
+					r.Enable(name)
+				case 1:
+					r.Disable(name)
+				case 2:
+					if err := r.ApplyPreset(presetNames[(g+i)%len(presetNames)]); err != nil {
+						t.Errorf("ApplyPreset failed: %v", err)
+					}
+				case 3:
+					r.Provenance(name)
+					r.LastPreset()
+				case 4:
+					r.LoadState(r.SaveState())
+				case 5:
+					r.LoadProvenance(r.SaveProvenance())
+				case 6:
+					r.MissingDeps(name)
+					r.Validate()
+				default:
+					r.Enabled(name)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !r.Enabled("shell") {
+		t.Error("core feature should stay enabled")
+	}
+}
@@ -1,4 +1,5 @@
 #!/usr/bin/env zsh
+# blackdot: sourced
 # ============================================================
 # FILE: lib/_colors.sh
 # Centralized color theme for blackdot
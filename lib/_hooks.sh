@@ -1,4 +1,5 @@
 #!/usr/bin/env zsh
+# blackdot: sourced
 # ============================================================
 # FILE: lib/_hooks.sh
 # Hook System Library (v3.1)
@@ -1,4 +1,5 @@
 #!/usr/bin/env bash
+# blackdot: sourced
 # ============================================================
 # FILE: lib/_logging.sh
 # Shared logging functions and color definitions for blackdot scripts